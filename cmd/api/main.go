@@ -9,7 +9,11 @@ import (
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/database"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/logger"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/reload"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/shutdown"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/server/api"
 
 	"go.uber.org/fx"
@@ -42,11 +46,21 @@ func main() {
 	app := fx.New(
 		fx.Provide(
 			config.NewConfig,
+			featureflag.New,
 			logger.NewLogger,
 			database.NewDatabase,
+			queue.NewClient,
+			queue.NewTaskRegistry,
 		),
+		fx.Invoke(logger.LogEffectiveConfig),
+		// Registered ahead of the servers so they stop before the DB and queue close
+		shutdown.Module,
+		// Reload the log level and feature flags on SIGHUP
+		reload.Module,
 		api.Module,
 		fx.Invoke(Run),
+		// Last, so its start hook runs once every other one has completed
+		fx.Invoke(logger.LogReady),
 		fx.StartTimeout(config.DefaultStartTimeout),
 		fx.StopTimeout(config.DefaultStopTimeout),
 	)
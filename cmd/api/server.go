@@ -2,19 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/shutdown"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/server/api"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
+	"golang.org/x/net/netutil"
 )
 
 type Server struct {
@@ -38,7 +40,10 @@ func NewServer(cfg *config.Config, logger *zap.Logger, apiServer *api.Server) *S
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
+		// Zero falls back to http.DefaultMaxHeaderBytes
+		MaxHeaderBytes: cfg.Server.MaxHeaderBytes,
 	}
+	server.SetKeepAlivesEnabled(!cfg.Server.DisableKeepAlives)
 
 	return &Server{
 		router:    router,
@@ -53,39 +58,72 @@ func (s *Server) setupRoutes() {
 	s.apiServer.SetupRoutes(s.router)
 }
 
-func Run(lifecycle fx.Lifecycle, cfg *config.Config, logger *zap.Logger, apiServer *api.Server) {
-	server := NewServer(cfg, logger, apiServer)
-	server.setupRoutes()
-
-	lifecycle.Append(fx.Hook{
-		OnStart: func(ctx context.Context) error {
-			go func() {
-				logger.Info("Starting HTTP API api",
-					zap.String("addr", server.server.Addr))
+// listen opens the listener for the configured network. A leftover socket file from an
+// unclean exit is removed first; the listener unlinks the file again when it closes.
+func (s *Server) listen() (net.Listener, string, error) {
+	switch s.config.Server.Network {
+	case "", "tcp":
+		listener, err := net.Listen("tcp", s.server.Addr)
+		return listener, s.server.Addr, err
+	case "unix":
+		path := s.config.Server.UnixSocketPath
+		if path == "" {
+			return nil, "", errors.New("api.unix_socket_path is required when api.network is unix")
+		}
+		if info, err := os.Stat(path); err == nil && info.Mode().Type() == fs.ModeSocket {
+			if err := os.Remove(path); err != nil {
+				return nil, "", err
+			}
+		}
+		listener, err := net.Listen("unix", path)
+		return listener, path, err
+	default:
+		return nil, "", fmt.Errorf("unsupported api.network %q, use tcp or unix", s.config.Server.Network)
+	}
+}
 
-				if err := server.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-					logger.Fatal("Failed to start API api", zap.Error(err))
-				}
-			}()
+// Start opens the listener and serves in the background. Listen errors, such as a port
+// already in use, are returned so the application fails to start.
+func (s *Server) Start() error {
+	listener, addr, err := s.listen()
+	if err != nil {
+		return err
+	}
+	if max := s.config.Server.MaxConnections; max > 0 {
+		listener = netutil.LimitListener(listener, max)
+	}
 
-			go func() {
-				quit := make(chan os.Signal, 1)
-				signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-				<-quit
-				logger.Info("Shutting down API api...")
+	s.logger.Info("HTTP server listening",
+		zap.String("network", listener.Addr().Network()),
+		zap.String("addr", addr))
 
-				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-				defer cancel()
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.logger.Fatal("Failed to start API api", zap.Error(err))
+		}
+	}()
+	return nil
+}
 
-				if err := server.server.Shutdown(ctx); err != nil {
-					logger.Fatal("Server forced to shutdown", zap.Error(err))
-				}
-			}()
+func Run(
+	lifecycle fx.Lifecycle,
+	cfg *config.Config,
+	logger *zap.Logger,
+	apiServer *api.Server,
+	shuttingDown *shutdown.Flag,
+) {
+	server := NewServer(cfg, logger, apiServer)
+	server.setupRoutes()
 
-			return nil
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return server.Start()
 		},
 		OnStop: func(ctx context.Context) error {
+			// Requests still arriving on open connections get 503 while in-flight ones
+			// finish; Shutdown stops accepting connections and waits for those requests
 			logger.Info("Stopping HTTP API api")
+			shuttingDown.Store(true)
 			return server.server.Shutdown(ctx)
 		},
 	})
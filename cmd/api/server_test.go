@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/logger"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newTestServer(t *testing.T, serverCfg config.ServerConfig) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := testutil.NewTestConfig()
+	cfg.Server = serverCfg
+	server := NewServer(cfg, testutil.NewSilentLogger(), nil)
+	server.router.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	return server
+}
+
+func unixClient(path string) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", path)
+		},
+	}}
+}
+
+func TestServer_Start(t *testing.T) {
+	t.Run("should serve requests over a unix socket", func(t *testing.T) {
+		// Setup
+		path := filepath.Join(t.TempDir(), "api.sock")
+		server := newTestServer(t, config.ServerConfig{Network: "unix", UnixSocketPath: path})
+
+		// When
+		require.NoError(t, server.Start())
+		t.Cleanup(func() { _ = server.server.Shutdown(context.Background()) })
+		resp, err := unixClient(path).Get("http://unix/ping")
+
+		// Then
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "pong", string(body))
+	})
+
+	t.Run("should replace a stale socket file and remove it on shutdown", func(t *testing.T) {
+		// Setup: a socket left behind by a process that did not shut down cleanly
+		path := filepath.Join(t.TempDir(), "api.sock")
+		stale, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		stale.(*net.UnixListener).SetUnlinkOnClose(false)
+		require.NoError(t, stale.Close())
+		server := newTestServer(t, config.ServerConfig{Network: "unix", UnixSocketPath: path})
+
+		// When
+		require.NoError(t, server.Start())
+		require.NoError(t, server.server.Shutdown(context.Background()))
+
+		// Then: Serve closes the listener, which unlinks the socket, as it returns
+		assert.Eventually(t, func() bool {
+			_, err := os.Stat(path)
+			return os.IsNotExist(err)
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("should fail to start without a socket path", func(t *testing.T) {
+		server := newTestServer(t, config.ServerConfig{Network: "unix"})
+
+		assert.EqualError(t, server.Start(), "api.unix_socket_path is required when api.network is unix")
+	})
+
+	t.Run("should reject unknown networks", func(t *testing.T) {
+		server := newTestServer(t, config.ServerConfig{Network: "udp"})
+
+		assert.EqualError(t, server.Start(), `unsupported api.network "udp", use tcp or unix`)
+	})
+
+	t.Run("should still listen on tcp by default", func(t *testing.T) {
+		// Setup
+		server := newTestServer(t, config.ServerConfig{Host: "127.0.0.1", Port: freePort(t)})
+
+		// When
+		require.NoError(t, server.Start())
+		t.Cleanup(func() { _ = server.server.Shutdown(context.Background()) })
+		resp, err := http.Get("http://" + server.server.Addr + "/ping")
+
+		// Then
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestServer_Limits(t *testing.T) {
+	// get sends a keep-alive request on conn and reads the response
+	get := func(conn net.Conn, path string) (*http.Response, error) {
+		if _, err := io.WriteString(conn, "GET "+path+" HTTP/1.1\r\nHost: test\r\n\r\n"); err != nil {
+			return nil, err
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if err != nil {
+			return nil, err
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp, resp.Body.Close()
+	}
+
+	t.Run("should queue connections beyond the limit until one closes", func(t *testing.T) {
+		// Setup
+		server := newTestServer(t, config.ServerConfig{
+			Host: "127.0.0.1", Port: freePort(t), IdleTimeout: time.Minute, MaxConnections: 1,
+		})
+		require.NoError(t, server.Start())
+		t.Cleanup(func() { _ = server.server.Shutdown(context.Background()) })
+
+		// Given: one idle keep-alive connection holds the only slot
+		first, err := net.Dial("tcp", server.server.Addr)
+		require.NoError(t, err)
+		resp, err := get(first, "/ping")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// When
+		second, err := net.Dial("tcp", server.server.Addr)
+		require.NoError(t, err)
+		defer second.Close()
+		require.NoError(t, second.SetReadDeadline(time.Now().Add(200*time.Millisecond)))
+		_, err = get(second, "/ping")
+
+		// Then: the second connection is not served while the first is open
+		var netErr net.Error
+		require.ErrorAs(t, err, &netErr)
+		assert.True(t, netErr.Timeout())
+
+		// When: the first connection closes, the waiting one is accepted
+		require.NoError(t, first.Close())
+		require.NoError(t, second.SetReadDeadline(time.Now().Add(5*time.Second)))
+		resp, err = http.ReadResponse(bufio.NewReader(second), nil)
+
+		// Then
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("should reject headers over the limit", func(t *testing.T) {
+		// Setup
+		server := newTestServer(t, config.ServerConfig{Host: "127.0.0.1", Port: freePort(t), MaxHeaderBytes: 1024})
+		require.NoError(t, server.Start())
+		t.Cleanup(func() { _ = server.server.Shutdown(context.Background()) })
+
+		req, err := http.NewRequest("GET", "http://"+server.server.Addr+"/ping", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Padding", strings.Repeat("a", 8<<10))
+
+		// When
+		resp, err := http.DefaultClient.Do(req)
+
+		// Then
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+	})
+
+	t.Run("should close connections after each request when keep-alives are disabled", func(t *testing.T) {
+		// Setup
+		server := newTestServer(t, config.ServerConfig{Host: "127.0.0.1", Port: freePort(t), DisableKeepAlives: true})
+		require.NoError(t, server.Start())
+		t.Cleanup(func() { _ = server.server.Shutdown(context.Background()) })
+		conn, err := net.Dial("tcp", server.server.Addr)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		// When
+		resp, err := get(conn, "/ping")
+
+		// Then
+		require.NoError(t, err)
+		assert.True(t, resp.Close)
+	})
+}
+
+func TestServer_LifecycleLogs(t *testing.T) {
+	t.Run("should log application ready after the server is listening", func(t *testing.T) {
+		// Setup
+		gin.SetMode(gin.TestMode)
+		core, logs := observer.New(zap.InfoLevel)
+		cfg := testutil.NewTestConfig()
+		cfg.Server = config.ServerConfig{Host: "127.0.0.1", Port: freePort(t)}
+
+		app := fxtest.New(t,
+			fx.Supply(cfg, zap.New(core)),
+			fx.Invoke(func(lifecycle fx.Lifecycle, cfg *config.Config, log *zap.Logger) {
+				server := NewServer(cfg, log, nil)
+				lifecycle.Append(fx.Hook{
+					OnStart: func(context.Context) error { return server.Start() },
+					OnStop:  server.server.Shutdown,
+				})
+			}),
+			fx.Invoke(logger.LogReady),
+		)
+
+		// When
+		app.RequireStart()
+		defer app.RequireStop()
+
+		// Then
+		var messages []string
+		for _, entry := range logs.All() {
+			messages = append(messages, entry.Message)
+		}
+		require.Equal(t, []string{"HTTP server listening", "Application ready"}, messages)
+		listening := logs.FilterMessage("HTTP server listening").All()[0].ContextMap()
+		assert.Equal(t, "tcp", listening["network"])
+		assert.Equal(t, cfg.Server.Host+":"+strconv.Itoa(cfg.Server.Port), listening["addr"])
+	})
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
@@ -10,7 +10,10 @@ import (
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/database"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/logger"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/shutdown"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/server/grpc"
 
 	"go.uber.org/fx"
@@ -25,13 +28,21 @@ func main() {
 	app := fx.New(
 		fx.Provide(
 			config.NewConfig,
+			featureflag.New,
 			logger.NewLogger,
 			database.NewDatabase,
+			queue.NewClient,
+			queue.NewTaskRegistry,
 		),
+		fx.Invoke(logger.LogEffectiveConfig),
+		// Registered ahead of the servers so they stop before the DB and queue close
+		shutdown.Module,
 		grpc.Module,
 		fx.Invoke(func(lifecycle fx.Lifecycle, grpcServer *grpc.Server) {
 			runGRPCServer(lifecycle, grpcServer, *port)
 		}),
+		// Last, so its start hook runs once every other one has completed
+		fx.Invoke(logger.LogReady),
 		fx.StartTimeout(config.DefaultStartTimeout),
 		fx.StopTimeout(config.DefaultStopTimeout),
 	)
@@ -61,13 +72,7 @@ func main() {
 func runGRPCServer(lifecycle fx.Lifecycle, server *grpc.Server, port string) {
 	lifecycle.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			go func() {
-				if err := server.Start(port); err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to start gRPC api: %v\n", err)
-					os.Exit(1)
-				}
-			}()
-			return nil
+			return server.Start(port)
 		},
 		OnStop: func(ctx context.Context) error {
 			server.Stop()
@@ -18,7 +18,7 @@ import (
 
 func main() {
 	var (
-		action = flag.String("action", "migrate", "Action to perform: migrate, seed, drop")
+		action = flag.String("action", "migrate", "Action to perform: migrate, seed, drop, backfill-wallets")
 	)
 	flag.Parse()
 
@@ -39,6 +39,7 @@ func main() {
 			logger.NewLogger,
 			database.NewDatabase,
 		),
+		fx.Invoke(logger.LogEffectiveConfig),
 		migration.Module,
 		fx.Invoke(func(migrationServer *migration.Server) {
 			runMigration(ctx, migrationServer, *action)
@@ -69,8 +70,13 @@ func runMigration(ctx context.Context, server *migration.Server, action string)
 	case "drop":
 		fmt.Println("Dropping database tables...")
 		err = server.DropTables()
+	case "backfill-wallets":
+		fmt.Println("Backfilling wallets for users without one...")
+		var created int
+		created, err = server.BackfillWallets(ctx)
+		fmt.Printf("Created %d wallets\n", created)
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown action: %s. Available actions: migrate, seed, drop\n", action)
+		fmt.Fprintf(os.Stderr, "Unknown action: %s. Available actions: migrate, seed, drop, backfill-wallets\n", action)
 		os.Exit(1)
 	}
 
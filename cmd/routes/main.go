@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/server/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Prints the routes the API server registers with the current configuration, one
+// "METHOD PATH" per line, without connecting to any dependency
+func main() {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	if err := printRoutes(os.Stdout, api.Routes(cfg)); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to print routes: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printRoutes(w io.Writer, routes []gin.RouteInfo) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, route := range routes {
+		fmt.Fprintf(table, "%s\t%s\n", route.Method, route.Path)
+	}
+	return table.Flush()
+}
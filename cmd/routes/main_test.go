@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/server/api"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintRoutes(t *testing.T) {
+	t.Run("should list the core API routes", func(t *testing.T) {
+		// Setup
+		gin.SetMode(gin.TestMode)
+		var out bytes.Buffer
+
+		// When
+		require.NoError(t, printRoutes(&out, api.Routes(testutil.NewTestConfig())))
+
+		// Then
+		lines := make(map[string]bool)
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			lines[strings.Join(strings.Fields(line), " ")] = true
+		}
+		for _, route := range []string{
+			"GET /api/v1/health",
+			"GET /api/v1/health/ready",
+			"POST /api/v1/users",
+			"GET /api/v1/users/:id",
+			"POST /api/v1/payments",
+			"PUT /api/v1/payments/:id",
+			"POST /api/v1/payments/:id/refund",
+			"POST /api/v1/payments/webhook/:gateway",
+			"POST /api/v1/wallets/:id/deposit",
+			"GET /api/v1/admin/tasks",
+			"GET /swagger/*any",
+		} {
+			assert.True(t, lines[route], "route %s not listed", route)
+		}
+	})
+
+	t.Run("should list pprof routes only when enabled", func(t *testing.T) {
+		// Setup
+		gin.SetMode(gin.TestMode)
+		cfg := testutil.NewTestConfig()
+		var disabled, enabled bytes.Buffer
+
+		// When
+		require.NoError(t, printRoutes(&disabled, api.Routes(cfg)))
+		cfg.Server.EnablePprof = true
+		require.NoError(t, printRoutes(&enabled, api.Routes(cfg)))
+
+		// Then
+		assert.NotContains(t, disabled.String(), "/debug/pprof/")
+		assert.Contains(t, enabled.String(), "/debug/pprof/")
+	})
+}
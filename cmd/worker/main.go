@@ -9,26 +9,52 @@ import (
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/database"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/logger"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/outbox"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/reload"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/shutdown"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/server/worker"
 
 	"go.uber.org/fx"
 )
 
 func main() {
+	// Loaded ahead of the app, since the stop timeout depends on the drain timeouts
+	cfg, err := config.NewConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
 	app := fx.New(
+		fx.Supply(cfg),
 		fx.Provide(
-			config.NewConfig,
+			featureflag.New,
 			logger.NewLogger,
 			database.NewDatabase,
 			queue.NewClient,
+			queue.NewTaskRegistry,
 			queue.NewServer,
+			queue.NewScheduler,
+			outbox.NewRelay,
+			// Provide the queue client as the outbox relay's Enqueuer
+			func(client *queue.Client) outbox.Enqueuer {
+				return client
+			},
 		),
+		fx.Invoke(logger.LogEffectiveConfig),
+		// Registered ahead of the servers so they stop before the DB and queue close
+		shutdown.Module,
+		// Reload the log level and feature flags on SIGHUP
+		reload.Module,
 		worker.Module,
 		fx.Invoke(runWorker),
+		// Last, so its start hook runs once every other one has completed
+		fx.Invoke(logger.LogReady),
 		fx.StartTimeout(config.DefaultStartTimeout),
-		fx.StopTimeout(config.DefaultStopTimeout),
+		fx.StopTimeout(queue.StopTimeout(cfg)),
 	)
 
 	ctx := context.Background()
@@ -45,7 +71,11 @@ func main() {
 	<-sigChan
 	fmt.Println("\nReceived shutdown signal, stopping worker gracefully...")
 
-	if err := app.Stop(ctx); err != nil {
+	// Bounded by the stop timeout, which leaves room for the longest queue drain
+	stopCtx, cancel := context.WithTimeout(ctx, app.StopTimeout())
+	err = app.Stop(stopCtx)
+	cancel()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to stop worker application gracefully: %v\n", err)
 		os.Exit(1)
 	}
@@ -53,10 +83,22 @@ func main() {
 	fmt.Println("Worker stopped successfully")
 }
 
-func runWorker(lifecycle fx.Lifecycle, workerServer *worker.Server, queueServer *queue.Server) {
+func runWorker(
+	lifecycle fx.Lifecycle,
+	workerServer *worker.Server,
+	queueServer *queue.Server,
+	scheduler *queue.Scheduler,
+	relay *outbox.Relay,
+) {
 	// Register worker handlers
 	workerServer.RegisterHandlers()
 
 	// Start the queue api (it manages its own lifecycle)
 	queueServer.Start(lifecycle)
+
+	// Enqueue the periodic tasks
+	scheduler.Start(lifecycle)
+
+	// Publish outbox messages written by the API
+	relay.Start(lifecycle)
 }
@@ -1,30 +1,132 @@
 package dto
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/money"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
 )
 
 type CreatePaymentRequest struct {
-	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	Amount      float64 `json:"amount" binding:"required,gt=0,finite"`
 	Currency    string  `json:"currency" binding:"required,len=3"`
 	Description string  `json:"description" binding:"required"`
 	UserID      uint    `json:"user_id" binding:"required"`
+	// Tags categorize the payment; they are stored trimmed and lowercased
+	Tags []string `json:"tags" binding:"omitempty,max=10,dive,required,max=50"`
 }
 
+// UpdatePaymentRequest only applies the fields it sets, so a description can change
+// without resending the status; a request setting neither is rejected
 type UpdatePaymentRequest struct {
-	Status      string `json:"status" binding:"required,oneof=pending completed failed canceled"`
+	Status      string `json:"status" binding:"omitempty,oneof=pending completed failed canceled"`
 	Description string `json:"description"`
+	// Actor is recorded in the status history; it is set by internal callers only
+	Actor string `json:"-"`
+}
+
+// RefundPaymentRequest refunds all or part of a payment. DryRun comes from the dry_run
+// query parameter and validates the refund without saving it.
+type RefundPaymentRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0,finite"`
+	DryRun bool    `json:"-"`
 }
 
 type PaymentResponse struct {
-	ID          uint      `json:"id"`
-	Amount      float64   `json:"amount"`
-	Currency    string    `json:"currency"`
-	Status      string    `json:"status"`
-	Description string    `json:"description"`
-	UserID      uint      `json:"user_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              uint      `json:"id"`
+	ReferenceNumber string    `json:"reference_number"`
+	Amount          float64   `json:"amount"`
+	RefundedAmount  float64   `json:"refunded_amount"`
+	Currency        string    `json:"currency"`
+	Status          string    `json:"status"`
+	Description     string    `json:"description"`
+	UserID          uint      `json:"user_id"`
+	Tags            []string  `json:"tags"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	// FormattedAmount is the amount for display, e.g. "$100.50"; only set when requested
+	FormattedAmount string `json:"formatted_amount,omitempty"`
+	// User is only populated when the user is expanded
+	User *PaymentUserResponse `json:"user,omitempty"`
+	// DeletedAt is only set on the deleted payments an updated_since listing returns
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// PaymentUserResponse is the user embedded in an expanded payment
+type PaymentUserResponse struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type PaymentReceiptResponse struct {
+	ReceiptNumber string    `json:"receipt_number"`
+	PaymentID     uint      `json:"payment_id"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency"`
+	Status        string    `json:"status"`
+	Description   string    `json:"description"`
+	UserID        uint      `json:"user_id"`
+	UserName      string    `json:"user_name"`
+	UserEmail     string    `json:"user_email"`
+	PaidAt        time.Time `json:"paid_at"`
+	IssuedAt      time.Time `json:"issued_at"`
+}
+
+// PaymentStatusChangeResponse is one transition in a payment's status history
+type PaymentStatusChangeResponse struct {
+	FromStatus string    `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status"`
+	Actor      string    `json:"actor"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// BulkStatusUpdateRequest moves several payments to one status
+type BulkStatusUpdateRequest struct {
+	IDs    []uint `json:"ids" binding:"required,min=1,max=100,dive,gt=0"`
+	Status string `json:"status" binding:"required,oneof=pending completed failed canceled"`
+}
+
+// BulkStatusResult is the outcome of a bulk status update for one payment
+type BulkStatusResult struct {
+	ID         uint   `json:"id"`
+	Updated    bool   `json:"updated"`
+	FromStatus string `json:"from_status,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// BulkStatusUpdateResponse reports which payments a bulk status update moved
+type BulkStatusUpdateResponse struct {
+	Status  string             `json:"status"`
+	Updated int                `json:"updated"`
+	Failed  int                `json:"failed"`
+	Results []BulkStatusResult `json:"results"`
+}
+
+// BulkDeleteRequest soft-deletes several payments. Force comes from the force query
+// parameter and allows completed payments to be deleted.
+type BulkDeleteRequest struct {
+	IDs   []uint `json:"ids" binding:"required,min=1,max=100,dive,gt=0"`
+	Force bool   `json:"-"`
+}
+
+// BulkDeleteResult is the outcome of a bulk delete for one payment
+type BulkDeleteResult struct {
+	ID      uint   `json:"id"`
+	Deleted bool   `json:"deleted"`
+	Status  string `json:"status,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkDeleteResponse reports which payments a bulk delete removed
+type BulkDeleteResponse struct {
+	Deleted int                `json:"deleted"`
+	Failed  int                `json:"failed"`
+	Results []BulkDeleteResult `json:"results"`
 }
 
 type PaymentListResponse struct {
@@ -34,10 +136,164 @@ type PaymentListResponse struct {
 	PageSize   int               `json:"page_size"`
 }
 
+// InLocation formats created_at, updated_at and deleted_at in loc
+func (r *PaymentResponse) InLocation(loc *time.Location) {
+	r.CreatedAt = r.CreatedAt.In(loc)
+	r.UpdatedAt = r.UpdatedAt.In(loc)
+	if r.DeletedAt != nil {
+		deletedAt := r.DeletedAt.In(loc)
+		r.DeletedAt = &deletedAt
+	}
+}
+
+// InLocation formats every payment's timestamps in loc
+func (r *PaymentListResponse) InLocation(loc *time.Location) {
+	for i := range r.Data {
+		r.Data[i].InLocation(loc)
+	}
+}
+
+// FormatAmount sets formatted_amount from amount and currency
+func (r *PaymentResponse) FormatAmount() {
+	r.FormattedAmount = money.Format(r.Amount, r.Currency)
+}
+
+// FormatAmounts sets formatted_amount on every payment
+func (r *PaymentListResponse) FormatAmounts() {
+	for i := range r.Data {
+		r.Data[i].FormatAmount()
+	}
+}
+
+// LastModified returns the latest updated_at or deleted_at on the page, zero for an
+// empty page
+func (r *PaymentListResponse) LastModified() time.Time {
+	var latest time.Time
+	for _, payment := range r.Data {
+		if payment.UpdatedAt.After(latest) {
+			latest = payment.UpdatedAt
+		}
+		if payment.DeletedAt != nil && payment.DeletedAt.After(latest) {
+			latest = *payment.DeletedAt
+		}
+	}
+	return latest
+}
+
+// paymentFields are the JSON names a fields parameter may select
+var paymentFields = map[string]bool{
+	"id":               true,
+	"reference_number": true,
+	"amount":           true,
+	"refunded_amount":  true,
+	"currency":         true,
+	"status":           true,
+	"description":      true,
+	"user_id":          true,
+	"tags":             true,
+	"created_at":       true,
+	"updated_at":       true,
+	"formatted_amount": true,
+	"user":             true,
+	"deleted_at":       true,
+}
+
+// ParsePaymentFields splits a comma-separated fields value such as "id,amount,status".
+// An empty value selects every field and returns nil.
+func ParsePaymentFields(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var fields []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if !paymentFields[name] {
+			return nil, apperror.New(apperror.CodeInvalidFields, fmt.Sprintf("unknown field %q", name))
+		}
+		if !seen[name] {
+			seen[name] = true
+			fields = append(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+// SelectFields returns only the given fields of the payment, keyed by their JSON names.
+// Fields left out of the full representation, such as an unexpanded user, stay absent.
+func (r *PaymentResponse) SelectFields(fields []string) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &all); err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			selected[field] = value
+		}
+	}
+	return selected, nil
+}
+
+// SparsePaymentListResponse is a page of payments trimmed to the requested fields
+type SparsePaymentListResponse struct {
+	Data       []map[string]json.RawMessage `json:"data"`
+	TotalCount int64                        `json:"total_count"`
+	Page       int                          `json:"page"`
+	PageSize   int                          `json:"page_size"`
+}
+
+// SelectFields trims every payment on the page to the given fields
+func (r *PaymentListResponse) SelectFields(fields []string) (*SparsePaymentListResponse, error) {
+	data := make([]map[string]json.RawMessage, 0, len(r.Data))
+	for i := range r.Data {
+		selected, err := r.Data[i].SelectFields(fields)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, selected)
+	}
+	return &SparsePaymentListResponse{
+		Data:       data,
+		TotalCount: r.TotalCount,
+		Page:       r.Page,
+		PageSize:   r.PageSize,
+	}, nil
+}
+
 type PaymentFilter struct {
 	Status   string `form:"status"`
 	Currency string `form:"currency"`
 	UserID   uint   `form:"user_id"`
-	Page     int    `form:"page"`
-	PageSize int    `form:"page_size"`
+	// Tag keeps only payments carrying this tag
+	Tag string `form:"tag"`
+	pagination.Pagination
+	// Expand embeds related resources; "user" adds each payment's user
+	Expand string `form:"expand" binding:"omitempty,oneof=user"`
+	// UpdatedSince, an RFC 3339 time, turns the listing into a delta: payments changed at
+	// or after it, oldest change first, with deleted ones included as tombstones
+	UpdatedSince time.Time `form:"updated_since"`
+}
+
+// GatewayEvent is a gateway callback reduced to the payment it concerns and the
+// status the gateway reports for it
+type GatewayEvent struct {
+	Gateway   string
+	ID        string
+	PaymentID uint
+	Status    string
+}
+
+type GatewayEventResponse struct {
+	EventID   string `json:"event_id"`
+	PaymentID uint   `json:"payment_id"`
+	Status    string `json:"status"`
+	// Duplicate is true when the event was processed before, or the payment already had
+	// its status, and nothing changed
+	Duplicate bool `json:"duplicate"`
 }
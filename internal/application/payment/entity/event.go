@@ -0,0 +1,7 @@
+package entity
+
+// Outbox topics recorded alongside payment state changes
+const (
+	// EventPaymentCreated asks the worker to process a newly created payment
+	EventPaymentCreated = "payment.created"
+)
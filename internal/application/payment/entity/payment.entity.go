@@ -1,18 +1,27 @@
 package entity
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type Payment struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	Amount      float64        `json:"amount" gorm:"not null"`
-	Currency    string         `json:"currency" gorm:"size:3;not null"`
-	Status      PaymentStatus  `json:"status" gorm:"default:pending"`
-	Description string         `json:"description" gorm:"size:500"`
-	UserID      uint           `json:"user_id" gorm:"not null"`
+	ID uint `json:"id" gorm:"primaryKey"`
+	// ReferenceNumber is the sequential, human-friendly identifier, e.g. PAY-000123
+	ReferenceNumber string        `json:"reference_number" gorm:"size:20;uniqueIndex:idx_payments_reference_number,where:reference_number <> ''"`
+	Amount          float64       `json:"amount" gorm:"not null"`
+	RefundedAmount  float64       `json:"refunded_amount" gorm:"not null;default:0"`
+	Currency        string        `json:"currency" gorm:"size:3;not null"`
+	Status          PaymentStatus `json:"status" gorm:"default:pending"`
+	Description     string        `json:"description" gorm:"size:500"`
+	UserID          uint          `json:"user_id" gorm:"not null"`
+	Tags            []PaymentTag  `json:"tags,omitempty" gorm:"foreignKey:PaymentID;constraint:OnDelete:CASCADE"`
+	// StatusActor is recorded in the status history when a save changes Status
+	StatusActor string         `json:"-" gorm:"-"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
@@ -25,21 +34,155 @@ const (
 	PaymentStatusCompleted PaymentStatus = "completed"
 	PaymentStatusFailed    PaymentStatus = "failed"
 	PaymentStatusCanceled  PaymentStatus = "canceled"
+	// PaymentStatusPartiallyRefunded marks a completed payment with part of its amount refunded
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded"
+	PaymentStatusRefunded          PaymentStatus = "refunded"
 )
 
+// paymentStatuses lists every valid status; IsValid and PaymentStatuses both read it
+var paymentStatuses = []PaymentStatus{
+	PaymentStatusPending,
+	PaymentStatusCompleted,
+	PaymentStatusFailed,
+	PaymentStatusCanceled,
+	PaymentStatusPartiallyRefunded,
+	PaymentStatusRefunded,
+}
+
+// paymentTransitions is the payment state machine: the statuses each status may move
+// to. Canceled and refunded payments are final.
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending:           {PaymentStatusCompleted, PaymentStatusFailed, PaymentStatusCanceled},
+	PaymentStatusFailed:            {PaymentStatusPending},
+	PaymentStatusCompleted:         {PaymentStatusPartiallyRefunded, PaymentStatusRefunded},
+	PaymentStatusPartiallyRefunded: {PaymentStatusRefunded},
+}
+
+// PaymentStatuses returns all valid payment statuses
+func PaymentStatuses() []PaymentStatus {
+	statuses := make([]PaymentStatus, len(paymentStatuses))
+	copy(statuses, paymentStatuses)
+	return statuses
+}
+
 func (p Payment) TableName() string {
 	return "payments"
 }
 
+// PaymentTag attaches one user-chosen category to a payment
+type PaymentTag struct {
+	PaymentID uint   `json:"payment_id" gorm:"primaryKey"`
+	Tag       string `json:"tag" gorm:"primaryKey;size:50;index"`
+}
+
+func (t PaymentTag) TableName() string {
+	return "payment_tags"
+}
+
+// TagNames returns the payment's tags as plain strings
+func (p Payment) TagNames() []string {
+	names := make([]string, 0, len(p.Tags))
+	for _, tag := range p.Tags {
+		names = append(names, tag.Tag)
+	}
+	return names
+}
+
 func (ps PaymentStatus) String() string {
 	return string(ps)
 }
 
-func (ps PaymentStatus) IsValid() bool {
+// HasReceipt reports whether a payment in this status can be issued a receipt
+func (ps PaymentStatus) HasReceipt() bool {
 	switch ps {
-	case PaymentStatusPending, PaymentStatusCompleted, PaymentStatusFailed, PaymentStatusCanceled:
+	case PaymentStatusCompleted, PaymentStatusPartiallyRefunded, PaymentStatusRefunded:
 		return true
 	default:
 		return false
 	}
 }
+
+// IsRefundable reports whether a (further) refund may be issued from this status
+func (ps PaymentStatus) IsRefundable() bool {
+	return ps == PaymentStatusCompleted || ps == PaymentStatusPartiallyRefunded
+}
+
+// IsSetByRefund reports whether only a refund may move a payment to this status, so
+// that the refunded amount always matches it
+func (ps PaymentStatus) IsSetByRefund() bool {
+	return ps == PaymentStatusPartiallyRefunded || ps == PaymentStatusRefunded
+}
+
+// IsDeleteProtected reports whether deleting a payment in this status needs an explicit force
+func (ps PaymentStatus) IsDeleteProtected() bool {
+	return ps == PaymentStatusCompleted
+}
+
+// CanTransitionTo reports whether a payment in this status may move to next
+func (ps PaymentStatus) CanTransitionTo(next PaymentStatus) bool {
+	for _, allowed := range paymentTransitions[ps] {
+		if next == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (ps PaymentStatus) IsValid() bool {
+	for _, status := range paymentStatuses {
+		if ps == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan implements sql.Scanner, rejecting values that are not a known status
+func (ps *PaymentStatus) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into PaymentStatus", value)
+	}
+
+	status := PaymentStatus(raw)
+	if !status.IsValid() {
+		return fmt.Errorf("invalid payment status %q", raw)
+	}
+
+	*ps = status
+	return nil
+}
+
+// Value implements driver.Valuer
+func (ps PaymentStatus) Value() (driver.Value, error) {
+	return string(ps), nil
+}
+
+// MarshalJSON implements json.Marshaler, refusing to serialize unknown statuses
+func (ps PaymentStatus) MarshalJSON() ([]byte, error) {
+	if !ps.IsValid() {
+		return nil, fmt.Errorf("invalid payment status %q", string(ps))
+	}
+	return json.Marshal(string(ps))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, rejecting unknown statuses
+func (ps *PaymentStatus) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("payment status must be a string: %w", err)
+	}
+
+	status := PaymentStatus(raw)
+	if !status.IsValid() {
+		return fmt.Errorf("invalid payment status %q", raw)
+	}
+
+	*ps = status
+	return nil
+}
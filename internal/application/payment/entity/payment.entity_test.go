@@ -0,0 +1,152 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentStatus_ScanValue(t *testing.T) {
+	t.Run("should round-trip valid statuses", func(t *testing.T) {
+		for _, status := range []PaymentStatus{
+			PaymentStatusPending,
+			PaymentStatusCompleted,
+			PaymentStatusFailed,
+			PaymentStatusCanceled,
+		} {
+			value, err := status.Value()
+			assert.NoError(t, err)
+
+			var scanned PaymentStatus
+			assert.NoError(t, scanned.Scan(value))
+			assert.Equal(t, status, scanned)
+
+			var fromBytes PaymentStatus
+			assert.NoError(t, fromBytes.Scan([]byte(status)))
+			assert.Equal(t, status, fromBytes)
+		}
+	})
+
+	t.Run("should reject unknown status", func(t *testing.T) {
+		var status PaymentStatus
+		err := status.Scan("refunded??")
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid payment status")
+		assert.Empty(t, status)
+	})
+
+	t.Run("should reject unsupported types", func(t *testing.T) {
+		var status PaymentStatus
+		assert.Error(t, status.Scan(42))
+		assert.Error(t, status.Scan(nil))
+	})
+}
+
+func TestPaymentStatus_JSON(t *testing.T) {
+	t.Run("should marshal valid status as string", func(t *testing.T) {
+		data, err := json.Marshal(PaymentStatusCompleted)
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `"completed"`, string(data))
+	})
+
+	t.Run("should refuse to marshal unknown status", func(t *testing.T) {
+		_, err := json.Marshal(PaymentStatus("bogus"))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("should unmarshal valid status", func(t *testing.T) {
+		var status PaymentStatus
+		err := json.Unmarshal([]byte(`"canceled"`), &status)
+
+		assert.NoError(t, err)
+		assert.Equal(t, PaymentStatusCanceled, status)
+	})
+
+	t.Run("should reject unknown status on unmarshal", func(t *testing.T) {
+		var status PaymentStatus
+		err := json.Unmarshal([]byte(`"bogus"`), &status)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid payment status")
+		assert.Empty(t, status)
+	})
+
+	t.Run("should reject non-string values on unmarshal", func(t *testing.T) {
+		var status PaymentStatus
+		assert.Error(t, json.Unmarshal([]byte(`1`), &status))
+		assert.Error(t, json.Unmarshal([]byte(`null`), &status))
+	})
+
+	t.Run("should round-trip payment entity", func(t *testing.T) {
+		payment := Payment{ID: 1, Amount: 10, Currency: "USD", Status: PaymentStatusFailed, UserID: 1}
+		data, err := json.Marshal(payment)
+		assert.NoError(t, err)
+
+		var decoded Payment
+		assert.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, PaymentStatusFailed, decoded.Status)
+	})
+}
+
+func TestPaymentStatuses(t *testing.T) {
+	t.Run("should list each valid status once", func(t *testing.T) {
+		// When
+		statuses := PaymentStatuses()
+
+		// Then
+		seen := make(map[PaymentStatus]bool)
+		for _, status := range statuses {
+			assert.True(t, status.IsValid(), "status %q", status)
+			assert.False(t, seen[status], "duplicate status %q", status)
+			seen[status] = true
+		}
+		assert.Len(t, statuses, 6)
+	})
+
+	t.Run("should return a copy", func(t *testing.T) {
+		// Given
+		statuses := PaymentStatuses()
+
+		// When
+		statuses[0] = "tampered"
+
+		// Then
+		assert.Equal(t, PaymentStatusPending, PaymentStatuses()[0])
+		assert.False(t, PaymentStatus("tampered").IsValid())
+	})
+}
+
+func TestPaymentStatus_CanTransitionTo(t *testing.T) {
+	t.Run("should follow the payment state machine", func(t *testing.T) {
+		cases := []struct {
+			from, to PaymentStatus
+			allowed  bool
+		}{
+			{PaymentStatusPending, PaymentStatusCompleted, true},
+			{PaymentStatusPending, PaymentStatusFailed, true},
+			{PaymentStatusPending, PaymentStatusCanceled, true},
+			{PaymentStatusPending, PaymentStatusRefunded, false},
+			{PaymentStatusFailed, PaymentStatusPending, true},
+			{PaymentStatusFailed, PaymentStatusCompleted, false},
+			{PaymentStatusCompleted, PaymentStatusRefunded, true},
+			{PaymentStatusCompleted, PaymentStatusPending, false},
+			{PaymentStatusPartiallyRefunded, PaymentStatusRefunded, true},
+			{PaymentStatusCanceled, PaymentStatusPending, false},
+			{PaymentStatusRefunded, PaymentStatusCompleted, false},
+		}
+
+		for _, c := range cases {
+			assert.Equal(t, c.allowed, c.from.CanTransitionTo(c.to), "%s -> %s", c.from, c.to)
+		}
+	})
+
+	t.Run("should not allow staying in the same status", func(t *testing.T) {
+		for _, status := range PaymentStatuses() {
+			assert.False(t, status.CanTransitionTo(status), "%s -> %s", status, status)
+		}
+	})
+}
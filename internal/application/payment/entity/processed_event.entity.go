@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// ProcessedEvent records a gateway callback that has been applied, so redeliveries of
+// the same event are recognised and skipped
+type ProcessedEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Gateway   string    `json:"gateway" gorm:"size:50;not null;uniqueIndex:idx_processed_events_gateway_event"`
+	EventID   string    `json:"event_id" gorm:"size:255;not null;uniqueIndex:idx_processed_events_gateway_event"`
+	PaymentID uint      `json:"payment_id" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (e ProcessedEvent) TableName() string {
+	return "processed_events"
+}
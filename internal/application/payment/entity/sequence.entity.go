@@ -0,0 +1,12 @@
+package entity
+
+// PaymentSequence is a named counter incremented inside the transaction that consumes
+// it, so concurrent payments never draw the same value
+type PaymentSequence struct {
+	Name  string `json:"name" gorm:"primaryKey;size:50"`
+	Value uint64 `json:"value" gorm:"not null;default:0"`
+}
+
+func (s PaymentSequence) TableName() string {
+	return "payment_sequences"
+}
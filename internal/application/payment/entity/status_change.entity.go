@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// Actors recorded against a payment status change
+const (
+	StatusActorAPI    = "api"
+	StatusActorWorker = "worker"
+	StatusActorAdmin  = "admin"
+)
+
+// GatewayStatusActor names the gateway whose callback changed a payment's status
+func GatewayStatusActor(gateway string) string {
+	return "gateway:" + gateway
+}
+
+// PaymentStatusChange is one entry in a payment's status history. FromStatus is empty
+// for the entry written when the payment is created.
+type PaymentStatusChange struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	PaymentID  uint      `json:"payment_id" gorm:"not null;index"`
+	FromStatus string    `json:"from_status" gorm:"size:20"`
+	ToStatus   string    `json:"to_status" gorm:"size:20;not null"`
+	Actor      string    `json:"actor" gorm:"size:100;not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (c PaymentStatusChange) TableName() string {
+	return "payment_status_changes"
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentHandler_ErrorCodes(t *testing.T) {
+	validPayment := `{"amount":10,"currency":"USD","description":"Order","user_id":1}`
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		mock   func(m *MockPaymentService)
+		status int
+		code   string
+	}{
+		{
+			name:   "amount exceeds currency precision",
+			method: "POST",
+			path:   "/payments",
+			body:   validPayment,
+			mock: func(m *MockPaymentService) {
+				m.On("CreatePayment", mock.Anything).Return(nil, apperror.New(apperror.CodeAmountPrecision, "amount exceeds currency precision"))
+			},
+			status: http.StatusUnprocessableEntity,
+			code:   apperror.CodeValidationFailed,
+		},
+		{
+			name:   "active payment limit reached",
+			method: "POST",
+			path:   "/payments",
+			body:   validPayment,
+			mock: func(m *MockPaymentService) {
+				m.On("CreatePayment", mock.Anything).Return(nil, apperror.New(apperror.CodeActivePaymentLimit, "active payment limit reached"))
+			},
+			status: http.StatusTooManyRequests,
+			code:   apperror.CodeActivePaymentLimit,
+		},
+		{
+			name:   "no wallet in payment currency",
+			method: "POST",
+			path:   "/payments",
+			body:   validPayment,
+			mock: func(m *MockPaymentService) {
+				m.On("CreatePayment", mock.Anything).Return(nil, apperror.New(apperror.CodeCurrencyMismatch, "user has no wallet in the payment currency"))
+			},
+			status: http.StatusUnprocessableEntity,
+			code:   apperror.CodeCurrencyMismatch,
+		},
+		{
+			name:   "invalid payment ID",
+			method: "GET",
+			path:   "/payments/abc",
+			status: http.StatusBadRequest,
+			code:   apperror.CodeInvalidID,
+		},
+		{
+			name:   "payment not found",
+			method: "GET",
+			path:   "/payments/99",
+			mock: func(m *MockPaymentService) {
+				m.On("GetPaymentByID", uint(99)).Return(nil, apperror.New(apperror.CodePaymentNotFound, "payment not found"))
+			},
+			status: http.StatusNotFound,
+			code:   apperror.CodePaymentNotFound,
+		},
+		{
+			name:   "update of a missing payment",
+			method: "PUT",
+			path:   "/payments/99",
+			body:   `{"status":"completed"}`,
+			mock: func(m *MockPaymentService) {
+				m.On("UpdatePayment", uint(99), mock.Anything).Return(nil, apperror.New(apperror.CodePaymentNotFound, "payment not found"))
+			},
+			status: http.StatusNotFound,
+			code:   apperror.CodePaymentNotFound,
+		},
+		{
+			name:   "update with an invalid status",
+			method: "PATCH",
+			path:   "/payments/1",
+			body:   `{"status":"completed"}`,
+			mock: func(m *MockPaymentService) {
+				m.On("UpdatePayment", uint(1), mock.Anything).Return(nil, apperror.New(apperror.CodeInvalidPaymentStatus, "invalid payment status"))
+			},
+			status: http.StatusBadRequest,
+			code:   apperror.CodeInvalidPaymentStatus,
+		},
+		{
+			name:   "update against the state machine",
+			method: "PUT",
+			path:   "/payments/1",
+			body:   `{"status":"pending"}`,
+			mock: func(m *MockPaymentService) {
+				m.On("UpdatePayment", uint(1), mock.Anything).Return(nil, apperror.New(apperror.CodeInvalidStatusTransition, "payment cannot move to the requested status"))
+			},
+			status: http.StatusConflict,
+			code:   apperror.CodeInvalidStatusTransition,
+		},
+		{
+			name:   "update with no fields",
+			method: "PATCH",
+			path:   "/payments/1",
+			body:   `{}`,
+			mock: func(m *MockPaymentService) {
+				m.On("UpdatePayment", uint(1), mock.Anything).Return(nil, apperror.New(apperror.CodeEmptyUpdate, "no fields to update"))
+			},
+			status: http.StatusBadRequest,
+			code:   apperror.CodeEmptyUpdate,
+		},
+		{
+			name:   "receipt not available",
+			method: "GET",
+			path:   "/payments/1/receipt",
+			mock: func(m *MockPaymentService) {
+				m.On("GetPaymentReceipt", uint(1)).Return(nil, apperror.New(apperror.CodeReceiptNotAvailable, "receipt not available for payment status"))
+			},
+			status: http.StatusConflict,
+			code:   apperror.CodeReceiptNotAvailable,
+		},
+		{
+			name:   "payment is not refundable",
+			method: "POST",
+			path:   "/payments/1/refund",
+			body:   `{"amount":5}`,
+			mock: func(m *MockPaymentService) {
+				m.On("RefundPayment", uint(1), mock.Anything).Return(nil, apperror.New(apperror.CodePaymentNotRefundable, "payment is not refundable"))
+			},
+			status: http.StatusConflict,
+			code:   apperror.CodePaymentNotRefundable,
+		},
+		{
+			name:   "refund exceeds refundable amount",
+			method: "POST",
+			path:   "/payments/1/refund",
+			body:   `{"amount":500}`,
+			mock: func(m *MockPaymentService) {
+				m.On("RefundPayment", uint(1), mock.Anything).Return(nil, apperror.New(apperror.CodeRefundExceedsAmount, "refund amount exceeds refundable amount"))
+			},
+			status: http.StatusBadRequest,
+			code:   apperror.CodeRefundExceedsAmount,
+		},
+		{
+			name:   "refund more precise than the currency",
+			method: "POST",
+			path:   "/payments/1/refund",
+			body:   `{"amount":10.125}`,
+			mock: func(m *MockPaymentService) {
+				m.On("RefundPayment", uint(1), mock.Anything).Return(nil, apperror.New(apperror.CodeAmountPrecision, "amount exceeds currency precision"))
+			},
+			status: http.StatusUnprocessableEntity,
+			code:   apperror.CodeValidationFailed,
+		},
+		{
+			name:   "history of a missing payment",
+			method: "GET",
+			path:   "/payments/99/history",
+			mock: func(m *MockPaymentService) {
+				m.On("GetPaymentHistory", uint(99)).Return(nil, apperror.New(apperror.CodePaymentNotFound, "payment not found"))
+			},
+			status: http.StatusNotFound,
+			code:   apperror.CodePaymentNotFound,
+		},
+		{
+			name:   "unexpected failure",
+			method: "DELETE",
+			path:   "/payments/1",
+			mock: func(m *MockPaymentService) {
+				m.On("DeletePayment", uint(1)).Return(assert.AnError)
+			},
+			status: http.StatusInternalServerError,
+			code:   apperror.CodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			handler, mockService := setupPaymentHandler()
+			if tt.mock != nil {
+				tt.mock(mockService)
+			}
+			router := gin.New()
+			handler.RegisterRoutes(router.Group(""))
+
+			// Given
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			// When
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Then
+			assert.Equal(t, tt.status, w.Code)
+			var result map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Equal(t, tt.code, result["code"])
+			assert.NotEmpty(t, result["error"])
+		})
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/service"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/validation"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
@@ -31,6 +34,10 @@ func (h *PaymentGrpcHandler) CreatePayment(
 	ctx context.Context,
 	req *payment.CreatePaymentRequest,
 ) (*payment.CreatePaymentResponse, error) {
+	if !validation.IsFinite(req.Amount) || req.Amount <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be a finite number greater than 0")
+	}
+
 	createReq := &dto.CreatePaymentRequest{
 		Amount:      req.Amount,
 		Currency:    req.Currency,
@@ -41,6 +48,12 @@ func (h *PaymentGrpcHandler) CreatePayment(
 	paymentResponse, err := h.paymentService.CreatePayment(createReq)
 	if err != nil {
 		h.logger.Error("Failed to create payment via gRPC", zap.Error(err))
+		switch apperror.Code(err) {
+		case apperror.CodeAmountPrecision, apperror.CodeUnsupportedCurrency:
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case apperror.CodeCurrencyMismatch:
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
 		return nil, status.Errorf(codes.Internal, "failed to create payment: %v", err)
 	}
 
@@ -68,19 +81,12 @@ func (h *PaymentGrpcHandler) ListPayments(
 	ctx context.Context,
 	req *payment.ListPaymentsRequest,
 ) (*payment.ListPaymentsResponse, error) {
+	// Page defaults and limits are applied by the service
 	page := int(req.Page)
 	pageSize := int(req.PageSize)
 
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 10
-	}
-
 	filter := &dto.PaymentFilter{
-		Page:     page,
-		PageSize: pageSize,
+		Pagination: pagination.Pagination{Page: page, PageSize: pageSize},
 	}
 
 	// Add status filter if provided
@@ -128,6 +134,14 @@ func (h *PaymentGrpcHandler) UpdatePayment(
 	paymentResponse, err := h.paymentService.UpdatePayment(uint(req.Id), updateReq)
 	if err != nil {
 		h.logger.Error("Failed to update payment via gRPC", zap.Uint32("id", req.Id), zap.Error(err))
+		switch apperror.Code(err) {
+		case apperror.CodePaymentNotFound:
+			return nil, status.Error(codes.NotFound, err.Error())
+		case apperror.CodeEmptyUpdate, apperror.CodeInvalidPaymentStatus:
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case apperror.CodeInvalidStatusTransition:
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
 		return nil, status.Errorf(codes.Internal, "failed to update payment: %v", err)
 	}
 
@@ -155,20 +169,13 @@ func (h *PaymentGrpcHandler) GetUserPayments(
 	ctx context.Context,
 	req *payment.GetUserPaymentsRequest,
 ) (*payment.GetUserPaymentsResponse, error) {
+	// Page defaults and limits are applied by the service
 	page := int(req.Page)
 	pageSize := int(req.PageSize)
 
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 10
-	}
-
 	filter := &dto.PaymentFilter{
-		Page:     page,
-		PageSize: pageSize,
-		UserID:   uint(req.UserId),
+		Pagination: pagination.Pagination{Page: page, PageSize: pageSize},
+		UserID:     uint(req.UserId),
 	}
 
 	listResponse, err := h.paymentService.GetPayments(filter)
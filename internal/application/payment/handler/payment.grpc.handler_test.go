@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/api/proto/payment"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func setupPaymentGrpcHandler() (*PaymentGrpcHandler, *MockPaymentService) {
+	mockService := &MockPaymentService{}
+	handler := NewPaymentGrpcHandler(mockService, testutil.NewSilentLogger())
+	return handler, mockService
+}
+
+func TestPaymentGrpcHandler_ErrorCodes(t *testing.T) {
+	createPayment := func(h *PaymentGrpcHandler) error {
+		_, err := h.CreatePayment(context.Background(), &payment.CreatePaymentRequest{Amount: 10, Currency: "XYZ", UserId: 1})
+		return err
+	}
+	updatePayment := func(h *PaymentGrpcHandler) error {
+		_, err := h.UpdatePayment(context.Background(), &payment.UpdatePaymentRequest{
+			Id:     1,
+			Status: payment.PaymentStatus_PAYMENT_STATUS_PENDING,
+		})
+		return err
+	}
+
+	tests := []struct {
+		name string
+		call func(h *PaymentGrpcHandler) error
+		mock func(m *MockPaymentService)
+		code codes.Code
+	}{
+		{
+			name: "unsupported currency on create",
+			call: createPayment,
+			mock: func(m *MockPaymentService) {
+				m.On("CreatePayment", mock.Anything).Return(nil, apperror.New(apperror.CodeUnsupportedCurrency, "unsupported currency"))
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "over-precise amount on create",
+			call: createPayment,
+			mock: func(m *MockPaymentService) {
+				m.On("CreatePayment", mock.Anything).Return(nil, apperror.New(apperror.CodeAmountPrecision, "amount exceeds currency precision"))
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "no wallet in the currency on create",
+			call: createPayment,
+			mock: func(m *MockPaymentService) {
+				m.On("CreatePayment", mock.Anything).Return(nil, apperror.New(apperror.CodeCurrencyMismatch, "user has no wallet in the payment currency"))
+			},
+			code: codes.FailedPrecondition,
+		},
+		{
+			name: "unexpected failure on create",
+			call: createPayment,
+			mock: func(m *MockPaymentService) {
+				m.On("CreatePayment", mock.Anything).Return(nil, assert.AnError)
+			},
+			code: codes.Internal,
+		},
+		{
+			name: "missing payment on update",
+			call: updatePayment,
+			mock: func(m *MockPaymentService) {
+				m.On("UpdatePayment", uint(1), mock.Anything).Return(nil, apperror.New(apperror.CodePaymentNotFound, "payment not found"))
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "disallowed status transition on update",
+			call: updatePayment,
+			mock: func(m *MockPaymentService) {
+				m.On("UpdatePayment", uint(1), mock.Anything).Return(nil, apperror.New(apperror.CodeInvalidStatusTransition, "payment cannot move to the requested status"))
+			},
+			code: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			handler, mockService := setupPaymentGrpcHandler()
+
+			// Given
+			tt.mock(mockService)
+
+			// When
+			err := tt.call(handler)
+
+			// Then
+			assert.Equal(t, tt.code, status.Code(err))
+			mockService.AssertExpectations(t)
+		})
+	}
+}
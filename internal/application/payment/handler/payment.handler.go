@@ -3,9 +3,15 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/service"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/httpcache"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/timezone"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/validation"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -25,27 +31,40 @@ func NewPaymentHandler(service service.PaymentService, logger *zap.Logger) *Paym
 
 // CreatePayment godoc
 // @Summary Create a new payment
-// @Description Create a new payment with the provided information
+// @Description Create a new payment with the provided information. The user must hold a wallet in the payment currency; a missing one is created while wallet.auto_create is on.
 // @Tags payments
 // @Accept json
 // @Produce json
 // @Param payment body dto.CreatePaymentRequest true "Payment creation request"
 // @Success 201 {object} map[string]interface{} "Created payment"
 // @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 422 {object} map[string]interface{} "Validation failed, with a message per field, including amounts more precise than the currency allows, the user has no wallet in the currency and wallet.auto_create is off, or the currency is not ISO 4217"
+// @Failure 429 {object} map[string]interface{} "User has reached the active payment limit"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /payments [post]
 func (h *PaymentHandler) CreatePayment(ctx *gin.Context) {
 	var req dto.CreatePaymentRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		h.logger.Error("Invalid request body", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(validation.BindErrorResponse(ctx, err))
 		return
 	}
 
 	payment, err := h.service.CreatePayment(&req)
 	if err != nil {
 		h.logger.Error("Failed to create payment", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payment"})
+		switch apperror.Code(err) {
+		case apperror.CodeInvalidAmount:
+			ctx.JSON(validation.FieldsResponse(ctx, map[string]string{"amount": "must be a finite number greater than 0"}))
+		case apperror.CodeAmountPrecision:
+			ctx.JSON(validation.FieldsResponse(ctx, map[string]string{"amount": "has more decimal places than the currency allows"}))
+		case apperror.CodeActivePaymentLimit:
+			apperror.JSON(ctx, http.StatusTooManyRequests, err)
+		case apperror.CodeCurrencyMismatch, apperror.CodeUnsupportedCurrency:
+			apperror.JSON(ctx, http.StatusUnprocessableEntity, err)
+		default:
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to create payment")
+		}
 		return
 	}
 
@@ -54,30 +73,82 @@ func (h *PaymentHandler) CreatePayment(ctx *gin.Context) {
 
 // GetPayment godoc
 // @Summary Get a payment by ID
-// @Description Get a single payment by its ID
+// @Description Get a single payment by its ID. The response carries an ETag; send it back in If-None-Match to get 304 while the payment is unchanged.
 // @Tags payments
 // @Accept json
 // @Produce json
 // @Param id path int true "Payment ID"
+// @Param tz query string false "IANA time zone for timestamps, e.g. Asia/Jakarta" default(UTC)
+// @Param format query bool false "Add formatted_amount, e.g. \"$100.50\"" default(false)
+// @Param fields query string false "Comma-separated fields to return, e.g. id,amount,status"
+// @Param If-None-Match header string false "ETag from an earlier response"
 // @Success 200 {object} map[string]interface{} "Payment details"
-// @Failure 400 {object} map[string]interface{} "Invalid payment ID"
+// @Header 200 {string} ETag "Version of the payment representation"
+// @Success 304 "Payment unchanged since the given ETag"
+// @Failure 400 {object} map[string]interface{} "Invalid payment ID, timezone, format or fields"
 // @Failure 404 {object} map[string]interface{} "Payment not found"
 // @Router /payments/{id} [get]
 func (h *PaymentHandler) GetPayment(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid payment ID")
+		return
+	}
+
+	loc, err := timezone.Parse(ctx.Query("tz"))
+	if err != nil {
+		apperror.JSON(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	formatted, ok := parseFormat(ctx)
+	if !ok {
+		return
+	}
+
+	fields, err := dto.ParsePaymentFields(ctx.Query("fields"))
+	if err != nil {
+		apperror.JSON(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	payment, err := h.service.GetPaymentByID(uint(id))
 	if err != nil {
 		h.logger.Error("Failed to get payment", zap.Error(err))
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		apperror.Message(ctx, http.StatusNotFound, apperror.CodePaymentNotFound, "Payment not found")
+		return
+	}
+	payment.InLocation(loc)
+	if formatted {
+		payment.FormatAmount()
+	}
+
+	// The representation changes with the payment and with the requested time zone, format
+	// and fields
+	etag := httpcache.ETag(
+		strconv.FormatUint(uint64(payment.ID), 10),
+		payment.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		loc.String(),
+		strconv.FormatBool(formatted),
+		strings.Join(fields, ","),
+	)
+	ctx.Header("ETag", etag)
+	if httpcache.MatchesETag(ctx.GetHeader("If-None-Match"), etag) {
+		ctx.AbortWithStatus(http.StatusNotModified)
 		return
 	}
 
+	if fields != nil {
+		selected, err := payment.SelectFields(fields)
+		if err != nil {
+			h.logger.Error("Failed to select payment fields", zap.Error(err))
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to get payment")
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"data": selected})
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{"data": payment})
 }
 
@@ -90,9 +161,18 @@ func (h *PaymentHandler) GetPayment(ctx *gin.Context) {
 // @Param status query string false "Filter by status" Enums(pending, completed, failed, canceled)
 // @Param currency query string false "Filter by currency (3-letter code)"
 // @Param user_id query int false "Filter by user ID"
+// @Param tag query string false "Filter by tag"
+// @Param updated_since query string false "RFC 3339 time; return payments changed since, oldest first, including deleted ones with deleted_at"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Number of items per page" default(10)
+// @Param expand query string false "Embed related resources" Enums(user)
+// @Param tz query string false "IANA time zone for timestamps, e.g. Asia/Jakarta" default(UTC)
+// @Param format query bool false "Add formatted_amount, e.g. \"$100.50\"" default(false)
+// @Param fields query string false "Comma-separated fields to return, e.g. id,amount,status"
+// @Param If-Modified-Since header string false "Last-Modified from an earlier response"
 // @Success 200 {object} dto.PaymentListResponse "List of payments"
+// @Header 200 {string} Last-Modified "Latest update among the returned payments"
+// @Success 304 "Nothing returned has changed since If-Modified-Since"
 // @Failure 400 {object} map[string]interface{} "Invalid query parameters"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /payments [get]
@@ -100,23 +180,57 @@ func (h *PaymentHandler) GetPayments(ctx *gin.Context) {
 	var filter dto.PaymentFilter
 	if err := ctx.ShouldBindQuery(&filter); err != nil {
 		h.logger.Error("Invalid query parameters", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apperror.JSON(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	loc, err := timezone.Parse(ctx.Query("tz"))
+	if err != nil {
+		apperror.JSON(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	formatted, ok := parseFormat(ctx)
+	if !ok {
+		return
+	}
+
+	fields, err := dto.ParsePaymentFields(ctx.Query("fields"))
+	if err != nil {
+		apperror.JSON(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	payments, err := h.service.GetPayments(&filter)
 	if err != nil {
 		h.logger.Error("Failed to get payments", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get payments"})
+		apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to get payments")
+		return
+	}
+	payments.InLocation(loc)
+	if formatted {
+		payments.FormatAmounts()
+	}
+	if httpcache.NotModified(ctx, payments.LastModified()) {
 		return
 	}
 
+	if fields != nil {
+		selected, err := payments.SelectFields(fields)
+		if err != nil {
+			h.logger.Error("Failed to select payment fields", zap.Error(err))
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to get payments")
+			return
+		}
+		ctx.JSON(http.StatusOK, selected)
+		return
+	}
 	ctx.JSON(http.StatusOK, payments)
 }
 
 // UpdatePayment godoc
 // @Summary Update a payment
-// @Description Update a payment's information by ID
+// @Description Update a payment's status and/or description by ID; omitted fields are left unchanged
 // @Tags payments
 // @Accept json
 // @Produce json
@@ -124,27 +238,40 @@ func (h *PaymentHandler) GetPayments(ctx *gin.Context) {
 // @Param payment body dto.UpdatePaymentRequest true "Payment update request"
 // @Success 200 {object} map[string]interface{} "Updated payment"
 // @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Payment not found"
+// @Failure 409 {object} map[string]interface{} "The payment state machine does not allow the status change"
+// @Failure 422 {object} map[string]interface{} "Validation failed, with a message per field"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /payments/{id} [put]
+// @Router /payments/{id} [patch]
 func (h *PaymentHandler) UpdatePayment(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid payment ID")
 		return
 	}
 
 	var req dto.UpdatePaymentRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		h.logger.Error("Invalid request body", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(validation.BindErrorResponse(ctx, err))
 		return
 	}
 
 	payment, err := h.service.UpdatePayment(uint(id), &req)
 	if err != nil {
 		h.logger.Error("Failed to update payment", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment"})
+		switch apperror.Code(err) {
+		case apperror.CodePaymentNotFound:
+			apperror.JSON(ctx, http.StatusNotFound, err)
+		case apperror.CodeEmptyUpdate, apperror.CodeInvalidPaymentStatus:
+			apperror.JSON(ctx, http.StatusBadRequest, err)
+		case apperror.CodeInvalidStatusTransition:
+			apperror.JSON(ctx, http.StatusConflict, err)
+		default:
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to update payment")
+		}
 		return
 	}
 
@@ -166,34 +293,307 @@ func (h *PaymentHandler) DeletePayment(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid payment ID")
 		return
 	}
 
 	err = h.service.DeletePayment(uint(id))
 	if err != nil {
 		h.logger.Error("Failed to delete payment", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete payment"})
+		apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to delete payment")
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Payment deleted successfully"})
 }
 
+// GetPaymentReceipt godoc
+// @Summary Get a payment receipt
+// @Description Get the receipt for a completed payment, including the paying user's details
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Success 200 {object} map[string]interface{} "Payment receipt"
+// @Failure 400 {object} map[string]interface{} "Invalid payment ID"
+// @Failure 404 {object} map[string]interface{} "Payment not found"
+// @Failure 409 {object} map[string]interface{} "Receipt not available for payment status"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /payments/{id}/receipt [get]
+func (h *PaymentHandler) GetPaymentReceipt(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid payment ID")
+		return
+	}
+
+	receipt, err := h.service.GetPaymentReceipt(uint(id))
+	if err != nil {
+		h.logger.Error("Failed to get payment receipt", zap.Error(err))
+		switch apperror.Code(err) {
+		case apperror.CodePaymentNotFound:
+			apperror.JSON(ctx, http.StatusNotFound, err)
+		case apperror.CodeReceiptNotAvailable:
+			apperror.JSON(ctx, http.StatusConflict, err)
+		default:
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to get payment receipt")
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": receipt})
+}
+
+// GetPaymentStatuses godoc
+// @Summary List payment statuses
+// @Description Get every valid payment status value, e.g. for filter dropdowns
+// @Tags payments
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Payment statuses"
+// @Router /payments/statuses [get]
+func (h *PaymentHandler) GetPaymentStatuses(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"data": h.service.GetPaymentStatuses()})
+}
+
+// GetPaymentHistory godoc
+// @Summary Get a payment's status history
+// @Description Get the payment's status transitions in order, with when and by whom
+// @Description (api, worker, admin or gateway:<name>) each change was made
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Success 200 {object} map[string]interface{} "Status history"
+// @Failure 400 {object} map[string]interface{} "Invalid payment ID"
+// @Failure 404 {object} map[string]interface{} "Payment not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /payments/{id}/history [get]
+func (h *PaymentHandler) GetPaymentHistory(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid payment ID")
+		return
+	}
+
+	history, err := h.service.GetPaymentHistory(uint(id))
+	if err != nil {
+		h.logger.Error("Failed to get payment history", zap.Error(err))
+		if apperror.Code(err) == apperror.CodePaymentNotFound {
+			apperror.JSON(ctx, http.StatusNotFound, err)
+			return
+		}
+		apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to get payment history")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": history})
+}
+
+// RefundPayment godoc
+// @Summary Refund a payment
+// @Description Refund all or part of a completed payment. Partial refunds move the payment to
+// @Description partially_refunded until the full amount has been refunded. With dry_run=true the
+// @Description refund is validated and the payment is returned as it would be, without saving it.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Param refund body dto.RefundPaymentRequest true "Refund request"
+// @Param dry_run query bool false "Validate the refund without applying it" default(false)
+// @Success 200 {object} map[string]interface{} "Refunded payment, or the would-be result of a dry run"
+// @Failure 400 {object} map[string]interface{} "Invalid request, dry_run value or refund amount exceeds refundable amount"
+// @Failure 404 {object} map[string]interface{} "Payment not found"
+// @Failure 409 {object} map[string]interface{} "Payment is not refundable"
+// @Failure 422 {object} map[string]interface{} "Validation failed, or the amount has more decimal places than the currency allows"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /payments/{id}/refund [post]
+func (h *PaymentHandler) RefundPayment(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid payment ID")
+		return
+	}
+
+	var req dto.RefundPaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Invalid request body", zap.Error(err))
+		apperror.JSON(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if dryRun := ctx.Query("dry_run"); dryRun != "" {
+		parsed, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidRequest, "invalid dry_run value")
+			return
+		}
+		req.DryRun = parsed
+	}
+
+	payment, err := h.service.RefundPayment(uint(id), &req)
+	if err != nil {
+		h.logger.Error("Failed to refund payment", zap.Error(err))
+		switch apperror.Code(err) {
+		case apperror.CodePaymentNotFound:
+			apperror.JSON(ctx, http.StatusNotFound, err)
+		case apperror.CodePaymentNotRefundable:
+			apperror.JSON(ctx, http.StatusConflict, err)
+		case apperror.CodeRefundExceedsAmount, apperror.CodeInvalidAmount:
+			apperror.JSON(ctx, http.StatusBadRequest, err)
+		case apperror.CodeAmountPrecision:
+			ctx.JSON(validation.FieldsResponse(ctx, map[string]string{"amount": "has more decimal places than the currency allows"}))
+		default:
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to refund payment")
+		}
+		return
+	}
+
+	if req.DryRun {
+		ctx.JSON(http.StatusOK, gin.H{"data": payment, "dry_run": true})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": payment})
+}
+
+// ReprocessPayment godoc
+// @Summary Requeue a payment for processing
+// @Description Enqueue another processing attempt for a failed or pending payment. A failed payment is returned to pending.
+// @Tags admin
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Success 202 {object} map[string]interface{} "Payment queued for processing"
+// @Failure 400 {object} map[string]interface{} "Invalid payment ID"
+// @Failure 404 {object} map[string]interface{} "Payment not found"
+// @Failure 409 {object} map[string]interface{} "Payment cannot be reprocessed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/payments/{id}/reprocess [post]
+func (h *PaymentHandler) ReprocessPayment(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid payment ID")
+		return
+	}
+
+	payment, err := h.service.ReprocessPayment(uint(id))
+	if err != nil {
+		h.logger.Error("Failed to reprocess payment", zap.Error(err))
+		switch apperror.Code(err) {
+		case apperror.CodePaymentNotFound:
+			apperror.JSON(ctx, http.StatusNotFound, err)
+		case apperror.CodePaymentNotReprocessable:
+			apperror.JSON(ctx, http.StatusConflict, err)
+		default:
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to reprocess payment")
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"data": payment})
+}
+
+// BulkUpdateStatus godoc
+// @Summary Update the status of several payments
+// @Description Move up to 100 payments to one status in a single transaction. Each transition is checked against the payment state machine; payments that are missing or cannot make the transition are reported per ID and left unchanged. Refund statuses are only reached through the refund endpoint.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body dto.BulkStatusUpdateRequest true "Payment IDs and the target status"
+// @Success 200 {object} dto.BulkStatusUpdateResponse "Per-payment results"
+// @Failure 400 {object} map[string]interface{} "Invalid request or status"
+// @Failure 422 {object} map[string]interface{} "Validation failed, with a message per field"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/payments/bulk-status [post]
+func (h *PaymentHandler) BulkUpdateStatus(ctx *gin.Context) {
+	var req dto.BulkStatusUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Invalid request body", zap.Error(err))
+		ctx.JSON(validation.BindErrorResponse(ctx, err))
+		return
+	}
+
+	result, err := h.service.BulkUpdateStatus(&req)
+	if err != nil {
+		h.logger.Error("Failed to bulk update payment status", zap.Error(err))
+		if apperror.Code(err) == apperror.CodeInvalidPaymentStatus {
+			apperror.JSON(ctx, http.StatusBadRequest, err)
+			return
+		}
+		apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to update payments")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// BulkDeletePayments godoc
+// @Summary Delete several payments
+// @Description Soft-delete up to 100 payments in a single transaction. Completed payments are kept and reported per ID unless force=true; missing payments are reported too.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body dto.BulkDeleteRequest true "Payment IDs"
+// @Param force query bool false "Also delete completed payments" default(false)
+// @Success 200 {object} dto.BulkDeleteResponse "Per-payment results"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or force value"
+// @Failure 422 {object} map[string]interface{} "Validation failed, with a message per field"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/payments [delete]
+func (h *PaymentHandler) BulkDeletePayments(ctx *gin.Context) {
+	var req dto.BulkDeleteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Invalid request body", zap.Error(err))
+		ctx.JSON(validation.BindErrorResponse(ctx, err))
+		return
+	}
+
+	if force := ctx.Query("force"); force != "" {
+		parsed, err := strconv.ParseBool(force)
+		if err != nil {
+			apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidRequest, "invalid force value")
+			return
+		}
+		req.Force = parsed
+	}
+
+	result, err := h.service.BulkDeletePayments(&req)
+	if err != nil {
+		h.logger.Error("Failed to bulk delete payments", zap.Error(err))
+		apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to delete payments")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": result})
+}
+
 func (h *PaymentHandler) RegisterRoutes(api *gin.RouterGroup) {
 	payments := api.Group("/payments")
 	{
 		payments.POST("", h.CreatePayment)
 		payments.GET("", h.GetPayments)
+		payments.GET("/statuses", h.GetPaymentStatuses)
 		payments.GET("/:id", h.GetPayment)
 		payments.PUT("/:id", h.UpdatePayment)
+		payments.PATCH("/:id", h.UpdatePayment)
 		payments.DELETE("/:id", h.DeletePayment)
+		payments.GET("/:id/receipt", h.GetPaymentReceipt)
+		payments.GET("/:id/history", h.GetPaymentHistory)
+		payments.POST("/:id/refund", h.RefundPayment)
 	}
 
 	users := api.Group("/users")
 	{
 		users.GET("/:id/payments", h.GetPaymentsByUser)
 	}
+
+	admin := api.Group("/admin/payments")
+	{
+		admin.DELETE("", h.BulkDeletePayments)
+		admin.POST("/bulk-status", h.BulkUpdateStatus)
+		admin.POST("/:id/reprocess", h.ReprocessPayment)
+	}
 }
 
 // GetPaymentsByUser godoc
@@ -203,24 +603,66 @@ func (h *PaymentHandler) RegisterRoutes(api *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param tz query string false "IANA time zone for timestamps, e.g. Asia/Jakarta" default(UTC)
+// @Param format query bool false "Add formatted_amount, e.g. \"$100.50\"" default(false)
+// @Param If-Modified-Since header string false "Last-Modified from an earlier response"
 // @Success 200 {object} map[string]interface{} "List of payments for the user"
-// @Failure 400 {object} map[string]interface{} "Invalid user ID"
+// @Header 200 {string} Last-Modified "Latest update among the returned payments"
+// @Success 304 "Nothing returned has changed since If-Modified-Since"
+// @Failure 400 {object} map[string]interface{} "Invalid user ID, timezone or format"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /users/{id}/payments [get]
 func (h *PaymentHandler) GetPaymentsByUser(ctx *gin.Context) {
 	userIDStr := ctx.Param("id")
 	userID, err := strconv.ParseUint(userIDStr, 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid user ID")
+		return
+	}
+
+	loc, err := timezone.Parse(ctx.Query("tz"))
+	if err != nil {
+		apperror.JSON(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	formatted, ok := parseFormat(ctx)
+	if !ok {
 		return
 	}
 
 	payments, err := h.service.GetPaymentsByUser(uint(userID))
 	if err != nil {
 		h.logger.Error("Failed to get payments by user", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get payments"})
+		apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to get payments")
+		return
+	}
+	var lastModified time.Time
+	for i := range payments {
+		payments[i].InLocation(loc)
+		if formatted {
+			payments[i].FormatAmount()
+		}
+		lastModified = httpcache.Latest(lastModified, payments[i].UpdatedAt)
+	}
+	if httpcache.NotModified(ctx, lastModified) {
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"data": payments})
 }
+
+// parseFormat reads the format query parameter, writing a 400 response when it isn't a
+// boolean
+func parseFormat(ctx *gin.Context) (bool, bool) {
+	raw := ctx.Query("format")
+	if raw == "" {
+		return false, true
+	}
+	formatted, err := strconv.ParseBool(raw)
+	if err != nil {
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidRequest, "Invalid format parameter, use true or false")
+		return false, false
+	}
+	return formatted, true
+}
@@ -11,11 +11,15 @@ import (
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/repository"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/service"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type MockPaymentService struct {
@@ -67,6 +71,71 @@ func (m *MockPaymentService) GetPaymentsByUser(userID uint) ([]dto.PaymentRespon
 	return args.Get(0).([]dto.PaymentResponse), args.Error(1)
 }
 
+func (m *MockPaymentService) GetPaymentReceipt(id uint) (*dto.PaymentReceiptResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PaymentReceiptResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) RefundPayment(id uint, req *dto.RefundPaymentRequest) (*dto.PaymentResponse, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PaymentResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) ApplyGatewayEvent(event *dto.GatewayEvent) (*dto.GatewayEventResponse, error) {
+	args := m.Called(event)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.GatewayEventResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) ReprocessPayment(id uint) (*dto.PaymentResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PaymentResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) BulkUpdateStatus(req *dto.BulkStatusUpdateRequest) (*dto.BulkStatusUpdateResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.BulkStatusUpdateResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) BulkDeletePayments(req *dto.BulkDeleteRequest) (*dto.BulkDeleteResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.BulkDeleteResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) GetPaymentStatuses() []string {
+	args := m.Called()
+	return args.Get(0).([]string)
+}
+
+func (m *MockPaymentService) GetPaymentHistory(id uint) ([]dto.PaymentStatusChangeResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.PaymentStatusChangeResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) SetTaskScheduler(scheduler service.TaskScheduler) {
+	m.Called(scheduler)
+}
+
 func setupPaymentHandler() (*PaymentHandler, *MockPaymentService) {
 	gin.SetMode(gin.TestMode)
 	mockService := &MockPaymentService{}
@@ -116,6 +185,89 @@ func TestPaymentHandler_CreatePayment(t *testing.T) {
 		assert.Equal(t, req.Currency, data["currency"])
 	})
 
+	t.Run("should return per-field messages for invalid fields", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/payments",
+			bytes.NewBufferString(`{"amount":-5,"currency":"US","description":"Test","user_id":1}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+
+		// When
+		handler.CreatePayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "validation failed", response["error"])
+		assert.Equal(t, map[string]interface{}{
+			"amount":   "must be greater than 0",
+			"currency": "must be exactly 3 characters",
+		}, response["fields"])
+		mockService.AssertNotCalled(t, "CreatePayment", mock.Anything)
+	})
+
+	t.Run("should return a validation error when the service rejects the amount", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		mockService.On("CreatePayment", mock.AnythingOfType("*dto.CreatePaymentRequest")).
+			Return(nil, apperror.New(apperror.CodeInvalidAmount, "invalid amount"))
+
+		reqBody, _ := json.Marshal(testutil.CreatePaymentRequestFixture())
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/payments", bytes.NewBuffer(reqBody))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+
+		// When
+		handler.CreatePayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "validation failed", response["error"])
+		assert.Equal(t, map[string]interface{}{"amount": "must be a finite number greater than 0"}, response["fields"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return unprocessable entity for amounts more precise than the currency", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		mockService.On("CreatePayment", mock.AnythingOfType("*dto.CreatePaymentRequest")).
+			Return(nil, apperror.New(apperror.CodeAmountPrecision, "amount exceeds currency precision"))
+
+		req := testutil.CreatePaymentRequestFixture()
+		req.Amount = 100.999
+		reqBody, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/payments", bytes.NewBuffer(reqBody))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+
+		// When
+		handler.CreatePayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "validation failed", response["error"])
+		assert.Equal(t, map[string]interface{}{"amount": "has more decimal places than the currency allows"}, response["fields"])
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("should return bad request for invalid JSON", func(t *testing.T) {
 		// Setup
 		handler, mockService := setupPaymentHandler()
@@ -133,6 +285,28 @@ func TestPaymentHandler_CreatePayment(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("should return too many requests when the user is at the active payment limit", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		req := testutil.CreatePaymentRequestFixture()
+		mockService.On("CreatePayment", mock.AnythingOfType("*dto.CreatePaymentRequest")).
+			Return(nil, apperror.New(apperror.CodeActivePaymentLimit, "active payment limit reached"))
+
+		reqBody, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/payments", bytes.NewBuffer(reqBody))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+
+		// When
+		handler.CreatePayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("should return internal api error when service fails", func(t *testing.T) {
 		// Setup
 		handler, mockService := setupPaymentHandler()
@@ -220,7 +394,7 @@ func TestPaymentHandler_GetPayment(t *testing.T) {
 		handler, mockService := setupPaymentHandler()
 
 		paymentID := uint(999)
-		mockService.On("GetPaymentByID", paymentID).Return(nil, errors.New("payment not found"))
+		mockService.On("GetPaymentByID", paymentID).Return(nil, apperror.New(apperror.CodePaymentNotFound, "payment not found"))
 
 		w := httptest.NewRecorder()
 		ctx, _ := gin.CreateTestContext(w)
@@ -236,6 +410,281 @@ func TestPaymentHandler_GetPayment(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("should format timestamps in the requested timezone", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		stored := time.Date(2024, 3, 1, 20, 30, 0, 0, time.UTC)
+		response := &dto.PaymentResponse{ID: 1, CreatedAt: stored, UpdatedAt: stored}
+		mockService.On("GetPaymentByID", uint(1)).Return(response, nil)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/payments/1?tz=Asia/Jakarta", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.GetPayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		var result map[string]map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, "2024-03-02T03:30:00+07:00", result["data"]["created_at"])
+		assert.Equal(t, "2024-03-02T03:30:00+07:00", result["data"]["updated_at"])
+	})
+
+	t.Run("should default timestamps to UTC", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		jakarta, err := time.LoadLocation("Asia/Jakarta")
+		require.NoError(t, err)
+		stored := time.Date(2024, 3, 2, 3, 30, 0, 0, jakarta)
+		response := &dto.PaymentResponse{ID: 1, CreatedAt: stored, UpdatedAt: stored}
+		mockService.On("GetPaymentByID", uint(1)).Return(response, nil)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/payments/1", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.GetPayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		var result map[string]map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, "2024-03-01T20:30:00Z", result["data"]["created_at"])
+	})
+
+	t.Run("should return bad request for an unknown timezone", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/payments/1?tz=Mars/Olympus", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.GetPayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid timezone","code":"INVALID_TIMEZONE"}`, w.Body.String())
+		mockService.AssertNotCalled(t, "GetPaymentByID", mock.Anything)
+	})
+}
+
+func TestPaymentHandler_GetPayment_Format(t *testing.T) {
+	tests := []struct {
+		query     string
+		currency  string
+		amount    float64
+		status    int
+		formatted interface{}
+	}{
+		{query: "", currency: "USD", amount: 100.5, status: http.StatusOK, formatted: nil},
+		{query: "?format=false", currency: "USD", amount: 100.5, status: http.StatusOK, formatted: nil},
+		{query: "?format=true", currency: "USD", amount: 100.5, status: http.StatusOK, formatted: "$100.50"},
+		{query: "?format=true", currency: "EUR", amount: 1999.9, status: http.StatusOK, formatted: "€1,999.90"},
+		{query: "?format=true", currency: "JPY", amount: 1500, status: http.StatusOK, formatted: "¥1,500"},
+		{query: "?format=yes", currency: "USD", amount: 100.5, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency+tt.query, func(t *testing.T) {
+			// Setup
+			handler, mockService := setupPaymentHandler()
+			router := gin.New()
+			handler.RegisterRoutes(router.Group(""))
+
+			// Given
+			mockService.On("GetPaymentByID", uint(1)).
+				Return(&dto.PaymentResponse{ID: 1, Amount: tt.amount, Currency: tt.currency}, nil).Maybe()
+
+			// When
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/payments/1"+tt.query, nil))
+
+			// Then
+			require.Equal(t, tt.status, w.Code)
+			if tt.status != http.StatusOK {
+				mockService.AssertNotCalled(t, "GetPaymentByID", mock.Anything)
+				return
+			}
+			var result struct {
+				Data map[string]interface{} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Equal(t, tt.amount, result.Data["amount"])
+			assert.Equal(t, tt.formatted, result.Data["formatted_amount"])
+		})
+	}
+}
+
+func TestPaymentHandler_GetPayments_Fields(t *testing.T) {
+	payments := &dto.PaymentListResponse{
+		Data: []dto.PaymentResponse{
+			{ID: 1, Amount: 100, Currency: "USD", Status: "pending", Description: "First", UserID: 1},
+			{ID: 2, Amount: 250, Currency: "EUR", Status: "completed", Description: "Second", UserID: 1},
+		},
+		TotalCount: 2,
+		Page:       1,
+		PageSize:   10,
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		keys   []string
+	}{
+		{name: "all fields by default", query: "", status: http.StatusOK, keys: []string{
+			"id", "reference_number", "amount", "refunded_amount", "currency", "status",
+			"description", "user_id", "tags", "created_at", "updated_at",
+		}},
+		{name: "requested fields only", query: "?fields=id,amount,status", status: http.StatusOK, keys: []string{"id", "amount", "status"}},
+		{name: "spaces and duplicates", query: "?fields=id,%20status,id", status: http.StatusOK, keys: []string{"id", "status"}},
+		{name: "unexpanded user stays absent", query: "?fields=id,user", status: http.StatusOK, keys: []string{"id"}},
+		{name: "formatted amount", query: "?fields=id,formatted_amount&format=true", status: http.StatusOK, keys: []string{"id", "formatted_amount"}},
+		{name: "unknown field", query: "?fields=id,password", status: http.StatusBadRequest},
+		{name: "empty field name", query: "?fields=id,,status", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			handler, mockService := setupPaymentHandler()
+			router := gin.New()
+			handler.RegisterRoutes(router.Group(""))
+
+			// Given
+			mockService.On("GetPayments", mock.AnythingOfType("*dto.PaymentFilter")).
+				Return(&dto.PaymentListResponse{
+					Data:       append([]dto.PaymentResponse(nil), payments.Data...),
+					TotalCount: payments.TotalCount,
+					Page:       payments.Page,
+					PageSize:   payments.PageSize,
+				}, nil).Maybe()
+
+			// When
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/payments"+tt.query, nil))
+
+			// Then
+			require.Equal(t, tt.status, w.Code)
+			if tt.status != http.StatusOK {
+				mockService.AssertNotCalled(t, "GetPayments", mock.Anything)
+				var result map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+				assert.Equal(t, apperror.CodeInvalidFields, result["code"])
+				return
+			}
+			var result struct {
+				Data       []map[string]interface{} `json:"data"`
+				TotalCount int64                    `json:"total_count"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Equal(t, int64(2), result.TotalCount)
+			require.Len(t, result.Data, 2)
+			for _, payment := range result.Data {
+				keys := make([]string, 0, len(payment))
+				for key := range payment {
+					keys = append(keys, key)
+				}
+				assert.ElementsMatch(t, tt.keys, keys)
+			}
+			assert.Equal(t, float64(1), result.Data[0]["id"])
+		})
+	}
+}
+
+func TestPaymentHandler_GetPayment_Fields(t *testing.T) {
+	// Setup
+	handler, mockService := setupPaymentHandler()
+	router := gin.New()
+	handler.RegisterRoutes(router.Group(""))
+
+	// Given
+	mockService.On("GetPaymentByID", uint(1)).
+		Return(&dto.PaymentResponse{ID: 1, Amount: 100.5, Currency: "USD", Status: "completed"}, nil)
+
+	// When
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/payments/1?fields=amount,status", nil))
+
+	// Then
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":{"amount":100.5,"status":"completed"}}`, w.Body.String())
+
+	// a different fieldset is a different representation
+	full := httptest.NewRecorder()
+	router.ServeHTTP(full, httptest.NewRequest(http.MethodGet, "/payments/1", nil))
+	assert.NotEqual(t, w.Header().Get("ETag"), full.Header().Get("ETag"))
+}
+
+func TestPaymentHandler_GetPayments_UpdatedSince(t *testing.T) {
+	t.Run("should pass updated_since and render tombstones", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+		router := gin.New()
+		handler.RegisterRoutes(router.Group(""))
+
+		// Given
+		since := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		deletedAt := since.Add(time.Hour)
+		mockService.On("GetPayments", mock.MatchedBy(func(filter *dto.PaymentFilter) bool {
+			return filter.UpdatedSince.Equal(since)
+		})).Return(&dto.PaymentListResponse{
+			Data: []dto.PaymentResponse{
+				{ID: 1, Status: "completed", UpdatedAt: since},
+				{ID: 2, Status: "pending", UpdatedAt: since.Add(-time.Hour), DeletedAt: &deletedAt},
+			},
+			TotalCount: 2,
+		}, nil)
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/payments?updated_since=2024-06-01T00:00:00Z", nil))
+
+		// Then
+		require.Equal(t, http.StatusOK, w.Code)
+		var result struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		require.Len(t, result.Data, 2)
+		assert.NotContains(t, result.Data[0], "deleted_at")
+		assert.Equal(t, "2024-06-01T01:00:00Z", result.Data[1]["deleted_at"])
+		// the deletion is the latest change on the page
+		assert.Equal(t, deletedAt.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should reject an updated_since that is not RFC 3339", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+		router := gin.New()
+		handler.RegisterRoutes(router.Group(""))
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/payments?updated_since=yesterday", nil))
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetPayments", mock.Anything)
+	})
 }
 
 func TestPaymentHandler_GetPayments(t *testing.T) {
@@ -419,6 +868,52 @@ func TestPaymentHandler_UpdatePayment(t *testing.T) {
 	})
 }
 
+func TestPaymentHandler_UpdatePayment_PartialFields(t *testing.T) {
+	t.Run("should accept a description-only patch", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+		router := gin.New()
+		handler.RegisterRoutes(router.Group(""))
+
+		// Given
+		mockService.On("UpdatePayment", uint(1), &dto.UpdatePaymentRequest{Description: "New description"}).
+			Return(&dto.PaymentResponse{ID: 1, Status: "completed", Description: "New description"}, nil)
+
+		// When
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, "/payments/1", bytes.NewBufferString(`{"description":"New description"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"completed"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return bad request for an empty update", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+		router := gin.New()
+		handler.RegisterRoutes(router.Group(""))
+
+		// Given
+		mockService.On("UpdatePayment", uint(1), &dto.UpdatePaymentRequest{}).
+			Return(nil, apperror.New(apperror.CodeEmptyUpdate, "no fields to update"))
+
+		// When
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, "/payments/1", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), apperror.CodeEmptyUpdate)
+		mockService.AssertExpectations(t)
+	})
+}
+
 func TestPaymentHandler_DeletePayment(t *testing.T) {
 	t.Run("should delete payment successfully", func(t *testing.T) {
 		// Setup
@@ -565,25 +1060,348 @@ func TestPaymentHandler_GetPaymentsByUser(t *testing.T) {
 	})
 }
 
-func TestPaymentHandler_RegisterRoutes(t *testing.T) {
-	t.Run("should register all routes correctly", func(t *testing.T) {
+func TestPaymentHandler_GetPaymentReceipt(t *testing.T) {
+	t.Run("should return receipt for completed payment", func(t *testing.T) {
 		// Setup
-		handler, _ := setupPaymentHandler()
-		router := gin.New()
-		api := router.Group("/api/v1")
+		handler, mockService := setupPaymentHandler()
 
-		// When
-		handler.RegisterRoutes(api)
+		receipt := &dto.PaymentReceiptResponse{
+			ReceiptNumber: "RCPT-20240101-00000001",
+			PaymentID:     1,
+			Amount:        100.50,
+			Currency:      "USD",
+			Status:        entity.PaymentStatusCompleted.String(),
+			UserID:        1,
+			UserName:      "John Doe",
+			UserEmail:     "john@example.com",
+			PaidAt:        time.Now(),
+			IssuedAt:      time.Now(),
+		}
 
-		// Then
-		routes := router.Routes()
-		expectedRoutes := []string{
-			"POST /api/v1/payments",
+		mockService.On("GetPaymentReceipt", uint(1)).Return(receipt, nil)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/payments/1/receipt", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.GetPaymentReceipt(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+
+		var result map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &result)
+		data := result["data"].(map[string]interface{})
+		assert.Equal(t, "RCPT-20240101-00000001", data["receipt_number"])
+		assert.Equal(t, float64(1), data["payment_id"])
+		assert.Equal(t, "John Doe", data["user_name"])
+		assert.Equal(t, "john@example.com", data["user_email"])
+	})
+
+	t.Run("should return conflict for pending payment", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		mockService.On("GetPaymentReceipt", uint(1)).Return(nil, apperror.New(apperror.CodeReceiptNotAvailable, "receipt not available for payment status"))
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/payments/1/receipt", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.GetPaymentReceipt(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return not found when payment does not exist", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		mockService.On("GetPaymentReceipt", uint(999)).Return(nil, apperror.New(apperror.CodePaymentNotFound, "payment not found"))
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/payments/999/receipt", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "999"},
+		}
+
+		// When
+		handler.GetPaymentReceipt(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestPaymentHandler_GetPaymentHistory(t *testing.T) {
+	t.Run("should return the status history in order", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		history := []dto.PaymentStatusChangeResponse{
+			{ToStatus: "pending", Actor: "api", ChangedAt: time.Now()},
+			{FromStatus: "pending", ToStatus: "completed", Actor: "worker", ChangedAt: time.Now()},
+		}
+		mockService.On("GetPaymentHistory", uint(1)).Return(history, nil)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/payments/1/history", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.GetPaymentHistory(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+
+		var result map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &result)
+		data := result["data"].([]interface{})
+		assert.Len(t, data, 2)
+		assert.Equal(t, "pending", data[0].(map[string]interface{})["to_status"])
+		assert.NotContains(t, data[0], "from_status")
+		assert.Equal(t, "completed", data[1].(map[string]interface{})["to_status"])
+		assert.Equal(t, "worker", data[1].(map[string]interface{})["actor"])
+	})
+
+	t.Run("should return not found for missing payment", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+		mockService.On("GetPaymentHistory", uint(999)).Return(nil, apperror.New(apperror.CodePaymentNotFound, "payment not found"))
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/payments/999/history", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "999"},
+		}
+
+		// When
+		handler.GetPaymentHistory(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return bad request for invalid ID", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/payments/abc/history", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "abc"},
+		}
+
+		// When
+		handler.GetPaymentHistory(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetPaymentHistory", mock.Anything)
+	})
+}
+
+func TestPaymentHandler_RefundPayment(t *testing.T) {
+	t.Run("should refund payment successfully", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		response := &dto.PaymentResponse{
+			ID:             1,
+			Amount:         100,
+			RefundedAmount: 25,
+			Currency:       "USD",
+			Status:         "partially_refunded",
+			UserID:         1,
+		}
+
+		mockService.On("RefundPayment", uint(1), &dto.RefundPaymentRequest{Amount: 25}).Return(response, nil)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/payments/1/refund", bytes.NewBufferString(`{"amount":25}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.RefundPayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+
+		var result map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &result)
+		data := result["data"].(map[string]interface{})
+		assert.Equal(t, "partially_refunded", data["status"])
+		assert.Equal(t, float64(25), data["refunded_amount"])
+	})
+
+	t.Run("should pass dry run to service and flag the response", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		response := &dto.PaymentResponse{ID: 1, Amount: 100, RefundedAmount: 100, Currency: "USD", Status: "refunded", UserID: 1}
+		mockService.On("RefundPayment", uint(1), &dto.RefundPaymentRequest{Amount: 100, DryRun: true}).Return(response, nil)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/payments/1/refund?dry_run=true", bytes.NewBufferString(`{"amount":100}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.RefundPayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+
+		var result map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &result)
+		assert.Equal(t, true, result["dry_run"])
+		data := result["data"].(map[string]interface{})
+		assert.Equal(t, "refunded", data["status"])
+	})
+
+	t.Run("should return bad request for invalid dry_run value", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/payments/1/refund?dry_run=maybe", bytes.NewBufferString(`{"amount":25}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.RefundPayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "RefundPayment")
+	})
+
+	t.Run("should return bad request for non-positive amount", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/payments/1/refund", bytes.NewBufferString(`{"amount":-5}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.RefundPayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "RefundPayment")
+	})
+
+	t.Run("should return bad request when refund exceeds refundable amount", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		mockService.On("RefundPayment", uint(1), mock.AnythingOfType("*dto.RefundPaymentRequest")).
+			Return(nil, apperror.New(apperror.CodeRefundExceedsAmount, "refund amount exceeds refundable amount"))
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/payments/1/refund", bytes.NewBufferString(`{"amount":500}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.RefundPayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return conflict when payment is not refundable", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		mockService.On("RefundPayment", uint(1), mock.AnythingOfType("*dto.RefundPaymentRequest")).
+			Return(nil, apperror.New(apperror.CodePaymentNotRefundable, "payment is not refundable"))
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/payments/1/refund", bytes.NewBufferString(`{"amount":10}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.RefundPayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestPaymentHandler_RegisterRoutes(t *testing.T) {
+	t.Run("should register all routes correctly", func(t *testing.T) {
+		// Setup
+		handler, _ := setupPaymentHandler()
+		router := gin.New()
+		api := router.Group("/api/v1")
+
+		// When
+		handler.RegisterRoutes(api)
+
+		// Then
+		routes := router.Routes()
+		expectedRoutes := []string{
+			"POST /api/v1/payments",
 			"GET /api/v1/payments",
+			"GET /api/v1/payments/statuses",
 			"GET /api/v1/payments/:id",
 			"PUT /api/v1/payments/:id",
+			"PATCH /api/v1/payments/:id",
 			"DELETE /api/v1/payments/:id",
+			"GET /api/v1/payments/:id/receipt",
+			"GET /api/v1/payments/:id/history",
+			"POST /api/v1/payments/:id/refund",
 			"GET /api/v1/users/:id/payments",
+			"DELETE /api/v1/admin/payments",
+			"POST /api/v1/admin/payments/bulk-status",
+			"POST /api/v1/admin/payments/:id/reprocess",
 		}
 
 		assert.Len(t, routes, len(expectedRoutes))
@@ -599,3 +1417,464 @@ func TestPaymentHandler_RegisterRoutes(t *testing.T) {
 		}
 	})
 }
+
+func TestPaymentHandler_GetPaymentStatuses(t *testing.T) {
+	t.Run("should return exactly the valid statuses", func(t *testing.T) {
+		// Setup: the real service, routed so /statuses is not taken for a payment ID
+		gin.SetMode(gin.TestMode)
+		mockRepo := &testutil.MockPaymentRepository{}
+		logger := testutil.NewSilentLogger()
+		paymentService := service.NewPaymentService(mockRepo, &testutil.MockUserService{}, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+		router := gin.New()
+		NewPaymentHandler(paymentService, logger).RegisterRoutes(router.Group("/api/v1"))
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/payments/statuses", nil)
+
+		// When
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+
+		var response struct {
+			Data []string `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		// Every returned value is valid, and every valid status is returned
+		for _, status := range response.Data {
+			assert.True(t, entity.PaymentStatus(status).IsValid(), "status %q", status)
+		}
+		candidates := []string{
+			"pending", "completed", "failed", "canceled", "partially_refunded", "refunded",
+			"", "unknown", "PENDING", "cancelled", "processing",
+		}
+		var valid []string
+		for _, candidate := range candidates {
+			if entity.PaymentStatus(candidate).IsValid() {
+				valid = append(valid, candidate)
+			}
+		}
+		assert.ElementsMatch(t, valid, response.Data)
+	})
+}
+
+func TestPaymentHandler_ReprocessPayment(t *testing.T) {
+	setup := func() (*PaymentHandler, *testutil.MockPaymentRepository, *testutil.MockTaskScheduler) {
+		gin.SetMode(gin.TestMode)
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockScheduler := &testutil.MockTaskScheduler{}
+		logger := testutil.NewSilentLogger()
+		paymentService := service.NewPaymentService(mockRepo, &testutil.MockUserService{}, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+		paymentService.SetTaskScheduler(mockScheduler)
+		return NewPaymentHandler(paymentService, logger), mockRepo, mockScheduler
+	}
+
+	newContext := func(id string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/admin/payments/"+id+"/reprocess", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: id},
+		}
+		return ctx, w
+	}
+
+	t.Run("should enqueue processing for a failed payment", func(t *testing.T) {
+		// Setup
+		handler, mockRepo, mockScheduler := setup()
+
+		payment := testutil.CreatePaymentFixture()
+		payment.Status = entity.PaymentStatusFailed
+
+		mockRepo.On("GetByID", payment.ID).Return(payment, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*entity.Payment")).Return(nil)
+		mockScheduler.On("SchedulePaymentProcessing", payment.ID).Return(nil)
+
+		ctx, w := newContext("1")
+
+		// When
+		handler.ReprocessPayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "pending", response["data"].(map[string]interface{})["status"])
+		mockScheduler.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a completed payment", func(t *testing.T) {
+		// Setup
+		handler, mockRepo, mockScheduler := setup()
+
+		payment := testutil.CreatePaymentFixture()
+		payment.Status = entity.PaymentStatusCompleted
+
+		mockRepo.On("GetByID", payment.ID).Return(payment, nil)
+
+		ctx, w := newContext("1")
+
+		// When
+		handler.ReprocessPayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockScheduler.AssertNotCalled(t, "SchedulePaymentProcessing", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("should restore a failed payment when enqueueing fails", func(t *testing.T) {
+		// Setup
+		handler, mockRepo, mockScheduler := setup()
+
+		payment := testutil.CreatePaymentFixture()
+		payment.Status = entity.PaymentStatusFailed
+
+		mockRepo.On("GetByID", payment.ID).Return(payment, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*entity.Payment")).Return(nil)
+		mockScheduler.On("SchedulePaymentProcessing", payment.ID).Return(errors.New("redis unavailable"))
+
+		ctx, w := newContext("1")
+
+		// When
+		handler.ReprocessPayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, entity.PaymentStatusFailed, payment.Status)
+		mockRepo.AssertNumberOfCalls(t, "Update", 2)
+	})
+
+	t.Run("should return not found for unknown payment", func(t *testing.T) {
+		// Setup
+		handler, mockRepo, _ := setup()
+
+		mockRepo.On("GetByID", uint(999)).Return(nil, repository.ErrNotFound)
+
+		ctx, w := newContext("999")
+
+		// When
+		handler.ReprocessPayment(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestPaymentHandler_BulkUpdateStatus(t *testing.T) {
+	newContext := func(body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/admin/payments/bulk-status", bytes.NewBufferString(body))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		return ctx, w
+	}
+
+	t.Run("should return per-payment results", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		req := &dto.BulkStatusUpdateRequest{IDs: []uint{1, 2}, Status: "canceled"}
+		mockService.On("BulkUpdateStatus", req).Return(&dto.BulkStatusUpdateResponse{
+			Status:  "canceled",
+			Updated: 1,
+			Failed:  1,
+			Results: []dto.BulkStatusResult{
+				{ID: 1, Updated: true, FromStatus: "pending"},
+				{ID: 2, FromStatus: "completed", Error: "cannot transition from completed to canceled"},
+			},
+		}, nil)
+
+		ctx, w := newContext(`{"ids":[1,2],"status":"canceled"}`)
+
+		// When
+		handler.BulkUpdateStatus(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":{"status":"canceled","updated":1,"failed":1,"results":[
+			{"id":1,"updated":true,"from_status":"pending"},
+			{"id":2,"updated":false,"from_status":"completed","error":"cannot transition from completed to canceled"}
+		]}}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should reject an unknown or refund status", func(t *testing.T) {
+		for _, status := range []string{"settled", "refunded", "partially_refunded"} {
+			// Setup
+			handler, mockService := setupPaymentHandler()
+
+			ctx, w := newContext(`{"ids":[1],"status":"` + status + `"}`)
+
+			// When
+			handler.BulkUpdateStatus(ctx)
+
+			// Then
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, status)
+			assert.Contains(t, w.Body.String(), apperror.CodeValidationFailed)
+			mockService.AssertNotCalled(t, "BulkUpdateStatus", mock.Anything)
+		}
+	})
+
+	t.Run("should return bad request when the service rejects the status", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+		mockService.On("BulkUpdateStatus", mock.Anything).Return(nil, apperror.New(apperror.CodeInvalidPaymentStatus, "invalid payment status"))
+
+		ctx, w := newContext(`{"ids":[1],"status":"canceled"}`)
+
+		// When
+		handler.BulkUpdateStatus(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid payment status","code":"INVALID_PAYMENT_STATUS"}`, w.Body.String())
+	})
+
+	t.Run("should reject an empty ID list", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		ctx, w := newContext(`{"ids":[],"status":"canceled"}`)
+
+		// When
+		handler.BulkUpdateStatus(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		mockService.AssertNotCalled(t, "BulkUpdateStatus", mock.Anything)
+	})
+}
+
+func TestPaymentHandler_BulkDeletePayments(t *testing.T) {
+	newContext := func(target, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("DELETE", target, bytes.NewBufferString(body))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		return ctx, w
+	}
+
+	t.Run("should return per-payment results", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		mockService.On("BulkDeletePayments", &dto.BulkDeleteRequest{IDs: []uint{1, 2}}).Return(&dto.BulkDeleteResponse{
+			Deleted: 1,
+			Failed:  1,
+			Results: []dto.BulkDeleteResult{
+				{ID: 1, Deleted: true, Status: "pending"},
+				{ID: 2, Status: "completed", Error: "completed payments can only be deleted with force"},
+			},
+		}, nil)
+
+		ctx, w := newContext("/admin/payments", `{"ids":[1,2]}`)
+
+		// When
+		handler.BulkDeletePayments(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":{"deleted":1,"failed":1,"results":[
+			{"id":1,"deleted":true,"status":"pending"},
+			{"id":2,"deleted":false,"status":"completed","error":"completed payments can only be deleted with force"}
+		]}}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should pass force from the query", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+		mockService.On("BulkDeletePayments", &dto.BulkDeleteRequest{IDs: []uint{2}, Force: true}).
+			Return(&dto.BulkDeleteResponse{Deleted: 1, Results: []dto.BulkDeleteResult{{ID: 2, Deleted: true, Status: "completed"}}}, nil)
+
+		ctx, w := newContext("/admin/payments?force=true", `{"ids":[2]}`)
+
+		// When
+		handler.BulkDeletePayments(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should reject an invalid force value", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		ctx, w := newContext("/admin/payments?force=maybe", `{"ids":[2]}`)
+
+		// When
+		handler.BulkDeletePayments(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid force value","code":"INVALID_REQUEST"}`, w.Body.String())
+		mockService.AssertNotCalled(t, "BulkDeletePayments", mock.Anything)
+	})
+
+	t.Run("should reject an empty ID list", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+
+		ctx, w := newContext("/admin/payments", `{"ids":[]}`)
+
+		// When
+		handler.BulkDeletePayments(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		mockService.AssertNotCalled(t, "BulkDeletePayments", mock.Anything)
+	})
+}
+
+func TestPaymentHandler_GetPayment_ETag(t *testing.T) {
+	updatedAt := time.Date(2024, 3, 1, 20, 30, 0, 0, time.UTC)
+	get := func(handler *PaymentHandler, ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/payments/1", nil)
+		if ifNoneMatch != "" {
+			ctx.Request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+		handler.GetPayment(ctx)
+		return w
+	}
+
+	t.Run("should return 304 for a matching ETag and 200 for a stale one", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+		mockService.On("GetPaymentByID", uint(1)).
+			Return(&dto.PaymentResponse{ID: 1, Status: "pending", CreatedAt: updatedAt, UpdatedAt: updatedAt}, nil)
+
+		// When
+		first := get(handler, "")
+
+		// Then
+		assert.Equal(t, http.StatusOK, first.Code)
+		etag := first.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		// When
+		conditional := get(handler, etag)
+
+		// Then
+		assert.Equal(t, http.StatusNotModified, conditional.Code)
+		assert.Empty(t, conditional.Body.String())
+		assert.Equal(t, etag, conditional.Header().Get("ETag"))
+
+		// When
+		stale := get(handler, `"0123456789abcdef0123456789abcdef"`)
+
+		// Then
+		assert.Equal(t, http.StatusOK, stale.Code)
+		assert.Contains(t, stale.Body.String(), `"id":1`)
+	})
+
+	t.Run("should change the ETag when the payment is updated", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupPaymentHandler()
+		mockService.On("GetPaymentByID", uint(1)).
+			Return(&dto.PaymentResponse{ID: 1, CreatedAt: updatedAt, UpdatedAt: updatedAt}, nil).Once()
+		mockService.On("GetPaymentByID", uint(1)).
+			Return(&dto.PaymentResponse{ID: 1, CreatedAt: updatedAt, UpdatedAt: updatedAt.Add(time.Second)}, nil).Once()
+
+		first := get(handler, "")
+		require.Equal(t, http.StatusOK, first.Code)
+
+		// When
+		afterUpdate := get(handler, first.Header().Get("ETag"))
+
+		// Then
+		assert.Equal(t, http.StatusOK, afterUpdate.Code)
+		assert.NotEqual(t, first.Header().Get("ETag"), afterUpdate.Header().Get("ETag"))
+	})
+}
+
+func TestPaymentHandler_GetPayments_LastModified(t *testing.T) {
+	// Setup: the real service and repository so updates move updated_at in the database
+	gin.SetMode(gin.TestMode)
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewSilentLogger()
+	repo := repository.NewPaymentRepository(db, logger)
+	paymentService := service.NewPaymentService(repo, &testutil.MockUserService{}, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+	router := gin.New()
+	NewPaymentHandler(paymentService, logger).RegisterRoutes(router.Group("/api/v1"))
+
+	list := func(ifModifiedSince string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/payments", nil)
+		if ifModifiedSince != "" {
+			req.Header.Set("If-Modified-Since", ifModifiedSince)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Given: payments last touched an hour ago
+	var ids []uint
+	for i := 0; i < 3; i++ {
+		payment := testutil.CreatePaymentFixture()
+		payment.ID = 0
+		require.NoError(t, repo.Create(payment))
+		ids = append(ids, payment.ID)
+	}
+	anHourAgo := time.Now().UTC().Add(-time.Hour)
+	require.NoError(t, db.Model(&entity.Payment{}).Where("id IN ?", ids).UpdateColumn("updated_at", anHourAgo).Error)
+
+	// When
+	first := list("")
+
+	// Then
+	require.Equal(t, http.StatusOK, first.Code)
+	lastModified := first.Header().Get("Last-Modified")
+	assert.Equal(t, anHourAgo.Format(http.TimeFormat), lastModified)
+
+	// When: polling again with nothing changed
+	for i := 0; i < 2; i++ {
+		polled := list(lastModified)
+
+		// Then
+		assert.Equal(t, http.StatusNotModified, polled.Code)
+		assert.Empty(t, polled.Body.String())
+	}
+
+	// When: a payment is updated
+	_, err = paymentService.UpdatePayment(ids[1], &dto.UpdatePaymentRequest{Status: entity.PaymentStatusCompleted.String()})
+	require.NoError(t, err)
+	afterUpdate := list(lastModified)
+
+	// Then
+	assert.Equal(t, http.StatusOK, afterUpdate.Code)
+	assert.NotEqual(t, lastModified, afterUpdate.Header().Get("Last-Modified"))
+	assert.Contains(t, afterUpdate.Body.String(), `"status":"completed"`)
+}
+
+func TestPaymentHandler_GetPayments_EmptyList(t *testing.T) {
+	// Setup
+	handler, mockService := setupPaymentHandler()
+	mockService.On("GetPayments", mock.Anything).Return(&dto.PaymentListResponse{Data: []dto.PaymentResponse{}}, nil)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/payments", nil)
+	ctx.Request.Header.Set("If-Modified-Since", time.Now().UTC().Format(http.TimeFormat))
+
+	// When
+	handler.GetPayments(ctx)
+
+	// Then: an empty page has nothing to date, so it is always returned in full
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Last-Modified"))
+}
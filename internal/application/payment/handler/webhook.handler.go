@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/service"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/webhook"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxWebhookBodyBytes caps a gateway callback body; larger bodies are rejected unread
+const maxWebhookBodyBytes = 64 << 10
+
+// WebhookHandler receives status callbacks from payment gateways
+type WebhookHandler struct {
+	service service.PaymentService
+	parsers service.GatewayEventParsers
+	secrets map[string]string
+	logger  *zap.Logger
+}
+
+func NewWebhookHandler(
+	service service.PaymentService,
+	parsers service.GatewayEventParsers,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		parsers: parsers,
+		secrets: cfg.Webhook.Secrets,
+		logger:  logger,
+	}
+}
+
+// HandleGatewayEvent godoc
+// @Summary Receive a gateway callback
+// @Description Verify a gateway's signed status callback and move the payment to the reported status. Redelivered events are acknowledged without changing the payment.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param gateway path string true "Gateway name"
+// @Param X-Webhook-Signature header string true "HMAC-SHA256 of the body, as sha256=<hex>"
+// @Success 200 {object} map[string]interface{} "Applied or duplicate event"
+// @Failure 400 {object} map[string]interface{} "Invalid event payload"
+// @Failure 401 {object} map[string]interface{} "Invalid signature"
+// @Failure 404 {object} map[string]interface{} "Unknown gateway or payment not found"
+// @Failure 409 {object} map[string]interface{} "Payment status cannot change"
+// @Failure 413 {object} map[string]interface{} "Body larger than 64KB"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /payments/webhook/{gateway} [post]
+func (h *WebhookHandler) HandleGatewayEvent(ctx *gin.Context) {
+	gateway := ctx.Param("gateway")
+	parser, ok := h.parsers[gateway]
+	secret := h.secrets[gateway]
+	if !ok || secret == "" {
+		h.logger.Warn("Callback from unknown or unconfigured gateway", zap.String("gateway", gateway))
+		apperror.Message(ctx, http.StatusNotFound, apperror.CodeNotFound, "unknown gateway")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxWebhookBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.logger.Warn("Rejected oversized gateway callback", zap.String("gateway", gateway))
+			apperror.Message(ctx, http.StatusRequestEntityTooLarge, apperror.CodePayloadTooLarge, "request body too large")
+			return
+		}
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidRequest, "Failed to read request body")
+		return
+	}
+
+	if !webhook.VerifySignature(body, ctx.GetHeader(webhook.SignatureHeader), secret) {
+		h.logger.Warn("Rejected gateway callback with invalid signature", zap.String("gateway", gateway))
+		apperror.Message(ctx, http.StatusUnauthorized, apperror.CodeInvalidSignature, "invalid signature")
+		return
+	}
+
+	event, err := parser.Parse(body)
+	if err != nil {
+		h.logger.Error("Invalid gateway event", zap.String("gateway", gateway), zap.Error(err))
+		apperror.JSON(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := h.service.ApplyGatewayEvent(event)
+	if err != nil {
+		h.logger.Error("Failed to apply gateway event", zap.String("event_id", event.ID), zap.Error(err))
+		switch apperror.Code(err) {
+		case apperror.CodePaymentNotFound:
+			apperror.JSON(ctx, http.StatusNotFound, err)
+		case apperror.CodePaymentStatusLocked:
+			apperror.JSON(ctx, http.StatusConflict, err)
+		default:
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to apply gateway event")
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+func (h *WebhookHandler) RegisterRoutes(api *gin.RouterGroup) {
+	payments := api.Group("/payments")
+	{
+		payments.POST("/webhook/:gateway", h.HandleGatewayEvent)
+	}
+}
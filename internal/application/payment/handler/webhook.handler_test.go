@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/service"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testWebhookSecret = "whsec_test"
+
+func setupWebhookHandler() (*WebhookHandler, *MockPaymentService) {
+	gin.SetMode(gin.TestMode)
+	mockService := &MockPaymentService{}
+	cfg := testutil.NewTestConfig()
+	cfg.Webhook.Secrets = map[string]string{service.SimulatedGateway: testWebhookSecret}
+	handler := NewWebhookHandler(mockService, service.NewGatewayEventParsers(), cfg, testutil.NewSilentLogger())
+	return handler, mockService
+}
+
+func newWebhookContext(gateway string, body []byte, signature string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("POST", "/payments/webhook/"+gateway, bytes.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Request.Header.Set(webhook.SignatureHeader, signature)
+	ctx.Params = gin.Params{
+		{Key: "gateway", Value: gateway},
+	}
+	return ctx, w
+}
+
+func TestWebhookHandler_HandleGatewayEvent(t *testing.T) {
+	body := []byte(`{"id":"evt_1","type":"payment.completed","data":{"payment_id":1}}`)
+
+	t.Run("should apply a correctly signed event", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWebhookHandler()
+
+		mockService.On("ApplyGatewayEvent", &dto.GatewayEvent{
+			Gateway: service.SimulatedGateway, ID: "evt_1", PaymentID: 1, Status: "completed",
+		}).Return(&dto.GatewayEventResponse{EventID: "evt_1", PaymentID: 1, Status: "completed"}, nil)
+
+		ctx, w := newWebhookContext(service.SimulatedGateway, body, webhook.ComputeSignature(body, testWebhookSecret))
+
+		// When
+		handler.HandleGatewayEvent(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, "evt_1", data["event_id"])
+		assert.Equal(t, false, data["duplicate"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should acknowledge a replayed event", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWebhookHandler()
+
+		mockService.On("ApplyGatewayEvent", mock.AnythingOfType("*dto.GatewayEvent")).
+			Return(&dto.GatewayEventResponse{EventID: "evt_1", PaymentID: 1, Status: "completed", Duplicate: true}, nil)
+
+		ctx, w := newWebhookContext(service.SimulatedGateway, body, webhook.ComputeSignature(body, testWebhookSecret))
+
+		// When
+		handler.HandleGatewayEvent(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, true, response["data"].(map[string]interface{})["duplicate"])
+	})
+
+	t.Run("should reject a bad signature", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWebhookHandler()
+
+		ctx, w := newWebhookContext(service.SimulatedGateway, body, webhook.ComputeSignature(body, "wrong"))
+
+		// When
+		handler.HandleGatewayEvent(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockService.AssertNotCalled(t, "ApplyGatewayEvent", mock.Anything)
+	})
+
+	t.Run("should reject a body over the size limit", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWebhookHandler()
+
+		large := bytes.Repeat([]byte("a"), maxWebhookBodyBytes+1)
+		ctx, w := newWebhookContext(service.SimulatedGateway, large, webhook.ComputeSignature(large, testWebhookSecret))
+
+		// When
+		handler.HandleGatewayEvent(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), apperror.CodePayloadTooLarge)
+		mockService.AssertNotCalled(t, "ApplyGatewayEvent", mock.Anything)
+	})
+
+	t.Run("should return not found for an unknown gateway", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWebhookHandler()
+
+		ctx, w := newWebhookContext("acme", body, webhook.ComputeSignature(body, testWebhookSecret))
+
+		// When
+		handler.HandleGatewayEvent(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertNotCalled(t, "ApplyGatewayEvent", mock.Anything)
+	})
+
+	t.Run("should return bad request for an unsupported event", func(t *testing.T) {
+		// Setup
+		handler, _ := setupWebhookHandler()
+
+		unsupported := []byte(`{"id":"evt_1","type":"payment.disputed","data":{"payment_id":1}}`)
+		ctx, w := newWebhookContext(service.SimulatedGateway, unsupported, webhook.ComputeSignature(unsupported, testWebhookSecret))
+
+		// When
+		handler.HandleGatewayEvent(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return conflict when the payment is already settled", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWebhookHandler()
+
+		mockService.On("ApplyGatewayEvent", mock.AnythingOfType("*dto.GatewayEvent")).
+			Return(nil, apperror.New(apperror.CodePaymentStatusLocked, "payment status cannot change"))
+
+		ctx, w := newWebhookContext(service.SimulatedGateway, body, webhook.ComputeSignature(body, testWebhookSecret))
+
+		// When
+		handler.HandleGatewayEvent(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
@@ -16,8 +16,16 @@ var Module = fx.Options(
 		repository.NewPaymentRepository,
 		service.NewPaymentService,
 		handler.NewPaymentHandler,
+		service.NewGatewayEventParsers,
+		handler.NewWebhookHandler,
+		// Provide the queue client as AsynqClient interface
+		func(client *queue.Client) worker.AsynqClient {
+			return client
+		},
+		worker.NewSimulatedGateway,
 		worker.NewPaymentWorker,
 	),
+	fx.Invoke(registerTaskScheduler, worker.RegisterTasks),
 )
 
 // WorkerModule provides only worker dependencies for worker api
@@ -29,6 +37,14 @@ var WorkerModule = fx.Options(
 		func(client *queue.Client) worker.AsynqClient {
 			return client
 		},
+		worker.NewSimulatedGateway,
 		worker.NewPaymentWorker,
 	),
+	fx.Invoke(worker.RegisterTasks, worker.RegisterOutboxRoutes),
 )
+
+// registerTaskScheduler lets the payment service schedule background tasks through the
+// worker without the service package depending on it
+func registerTaskScheduler(paymentService service.PaymentService, paymentWorker *worker.PaymentWorker) {
+	paymentService.SetTaskScheduler(paymentWorker)
+}
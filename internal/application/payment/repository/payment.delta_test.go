@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentRepository_GetAll_UpdatedSince(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	repo := NewPaymentRepository(db, testutil.NewTestLogger(t))
+
+	since := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	create := func(updatedAt time.Time) *entity.Payment {
+		payment := testutil.CreatePaymentFixture()
+		payment.ID = 0
+		require.NoError(t, repo.Create(payment))
+		require.NoError(t, db.Model(payment).UpdateColumn("updated_at", updatedAt).Error)
+		return payment
+	}
+
+	// Given
+	create(since.Add(-time.Hour))
+	changedLater := create(since.Add(2 * time.Hour))
+	changedAtSince := create(since)
+	deleted := create(since.Add(-2 * time.Hour))
+	require.NoError(t, repo.Delete(deleted.ID))
+	require.NoError(t, db.Unscoped().Model(deleted).UpdateColumn("deleted_at", since.Add(time.Hour)).Error)
+	deletedBefore := create(since.Add(-3 * time.Hour))
+	require.NoError(t, repo.Delete(deletedBefore.ID))
+	require.NoError(t, db.Unscoped().Model(deletedBefore).UpdateColumn("deleted_at", since.Add(-time.Hour)).Error)
+
+	t.Run("should return only payments changed since, oldest change first", func(t *testing.T) {
+		// When
+		payments, totalCount, err := repo.GetAll(&dto.PaymentFilter{UpdatedSince: since})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), totalCount)
+		require.Len(t, payments, 3)
+		assert.Equal(t, changedAtSince.ID, payments[0].ID)
+		assert.Equal(t, deleted.ID, payments[1].ID)
+		assert.Equal(t, changedLater.ID, payments[2].ID)
+	})
+
+	t.Run("should include deletions as tombstones", func(t *testing.T) {
+		// When
+		payments, _, err := repo.GetAll(&dto.PaymentFilter{UpdatedSince: since})
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, payments, 3)
+		assert.True(t, payments[1].DeletedAt.Valid)
+		assert.True(t, payments[1].DeletedAt.Time.Equal(since.Add(time.Hour)))
+		assert.False(t, payments[0].DeletedAt.Valid)
+	})
+
+	t.Run("should leave deleted payments out without updated_since", func(t *testing.T) {
+		// When
+		_, totalCount, err := repo.GetAll(&dto.PaymentFilter{})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), totalCount)
+	})
+}
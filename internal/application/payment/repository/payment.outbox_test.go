@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/outbox"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentRepository_CreateOutbox(t *testing.T) {
+	t.Run("should write an outbox message with the payment", func(t *testing.T) {
+		// Setup
+		db, err := testutil.SetupTestDB()
+		require.NoError(t, err)
+		testutil.WithCleanDB(t, db)
+		repo := NewPaymentRepository(db, testutil.NewSilentLogger())
+
+		// Given
+		payment := testutil.CreatePaymentFixture()
+		payment.ID = 0
+
+		// When
+		err = repo.Create(payment, entity.EventPaymentCreated)
+
+		// Then
+		require.NoError(t, err)
+		var messages []outbox.Message
+		require.NoError(t, db.Find(&messages).Error)
+		require.Len(t, messages, 1)
+		assert.Equal(t, entity.EventPaymentCreated, messages[0].Topic)
+		assert.Equal(t, payment.ID, messages[0].AggregateID)
+		assert.Nil(t, messages[0].PublishedAt)
+	})
+
+	t.Run("should write neither the payment nor the message when the transaction fails", func(t *testing.T) {
+		// Setup
+		db, err := testutil.SetupTestDB()
+		require.NoError(t, err)
+		testutil.WithCleanDB(t, db)
+		repo := NewPaymentRepository(db, testutil.NewSilentLogger())
+
+		// Given: recording the status change runs after the outbox write, so failing it
+		// rolls back a transaction that already holds the message
+		require.NoError(t, db.Migrator().DropTable(&entity.PaymentStatusChange{}))
+		t.Cleanup(func() { require.NoError(t, db.AutoMigrate(&entity.PaymentStatusChange{})) })
+		payment := testutil.CreatePaymentFixture()
+		payment.ID = 0
+
+		// When
+		err = repo.Create(payment, entity.EventPaymentCreated)
+
+		// Then
+		require.Error(t, err)
+		var payments, messages int64
+		require.NoError(t, db.Model(&entity.Payment{}).Count(&payments).Error)
+		require.NoError(t, db.Model(&outbox.Message{}).Count(&messages).Error)
+		assert.Zero(t, payments)
+		assert.Zero(t, messages)
+	})
+
+	t.Run("should write no message without events", func(t *testing.T) {
+		// Setup
+		db, err := testutil.SetupTestDB()
+		require.NoError(t, err)
+		testutil.WithCleanDB(t, db)
+		repo := NewPaymentRepository(db, testutil.NewSilentLogger())
+
+		payment := testutil.CreatePaymentFixture()
+		payment.ID = 0
+
+		// When
+		err = repo.Create(payment)
+
+		// Then
+		require.NoError(t, err)
+		var messages int64
+		require.NoError(t, db.Model(&outbox.Message{}).Count(&messages).Error)
+		assert.Zero(t, messages)
+	})
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var referencePattern = regexp.MustCompile(`^PAY-\d{6}$`)
+
+func TestPaymentRepository_ReferenceNumber(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	repo := NewPaymentRepository(db, testutil.NewTestLogger(t))
+
+	t.Run("should assign sequential references on create", func(t *testing.T) {
+		// Given
+		first := testutil.CreatePaymentFixture()
+		first.ID = 0
+		second := testutil.CreatePaymentFixture()
+		second.ID = 0
+
+		// When
+		require.NoError(t, repo.Create(first))
+		require.NoError(t, repo.Create(second))
+
+		// Then
+		assert.Equal(t, "PAY-000001", first.ReferenceNumber)
+		assert.Equal(t, "PAY-000002", second.ReferenceNumber)
+
+		stored, err := repo.GetByID(second.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "PAY-000002", stored.ReferenceNumber)
+	})
+
+	t.Run("should keep a reference that is already set", func(t *testing.T) {
+		// Given
+		payment := testutil.CreatePaymentFixture()
+		payment.ID = 0
+		payment.ReferenceNumber = "PAY-999999"
+
+		// When
+		err := repo.Create(payment)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "PAY-999999", payment.ReferenceNumber)
+	})
+}
+
+func TestPaymentRepository_ConcurrentReferenceNumbers(t *testing.T) {
+	// Setup: a file database so every goroutine's connection sees the same counter.
+	// SQLite has no row locks, so immediate transactions stand in for FOR UPDATE.
+	dsn := filepath.Join(t.TempDir(), "payment.db") + "?_busy_timeout=5000&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&entity.Payment{}, &entity.PaymentTag{}, &entity.PaymentStatusChange{}, &entity.PaymentSequence{},
+	))
+	repo := NewPaymentRepository(db, testutil.NewSilentLogger())
+
+	// When
+	const workers, paymentsPerWorker = 8, 10
+	var wg sync.WaitGroup
+	references := make(chan string, workers*paymentsPerWorker)
+	errs := make(chan error, workers*paymentsPerWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < paymentsPerWorker; i++ {
+				payment := testutil.CreatePaymentFixture()
+				payment.ID = 0
+				if err := repo.Create(payment); err != nil {
+					errs <- err
+					continue
+				}
+				references <- payment.ReferenceNumber
+			}
+		}()
+	}
+	wg.Wait()
+	close(references)
+	close(errs)
+
+	// Then: every payment drew a distinct, well-formed reference with no gaps
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	var got []string
+	for reference := range references {
+		assert.Regexp(t, referencePattern, reference)
+		got = append(got, reference)
+	}
+	require.Len(t, got, workers*paymentsPerWorker)
+
+	sort.Strings(got)
+	assert.Equal(t, "PAY-000001", got[0])
+	assert.Equal(t, "PAY-000080", got[len(got)-1])
+	for i := 1; i < len(got); i++ {
+		assert.NotEqual(t, got[i-1], got[i])
+	}
+
+	var sequence entity.PaymentSequence
+	require.NoError(t, db.First(&sequence, "name = ?", referenceSequence).Error)
+	assert.Equal(t, uint64(workers*paymentsPerWorker), sequence.Value)
+}
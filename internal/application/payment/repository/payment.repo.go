@@ -1,20 +1,69 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	userRepository "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/repository"
+	walletRepository "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/repository"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/database"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/outbox"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrNotFound is returned when no payment has the requested ID
+var ErrNotFound = errors.New("payment not found")
+
+// ErrEventAlreadyProcessed is returned by ApplyGatewayEvent for an event recorded earlier
+var ErrEventAlreadyProcessed = errors.New("gateway event already processed")
+
+// ErrActivePaymentLimit is returned by CreateForUser when the user already holds
+// maxActive pending payments
+var ErrActivePaymentLimit = apperror.New(apperror.CodeActivePaymentLimit, "active payment limit reached")
+
 type PaymentRepository interface {
-	Create(payment *entity.Payment) error
+	// Create inserts payment and records an outbox message for each event topic in the
+	// same transaction
+	Create(payment *entity.Payment, events ...string) error
+	// CreateForUser is Create that, in the same transaction, also creates the user's
+	// wallet in the payment currency when withWallet is set and one is missing, and
+	// fails with ErrActivePaymentLimit when the user holds maxActive pending payments
+	// (zero means no cap). The cap is counted under the user's row lock, so concurrent
+	// creations for one user cannot exceed it together.
+	CreateForUser(payment *entity.Payment, withWallet bool, maxActive int, events ...string) error
 	GetByID(id uint) (*entity.Payment, error)
 	GetAll(filter *dto.PaymentFilter) ([]entity.Payment, int64, error)
 	Update(payment *entity.Payment) error
+	// UpdateLocked locks the payment with id and runs apply on it in one transaction,
+	// saving the payment when apply reports a change. Checks made in apply hold until the
+	// write, so concurrent updates of one payment cannot overwrite each other.
+	UpdateLocked(id uint, apply func(payment *entity.Payment) (bool, error)) (*entity.Payment, error)
 	Delete(id uint) error
 	GetByUserID(userID uint) ([]entity.Payment, error)
+	// BulkUpdateStatus locks the payments with ids and, in one transaction, moves every
+	// one whose status may transition to status. It returns the payments as they were
+	// before the update; IDs without a payment are left out.
+	BulkUpdateStatus(ids []uint, status entity.PaymentStatus, actor string) ([]entity.Payment, error)
+	// BulkDelete locks the payments with ids and soft-deletes them in one transaction,
+	// skipping delete-protected ones unless force is set. It returns the payments as they
+	// were before the delete; IDs without a payment are left out.
+	BulkDelete(ids []uint, force bool) ([]entity.Payment, error)
+	// GetStatusHistory returns a payment's status changes, oldest first
+	GetStatusHistory(paymentID uint) ([]entity.PaymentStatusChange, error)
+	// ApplyGatewayEvent records event and runs apply on its locked payment in one
+	// transaction, saving the payment when apply reports a change
+	ApplyGatewayEvent(
+		event *entity.ProcessedEvent,
+		apply func(payment *entity.Payment) (bool, error),
+	) (*entity.Payment, error)
 }
 
 type paymentRepository struct {
@@ -29,15 +78,85 @@ func NewPaymentRepository(db *gorm.DB, logger *zap.Logger) PaymentRepository {
 	}
 }
 
-func (r *paymentRepository) Create(payment *entity.Payment) error {
+func (r *paymentRepository) Create(payment *entity.Payment, events ...string) error {
+	return r.CreateForUser(payment, false, 0, events...)
+}
+
+func (r *paymentRepository) CreateForUser(payment *entity.Payment, withWallet bool, maxActive int, events ...string) error {
 	r.logger.Info("Creating payment", zap.Uint("user_id", payment.UserID))
-	return r.db.Create(payment).Error
+	return database.WithTransaction(context.Background(), r.db, func(tx *gorm.DB) error {
+		if maxActive > 0 {
+			if err := userRepository.Lock(tx, payment.UserID); err != nil {
+				return err
+			}
+			var active int64
+			err := tx.Model(&entity.Payment{}).
+				Where("user_id = ? AND status = ?", payment.UserID, entity.PaymentStatusPending).
+				Count(&active).Error
+			if err != nil {
+				return err
+			}
+			if active >= int64(maxActive) {
+				return ErrActivePaymentLimit
+			}
+		}
+		if withWallet {
+			if err := walletRepository.CreateMissing(tx, payment.UserID, payment.Currency); err != nil {
+				return err
+			}
+		}
+		if payment.ReferenceNumber == "" {
+			reference, err := nextReferenceNumber(tx)
+			if err != nil {
+				return err
+			}
+			payment.ReferenceNumber = reference
+		}
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+		for _, topic := range events {
+			if err := outbox.Write(tx, topic, payment.ID); err != nil {
+				return err
+			}
+		}
+		return recordStatusChange(tx, payment, "")
+	})
+}
+
+// referenceSequence names the counter that payment reference numbers are drawn from
+const referenceSequence = "payment_reference"
+
+// nextReferenceNumber draws the next reference number. Incrementing before reading takes
+// the counter row's lock, so concurrent creations wait for each other's commit.
+func nextReferenceNumber(tx *gorm.DB) (string, error) {
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entity.PaymentSequence{Name: referenceSequence}).Error
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Model(&entity.PaymentSequence{}).
+		Where("name = ?", referenceSequence).
+		Update("value", gorm.Expr("value + 1")).Error
+	if err != nil {
+		return "", err
+	}
+
+	var sequence entity.PaymentSequence
+	if err := tx.Where("name = ?", referenceSequence).First(&sequence).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("PAY-%06d", sequence.Value), nil
 }
 
 func (r *paymentRepository) GetByID(id uint) (*entity.Payment, error) {
 	var payment entity.Payment
-	err := r.db.First(&payment, id).Error
+	err := r.db.Preload("Tags").First(&payment, id).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
 		r.logger.Error("Failed to get payment by ID", zap.Uint("id", id), zap.Error(err))
 		return nil, err
 	}
@@ -49,6 +168,11 @@ func (r *paymentRepository) GetAll(filter *dto.PaymentFilter) ([]entity.Payment,
 	var totalCount int64
 
 	query := r.db.Model(&entity.Payment{})
+	if !filter.UpdatedSince.IsZero() {
+		// Deleted payments stay in a delta so clients can drop their copies
+		query = r.db.Unscoped().Model(&entity.Payment{}).
+			Where("(payments.updated_at >= ? OR payments.deleted_at >= ?)", filter.UpdatedSince, filter.UpdatedSince)
+	}
 
 	if filter.Status != "" {
 		query = query.Where("status = ?", filter.Status)
@@ -59,15 +183,20 @@ func (r *paymentRepository) GetAll(filter *dto.PaymentFilter) ([]entity.Payment,
 	if filter.UserID != 0 {
 		query = query.Where("user_id = ?", filter.UserID)
 	}
+	if filter.Tag != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM payment_tags WHERE payment_tags.payment_id = payments.id AND payment_tags.tag = ?)", filter.Tag)
+	}
 
 	query.Count(&totalCount)
 
+	if !filter.UpdatedSince.IsZero() {
+		query = query.Order("COALESCE(payments.deleted_at, payments.updated_at), payments.id")
+	}
 	if filter.Page > 0 && filter.PageSize > 0 {
-		offset := (filter.Page - 1) * filter.PageSize
-		query = query.Offset(offset).Limit(filter.PageSize)
+		query = query.Offset(filter.Offset()).Limit(filter.PageSize)
 	}
 
-	err := query.Find(&payments).Error
+	err := query.Preload("Tags").Find(&payments).Error
 	if err != nil {
 		r.logger.Error("Failed to get payments", zap.Error(err))
 		return nil, 0, err
@@ -76,9 +205,74 @@ func (r *paymentRepository) GetAll(filter *dto.PaymentFilter) ([]entity.Payment,
 	return payments, totalCount, nil
 }
 
+// Update writes every field of an existing payment, except its tags, and records a
+// status change. Unlike Save it never inserts: it returns ErrNotFound when no live
+// payment has the ID, including ID 0.
 func (r *paymentRepository) Update(payment *entity.Payment) error {
 	r.logger.Info("Updating payment", zap.Uint("id", payment.ID))
-	return r.db.Save(payment).Error
+	return database.WithTransaction(context.Background(), r.db, func(tx *gorm.DB) error {
+		var previous []string
+		err := tx.Model(&entity.Payment{}).Where("id = ?", payment.ID).Pluck("status", &previous).Error
+		if err != nil {
+			return err
+		}
+		if err := updatePayment(tx, payment); err != nil {
+			return err
+		}
+
+		from := ""
+		if len(previous) > 0 {
+			from = previous[0]
+		}
+		return recordStatusChange(tx, payment, from)
+	})
+}
+
+func (r *paymentRepository) UpdateLocked(
+	id uint,
+	apply func(payment *entity.Payment) (bool, error),
+) (*entity.Payment, error) {
+	var payment entity.Payment
+	err := database.WithTransaction(context.Background(), r.db, func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Tags").First(&payment, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		from := payment.Status.String()
+		changed, err := apply(&payment)
+		if err != nil || !changed {
+			return err
+		}
+		r.logger.Info("Updating payment", zap.Uint("id", id))
+		if err := updatePayment(tx, &payment); err != nil {
+			return err
+		}
+		return recordStatusChange(tx, &payment, from)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// updatePayment writes every column of payment to its row, returning ErrNotFound when
+// no live row has its ID
+func updatePayment(tx *gorm.DB, payment *entity.Payment) error {
+	result := tx.Model(payment).
+		Where("id = ?", payment.ID).
+		Select("*").Omit("id", "created_at", clause.Associations).
+		Updates(payment)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
 func (r *paymentRepository) Delete(id uint) error {
@@ -88,10 +282,165 @@ func (r *paymentRepository) Delete(id uint) error {
 
 func (r *paymentRepository) GetByUserID(userID uint) ([]entity.Payment, error) {
 	var payments []entity.Payment
-	err := r.db.Where("user_id = ?", userID).Find(&payments).Error
+	err := r.db.Preload("Tags").Where("user_id = ?", userID).Find(&payments).Error
 	if err != nil {
 		r.logger.Error("Failed to get payments by user ID", zap.Uint("user_id", userID), zap.Error(err))
 		return nil, err
 	}
 	return payments, nil
 }
+
+func (r *paymentRepository) GetStatusHistory(paymentID uint) ([]entity.PaymentStatusChange, error) {
+	var changes []entity.PaymentStatusChange
+	err := r.db.Where("payment_id = ?", paymentID).Order("created_at, id").Find(&changes).Error
+	if err != nil {
+		r.logger.Error("Failed to get payment status history", zap.Uint("payment_id", paymentID), zap.Error(err))
+		return nil, err
+	}
+	return changes, nil
+}
+
+// recordStatusChange appends to the payment's status history when its status differs from
+// the stored one; from is empty for a payment that is being created
+func recordStatusChange(tx *gorm.DB, payment *entity.Payment, from string) error {
+	if from == payment.Status.String() {
+		return nil
+	}
+	actor := payment.StatusActor
+	if actor == "" {
+		actor = entity.StatusActorAPI
+	}
+	return tx.Create(&entity.PaymentStatusChange{
+		PaymentID:  payment.ID,
+		FromStatus: from,
+		ToStatus:   payment.Status.String(),
+		Actor:      actor,
+	}).Error
+}
+
+func (r *paymentRepository) BulkUpdateStatus(
+	ids []uint,
+	status entity.PaymentStatus,
+	actor string,
+) ([]entity.Payment, error) {
+	r.logger.Info("Bulk updating payment status",
+		zap.Int("count", len(ids)),
+		zap.String("status", status.String()))
+
+	var before []entity.Payment
+	err := database.WithTransaction(context.Background(), r.db, func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", ids).
+			Order("id").
+			Find(&before).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		for _, payment := range before {
+			if !payment.Status.CanTransitionTo(status) {
+				continue
+			}
+
+			err := tx.Model(&entity.Payment{}).
+				Where("id = ?", payment.ID).
+				Updates(map[string]interface{}{"status": status, "updated_at": now}).Error
+			if err != nil {
+				return err
+			}
+
+			updated := payment
+			updated.Status = status
+			updated.StatusActor = actor
+			if err := recordStatusChange(tx, &updated, payment.Status.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to bulk update payment status", zap.Error(err))
+		return nil, err
+	}
+	return before, nil
+}
+
+func (r *paymentRepository) BulkDelete(ids []uint, force bool) ([]entity.Payment, error) {
+	r.logger.Info("Bulk deleting payments", zap.Int("count", len(ids)), zap.Bool("force", force))
+
+	var before []entity.Payment
+	err := database.WithTransaction(context.Background(), r.db, func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", ids).
+			Order("id").
+			Find(&before).Error
+		if err != nil {
+			return err
+		}
+
+		deletable := make([]uint, 0, len(before))
+		for _, payment := range before {
+			if force || !payment.Status.IsDeleteProtected() {
+				deletable = append(deletable, payment.ID)
+			}
+		}
+		if len(deletable) == 0 {
+			return nil
+		}
+		return tx.Delete(&entity.Payment{}, deletable).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to bulk delete payments", zap.Error(err))
+		return nil, err
+	}
+	return before, nil
+}
+
+func (r *paymentRepository) ApplyGatewayEvent(
+	event *entity.ProcessedEvent,
+	apply func(payment *entity.Payment) (bool, error),
+) (*entity.Payment, error) {
+	var payment entity.Payment
+
+	err := database.WithTransaction(context.Background(), r.db, func(tx *gorm.DB) error {
+		// Recording the event first makes a concurrent redelivery wait on the unique
+		// index and then find the row, rather than applying the event a second time
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrEventAlreadyProcessed
+		}
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, event.PaymentID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		from := payment.Status.String()
+		changed, err := apply(&payment)
+		if err != nil || !changed {
+			return err
+		}
+		if err := updatePayment(tx, &payment); err != nil {
+			return err
+		}
+		return recordStatusChange(tx, &payment, from)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrEventAlreadyProcessed) && !errors.Is(err, ErrNotFound) {
+			r.logger.Error("Failed to apply gateway event",
+				zap.String("gateway", event.Gateway),
+				zap.String("event_id", event.EventID),
+				zap.Error(err))
+		}
+		return nil, err
+	}
+
+	return &payment, nil
+}
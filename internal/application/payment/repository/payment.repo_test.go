@@ -5,6 +5,7 @@ import (
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
 
 	"github.com/stretchr/testify/assert"
@@ -16,6 +17,7 @@ func TestPaymentRepository_Create(t *testing.T) {
 	// Setup
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 	logger := testutil.NewTestLogger(t)
 	repo := NewPaymentRepository(db, logger)
 
@@ -39,15 +41,13 @@ func TestPaymentRepository_Create(t *testing.T) {
 		assert.Equal(t, payment.Currency, dbPayment.Currency)
 		assert.Equal(t, payment.UserID, dbPayment.UserID)
 	})
-
-	// Cleanup
-	testutil.CleanDB(db)
 }
 
 func TestPaymentRepository_GetByID(t *testing.T) {
 	// Setup
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 	logger := testutil.NewTestLogger(t)
 	repo := NewPaymentRepository(db, logger)
 
@@ -71,21 +71,20 @@ func TestPaymentRepository_GetByID(t *testing.T) {
 
 	t.Run("should return error when payment not found", func(t *testing.T) {
 		// When
-		_, err := repo.GetByID(999)
+		payment, err := repo.GetByID(999)
 
 		// Then
-		assert.Error(t, err)
-		assert.Equal(t, gorm.ErrRecordNotFound, err)
+		assert.Nil(t, payment)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NotErrorIs(t, err, gorm.ErrRecordNotFound)
 	})
-
-	// Cleanup
-	testutil.CleanDB(db)
 }
 
 func TestPaymentRepository_GetAll(t *testing.T) {
 	// Setup
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 	logger := testutil.NewTestLogger(t)
 	repo := NewPaymentRepository(db, logger)
 
@@ -107,8 +106,7 @@ func TestPaymentRepository_GetAll(t *testing.T) {
 		}
 
 		filter := &dto.PaymentFilter{
-			Page:     1,
-			PageSize: 3,
+			Pagination: pagination.Pagination{Page: 1, PageSize: 3},
 		}
 
 		// When
@@ -210,15 +208,13 @@ func TestPaymentRepository_GetAll(t *testing.T) {
 		assert.Equal(t, int64(1), totalCount)
 		assert.Equal(t, uint(1), payments[0].UserID)
 	})
-
-	// Cleanup
-	testutil.CleanDB(db)
 }
 
 func TestPaymentRepository_Update(t *testing.T) {
 	// Setup
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 	logger := testutil.NewTestLogger(t)
 	repo := NewPaymentRepository(db, logger)
 
@@ -245,14 +241,56 @@ func TestPaymentRepository_Update(t *testing.T) {
 		assert.Equal(t, "Updated description", dbPayment.Description)
 	})
 
-	// Cleanup
-	testutil.CleanDB(db)
+	t.Run("should clear fields set back to their zero value", func(t *testing.T) {
+		// Given
+		payment := testutil.CreatePaymentFixture()
+		payment.ID = 0
+		payment.RefundedAmount = 10
+		require.NoError(t, repo.Create(payment))
+
+		// When
+		payment.RefundedAmount = 0
+		payment.Description = ""
+		err := repo.Update(payment)
+
+		// Then
+		require.NoError(t, err)
+		var dbPayment entity.Payment
+		require.NoError(t, db.First(&dbPayment, payment.ID).Error)
+		assert.Zero(t, dbPayment.RefundedAmount)
+		assert.Empty(t, dbPayment.Description)
+	})
+
+	t.Run("should return not found instead of inserting a missing payment", func(t *testing.T) {
+		var before int64
+		require.NoError(t, db.Unscoped().Model(&entity.Payment{}).Count(&before).Error)
+
+		for _, id := range []uint{0, 999} {
+			// Given
+			payment := testutil.CreatePaymentFixture()
+			payment.ID = id
+
+			// When
+			err := repo.Update(payment)
+
+			// Then
+			assert.ErrorIs(t, err, ErrNotFound, "id %d", id)
+		}
+
+		var after int64
+		require.NoError(t, db.Unscoped().Model(&entity.Payment{}).Count(&after).Error)
+		assert.Equal(t, before, after)
+		var history int64
+		require.NoError(t, db.Model(&entity.PaymentStatusChange{}).Where("payment_id IN ?", []uint{0, 999}).Count(&history).Error)
+		assert.Zero(t, history)
+	})
 }
 
 func TestPaymentRepository_Delete(t *testing.T) {
 	// Setup
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 	logger := testutil.NewTestLogger(t)
 	repo := NewPaymentRepository(db, logger)
 
@@ -275,15 +313,13 @@ func TestPaymentRepository_Delete(t *testing.T) {
 		assert.Error(t, err)
 		assert.Equal(t, gorm.ErrRecordNotFound, err)
 	})
-
-	// Cleanup
-	testutil.CleanDB(db)
 }
 
 func TestPaymentRepository_GetByUserID(t *testing.T) {
 	// Setup
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 	logger := testutil.NewTestLogger(t)
 	repo := NewPaymentRepository(db, logger)
 
@@ -325,7 +361,249 @@ func TestPaymentRepository_GetByUserID(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Empty(t, payments)
 	})
+}
+
+func TestPaymentRepository_StatusStorage(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewTestLogger(t)
+	repo := NewPaymentRepository(db, logger)
+
+	t.Run("should round-trip valid statuses", func(t *testing.T) {
+		for _, status := range []entity.PaymentStatus{
+			entity.PaymentStatusPending,
+			entity.PaymentStatusCompleted,
+			entity.PaymentStatusFailed,
+			entity.PaymentStatusCanceled,
+		} {
+			payment := testutil.CreatePaymentFixture()
+			payment.ID = 0
+			payment.Status = status
+			require.NoError(t, repo.Create(payment))
+
+			result, err := repo.GetByID(payment.ID)
+			require.NoError(t, err)
+			assert.Equal(t, status, result.Status)
+		}
+	})
+
+	t.Run("should reject corrupted status read from the database", func(t *testing.T) {
+		payment := testutil.CreatePaymentFixture()
+		payment.ID = 0
+		require.NoError(t, repo.Create(payment))
+		require.NoError(t, db.Exec("UPDATE payments SET status = ? WHERE id = ?", "bogus", payment.ID).Error)
+
+		result, err := repo.GetByID(payment.ID)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "invalid payment status")
+	})
+}
+
+func TestPaymentRepository_ApplyGatewayEvent(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewTestLogger(t)
+	repo := NewPaymentRepository(db, logger)
+
+	t.Run("should apply an event only once when it is delivered twice", func(t *testing.T) {
+		// Given
+		payment := testutil.CreatePaymentFixture()
+		payment.ID = 0
+		require.NoError(t, repo.Create(payment))
+
+		applied := 0
+		complete := func(p *entity.Payment) (bool, error) {
+			applied++
+			p.Status = entity.PaymentStatusCompleted
+			return true, nil
+		}
+		newEvent := func() *entity.ProcessedEvent {
+			return &entity.ProcessedEvent{Gateway: "simulated", EventID: "evt_1", PaymentID: payment.ID}
+		}
+
+		// When
+		updated, firstErr := repo.ApplyGatewayEvent(newEvent(), complete)
+		_, secondErr := repo.ApplyGatewayEvent(newEvent(), complete)
+
+		// Then
+		assert.NoError(t, firstErr)
+		assert.Equal(t, entity.PaymentStatusCompleted, updated.Status)
+		assert.ErrorIs(t, secondErr, ErrEventAlreadyProcessed)
+		assert.Equal(t, 1, applied)
+
+		var events int64
+		db.Model(&entity.ProcessedEvent{}).Where("event_id = ?", "evt_1").Count(&events)
+		assert.Equal(t, int64(1), events)
+	})
+
+	t.Run("should not record the event when applying it fails", func(t *testing.T) {
+		// Given
+		payment := testutil.CreatePaymentFixture()
+		payment.ID = 0
+		require.NoError(t, repo.Create(payment))
+
+		event := &entity.ProcessedEvent{Gateway: "simulated", EventID: "evt_2", PaymentID: payment.ID}
+
+		// When
+		_, err := repo.ApplyGatewayEvent(event, func(p *entity.Payment) (bool, error) {
+			return false, assert.AnError
+		})
+
+		// Then
+		assert.ErrorIs(t, err, assert.AnError)
+
+		var events int64
+		db.Model(&entity.ProcessedEvent{}).Where("event_id = ?", "evt_2").Count(&events)
+		assert.Zero(t, events)
+	})
+
+	t.Run("should return not found for unknown payment", func(t *testing.T) {
+		// Given
+		event := &entity.ProcessedEvent{Gateway: "simulated", EventID: "evt_3", PaymentID: 999}
+
+		// When
+		_, err := repo.ApplyGatewayEvent(event, func(p *entity.Payment) (bool, error) {
+			return true, nil
+		})
+
+		// Then
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestPaymentRepository_Tags(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewTestLogger(t)
+	repo := NewPaymentRepository(db, logger)
+
+	subscription := testutil.CreatePaymentFixture()
+	subscription.ID = 0
+	subscription.Tags = []entity.PaymentTag{{Tag: "subscription"}, {Tag: "monthly"}}
+	require.NoError(t, repo.Create(subscription))
+
+	oneOff := testutil.CreatePaymentFixture()
+	oneOff.ID = 0
+	oneOff.Tags = []entity.PaymentTag{{Tag: "groceries"}}
+	require.NoError(t, repo.Create(oneOff))
+
+	untagged := testutil.CreatePaymentFixture()
+	untagged.ID = 0
+	require.NoError(t, repo.Create(untagged))
+
+	t.Run("should round-trip tags", func(t *testing.T) {
+		// When
+		payment, err := repo.GetByID(subscription.ID)
+
+		// Then
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"subscription", "monthly"}, payment.TagNames())
+	})
+
+	t.Run("should filter by tag", func(t *testing.T) {
+		// When
+		payments, totalCount, err := repo.GetAll(&dto.PaymentFilter{Tag: "subscription"})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), totalCount)
+		require.Len(t, payments, 1)
+		assert.Equal(t, subscription.ID, payments[0].ID)
+		assert.ElementsMatch(t, []string{"subscription", "monthly"}, payments[0].TagNames())
+	})
+
+	t.Run("should return nothing for an unused tag", func(t *testing.T) {
+		payments, totalCount, err := repo.GetAll(&dto.PaymentFilter{Tag: "travel"})
+
+		require.NoError(t, err)
+		assert.Zero(t, totalCount)
+		assert.Empty(t, payments)
+	})
+
+	t.Run("should keep tags when the payment is updated", func(t *testing.T) {
+		// Given
+		payment, err := repo.GetByID(oneOff.ID)
+		require.NoError(t, err)
+		payment.Status = entity.PaymentStatusCompleted
+
+		// When
+		err = repo.Update(payment)
+
+		// Then
+		require.NoError(t, err)
+		reloaded, err := repo.GetByID(oneOff.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"groceries"}, reloaded.TagNames())
+	})
+}
+
+func TestPaymentRepository_StatusHistory(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewTestLogger(t)
+	repo := NewPaymentRepository(db, logger)
+
+	payment := testutil.CreatePaymentFixture()
+	payment.ID = 0
+	payment.Status = entity.PaymentStatusPending
+	require.NoError(t, repo.Create(payment))
 
-	// Cleanup
-	testutil.CleanDB(db)
+	t.Run("should record creation and status changes only", func(t *testing.T) {
+		// Given: one save that changes the status and one that does not
+		payment.Status = entity.PaymentStatusCompleted
+		payment.StatusActor = entity.StatusActorWorker
+		require.NoError(t, repo.Update(payment))
+
+		payment.Description = "Edited description"
+		payment.StatusActor = ""
+		require.NoError(t, repo.Update(payment))
+
+		// When
+		history, err := repo.GetStatusHistory(payment.ID)
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, "", history[0].FromStatus)
+		assert.Equal(t, "pending", history[0].ToStatus)
+		assert.Equal(t, entity.StatusActorAPI, history[0].Actor)
+		assert.Equal(t, "pending", history[1].FromStatus)
+		assert.Equal(t, "completed", history[1].ToStatus)
+		assert.Equal(t, entity.StatusActorWorker, history[1].Actor)
+	})
+
+	t.Run("should record gateway events with the gateway as actor", func(t *testing.T) {
+		// Given
+		pending := testutil.CreatePaymentFixture()
+		pending.ID = 0
+		pending.Status = entity.PaymentStatusPending
+		require.NoError(t, repo.Create(pending))
+
+		event := &entity.ProcessedEvent{Gateway: "simulated", EventID: "evt_history", PaymentID: pending.ID}
+
+		// When
+		_, err := repo.ApplyGatewayEvent(event, func(p *entity.Payment) (bool, error) {
+			p.Status = entity.PaymentStatusFailed
+			p.StatusActor = entity.GatewayStatusActor(event.Gateway)
+			return true, nil
+		})
+
+		// Then
+		require.NoError(t, err)
+		history, err := repo.GetStatusHistory(pending.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, "failed", history[1].ToStatus)
+		assert.Equal(t, "gateway:simulated", history[1].Actor)
+	})
 }
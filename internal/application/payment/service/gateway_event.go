@@ -0,0 +1,68 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+)
+
+// GatewayEventParser turns a gateway's callback payload into a GatewayEvent
+type GatewayEventParser interface {
+	Parse(body []byte) (*dto.GatewayEvent, error)
+}
+
+// GatewayEventParsers holds the parser for each gateway that may call back, by gateway name
+type GatewayEventParsers map[string]GatewayEventParser
+
+// SimulatedGateway names the simulated provider the worker talks to
+const SimulatedGateway = "simulated"
+
+func NewGatewayEventParsers() GatewayEventParsers {
+	return GatewayEventParsers{
+		SimulatedGateway: simulatedEventParser{},
+	}
+}
+
+// simulatedEventParser reads the simulated gateway's payload:
+//
+//	{"id": "evt_123", "type": "payment.completed", "data": {"payment_id": 42}}
+type simulatedEventParser struct{}
+
+// simulatedEventStatuses maps simulated event types to the payment status they report
+var simulatedEventStatuses = map[string]entity.PaymentStatus{
+	"payment.completed": entity.PaymentStatusCompleted,
+	"payment.failed":    entity.PaymentStatusFailed,
+	"payment.canceled":  entity.PaymentStatusCanceled,
+}
+
+func (simulatedEventParser) Parse(body []byte) (*dto.GatewayEvent, error) {
+	var payload struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			PaymentID uint `json:"payment_id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid event payload: %w", err)
+	}
+
+	if payload.ID == "" || payload.Data.PaymentID == 0 {
+		return nil, apperror.New(apperror.CodeInvalidGatewayEvent, "event is missing id or payment_id")
+	}
+
+	status, ok := simulatedEventStatuses[payload.Type]
+	if !ok {
+		return nil, fmt.Errorf("unsupported event type %q", payload.Type)
+	}
+
+	return &dto.GatewayEvent{
+		Gateway:   SimulatedGateway,
+		ID:        payload.ID,
+		PaymentID: payload.Data.PaymentID,
+		Status:    status.String(),
+	}, nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulatedEventParser_Parse(t *testing.T) {
+	parser := NewGatewayEventParsers()[SimulatedGateway]
+
+	t.Run("should parse a status event", func(t *testing.T) {
+		// When
+		event, err := parser.Parse([]byte(`{"id":"evt_1","type":"payment.failed","data":{"payment_id":42}}`))
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, SimulatedGateway, event.Gateway)
+		assert.Equal(t, "evt_1", event.ID)
+		assert.Equal(t, uint(42), event.PaymentID)
+		assert.Equal(t, "failed", event.Status)
+	})
+
+	t.Run("should reject unsupported event types", func(t *testing.T) {
+		_, err := parser.Parse([]byte(`{"id":"evt_1","type":"payment.disputed","data":{"payment_id":42}}`))
+
+		assert.EqualError(t, err, `unsupported event type "payment.disputed"`)
+	})
+
+	t.Run("should reject incomplete or malformed payloads", func(t *testing.T) {
+		_, err := parser.Parse([]byte(`{"type":"payment.completed","data":{"payment_id":42}}`))
+		assert.Error(t, err)
+
+		_, err = parser.Parse([]byte(`not json`))
+		assert.Error(t, err)
+	})
+}
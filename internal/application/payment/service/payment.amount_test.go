@@ -0,0 +1,218 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	userDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPaymentService_CreatePayment_Amount(t *testing.T) {
+	cases := []struct {
+		name   string
+		amount float64
+		valid  bool
+	}{
+		{name: "NaN", amount: math.NaN(), valid: false},
+		{name: "positive infinity", amount: math.Inf(1), valid: false},
+		{name: "negative infinity", amount: math.Inf(-1), valid: false},
+		{name: "zero", amount: 0, valid: false},
+		{name: "normal value", amount: 100.5, valid: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			mockRepo := &testutil.MockPaymentRepository{}
+			mockUserService := &testutil.MockUserService{}
+			service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), testutil.NewSilentLogger())
+
+			// Given
+			req := testutil.CreatePaymentRequestFixture()
+			req.Amount = tc.amount
+			if tc.valid {
+				mockUserService.On("GetUserByID", req.UserID).Return(&userDto.UserResponse{ID: req.UserID}, nil)
+				mockRepo.On("CreateForUser", mock.AnythingOfType("*entity.Payment"), false, 0, mock.Anything).Return(nil)
+			}
+
+			// When
+			response, err := service.CreatePayment(req)
+
+			// Then
+			if tc.valid {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.amount, response.Amount)
+			} else {
+				assert.EqualError(t, err, "invalid amount")
+				assert.Nil(t, response)
+				mockRepo.AssertNotCalled(t, "CreateForUser", mock.Anything)
+			}
+			mockRepo.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPaymentService_RefundPayment_Amount(t *testing.T) {
+	t.Run("should reject non-finite refund amounts", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		service := NewPaymentService(mockRepo, &testutil.MockUserService{}, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), testutil.NewSilentLogger())
+
+		for _, amount := range []float64{math.NaN(), math.Inf(1)} {
+			// When
+			response, err := service.RefundPayment(1, &dto.RefundPaymentRequest{Amount: amount})
+
+			// Then
+			assert.EqualError(t, err, "invalid amount")
+			assert.Nil(t, response)
+		}
+		mockRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+	})
+
+	t.Run("should still accept a finite refund", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		service := NewPaymentService(mockRepo, &testutil.MockUserService{}, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), testutil.NewSilentLogger())
+
+		// Given
+		payment := testutil.CreatePaymentFixture()
+		payment.Status = entity.PaymentStatusCompleted
+		mockRepo.On("GetByID", payment.ID).Return(payment, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*entity.Payment")).Return(nil)
+
+		// When
+		response, err := service.RefundPayment(payment.ID, &dto.RefundPaymentRequest{Amount: 1})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, 1.0, response.RefundedAmount)
+	})
+}
+
+func TestPaymentService_CreatePayment_Precision(t *testing.T) {
+	cases := []struct {
+		name     string
+		amount   float64
+		currency string
+		valid    bool
+	}{
+		{name: "USD with two decimals", amount: 100.99, currency: "USD", valid: true},
+		{name: "USD with three decimals", amount: 100.999, currency: "USD", valid: false},
+		{name: "EUR with one decimal", amount: 0.1, currency: "EUR", valid: true},
+		{name: "EUR with three decimals", amount: 0.005, currency: "EUR", valid: false},
+		{name: "JPY whole amount", amount: 1500, currency: "JPY", valid: true},
+		{name: "JPY with decimals", amount: 1500.5, currency: "JPY", valid: false},
+		{name: "lowercase currency code", amount: 1500.5, currency: "jpy", valid: false},
+		{name: "KWD with three decimals", amount: 12.345, currency: "KWD", valid: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			mockRepo := &testutil.MockPaymentRepository{}
+			mockUserService := &testutil.MockUserService{}
+			service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), testutil.NewSilentLogger())
+
+			// Given
+			req := testutil.CreatePaymentRequestFixture()
+			req.Amount = tc.amount
+			req.Currency = tc.currency
+			if tc.valid {
+				mockUserService.On("GetUserByID", req.UserID).Return(&userDto.UserResponse{ID: req.UserID}, nil)
+				mockRepo.On("CreateForUser", mock.AnythingOfType("*entity.Payment"), false, 0, mock.Anything).Return(nil)
+			}
+
+			// When
+			response, err := service.CreatePayment(req)
+
+			// Then
+			if tc.valid {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.amount, response.Amount)
+			} else {
+				assert.EqualError(t, err, "amount exceeds currency precision")
+				assert.Nil(t, response)
+				mockUserService.AssertNotCalled(t, "GetUserByID", mock.Anything)
+			}
+			mockRepo.AssertExpectations(t)
+			mockUserService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPaymentService_RefundPayment_Precision(t *testing.T) {
+	refund := func(t *testing.T, payment *entity.Payment, amount float64) (*dto.PaymentResponse, error) {
+		t.Helper()
+		mockRepo := &testutil.MockPaymentRepository{}
+		service := NewPaymentService(mockRepo, &testutil.MockUserService{}, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), testutil.NewSilentLogger())
+		mockRepo.On("GetByID", payment.ID).Return(payment, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*entity.Payment")).Return(nil).Maybe()
+		response, err := service.RefundPayment(payment.ID, &dto.RefundPaymentRequest{Amount: amount})
+		if err != nil {
+			mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		}
+		return response, err
+	}
+
+	completed := func(amount float64, currency string) *entity.Payment {
+		payment := testutil.CreatePaymentFixture()
+		payment.Amount = amount
+		payment.Currency = currency
+		payment.Status = entity.PaymentStatusCompleted
+		return payment
+	}
+
+	t.Run("should reject a refund more precise than the currency", func(t *testing.T) {
+		cases := []struct {
+			currency string
+			amount   float64
+		}{
+			{currency: "USD", amount: 10.125},
+			{currency: "USD", amount: 0.009},
+			{currency: "JPY", amount: 2.5},
+			{currency: "KWD", amount: 1.0005},
+		}
+
+		for _, tc := range cases {
+			// When
+			response, err := refund(t, completed(1000, tc.currency), tc.amount)
+
+			// Then
+			assert.Equal(t, apperror.CodeAmountPrecision, apperror.Code(err), "%v %s", tc.amount, tc.currency)
+			assert.Nil(t, response)
+		}
+	})
+
+	t.Run("should refund an amount at the currency's precision unchanged", func(t *testing.T) {
+		// When
+		response, err := refund(t, completed(100, "USD"), 10.12)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, 10.12, response.RefundedAmount)
+		assert.Equal(t, string(entity.PaymentStatusPartiallyRefunded), response.Status)
+	})
+
+	t.Run("should complete the refund when partial refunds add up", func(t *testing.T) {
+		// Given: 0.1 + 0.2 is not exactly 0.3 in floating point
+		payment := completed(0.3, "USD")
+		payment.RefundedAmount = 0.1
+		payment.Status = entity.PaymentStatusPartiallyRefunded
+
+		// When
+		response, err := refund(t, payment, 0.2)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, 0.3, response.RefundedAmount)
+		assert.Equal(t, string(entity.PaymentStatusRefunded), response.Status)
+	})
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/repository"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentService_BulkUpdateStatus(t *testing.T) {
+	// Setup: a real repository so the test sees which rows the transaction changed
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewSilentLogger()
+	repo := repository.NewPaymentRepository(db, logger)
+	service := NewPaymentService(repo, &testutil.MockUserService{}, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+	seed := func(status entity.PaymentStatus) uint {
+		payment := testutil.CreatePaymentFixture()
+		payment.ID = 0
+		payment.Status = status
+		require.NoError(t, repo.Create(payment))
+		return payment.ID
+	}
+
+	t.Run("should apply valid transitions and report the rest", func(t *testing.T) {
+		// Given
+		pending := seed(entity.PaymentStatusPending)
+		otherPending := seed(entity.PaymentStatusPending)
+		completed := seed(entity.PaymentStatusCompleted)
+		refunded := seed(entity.PaymentStatusRefunded)
+		missing := uint(9999)
+
+		// When
+		result, err := service.BulkUpdateStatus(&dto.BulkStatusUpdateRequest{
+			IDs:    []uint{pending, completed, missing, otherPending, refunded, pending},
+			Status: entity.PaymentStatusCanceled.String(),
+		})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "canceled", result.Status)
+		assert.Equal(t, 2, result.Updated)
+		assert.Equal(t, 3, result.Failed)
+		assert.Equal(t, []dto.BulkStatusResult{
+			{ID: pending, Updated: true, FromStatus: "pending"},
+			{ID: completed, FromStatus: "completed", Error: "cannot transition from completed to canceled"},
+			{ID: missing, Error: "payment not found"},
+			{ID: otherPending, Updated: true, FromStatus: "pending"},
+			{ID: refunded, FromStatus: "refunded", Error: "cannot transition from refunded to canceled"},
+		}, result.Results)
+
+		// No illegal transition reached the database
+		statuses := map[uint]entity.PaymentStatus{}
+		for _, id := range []uint{pending, otherPending, completed, refunded} {
+			payment, err := repo.GetByID(id)
+			require.NoError(t, err)
+			statuses[id] = payment.Status
+		}
+		assert.Equal(t, map[uint]entity.PaymentStatus{
+			pending:      entity.PaymentStatusCanceled,
+			otherPending: entity.PaymentStatusCanceled,
+			completed:    entity.PaymentStatusCompleted,
+			refunded:     entity.PaymentStatusRefunded,
+		}, statuses)
+
+		// Updated payments record the admin as the actor
+		history, err := repo.GetStatusHistory(pending)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, "pending", history[1].FromStatus)
+		assert.Equal(t, "canceled", history[1].ToStatus)
+		assert.Equal(t, entity.StatusActorAdmin, history[1].Actor)
+
+		history, err = repo.GetStatusHistory(completed)
+		require.NoError(t, err)
+		assert.Len(t, history, 1)
+	})
+
+	t.Run("should reject a move to a refund status", func(t *testing.T) {
+		// Given
+		completed := seed(entity.PaymentStatusCompleted)
+
+		for _, status := range []entity.PaymentStatus{entity.PaymentStatusRefunded, entity.PaymentStatusPartiallyRefunded} {
+			// When
+			result, err := service.BulkUpdateStatus(&dto.BulkStatusUpdateRequest{IDs: []uint{completed}, Status: status.String()})
+
+			// Then
+			assert.Nil(t, result)
+			assert.EqualError(t, err, "invalid payment status", status)
+		}
+		payment, err := repo.GetByID(completed)
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusCompleted, payment.Status)
+		assert.Zero(t, payment.RefundedAmount)
+	})
+
+	t.Run("should reject an unknown status", func(t *testing.T) {
+		// When
+		result, err := service.BulkUpdateStatus(&dto.BulkStatusUpdateRequest{IDs: []uint{1}, Status: "settled"})
+
+		// Then
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "invalid payment status")
+	})
+}
+
+func TestPaymentService_BulkDeletePayments(t *testing.T) {
+	// Setup: a real repository so the test sees which rows the transaction deleted
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewSilentLogger()
+	repo := repository.NewPaymentRepository(db, logger)
+	service := NewPaymentService(repo, &testutil.MockUserService{}, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+	seed := func(status entity.PaymentStatus) uint {
+		payment := testutil.CreatePaymentFixture()
+		payment.ID = 0
+		payment.Status = status
+		require.NoError(t, repo.Create(payment))
+		return payment.ID
+	}
+
+	t.Run("should soft-delete a batch and keep completed payments", func(t *testing.T) {
+		// Given
+		pending := seed(entity.PaymentStatusPending)
+		failed := seed(entity.PaymentStatusFailed)
+		completed := seed(entity.PaymentStatusCompleted)
+		missing := uint(9999)
+
+		// When
+		result, err := service.BulkDeletePayments(&dto.BulkDeleteRequest{
+			IDs: []uint{pending, completed, missing, failed, pending},
+		})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Deleted)
+		assert.Equal(t, 2, result.Failed)
+		assert.Equal(t, []dto.BulkDeleteResult{
+			{ID: pending, Deleted: true, Status: "pending"},
+			{ID: completed, Status: "completed", Error: "completed payments can only be deleted with force"},
+			{ID: missing, Error: "payment not found"},
+			{ID: failed, Deleted: true, Status: "failed"},
+		}, result.Results)
+
+		for _, id := range []uint{pending, failed} {
+			_, err := repo.GetByID(id)
+			assert.ErrorIs(t, err, repository.ErrNotFound)
+
+			// Soft-deleted rows stay in the table
+			var deleted entity.Payment
+			require.NoError(t, db.Unscoped().First(&deleted, id).Error)
+			assert.True(t, deleted.DeletedAt.Valid)
+		}
+
+		kept, err := repo.GetByID(completed)
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusCompleted, kept.Status)
+	})
+
+	t.Run("should delete completed payments when forced", func(t *testing.T) {
+		// Given
+		completed := seed(entity.PaymentStatusCompleted)
+
+		// When
+		result, err := service.BulkDeletePayments(&dto.BulkDeleteRequest{IDs: []uint{completed}, Force: true})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Deleted)
+		assert.Equal(t, []dto.BulkDeleteResult{{ID: completed, Deleted: true, Status: "completed"}}, result.Results)
+
+		_, err = repo.GetByID(completed)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/repository"
+	userDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// runConcurrently calls fn n times at once and returns the errors in call order
+func runConcurrently(n int, fn func(i int) error) []error {
+	errs := make([]error, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = fn(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+func setupConcurrencyTest(t *testing.T, cfg *config.Config) (*gorm.DB, repository.PaymentRepository, PaymentService) {
+	t.Helper()
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	// In-memory SQLite gives each connection its own database
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	// Pausing after each read gives a concurrent caller the chance to read the same row
+	// before the first one writes, unless the read holds a lock until the write
+	err = db.Callback().Query().After("gorm:query").Register("test:pause", func(*gorm.DB) {
+		time.Sleep(5 * time.Millisecond)
+	})
+	require.NoError(t, err)
+
+	mockUserService := &testutil.MockUserService{}
+	mockUserService.On("GetUserByID", mock.Anything).Return(&userDto.UserResponse{ID: 1}, nil).Maybe()
+
+	logger := testutil.NewSilentLogger()
+	repo := repository.NewPaymentRepository(db, logger)
+	service := NewPaymentService(repo, mockUserService, testutil.NewMockWalletService(), cfg, featureflag.New(cfg), logger)
+	return db, repo, service
+}
+
+func TestPaymentService_CreatePayment_ConcurrentActiveLimit(t *testing.T) {
+	// Setup
+	cfg := testutil.NewTestConfig()
+	cfg.Payment.MaxActivePerUser = 2
+	db, repo, service := setupConcurrencyTest(t, cfg)
+
+	// Given - the user row whose lock serializes the creations
+	user := testutil.CreateUserFixture()
+	require.NoError(t, db.Create(user).Error)
+	req := testutil.CreatePaymentRequestFixture()
+	req.UserID = user.ID
+
+	// When - more parallel creations than the cap allows
+	errs := runConcurrently(5, func(int) error {
+		_, err := service.CreatePayment(req)
+		return err
+	})
+
+	// Then
+	var succeeded int
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.Equal(t, apperror.CodeActivePaymentLimit, apperror.Code(err))
+	}
+	assert.Equal(t, 2, succeeded)
+
+	payments, err := repo.GetByUserID(user.ID)
+	require.NoError(t, err)
+	assert.Len(t, payments, 2)
+}
+
+func TestPaymentService_RefundPayment_Concurrent(t *testing.T) {
+	// Setup
+	_, repo, service := setupConcurrencyTest(t, testutil.NewTestConfig())
+
+	// Given
+	payment := &entity.Payment{Amount: 100, Currency: "USD", UserID: 1, Status: entity.PaymentStatusCompleted}
+	require.NoError(t, repo.Create(payment))
+
+	// When - two refunds that each take more than half the amount
+	errs := runConcurrently(2, func(int) error {
+		_, err := service.RefundPayment(payment.ID, &dto.RefundPaymentRequest{Amount: 60})
+		return err
+	})
+
+	// Then
+	var succeeded int
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.Equal(t, apperror.CodeRefundExceedsAmount, apperror.Code(err))
+	}
+	assert.Equal(t, 1, succeeded)
+
+	stored, err := repo.GetByID(payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 60.0, stored.RefundedAmount)
+	assert.Equal(t, entity.PaymentStatusPartiallyRefunded, stored.Status)
+}
+
+func TestPaymentService_UpdatePayment_KeepsConcurrentRefund(t *testing.T) {
+	// Setup
+	_, repo, service := setupConcurrencyTest(t, testutil.NewTestConfig())
+
+	// Given
+	payment := &entity.Payment{Amount: 100, Currency: "USD", UserID: 1, Status: entity.PaymentStatusCompleted}
+	require.NoError(t, repo.Create(payment))
+
+	// When - a description-only update races a refund
+	errs := runConcurrently(2, func(i int) error {
+		if i == 0 {
+			_, err := service.RefundPayment(payment.ID, &dto.RefundPaymentRequest{Amount: 40})
+			return err
+		}
+		_, err := service.UpdatePayment(payment.ID, &dto.UpdatePaymentRequest{Description: "Renamed"})
+		return err
+	})
+
+	// Then
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	stored, err := repo.GetByID(payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 40.0, stored.RefundedAmount)
+	assert.Equal(t, entity.PaymentStatusPartiallyRefunded, stored.Status)
+	assert.Equal(t, "Renamed", stored.Description)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/repository"
+	userDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentService_GetPaymentHistory(t *testing.T) {
+	// Setup: a real repository so history rows are written alongside each status change
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewSilentLogger()
+	repo := repository.NewPaymentRepository(db, logger)
+
+	mockUserService := &testutil.MockUserService{}
+	mockUserService.On("GetUserByID", uint(1)).Return(&userDto.UserResponse{ID: 1}, nil)
+
+	mockScheduler := &testutil.MockTaskScheduler{}
+	service := NewPaymentService(repo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+	service.SetTaskScheduler(mockScheduler)
+
+	t.Run("should list transitions from creation through worker update and refund", func(t *testing.T) {
+		// Given
+		req := testutil.CreatePaymentRequestFixture()
+		req.UserID = 1
+		req.Amount = 100
+		mockScheduler.On("SchedulePaymentProcessing", uint(1)).Return(nil).Once()
+
+		payment, err := service.CreatePayment(req)
+		require.NoError(t, err)
+		_, err = service.UpdatePayment(payment.ID, &dto.UpdatePaymentRequest{
+			Status: entity.PaymentStatusCompleted.String(),
+			Actor:  entity.StatusActorWorker,
+		})
+		require.NoError(t, err)
+		_, err = service.RefundPayment(payment.ID, &dto.RefundPaymentRequest{Amount: 40})
+		require.NoError(t, err)
+
+		// When
+		history, err := service.GetPaymentHistory(payment.ID)
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		assert.Equal(t, dto.PaymentStatusChangeResponse{
+			ToStatus: "pending", Actor: "api", ChangedAt: history[0].ChangedAt,
+		}, history[0])
+		assert.Equal(t, dto.PaymentStatusChangeResponse{
+			FromStatus: "pending", ToStatus: "completed", Actor: "worker", ChangedAt: history[1].ChangedAt,
+		}, history[1])
+		assert.Equal(t, dto.PaymentStatusChangeResponse{
+			FromStatus: "completed", ToStatus: "partially_refunded", Actor: "api", ChangedAt: history[2].ChangedAt,
+		}, history[2])
+		assert.False(t, history[1].ChangedAt.Before(history[0].ChangedAt))
+		assert.False(t, history[2].ChangedAt.Before(history[1].ChangedAt))
+	})
+
+	t.Run("should attribute gateway callbacks and admin reprocessing", func(t *testing.T) {
+		// Given
+		req := testutil.CreatePaymentRequestFixture()
+		req.UserID = 1
+		mockScheduler.On("SchedulePaymentProcessing", uint(2)).Return(nil).Twice()
+
+		payment, err := service.CreatePayment(req)
+		require.NoError(t, err)
+		_, err = service.ApplyGatewayEvent(&dto.GatewayEvent{
+			Gateway: SimulatedGateway, ID: "evt_1", PaymentID: payment.ID, Status: "failed",
+		})
+		require.NoError(t, err)
+		_, err = service.ReprocessPayment(payment.ID)
+		require.NoError(t, err)
+
+		// When
+		history, err := service.GetPaymentHistory(payment.ID)
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		assert.Equal(t, []string{"api", "gateway:simulated", "admin"},
+			[]string{history[0].Actor, history[1].Actor, history[2].Actor})
+		assert.Equal(t, []string{"pending", "failed", "pending"},
+			[]string{history[0].ToStatus, history[1].ToStatus, history[2].ToStatus})
+	})
+
+	t.Run("should leave payment and history unchanged on a dry-run refund", func(t *testing.T) {
+		// Given
+		req := testutil.CreatePaymentRequestFixture()
+		req.UserID = 1
+		req.Amount = 100
+		mockScheduler.On("SchedulePaymentProcessing", uint(3)).Return(nil).Once()
+
+		payment, err := service.CreatePayment(req)
+		require.NoError(t, err)
+		_, err = service.UpdatePayment(payment.ID, &dto.UpdatePaymentRequest{
+			Status: entity.PaymentStatusCompleted.String(),
+			Actor:  entity.StatusActorWorker,
+		})
+		require.NoError(t, err)
+
+		// When
+		result, err := service.RefundPayment(payment.ID, &dto.RefundPaymentRequest{Amount: 100, DryRun: true})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "refunded", result.Status)
+		assert.Equal(t, float64(100), result.RefundedAmount)
+
+		stored, err := service.GetPaymentByID(payment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "completed", stored.Status)
+		assert.Zero(t, stored.RefundedAmount)
+
+		history, err := service.GetPaymentHistory(payment.ID)
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+
+		// a dry run over the refundable amount reports the same error as a real refund
+		_, err = service.RefundPayment(payment.ID, &dto.RefundPaymentRequest{Amount: 150, DryRun: true})
+		assert.EqualError(t, err, "refund amount exceeds refundable amount")
+	})
+
+	t.Run("should return error for a missing payment", func(t *testing.T) {
+		// When
+		history, err := service.GetPaymentHistory(999)
+
+		// Then
+		assert.Nil(t, history)
+		assert.EqualError(t, err, "payment not found")
+	})
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/repository"
+	userDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentService_CreatePayment_ActiveLimit(t *testing.T) {
+	// Setup: a real repository so the pending count comes from stored payments
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewSilentLogger()
+	repo := repository.NewPaymentRepository(db, logger)
+
+	mockUserService := &testutil.MockUserService{}
+	mockUserService.On("GetUserByID", uint(1)).Return(&userDto.UserResponse{ID: 1}, nil)
+	mockUserService.On("GetUserByID", uint(2)).Return(&userDto.UserResponse{ID: 2}, nil)
+
+	cfg := testutil.NewTestConfig()
+	cfg.Payment.MaxActivePerUser = 2
+	cfg.Payment.MaxActivePerUserOverrides = map[string]int{"2": 3}
+	service := NewPaymentService(repo, mockUserService, testutil.NewMockWalletService(), cfg, featureflag.New(cfg), logger)
+
+	t.Run("should allow payments up to the cap and reject the next", func(t *testing.T) {
+		// Given
+		req := testutil.CreatePaymentRequestFixture()
+		req.UserID = 1
+
+		// When
+		first, firstErr := service.CreatePayment(req)
+		_, secondErr := service.CreatePayment(req)
+		third, thirdErr := service.CreatePayment(req)
+
+		// Then
+		assert.NoError(t, firstErr)
+		assert.NoError(t, secondErr)
+		assert.Nil(t, third)
+		assert.EqualError(t, thirdErr, "active payment limit reached")
+
+		// And: settling a payment frees a slot, since completed payments are not counted
+		require.NoError(t, db.Model(&entity.Payment{}).Where("id = ?", first.ID).
+			Update("status", entity.PaymentStatusCompleted).Error)
+		_, err := service.CreatePayment(req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should apply a per-user override", func(t *testing.T) {
+		// Given
+		req := testutil.CreatePaymentRequestFixture()
+		req.UserID = 2
+
+		// When
+		for i := 0; i < 3; i++ {
+			_, err := service.CreatePayment(req)
+			require.NoError(t, err)
+		}
+		_, err := service.CreatePayment(req)
+
+		// Then
+		assert.EqualError(t, err, "active payment limit reached")
+	})
+}
@@ -2,15 +2,23 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/repository"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/service"
+	walletService "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/service"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/money"
 
 	"go.uber.org/zap"
-	"gorm.io/gorm"
 )
 
 type PaymentService interface {
@@ -20,32 +28,77 @@ type PaymentService interface {
 	UpdatePayment(id uint, req *dto.UpdatePaymentRequest) (*dto.PaymentResponse, error)
 	DeletePayment(id uint) error
 	GetPaymentsByUser(userID uint) ([]dto.PaymentResponse, error)
+	GetPaymentReceipt(id uint) (*dto.PaymentReceiptResponse, error)
+	RefundPayment(id uint, req *dto.RefundPaymentRequest) (*dto.PaymentResponse, error)
+	ApplyGatewayEvent(event *dto.GatewayEvent) (*dto.GatewayEventResponse, error)
+	ReprocessPayment(id uint) (*dto.PaymentResponse, error)
+	BulkUpdateStatus(req *dto.BulkStatusUpdateRequest) (*dto.BulkStatusUpdateResponse, error)
+	BulkDeletePayments(req *dto.BulkDeleteRequest) (*dto.BulkDeleteResponse, error)
+	GetPaymentHistory(id uint) ([]dto.PaymentStatusChangeResponse, error)
+	GetPaymentStatuses() []string
+	SetTaskScheduler(scheduler TaskScheduler)
+}
+
+// TaskScheduler schedules background payment tasks. It is implemented by the payment
+// worker and injected after construction so this package never imports the worker.
+type TaskScheduler interface {
+	SchedulePaymentProcessing(paymentID uint) error
+	SchedulePaymentStatusCheck(paymentID uint, delay time.Duration) error
 }
 
 type paymentService struct {
 	repo        repository.PaymentRepository
 	userService service.UserService
+	wallets     walletService.WalletService
+	scheduler   TaskScheduler
+	cfg         *config.Config
+	flags       *featureflag.Flags
 	logger      *zap.Logger
 }
 
 func NewPaymentService(
 	repo repository.PaymentRepository,
 	userService service.UserService,
+	wallets walletService.WalletService,
+	cfg *config.Config,
+	flags *featureflag.Flags,
 	logger *zap.Logger,
 ) PaymentService {
 	return &paymentService{
 		repo:        repo,
 		userService: userService,
+		wallets:     wallets,
+		cfg:         cfg,
+		flags:       flags,
 		logger:      logger,
 	}
 }
 
 func (s *paymentService) CreatePayment(req *dto.CreatePaymentRequest) (*dto.PaymentResponse, error) {
+	// Binding rejects these over HTTP, but gRPC and gateway requests reach the service unvalidated
+	if !validAmount(req.Amount) {
+		return nil, apperror.New(apperror.CodeInvalidAmount, "invalid amount")
+	}
+	if decimalPlaces(req.Amount) > money.CurrencyDecimals(req.Currency) {
+		return nil, apperror.New(apperror.CodeAmountPrecision, "amount exceeds currency precision")
+	}
+
 	// Validate that user exists before creating payment
 	_, err := s.userService.GetUserByID(req.UserID)
 	if err != nil {
 		s.logger.Error("User not found for payment creation", zap.Uint("user_id", req.UserID), zap.Error(err))
-		return nil, errors.New("user not found")
+		return nil, apperror.New(apperror.CodeUserNotFound, "user not found")
+	}
+
+	// A payment has to be in a currency the user holds a wallet in; a missing one that
+	// may be created is inserted with the payment, so a rejected payment leaves none behind
+	createWallet, err := s.wallets.RequireWallet(req.UserID, req.Currency)
+	if err != nil {
+		s.logger.Warn("No wallet in payment currency",
+			zap.Uint("user_id", req.UserID),
+			zap.String("currency", req.Currency),
+			zap.Error(err))
+		return nil, err
 	}
 
 	payment := &entity.Payment{
@@ -54,11 +107,27 @@ func (s *paymentService) CreatePayment(req *dto.CreatePaymentRequest) (*dto.Paym
 		Status:      entity.PaymentStatusPending,
 		Description: req.Description,
 		UserID:      req.UserID,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		Tags:        paymentTags(req.Tags),
+		StatusActor: entity.StatusActorAPI,
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
 	}
 
-	err = s.repo.Create(payment)
+	// Processing is requested through the outbox so the request commits with the payment
+	// and survives a queue outage; the worker's relay enqueues the task
+	var events []string
+	if s.flags.IsEnabled(featureflag.ScheduleOnCreate) {
+		events = append(events, entity.EventPaymentCreated)
+	}
+
+	// The pending payment cap is counted inside the create transaction, under the user's
+	// row lock, so parallel requests cannot all pass it
+	limit := s.cfg.Payment.MaxActivePayments(req.UserID)
+	err = s.repo.CreateForUser(payment, createWallet, limit, events...)
+	if errors.Is(err, repository.ErrActivePaymentLimit) {
+		s.logger.Warn("Active payment limit reached", zap.Uint("user_id", req.UserID), zap.Int("limit", limit))
+		return nil, err
+	}
 	if err != nil {
 		s.logger.Error("Failed to create payment", zap.Error(err))
 		return nil, err
@@ -67,11 +136,15 @@ func (s *paymentService) CreatePayment(req *dto.CreatePaymentRequest) (*dto.Paym
 	return s.entityToResponse(payment), nil
 }
 
+func (s *paymentService) SetTaskScheduler(scheduler TaskScheduler) {
+	s.scheduler = scheduler
+}
+
 func (s *paymentService) GetPaymentByID(id uint) (*dto.PaymentResponse, error) {
 	payment, err := s.repo.GetByID(id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("payment not found")
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.New(apperror.CodePaymentNotFound, "payment not found")
 		}
 		return nil, err
 	}
@@ -80,12 +153,8 @@ func (s *paymentService) GetPaymentByID(id uint) (*dto.PaymentResponse, error) {
 }
 
 func (s *paymentService) GetPayments(filter *dto.PaymentFilter) (*dto.PaymentListResponse, error) {
-	if filter.Page <= 0 {
-		filter.Page = 1
-	}
-	if filter.PageSize <= 0 {
-		filter.PageSize = 10
-	}
+	filter.Normalize(s.cfg.Pagination)
+	filter.Tag = strings.ToLower(strings.TrimSpace(filter.Tag))
 
 	payments, totalCount, err := s.repo.GetAll(filter)
 	if err != nil {
@@ -97,6 +166,12 @@ func (s *paymentService) GetPayments(filter *dto.PaymentFilter) (*dto.PaymentLis
 		responses = append(responses, *s.entityToResponse(&payment))
 	}
 
+	if filter.Expand == "user" {
+		if err := s.expandUsers(responses); err != nil {
+			return nil, err
+		}
+	}
+
 	return &dto.PaymentListResponse{
 		Data:       responses,
 		TotalCount: totalCount,
@@ -106,28 +181,41 @@ func (s *paymentService) GetPayments(filter *dto.PaymentFilter) (*dto.PaymentLis
 }
 
 func (s *paymentService) UpdatePayment(id uint, req *dto.UpdatePaymentRequest) (*dto.PaymentResponse, error) {
-	payment, err := s.repo.GetByID(id)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("payment not found")
+	payment, err := s.repo.UpdateLocked(id, func(payment *entity.Payment) (bool, error) {
+		if req.Status == "" && req.Description == "" {
+			return false, apperror.New(apperror.CodeEmptyUpdate, "no fields to update")
 		}
-		return nil, err
-	}
-
-	status := entity.PaymentStatus(req.Status)
-	if !status.IsValid() {
-		return nil, errors.New("invalid payment status")
-	}
 
-	payment.Status = status
-	if req.Description != "" {
-		payment.Description = req.Description
-	}
-	payment.UpdatedAt = time.Now()
+		if req.Status != "" {
+			// The same rules as BulkUpdateStatus: refund statuses are left to RefundPayment,
+			// and any other change must follow the state machine
+			status := entity.PaymentStatus(req.Status)
+			if !status.IsValid() || status.IsSetByRefund() {
+				return false, apperror.New(apperror.CodeInvalidPaymentStatus, "invalid payment status")
+			}
+			if status != payment.Status && !payment.Status.CanTransitionTo(status) {
+				return false, apperror.New(apperror.CodeInvalidStatusTransition, "payment cannot move to the requested status")
+			}
 
-	err = s.repo.Update(payment)
+			payment.Status = status
+			payment.StatusActor = req.Actor
+			if payment.StatusActor == "" {
+				payment.StatusActor = entity.StatusActorAPI
+			}
+		}
+		if req.Description != "" {
+			payment.Description = req.Description
+		}
+		payment.UpdatedAt = time.Now().UTC()
+		return true, nil
+	})
 	if err != nil {
-		s.logger.Error("Failed to update payment", zap.Error(err))
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.New(apperror.CodePaymentNotFound, "payment not found")
+		}
+		if apperror.Code(err) == "" {
+			s.logger.Error("Failed to update payment", zap.Error(err))
+		}
 		return nil, err
 	}
 
@@ -137,8 +225,8 @@ func (s *paymentService) UpdatePayment(id uint, req *dto.UpdatePaymentRequest) (
 func (s *paymentService) DeletePayment(id uint) error {
 	_, err := s.repo.GetByID(id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("payment not found")
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.New(apperror.CodePaymentNotFound, "payment not found")
 		}
 		return err
 	}
@@ -160,15 +248,447 @@ func (s *paymentService) GetPaymentsByUser(userID uint) ([]dto.PaymentResponse,
 	return responses, nil
 }
 
+func (s *paymentService) GetPaymentReceipt(id uint) (*dto.PaymentReceiptResponse, error) {
+	payment, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.New(apperror.CodePaymentNotFound, "payment not found")
+		}
+		return nil, err
+	}
+
+	if !payment.Status.HasReceipt() {
+		return nil, apperror.New(apperror.CodeReceiptNotAvailable, "receipt not available for payment status")
+	}
+
+	user, err := s.userService.GetUserByID(payment.UserID)
+	if err != nil {
+		s.logger.Error("Failed to get user for payment receipt",
+			zap.Uint("payment_id", payment.ID),
+			zap.Uint("user_id", payment.UserID),
+			zap.Error(err))
+		return nil, err
+	}
+
+	return &dto.PaymentReceiptResponse{
+		ReceiptNumber: receiptNumber(payment),
+		PaymentID:     payment.ID,
+		Amount:        payment.Amount,
+		Currency:      payment.Currency,
+		Status:        payment.Status.String(),
+		Description:   payment.Description,
+		UserID:        payment.UserID,
+		UserName:      user.Name,
+		UserEmail:     user.Email,
+		PaidAt:        payment.UpdatedAt,
+		IssuedAt:      time.Now().UTC(),
+	}, nil
+}
+
+// expandUsers attaches each payment's user, resolving all of them in one lookup
+func (s *paymentService) expandUsers(payments []dto.PaymentResponse) error {
+	if len(payments) == 0 {
+		return nil
+	}
+
+	seen := make(map[uint]bool)
+	ids := make([]uint, 0, len(payments))
+	for _, payment := range payments {
+		if !seen[payment.UserID] {
+			seen[payment.UserID] = true
+			ids = append(ids, payment.UserID)
+		}
+	}
+
+	users, err := s.userService.GetUsersByIDs(ids)
+	if err != nil {
+		s.logger.Error("Failed to expand payment users", zap.Error(err))
+		return err
+	}
+
+	for i := range payments {
+		if user, ok := users[payments[i].UserID]; ok {
+			payments[i].User = &dto.PaymentUserResponse{
+				ID:    user.ID,
+				Name:  user.Name,
+				Email: user.Email,
+			}
+		}
+	}
+	return nil
+}
+
+// validAmount reports whether amount is a finite number greater than zero
+func validAmount(amount float64) bool {
+	return !math.IsNaN(amount) && !math.IsInf(amount, 0) && amount > 0
+}
+
+// decimalPlaces counts the decimals of the shortest representation of amount, so 100.1
+// has one decimal even though its binary value is not exact
+func decimalPlaces(amount float64) int {
+	formatted := strconv.FormatFloat(amount, 'f', -1, 64)
+	if i := strings.IndexByte(formatted, '.'); i >= 0 {
+		return len(formatted) - i - 1
+	}
+	return 0
+}
+
+func (s *paymentService) RefundPayment(id uint, req *dto.RefundPaymentRequest) (*dto.PaymentResponse, error) {
+	if !validAmount(req.Amount) {
+		return nil, apperror.New(apperror.CodeInvalidAmount, "invalid amount")
+	}
+
+	amount := req.Amount
+	// The remaining amount is checked on the locked row, so concurrent refunds cannot
+	// together exceed the payment amount
+	payment, err := s.repo.UpdateLocked(id, func(payment *entity.Payment) (bool, error) {
+		if !payment.Status.IsRefundable() {
+			return false, apperror.New(apperror.CodePaymentNotRefundable, "payment is not refundable")
+		}
+
+		// Like a payment amount, a refund may not be more precise than its currency. Totals
+		// of amounts already at that precision only carry float error, which rounding to
+		// nearest removes, so partial refunds add up to the payment amount exactly.
+		if decimalPlaces(amount) > money.CurrencyDecimals(payment.Currency) {
+			return false, apperror.New(apperror.CodeAmountPrecision, "amount exceeds currency precision")
+		}
+		remaining := money.RoundToCurrency(payment.Amount-payment.RefundedAmount, payment.Currency, money.HalfUp)
+		if amount > remaining {
+			return false, apperror.New(apperror.CodeRefundExceedsAmount, "refund amount exceeds refundable amount")
+		}
+
+		payment.RefundedAmount = money.RoundToCurrency(payment.RefundedAmount+amount, payment.Currency, money.HalfUp)
+		if payment.RefundedAmount >= payment.Amount {
+			payment.RefundedAmount = payment.Amount
+			payment.Status = entity.PaymentStatusRefunded
+		} else {
+			payment.Status = entity.PaymentStatusPartiallyRefunded
+		}
+		payment.StatusActor = entity.StatusActorAPI
+		payment.UpdatedAt = time.Now().UTC()
+		return !req.DryRun, nil
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.New(apperror.CodePaymentNotFound, "payment not found")
+		}
+		if apperror.Code(err) == "" {
+			s.logger.Error("Failed to refund payment", zap.Uint("payment_id", id), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if req.DryRun {
+		s.logger.Info("Payment refund dry run",
+			zap.Uint("payment_id", id),
+			zap.Float64("amount", amount),
+			zap.String("status", payment.Status.String()))
+		return s.entityToResponse(payment), nil
+	}
+
+	s.logger.Info("Payment refunded",
+		zap.Uint("payment_id", id),
+		zap.Float64("amount", amount),
+		zap.Float64("refunded_amount", payment.RefundedAmount),
+		zap.String("status", payment.Status.String()))
+
+	return s.entityToResponse(payment), nil
+}
+
+// ApplyGatewayEvent moves a pending payment to the status reported by a gateway callback.
+// The event ID is recorded with the status change, so a redelivered event changes nothing.
+func (s *paymentService) ApplyGatewayEvent(event *dto.GatewayEvent) (*dto.GatewayEventResponse, error) {
+	response := &dto.GatewayEventResponse{
+		EventID:   event.ID,
+		PaymentID: event.PaymentID,
+		Status:    event.Status,
+	}
+
+	status := entity.PaymentStatus(event.Status)
+	processed := &entity.ProcessedEvent{
+		Gateway:   event.Gateway,
+		EventID:   event.ID,
+		PaymentID: event.PaymentID,
+	}
+
+	_, err := s.repo.ApplyGatewayEvent(processed, func(payment *entity.Payment) (bool, error) {
+		// A different event may already have reported the same status
+		if payment.Status == status {
+			response.Duplicate = true
+			return false, nil
+		}
+		if payment.Status != entity.PaymentStatusPending {
+			return false, apperror.New(apperror.CodePaymentStatusLocked, "payment status cannot change")
+		}
+
+		payment.Status = status
+		payment.StatusActor = entity.GatewayStatusActor(event.Gateway)
+		payment.UpdatedAt = time.Now().UTC()
+		return true, nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrEventAlreadyProcessed):
+			response.Duplicate = true
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, apperror.New(apperror.CodePaymentNotFound, "payment not found")
+		default:
+			return nil, err
+		}
+	}
+
+	if response.Duplicate {
+		s.logger.Info("Ignoring duplicate gateway event",
+			zap.String("gateway", event.Gateway),
+			zap.String("event_id", event.ID),
+			zap.Uint("payment_id", event.PaymentID))
+		return response, nil
+	}
+
+	s.logger.Info("Gateway event applied",
+		zap.String("gateway", event.Gateway),
+		zap.String("event_id", event.ID),
+		zap.Uint("payment_id", event.PaymentID),
+		zap.String("status", status.String()))
+
+	return response, nil
+}
+
+// ReprocessPayment enqueues another processing attempt for a failed or pending payment.
+// A failed payment is returned to pending first so the worker's result replaces it.
+func (s *paymentService) ReprocessPayment(id uint) (*dto.PaymentResponse, error) {
+	payment, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.New(apperror.CodePaymentNotFound, "payment not found")
+		}
+		return nil, err
+	}
+
+	if payment.Status != entity.PaymentStatusFailed && payment.Status != entity.PaymentStatusPending {
+		return nil, apperror.New(apperror.CodePaymentNotReprocessable, "payment cannot be reprocessed")
+	}
+
+	if s.scheduler == nil {
+		return nil, apperror.New(apperror.CodeSchedulerUnavailable, "task scheduler unavailable")
+	}
+
+	previous := payment.Status
+	payment.StatusActor = entity.StatusActorAdmin
+	if previous == entity.PaymentStatusFailed {
+		payment.Status = entity.PaymentStatusPending
+		payment.UpdatedAt = time.Now().UTC()
+		if err := s.repo.Update(payment); err != nil {
+			s.logger.Error("Failed to reset payment for reprocessing", zap.Uint("payment_id", id), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if err := s.scheduler.SchedulePaymentProcessing(payment.ID); err != nil {
+		s.logger.Error("Failed to schedule payment reprocessing", zap.Uint("payment_id", id), zap.Error(err))
+		if previous != payment.Status {
+			payment.Status = previous
+			payment.UpdatedAt = time.Now().UTC()
+			if restoreErr := s.repo.Update(payment); restoreErr != nil {
+				s.logger.Error("Failed to restore payment status", zap.Uint("payment_id", id), zap.Error(restoreErr))
+			}
+		}
+		return nil, apperror.New(apperror.CodeInternal, "failed to schedule payment processing")
+	}
+
+	s.logger.Info("Payment queued for reprocessing",
+		zap.Uint("payment_id", id),
+		zap.String("previous_status", previous.String()))
+
+	return s.entityToResponse(payment), nil
+}
+
+// BulkUpdateStatus moves the requested payments to one status in a single transaction.
+// Payments that are missing or whose status cannot make the transition are reported and
+// left untouched; the rest are updated.
+func (s *paymentService) BulkUpdateStatus(req *dto.BulkStatusUpdateRequest) (*dto.BulkStatusUpdateResponse, error) {
+	status := entity.PaymentStatus(req.Status)
+	// Refund statuses are only reached through RefundPayment, which records the amount
+	if !status.IsValid() || status.IsSetByRefund() {
+		return nil, apperror.New(apperror.CodeInvalidPaymentStatus, "invalid payment status")
+	}
+
+	ids := uniqueIDs(req.IDs)
+	before, err := s.repo.BulkUpdateStatus(ids, status, entity.StatusActorAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[uint]entity.PaymentStatus, len(before))
+	for _, payment := range before {
+		found[payment.ID] = payment.Status
+	}
+
+	response := &dto.BulkStatusUpdateResponse{
+		Status:  status.String(),
+		Results: make([]dto.BulkStatusResult, 0, len(ids)),
+	}
+	for _, id := range ids {
+		result := dto.BulkStatusResult{ID: id}
+		from, ok := found[id]
+		switch {
+		case !ok:
+			result.Error = "payment not found"
+		case !from.CanTransitionTo(status):
+			result.FromStatus = from.String()
+			result.Error = fmt.Sprintf("cannot transition from %s to %s", from, status)
+		default:
+			result.FromStatus = from.String()
+			result.Updated = true
+		}
+
+		if result.Updated {
+			response.Updated++
+		} else {
+			response.Failed++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	s.logger.Info("Bulk payment status update",
+		zap.String("status", status.String()),
+		zap.Int("updated", response.Updated),
+		zap.Int("failed", response.Failed))
+
+	return response, nil
+}
+
+// BulkDeletePayments soft-deletes the requested payments in a single transaction.
+// Completed payments are kept and reported unless the request forces the delete.
+func (s *paymentService) BulkDeletePayments(req *dto.BulkDeleteRequest) (*dto.BulkDeleteResponse, error) {
+	ids := uniqueIDs(req.IDs)
+	before, err := s.repo.BulkDelete(ids, req.Force)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[uint]entity.PaymentStatus, len(before))
+	for _, payment := range before {
+		found[payment.ID] = payment.Status
+	}
+
+	response := &dto.BulkDeleteResponse{Results: make([]dto.BulkDeleteResult, 0, len(ids))}
+	for _, id := range ids {
+		result := dto.BulkDeleteResult{ID: id}
+		status, ok := found[id]
+		switch {
+		case !ok:
+			result.Error = "payment not found"
+		case status.IsDeleteProtected() && !req.Force:
+			result.Status = status.String()
+			result.Error = fmt.Sprintf("%s payments can only be deleted with force", status)
+		default:
+			result.Status = status.String()
+			result.Deleted = true
+		}
+
+		if result.Deleted {
+			response.Deleted++
+		} else {
+			response.Failed++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	s.logger.Info("Bulk payment delete",
+		zap.Bool("force", req.Force),
+		zap.Int("deleted", response.Deleted),
+		zap.Int("failed", response.Failed))
+
+	return response, nil
+}
+
+// uniqueIDs drops repeated IDs, keeping the order of first appearance
+func uniqueIDs(ids []uint) []uint {
+	unique := make([]uint, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// GetPaymentHistory returns the payment's status transitions in the order they happened
+func (s *paymentService) GetPaymentHistory(id uint) ([]dto.PaymentStatusChangeResponse, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.New(apperror.CodePaymentNotFound, "payment not found")
+		}
+		return nil, err
+	}
+
+	changes, err := s.repo.GetStatusHistory(id)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]dto.PaymentStatusChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		history = append(history, dto.PaymentStatusChangeResponse{
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			Actor:      change.Actor,
+			ChangedAt:  change.CreatedAt,
+		})
+	}
+	return history, nil
+}
+
+// GetPaymentStatuses lists the valid payment status values
+func (s *paymentService) GetPaymentStatuses() []string {
+	statuses := entity.PaymentStatuses()
+	names := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		names = append(names, status.String())
+	}
+	return names
+}
+
+// paymentTags normalizes tags to trimmed lowercase, dropping blanks and duplicates
+func paymentTags(tags []string) []entity.PaymentTag {
+	seen := make(map[string]bool, len(tags))
+	result := make([]entity.PaymentTag, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, entity.PaymentTag{Tag: tag})
+	}
+	return result
+}
+
+// receiptNumber derives a stable receipt number from the payment date and ID
+func receiptNumber(payment *entity.Payment) string {
+	return fmt.Sprintf("RCPT-%s-%08d", payment.CreatedAt.UTC().Format("20060102"), payment.ID)
+}
+
 func (s *paymentService) entityToResponse(payment *entity.Payment) *dto.PaymentResponse {
-	return &dto.PaymentResponse{
-		ID:          payment.ID,
-		Amount:      payment.Amount,
-		Currency:    payment.Currency,
-		Status:      payment.Status.String(),
-		Description: payment.Description,
-		UserID:      payment.UserID,
-		CreatedAt:   payment.CreatedAt,
-		UpdatedAt:   payment.UpdatedAt,
+	response := &dto.PaymentResponse{
+		ID:              payment.ID,
+		ReferenceNumber: payment.ReferenceNumber,
+		Amount:          payment.Amount,
+		RefundedAmount:  payment.RefundedAmount,
+		Currency:        payment.Currency,
+		Status:          payment.Status.String(),
+		Description:     payment.Description,
+		UserID:          payment.UserID,
+		Tags:            payment.TagNames(),
+		CreatedAt:       payment.CreatedAt,
+		UpdatedAt:       payment.UpdatedAt,
+	}
+	if payment.DeletedAt.Valid {
+		deletedAt := payment.DeletedAt.Time
+		response.DeletedAt = &deletedAt
 	}
+	return response
 }
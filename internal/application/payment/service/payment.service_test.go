@@ -7,12 +7,16 @@ import (
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/repository"
 	userDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"gorm.io/gorm"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPaymentService_CreatePayment(t *testing.T) {
@@ -21,7 +25,7 @@ func TestPaymentService_CreatePayment(t *testing.T) {
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		req := testutil.CreatePaymentRequestFixture()
 		userResponse := &userDto.UserResponse{
@@ -32,7 +36,7 @@ func TestPaymentService_CreatePayment(t *testing.T) {
 
 		// Mock expectations
 		mockUserService.On("GetUserByID", req.UserID).Return(userResponse, nil)
-		mockRepo.On("Create", mock.AnythingOfType("*entity.Payment")).Return(nil).Run(func(args mock.Arguments) {
+		mockRepo.On("CreateForUser", mock.AnythingOfType("*entity.Payment"), false, 0, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
 			payment := args.Get(0).(*entity.Payment)
 			payment.ID = 1
 		})
@@ -53,12 +57,88 @@ func TestPaymentService_CreatePayment(t *testing.T) {
 		mockUserService.AssertExpectations(t)
 	})
 
+	t.Run("should store timestamps in UTC", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), testutil.NewSilentLogger())
+
+		req := testutil.CreatePaymentRequestFixture()
+		mockUserService.On("GetUserByID", req.UserID).Return(&userDto.UserResponse{ID: req.UserID}, nil)
+
+		var created *entity.Payment
+		mockRepo.On("CreateForUser", mock.AnythingOfType("*entity.Payment"), false, 0, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			created = args.Get(0).(*entity.Payment)
+		})
+
+		// When
+		_, err := service.CreatePayment(req)
+
+		// Then
+		require.NoError(t, err)
+		require.NotNil(t, created)
+		assert.Equal(t, time.UTC, created.CreatedAt.Location())
+		assert.Equal(t, time.UTC, created.UpdatedAt.Location())
+	})
+
+	t.Run("should record a payment.created event with the payment", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		mockScheduler := &testutil.MockTaskScheduler{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+		service.SetTaskScheduler(mockScheduler)
+
+		req := testutil.CreatePaymentRequestFixture()
+
+		// Mock expectations
+		mockUserService.On("GetUserByID", req.UserID).Return(&userDto.UserResponse{ID: req.UserID}, nil)
+		mockRepo.On("CreateForUser", mock.AnythingOfType("*entity.Payment"), false, 0, []string{entity.EventPaymentCreated}).Return(nil).Run(func(args mock.Arguments) {
+			payment := args.Get(0).(*entity.Payment)
+			payment.ID = 7
+		})
+
+		// When
+		response, err := service.CreatePayment(req)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), response.ID)
+		mockRepo.AssertExpectations(t)
+		// The worker's outbox relay enqueues processing, not the request
+		mockScheduler.AssertNotCalled(t, "SchedulePaymentProcessing", mock.Anything)
+	})
+
+	t.Run("should not record an event when schedule_on_create is off", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		flags := testutil.NewTestFlags()
+		flags.Set(featureflag.ScheduleOnCreate, false)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), flags, testutil.NewSilentLogger())
+
+		req := testutil.CreatePaymentRequestFixture()
+
+		// Mock expectations
+		mockUserService.On("GetUserByID", req.UserID).Return(&userDto.UserResponse{ID: req.UserID}, nil)
+		mockRepo.On("CreateForUser", mock.AnythingOfType("*entity.Payment"), false, 0, []string(nil)).Return(nil)
+
+		// When
+		response, err := service.CreatePayment(req)
+
+		// Then
+		assert.NoError(t, err)
+		assert.NotNil(t, response)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("should return error when user not found", func(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		req := testutil.CreatePaymentRequestFixture()
 
@@ -73,7 +153,7 @@ func TestPaymentService_CreatePayment(t *testing.T) {
 		assert.Nil(t, response)
 		assert.Contains(t, err.Error(), "user not found")
 		mockUserService.AssertExpectations(t)
-		mockRepo.AssertNotCalled(t, "Create")
+		mockRepo.AssertNotCalled(t, "CreateForUser")
 	})
 
 	t.Run("should return error when payment creation fails", func(t *testing.T) {
@@ -81,7 +161,7 @@ func TestPaymentService_CreatePayment(t *testing.T) {
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		req := testutil.CreatePaymentRequestFixture()
 		userResponse := &userDto.UserResponse{
@@ -92,7 +172,7 @@ func TestPaymentService_CreatePayment(t *testing.T) {
 
 		// Mock expectations
 		mockUserService.On("GetUserByID", req.UserID).Return(userResponse, nil)
-		mockRepo.On("Create", mock.AnythingOfType("*entity.Payment")).Return(errors.New("create failed"))
+		mockRepo.On("CreateForUser", mock.AnythingOfType("*entity.Payment"), false, 0, mock.Anything).Return(errors.New("create failed"))
 
 		// When
 		response, err := service.CreatePayment(req)
@@ -106,13 +186,67 @@ func TestPaymentService_CreatePayment(t *testing.T) {
 	})
 }
 
+func TestPaymentService_CreatePayment_Tags(t *testing.T) {
+	t.Run("should store normalized tags and return them", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		req := testutil.CreatePaymentRequestFixture()
+		req.Tags = []string{" Subscription", "monthly", "subscription", ""}
+
+		// Mock expectations
+		mockUserService.On("GetUserByID", req.UserID).Return(&userDto.UserResponse{ID: req.UserID}, nil)
+		mockRepo.On("CreateForUser", mock.MatchedBy(func(payment *entity.Payment) bool {
+			return assert.ObjectsAreEqual([]string{"subscription", "monthly"}, payment.TagNames())
+		}), false, 0, mock.Anything).Return(nil)
+
+		// When
+		response, err := service.CreatePayment(req)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"subscription", "monthly"}, response.Tags)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestPaymentService_GetPayments_Tag(t *testing.T) {
+	t.Run("should filter by the normalized tag", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		payment := testutil.CreatePaymentFixture()
+		payment.Tags = []entity.PaymentTag{{PaymentID: payment.ID, Tag: "subscription"}}
+
+		// Mock expectations
+		mockRepo.On("GetAll", mock.MatchedBy(func(filter *dto.PaymentFilter) bool {
+			return filter.Tag == "subscription"
+		})).Return([]entity.Payment{*payment}, int64(1), nil)
+
+		// When
+		response, err := service.GetPayments(&dto.PaymentFilter{Tag: " Subscription "})
+
+		// Then
+		assert.NoError(t, err)
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, []string{"subscription"}, response.Data[0].Tags)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestPaymentService_GetPaymentByID(t *testing.T) {
 	t.Run("should get payment by ID successfully", func(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		paymentID := uint(1)
 		payment := testutil.CreatePaymentFixture()
@@ -139,12 +273,12 @@ func TestPaymentService_GetPaymentByID(t *testing.T) {
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		paymentID := uint(999)
 
 		// Mock expectations
-		mockRepo.On("GetByID", paymentID).Return(nil, gorm.ErrRecordNotFound)
+		mockRepo.On("GetByID", paymentID).Return(nil, repository.ErrNotFound)
 
 		// When
 		response, err := service.GetPaymentByID(paymentID)
@@ -156,12 +290,31 @@ func TestPaymentService_GetPaymentByID(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("should pass through repository errors other than not found", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		// Mock expectations
+		mockRepo.On("GetByID", uint(1)).Return(nil, errors.New("connection reset"))
+
+		// When
+		response, err := service.GetPaymentByID(1)
+
+		// Then
+		assert.Nil(t, response)
+		assert.EqualError(t, err, "connection reset")
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("should return error when repository fails", func(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		paymentID := uint(1)
 
@@ -185,11 +338,10 @@ func TestPaymentService_GetPayments(t *testing.T) {
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		filter := &dto.PaymentFilter{
-			Page:     1,
-			PageSize: 10,
+			Pagination: pagination.Pagination{Page: 1, PageSize: 10},
 		}
 
 		payments := []entity.Payment{
@@ -221,16 +373,14 @@ func TestPaymentService_GetPayments(t *testing.T) {
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		filter := &dto.PaymentFilter{
-			Page:     0,
-			PageSize: 0,
+			Pagination: pagination.Pagination{Page: 0, PageSize: 0},
 		}
 
 		expectedFilter := &dto.PaymentFilter{
-			Page:     1,
-			PageSize: 10,
+			Pagination: pagination.Pagination{Page: 1, PageSize: 10},
 		}
 
 		// Mock expectations
@@ -247,16 +397,41 @@ func TestPaymentService_GetPayments(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("should apply configured default and max page size", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		cfg := testutil.NewTestConfig()
+		cfg.Pagination.DefaultPageSize = 25
+		cfg.Pagination.MaxPageSize = 50
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), cfg, featureflag.New(cfg), logger)
+
+		// Mock expectations
+		mockRepo.On("GetAll", &dto.PaymentFilter{Pagination: pagination.Pagination{Page: 1, PageSize: 25}}).Return([]entity.Payment{}, int64(0), nil)
+		mockRepo.On("GetAll", &dto.PaymentFilter{Pagination: pagination.Pagination{Page: 1, PageSize: 50}}).Return([]entity.Payment{}, int64(0), nil)
+
+		// When
+		defaultResponse, err := service.GetPayments(&dto.PaymentFilter{})
+		assert.NoError(t, err)
+		cappedResponse, err := service.GetPayments(&dto.PaymentFilter{Pagination: pagination.Pagination{Page: 1, PageSize: 500}})
+		assert.NoError(t, err)
+
+		// Then
+		assert.Equal(t, 25, defaultResponse.PageSize)
+		assert.Equal(t, 50, cappedResponse.PageSize)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("should return error when repository fails", func(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		filter := &dto.PaymentFilter{
-			Page:     1,
-			PageSize: 10,
+			Pagination: pagination.Pagination{Page: 1, PageSize: 10},
 		}
 
 		// Mock expectations
@@ -273,13 +448,70 @@ func TestPaymentService_GetPayments(t *testing.T) {
 	})
 }
 
+func TestPaymentService_GetPayments_ExpandUser(t *testing.T) {
+	t.Run("should embed users resolved in a single lookup", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		filter := &dto.PaymentFilter{Expand: "user", Pagination: pagination.Pagination{Page: 1, PageSize: 10}}
+		payments := []entity.Payment{
+			{ID: 1, UserID: 1, Amount: 10, Currency: "USD", Status: entity.PaymentStatusPending},
+			{ID: 2, UserID: 2, Amount: 20, Currency: "USD", Status: entity.PaymentStatusPending},
+			{ID: 3, UserID: 1, Amount: 30, Currency: "USD", Status: entity.PaymentStatusPending},
+		}
+		users := map[uint]*userDto.UserResponse{
+			1: {ID: 1, Name: "John Doe"},
+		}
+
+		// Mock expectations
+		mockRepo.On("GetAll", filter).Return(payments, int64(3), nil)
+		mockUserService.On("GetUsersByIDs", []uint{1, 2}).Return(users, nil).Once()
+
+		// When
+		response, err := service.GetPayments(filter)
+
+		// Then
+		assert.NoError(t, err)
+		require.Len(t, response.Data, 3)
+		assert.Equal(t, "John Doe", response.Data[0].User.Name)
+		assert.Nil(t, response.Data[1].User)
+		assert.Equal(t, "John Doe", response.Data[2].User.Name)
+		mockUserService.AssertExpectations(t)
+		mockUserService.AssertNotCalled(t, "GetUserByID", mock.Anything)
+	})
+
+	t.Run("should not look up users without expand", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		filter := &dto.PaymentFilter{Pagination: pagination.Pagination{Page: 1, PageSize: 10}}
+
+		// Mock expectations
+		mockRepo.On("GetAll", filter).Return([]entity.Payment{*testutil.CreatePaymentFixture()}, int64(1), nil)
+
+		// When
+		response, err := service.GetPayments(filter)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Nil(t, response.Data[0].User)
+		mockUserService.AssertNotCalled(t, "GetUsersByIDs", mock.Anything)
+	})
+}
+
 func TestPaymentService_UpdatePayment(t *testing.T) {
 	t.Run("should update payment successfully", func(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		paymentID := uint(1)
 		existingPayment := testutil.CreatePaymentFixture()
@@ -311,13 +543,13 @@ func TestPaymentService_UpdatePayment(t *testing.T) {
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		paymentID := uint(999)
 		req := testutil.CreateUpdatePaymentRequestFixture()
 
 		// Mock expectations
-		mockRepo.On("GetByID", paymentID).Return(nil, gorm.ErrRecordNotFound)
+		mockRepo.On("GetByID", paymentID).Return(nil, repository.ErrNotFound)
 
 		// When
 		response, err := service.UpdatePayment(paymentID, req)
@@ -334,7 +566,7 @@ func TestPaymentService_UpdatePayment(t *testing.T) {
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		paymentID := uint(1)
 		existingPayment := testutil.CreatePaymentFixture()
@@ -361,7 +593,7 @@ func TestPaymentService_UpdatePayment(t *testing.T) {
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		paymentID := uint(1)
 		existingPayment := testutil.CreatePaymentFixture()
@@ -385,13 +617,148 @@ func TestPaymentService_UpdatePayment(t *testing.T) {
 	})
 }
 
+func TestPaymentService_UpdatePayment_PartialFields(t *testing.T) {
+	t.Run("should update only the description and preserve the status", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		// Given
+		existingPayment := testutil.CreatePaymentFixture()
+		existingPayment.Status = entity.PaymentStatusCompleted
+		mockRepo.On("GetByID", existingPayment.ID).Return(existingPayment, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(payment *entity.Payment) bool {
+			return payment.Status == entity.PaymentStatusCompleted && payment.Description == "New description"
+		})).Return(nil)
+
+		// When
+		response, err := service.UpdatePayment(existingPayment.ID, &dto.UpdatePaymentRequest{Description: "New description"})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusCompleted.String(), response.Status)
+		assert.Equal(t, "New description", response.Description)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should update only the status and preserve the description", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		// Given
+		existingPayment := testutil.CreatePaymentFixture()
+		mockRepo.On("GetByID", existingPayment.ID).Return(existingPayment, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(payment *entity.Payment) bool {
+			return payment.Status == entity.PaymentStatusCanceled && payment.Description == "Test payment"
+		})).Return(nil)
+
+		// When
+		response, err := service.UpdatePayment(existingPayment.ID, &dto.UpdatePaymentRequest{Status: entity.PaymentStatusCanceled.String()})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusCanceled.String(), response.Status)
+		assert.Equal(t, "Test payment", response.Description)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject an update without fields", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		// Given
+		existingPayment := testutil.CreatePaymentFixture()
+		mockRepo.On("GetByID", existingPayment.ID).Return(existingPayment, nil)
+
+		// When
+		response, err := service.UpdatePayment(existingPayment.ID, &dto.UpdatePaymentRequest{})
+
+		// Then
+		assert.Nil(t, response)
+		assert.EqualError(t, err, "no fields to update")
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestPaymentService_UpdatePayment_Transitions(t *testing.T) {
+	setup := func(status entity.PaymentStatus) (PaymentService, *testutil.MockPaymentRepository, *entity.Payment) {
+		mockRepo := &testutil.MockPaymentRepository{}
+		service := NewPaymentService(mockRepo, &testutil.MockUserService{}, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), testutil.NewSilentLogger())
+		existing := testutil.CreatePaymentFixture()
+		existing.Status = status
+		mockRepo.On("GetByID", existing.ID).Return(existing, nil)
+		return service, mockRepo, existing
+	}
+
+	t.Run("should reject a move the state machine forbids", func(t *testing.T) {
+		cases := []struct{ from, to entity.PaymentStatus }{
+			{entity.PaymentStatusCompleted, entity.PaymentStatusPending},
+			{entity.PaymentStatusRefunded, entity.PaymentStatusCompleted},
+			{entity.PaymentStatusPartiallyRefunded, entity.PaymentStatusPending},
+			{entity.PaymentStatusCanceled, entity.PaymentStatusPending},
+		}
+
+		for _, c := range cases {
+			// Setup
+			service, mockRepo, existing := setup(c.from)
+
+			// When
+			response, err := service.UpdatePayment(existing.ID, &dto.UpdatePaymentRequest{Status: c.to.String()})
+
+			// Then
+			assert.Nil(t, response)
+			assert.Equal(t, apperror.CodeInvalidStatusTransition, apperror.Code(err), "%s -> %s", c.from, c.to)
+			mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		}
+	})
+
+	t.Run("should reject a refund status", func(t *testing.T) {
+		// Setup
+		service, mockRepo, existing := setup(entity.PaymentStatusCompleted)
+
+		// When
+		response, err := service.UpdatePayment(existing.ID, &dto.UpdatePaymentRequest{Status: entity.PaymentStatusRefunded.String()})
+
+		// Then
+		assert.Nil(t, response)
+		assert.Equal(t, apperror.CodeInvalidPaymentStatus, apperror.Code(err))
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("should allow restating the current status", func(t *testing.T) {
+		// Setup
+		service, mockRepo, existing := setup(entity.PaymentStatusCompleted)
+		mockRepo.On("Update", mock.AnythingOfType("*entity.Payment")).Return(nil)
+
+		// When
+		response, err := service.UpdatePayment(existing.ID, &dto.UpdatePaymentRequest{
+			Status:      entity.PaymentStatusCompleted.String(),
+			Description: "Renamed",
+		})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusCompleted.String(), response.Status)
+		assert.Equal(t, "Renamed", response.Description)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestPaymentService_DeletePayment(t *testing.T) {
 	t.Run("should delete payment successfully", func(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		paymentID := uint(1)
 		payment := testutil.CreatePaymentFixture()
@@ -414,12 +781,12 @@ func TestPaymentService_DeletePayment(t *testing.T) {
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		paymentID := uint(999)
 
 		// Mock expectations
-		mockRepo.On("GetByID", paymentID).Return(nil, gorm.ErrRecordNotFound)
+		mockRepo.On("GetByID", paymentID).Return(nil, repository.ErrNotFound)
 
 		// When
 		err := service.DeletePayment(paymentID)
@@ -435,7 +802,7 @@ func TestPaymentService_DeletePayment(t *testing.T) {
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		paymentID := uint(1)
 		payment := testutil.CreatePaymentFixture()
@@ -461,7 +828,7 @@ func TestPaymentService_GetPaymentsByUser(t *testing.T) {
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		userID := uint(1)
 		payments := []entity.Payment{
@@ -496,7 +863,7 @@ func TestPaymentService_GetPaymentsByUser(t *testing.T) {
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		userID := uint(1)
 
@@ -518,7 +885,7 @@ func TestPaymentService_GetPaymentsByUser(t *testing.T) {
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
 
 		userID := uint(1)
 
@@ -536,13 +903,324 @@ func TestPaymentService_GetPaymentsByUser(t *testing.T) {
 	})
 }
 
+func TestPaymentService_GetPaymentReceipt(t *testing.T) {
+	t.Run("should generate receipt for completed payment", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		payment := testutil.CreatePaymentFixture()
+		payment.ID = 42
+		payment.Status = entity.PaymentStatusCompleted
+		payment.CreatedAt = time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+		user := &userDto.UserResponse{ID: payment.UserID, Name: "John Doe", Email: "john@example.com"}
+
+		// Mock expectations
+		mockRepo.On("GetByID", uint(42)).Return(payment, nil)
+		mockUserService.On("GetUserByID", payment.UserID).Return(user, nil)
+
+		// When
+		receipt, err := service.GetPaymentReceipt(42)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, "RCPT-20240315-00000042", receipt.ReceiptNumber)
+		assert.Equal(t, uint(42), receipt.PaymentID)
+		assert.Equal(t, payment.Amount, receipt.Amount)
+		assert.Equal(t, payment.Currency, receipt.Currency)
+		assert.Equal(t, entity.PaymentStatusCompleted.String(), receipt.Status)
+		assert.Equal(t, "John Doe", receipt.UserName)
+		assert.Equal(t, "john@example.com", receipt.UserEmail)
+		assert.False(t, receipt.IssuedAt.IsZero())
+		mockRepo.AssertExpectations(t)
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("should reject receipt for pending payment", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		payment := testutil.CreatePaymentFixture()
+		payment.Status = entity.PaymentStatusPending
+
+		// Mock expectations
+		mockRepo.On("GetByID", payment.ID).Return(payment, nil)
+
+		// When
+		receipt, err := service.GetPaymentReceipt(payment.ID)
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, receipt)
+		assert.Equal(t, "receipt not available for payment status", err.Error())
+		mockUserService.AssertNotCalled(t, "GetUserByID")
+	})
+
+	t.Run("should return error when payment not found", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		// Mock expectations
+		mockRepo.On("GetByID", uint(999)).Return(nil, repository.ErrNotFound)
+
+		// When
+		receipt, err := service.GetPaymentReceipt(999)
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, receipt)
+		assert.Equal(t, "payment not found", err.Error())
+	})
+}
+
+func TestPaymentService_RefundPayment(t *testing.T) {
+	t.Run("should partially refund completed payment", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		payment := testutil.CreatePaymentFixture()
+		payment.Amount = 100
+		payment.Status = entity.PaymentStatusCompleted
+
+		// Mock expectations
+		mockRepo.On("GetByID", payment.ID).Return(payment, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*entity.Payment")).Return(nil)
+
+		// When
+		response, err := service.RefundPayment(payment.ID, &dto.RefundPaymentRequest{Amount: 40})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusPartiallyRefunded.String(), response.Status)
+		assert.Equal(t, 40.0, response.RefundedAmount)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should mark payment refunded once the remaining amount is refunded", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		payment := testutil.CreatePaymentFixture()
+		payment.Amount = 100.30
+		payment.RefundedAmount = 40.10
+		payment.Status = entity.PaymentStatusPartiallyRefunded
+
+		// Mock expectations
+		mockRepo.On("GetByID", payment.ID).Return(payment, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*entity.Payment")).Return(nil)
+
+		// When
+		response, err := service.RefundPayment(payment.ID, &dto.RefundPaymentRequest{Amount: 60.20})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusRefunded.String(), response.Status)
+		assert.Equal(t, 100.30, response.RefundedAmount)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject refund exceeding the refundable amount", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		payment := testutil.CreatePaymentFixture()
+		payment.Amount = 100
+		payment.RefundedAmount = 80
+		payment.Status = entity.PaymentStatusPartiallyRefunded
+
+		// Mock expectations
+		mockRepo.On("GetByID", payment.ID).Return(payment, nil)
+
+		// When
+		response, err := service.RefundPayment(payment.ID, &dto.RefundPaymentRequest{Amount: 30})
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, response)
+		assert.Equal(t, "refund amount exceeds refundable amount", err.Error())
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("should reject refund for payment that is not completed", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		payment := testutil.CreatePaymentFixture()
+		payment.Status = entity.PaymentStatusPending
+
+		// Mock expectations
+		mockRepo.On("GetByID", payment.ID).Return(payment, nil)
+
+		// When
+		response, err := service.RefundPayment(payment.ID, &dto.RefundPaymentRequest{Amount: 10})
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, response)
+		assert.Equal(t, "payment is not refundable", err.Error())
+	})
+
+	t.Run("should return error when payment not found", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		// Mock expectations
+		mockRepo.On("GetByID", uint(999)).Return(nil, repository.ErrNotFound)
+
+		// When
+		response, err := service.RefundPayment(999, &dto.RefundPaymentRequest{Amount: 10})
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, response)
+		assert.Equal(t, "payment not found", err.Error())
+	})
+}
+
+func TestPaymentService_ApplyGatewayEvent(t *testing.T) {
+	t.Run("should move a pending payment to the reported status", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		payment := testutil.CreatePaymentFixture()
+		event := &dto.GatewayEvent{Gateway: SimulatedGateway, ID: "evt_1", PaymentID: payment.ID, Status: "completed"}
+
+		// Mock expectations
+		mockRepo.On("ApplyGatewayEvent", SimulatedGateway, "evt_1").Return(payment, nil)
+
+		// When
+		response, err := service.ApplyGatewayEvent(event)
+
+		// Then
+		assert.NoError(t, err)
+		assert.False(t, response.Duplicate)
+		assert.Equal(t, "evt_1", response.EventID)
+		assert.Equal(t, entity.PaymentStatusCompleted, payment.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should report a replayed event as a duplicate", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		event := &dto.GatewayEvent{Gateway: SimulatedGateway, ID: "evt_1", PaymentID: 1, Status: "completed"}
+
+		// Mock expectations
+		mockRepo.On("ApplyGatewayEvent", SimulatedGateway, "evt_1").Return(nil, repository.ErrEventAlreadyProcessed)
+
+		// When
+		response, err := service.ApplyGatewayEvent(event)
+
+		// Then
+		assert.NoError(t, err)
+		assert.True(t, response.Duplicate)
+		assert.Equal(t, uint(1), response.PaymentID)
+	})
+
+	t.Run("should treat a new event repeating the current status as a duplicate", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		payment := testutil.CreatePaymentFixture()
+		payment.Status = entity.PaymentStatusCompleted
+
+		// Mock expectations
+		mockRepo.On("ApplyGatewayEvent", SimulatedGateway, "evt_2").Return(payment, nil)
+
+		// When
+		response, err := service.ApplyGatewayEvent(&dto.GatewayEvent{
+			Gateway: SimulatedGateway, ID: "evt_2", PaymentID: payment.ID, Status: "completed",
+		})
+
+		// Then
+		assert.NoError(t, err)
+		assert.True(t, response.Duplicate)
+	})
+
+	t.Run("should refuse to change a settled payment", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		payment := testutil.CreatePaymentFixture()
+		payment.Status = entity.PaymentStatusCompleted
+
+		// Mock expectations
+		mockRepo.On("ApplyGatewayEvent", SimulatedGateway, "evt_2").Return(payment, nil)
+
+		// When
+		response, err := service.ApplyGatewayEvent(&dto.GatewayEvent{
+			Gateway: SimulatedGateway, ID: "evt_2", PaymentID: payment.ID, Status: "failed",
+		})
+
+		// Then
+		assert.Nil(t, response)
+		assert.EqualError(t, err, "payment status cannot change")
+		assert.Equal(t, entity.PaymentStatusCompleted, payment.Status)
+	})
+
+	t.Run("should return not found for unknown payment", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockPaymentRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		// Mock expectations
+		mockRepo.On("ApplyGatewayEvent", SimulatedGateway, "evt_3").Return(nil, repository.ErrNotFound)
+
+		// When
+		response, err := service.ApplyGatewayEvent(&dto.GatewayEvent{
+			Gateway: SimulatedGateway, ID: "evt_3", PaymentID: 999, Status: "completed",
+		})
+
+		// Then
+		assert.Nil(t, response)
+		assert.EqualError(t, err, "payment not found")
+	})
+}
+
 func TestPaymentService_entityToResponse(t *testing.T) {
 	t.Run("should convert entity to response correctly", func(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockPaymentRepository{}
 		mockUserService := &testutil.MockUserService{}
 		logger := testutil.NewSilentLogger()
-		service := NewPaymentService(mockRepo, mockUserService, logger).(*paymentService)
+		service := NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger).(*paymentService)
 
 		payment := testutil.CreatePaymentFixture()
 		payment.ID = 1
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/repository"
+	userDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	walletEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+	walletRepository "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/repository"
+	walletService "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/service"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestPaymentService_CreatePayment_WalletCurrency(t *testing.T) {
+	// Setup: real repositories so wallets and payments come from the database
+	setup := func(t *testing.T, autoCreate bool, maxActive int) (*gorm.DB, PaymentService) {
+		db, err := testutil.SetupTestDB()
+		require.NoError(t, err)
+		testutil.WithCleanDB(t, db)
+		logger := testutil.NewSilentLogger()
+
+		mockUserService := &testutil.MockUserService{}
+		mockUserService.On("GetUserByID", uint(1)).Return(&userDto.UserResponse{ID: 1}, nil)
+
+		cfg := testutil.NewTestConfig()
+		cfg.Wallet.AutoCreate = autoCreate
+		cfg.Payment.MaxActivePerUser = maxActive
+		wallets := walletService.NewWalletService(walletRepository.NewWalletRepository(db, logger),
+			walletRepository.NewTransactionRepository(db, logger), mockUserService, cfg, logger)
+		service := NewPaymentService(repository.NewPaymentRepository(db, logger), mockUserService, wallets,
+			cfg, featureflag.New(cfg), logger)
+		return db, service
+	}
+	countWallets := func(t *testing.T, db *gorm.DB) int64 {
+		var count int64
+		require.NoError(t, db.Model(&walletEntity.Wallet{}).Where("user_id = ?", 1).Count(&count).Error)
+		return count
+	}
+
+	t.Run("should create the payment when the user has a wallet in its currency", func(t *testing.T) {
+		// Setup
+		db, service := setup(t, false, 0)
+
+		// Given
+		require.NoError(t, db.Create(&walletEntity.Wallet{UserID: 1, Currency: "USD"}).Error)
+		req := testutil.CreatePaymentRequestFixture()
+		req.UserID = 1
+		req.Currency = "USD"
+
+		// When
+		result, err := service.CreatePayment(req)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "USD", result.Currency)
+		assert.Equal(t, int64(1), countWallets(t, db))
+	})
+
+	t.Run("should create a missing wallet when auto-create is on", func(t *testing.T) {
+		// Setup
+		db, service := setup(t, true, 0)
+
+		// Given
+		require.NoError(t, db.Create(&walletEntity.Wallet{UserID: 1, Currency: "USD"}).Error)
+		req := testutil.CreatePaymentRequestFixture()
+		req.UserID = 1
+		req.Currency = "EUR"
+
+		// When
+		result, err := service.CreatePayment(req)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", result.Currency)
+		var wallet walletEntity.Wallet
+		require.NoError(t, db.Where("user_id = ? AND currency = ?", 1, "EUR").First(&wallet).Error)
+		assert.Zero(t, wallet.Balance)
+		assert.Equal(t, int64(2), countWallets(t, db))
+	})
+
+	t.Run("should reject the payment when the wallet is missing and auto-create is off", func(t *testing.T) {
+		// Setup
+		db, service := setup(t, false, 0)
+
+		// Given
+		require.NoError(t, db.Create(&walletEntity.Wallet{UserID: 1, Currency: "USD"}).Error)
+		req := testutil.CreatePaymentRequestFixture()
+		req.UserID = 1
+		req.Currency = "EUR"
+
+		// When
+		result, err := service.CreatePayment(req)
+
+		// Then
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "user has no wallet in the payment currency")
+		assert.Equal(t, apperror.CodeCurrencyMismatch, apperror.CodeOf(http.StatusInternalServerError, err))
+		var payments int64
+		require.NoError(t, db.Model(&entity.Payment{}).Count(&payments).Error)
+		assert.Zero(t, payments)
+		assert.Equal(t, int64(1), countWallets(t, db))
+	})
+	t.Run("should not leave a wallet behind when the active payment limit rejects the payment", func(t *testing.T) {
+		// Setup
+		db, service := setup(t, true, 1)
+
+		// Given - one pending payment already holds the user's only slot
+		require.NoError(t, db.Create(&walletEntity.Wallet{UserID: 1, Currency: "USD"}).Error)
+		req := testutil.CreatePaymentRequestFixture()
+		req.UserID = 1
+		_, err := service.CreatePayment(req)
+		require.NoError(t, err)
+		req.Currency = "EUR"
+
+		// When
+		result, err := service.CreatePayment(req)
+
+		// Then
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "active payment limit reached")
+		assert.Equal(t, int64(1), countWallets(t, db))
+	})
+
+	t.Run("should reject an unknown currency instead of creating a wallet in it", func(t *testing.T) {
+		// Setup
+		db, service := setup(t, true, 0)
+
+		// Given
+		req := testutil.CreatePaymentRequestFixture()
+		req.UserID = 1
+		req.Currency = "ABC"
+
+		// When
+		result, err := service.CreatePayment(req)
+
+		// Then
+		assert.Nil(t, result)
+		assert.Equal(t, apperror.CodeUnsupportedCurrency, apperror.CodeOf(http.StatusInternalServerError, err))
+		assert.Zero(t, countWallets(t, db))
+	})
+}
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+)
+
+// PaymentGateway is the external payment provider the worker talks to
+type PaymentGateway interface {
+	// CheckStatus returns the gateway's current status for the payment
+	CheckStatus(ctx context.Context, payment *dto.PaymentResponse) (string, error)
+	// ProcessPayment charges the payment, returning nil when it succeeded
+	ProcessPayment(ctx context.Context, payment *dto.PaymentResponse) error
+}
+
+// GatewayError describes a failed gateway call
+type GatewayError struct {
+	// StatusCode is the HTTP status returned by the gateway, 0 for network failures
+	StatusCode int
+	Message    string
+	Err        error
+}
+
+func (e *GatewayError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("gateway error (status %d): %s: %v", e.StatusCode, e.Message, e.Err)
+	}
+	return fmt.Sprintf("gateway error (status %d): %s", e.StatusCode, e.Message)
+}
+
+func (e *GatewayError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetriable reports whether the call may succeed if retried: network failures,
+// rate limiting and 5xx responses are transient, other 4xx responses are permanent.
+func (e *GatewayError) IsRetriable() bool {
+	return e.StatusCode == 0 ||
+		e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode >= http.StatusInternalServerError
+}
+
+// simulatedGateway stands in for a real payment provider
+type simulatedGateway struct{}
+
+func NewSimulatedGateway() PaymentGateway {
+	return &simulatedGateway{}
+}
+
+// CheckStatus simulates checking payment status with external gateway
+func (g *simulatedGateway) CheckStatus(ctx context.Context, payment *dto.PaymentResponse) (string, error) {
+	// Simulate random status changes for demo purposes
+	// In real implementation, this would call actual payment gateway API
+
+	elapsed := time.Since(payment.CreatedAt)
+
+	// After 2 minutes, 80% chance to complete, 10% to fail, 10% stay pending
+	if elapsed > 2*time.Minute {
+		rand := time.Now().UnixNano() % 10
+		if rand < 8 {
+			return entity.PaymentStatusCompleted.String(), nil
+		} else if rand < 9 {
+			return entity.PaymentStatusFailed.String(), nil
+		}
+	}
+
+	return entity.PaymentStatusPending.String(), nil
+}
+
+// ProcessPayment simulates processing payment with external gateway
+func (g *simulatedGateway) ProcessPayment(ctx context.Context, payment *dto.PaymentResponse) error {
+	// Simulate 90% success rate for demo purposes
+	rand := time.Now().UnixNano() % 10
+	if rand < 9 {
+		return nil
+	}
+
+	return &GatewayError{StatusCode: http.StatusPaymentRequired, Message: "payment declined"}
+}
@@ -2,7 +2,7 @@ package worker
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,6 +10,7 @@ import (
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/service"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
 
 	"github.com/hibiken/asynq"
 	"go.uber.org/zap"
@@ -22,6 +23,7 @@ type AsynqClient interface {
 type PaymentWorker struct {
 	paymentService service.PaymentService
 	client         AsynqClient
+	gateway        PaymentGateway
 	logger         *zap.Logger
 	cfg            *config.Config
 }
@@ -34,27 +36,34 @@ type ProcessPaymentPayload struct {
 	PaymentID uint `json:"payment_id"`
 }
 
+type BatchProcessPendingPayload struct {
+	// BatchSize overrides worker.batch_process_size for this run when positive
+	BatchSize int `json:"batch_size,omitempty"`
+}
+
 func NewPaymentWorker(
 	paymentService service.PaymentService,
 	client AsynqClient,
+	gateway PaymentGateway,
 	logger *zap.Logger,
 	cfg *config.Config,
 ) *PaymentWorker {
 	return &PaymentWorker{
 		paymentService: paymentService,
 		client:         client,
+		gateway:        gateway,
 		logger:         logger,
 		cfg:            cfg,
 	}
 }
 
 func (w *PaymentWorker) HandleCheckPaymentStatus(ctx context.Context, task *asynq.Task) error {
-	var payload CheckPaymentStatusPayload
-	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+	payload, err := queue.UnmarshalPayload[CheckPaymentStatusPayload](task)
+	if err != nil {
 		w.logger.Error("Failed to unmarshal payment status check payload",
 			zap.Error(err),
 			zap.ByteString("payload", task.Payload()))
-		return fmt.Errorf("json.Unmarshal failed: %w", err)
+		return err
 	}
 
 	w.logger.Info("Processing payment status check",
@@ -70,24 +79,27 @@ func (w *PaymentWorker) HandleCheckPaymentStatus(ctx context.Context, task *asyn
 	}
 
 	// Skip if payment is already completed or failed
-	if payment.Status == entity.PaymentStatusCompleted.String() ||
-		payment.Status == entity.PaymentStatusFailed.String() ||
-		payment.Status == entity.PaymentStatusCanceled.String() {
+	if payment.Status != entity.PaymentStatusPending.String() {
 		w.logger.Info("Payment already in final state, skipping check",
 			zap.Uint("payment_id", payload.PaymentID),
 			zap.String("status", payment.Status))
 		return nil
 	}
 
-	// Simulate external payment gateway status check
-	// In real implementation, you would call external payment gateway API
-	newStatus := w.simulatePaymentGatewayCheck(payment)
+	newStatus, err := w.gateway.CheckStatus(ctx, payment)
+	if err != nil {
+		w.logger.Error("Failed to check payment status with gateway",
+			zap.Uint("payment_id", payload.PaymentID),
+			zap.Error(err))
+		return fmt.Errorf("failed to check payment status: %w", err)
+	}
 
 	// Update payment status if changed
 	if newStatus != payment.Status {
 		updateReq := &dto.UpdatePaymentRequest{
 			Status:      newStatus,
-			Description: fmt.Sprintf("Status updated by worker at %s", time.Now().Format(time.RFC3339)),
+			Description: fmt.Sprintf("Status updated by worker at %s", time.Now().UTC().Format(time.RFC3339)),
+			Actor:       entity.StatusActorWorker,
 		}
 
 		_, err := w.paymentService.UpdatePayment(payload.PaymentID, updateReq)
@@ -119,12 +131,12 @@ func (w *PaymentWorker) HandleCheckPaymentStatus(ctx context.Context, task *asyn
 }
 
 func (w *PaymentWorker) HandleProcessPayment(ctx context.Context, task *asynq.Task) error {
-	var payload ProcessPaymentPayload
-	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+	payload, err := queue.UnmarshalPayload[ProcessPaymentPayload](task)
+	if err != nil {
 		w.logger.Error("Failed to unmarshal process payment payload",
 			zap.Error(err),
 			zap.ByteString("payload", task.Payload()))
-		return fmt.Errorf("json.Unmarshal failed: %w", err)
+		return err
 	}
 
 	w.logger.Info("Processing payment",
@@ -139,33 +151,106 @@ func (w *PaymentWorker) HandleProcessPayment(ctx context.Context, task *asynq.Ta
 		return fmt.Errorf("failed to get payment: %w", err)
 	}
 
-	// Simulate payment processing
-	// In real implementation, you would call external payment gateway
-	success := w.simulatePaymentProcessing(payment)
+	return w.processPayment(ctx, payment)
+}
+
+// HandleBatchProcessPending submits a page of pending payments to the gateway in one run.
+// A payment that fails is logged and left for the next run so it doesn't abort the rest
+// of the batch; the task only fails when the batch itself can't be fetched.
+func (w *PaymentWorker) HandleBatchProcessPending(ctx context.Context, task *asynq.Task) error {
+	payload, err := queue.UnmarshalPayload[BatchProcessPendingPayload](task)
+	if err != nil {
+		w.logger.Error("Failed to unmarshal batch process payload",
+			zap.Error(err),
+			zap.ByteString("payload", task.Payload()))
+		return err
+	}
+
+	batchSize := payload.BatchSize
+	if batchSize <= 0 {
+		batchSize = w.cfg.Worker.BatchProcessSize
+	}
+
+	filter := &dto.PaymentFilter{Status: entity.PaymentStatusPending.String()}
+	filter.Page = 1
+	filter.PageSize = batchSize
+
+	pending, err := w.paymentService.GetPayments(filter)
+	if err != nil {
+		w.logger.Error("Failed to fetch pending payments for batch processing",
+			zap.Int("batch_size", batchSize),
+			zap.Error(err))
+		return fmt.Errorf("failed to fetch pending payments: %w", err)
+	}
+
+	failed := 0
+	for i := range pending.Data {
+		payment := &pending.Data[i]
+		if err := ctx.Err(); err != nil {
+			w.logger.Warn("Batch processing interrupted",
+				zap.Int("processed", i),
+				zap.Int("remaining", len(pending.Data)-i),
+				zap.Error(err))
+			break
+		}
+		if err := w.processPayment(ctx, payment); err != nil {
+			failed++
+			w.logger.Warn("Payment in batch failed, leaving it for the next run",
+				zap.Uint("payment_id", payment.ID),
+				zap.Error(err))
+		}
+	}
+
+	w.logger.Info("Batch processing completed",
+		zap.Int("fetched", len(pending.Data)),
+		zap.Int("failed", failed))
+
+	return nil
+}
+
+// processPayment submits a fetched payment to the gateway and records the outcome
+func (w *PaymentWorker) processPayment(ctx context.Context, payment *dto.PaymentResponse) error {
+	success := true
+	if err := w.gateway.ProcessPayment(ctx, payment); err != nil {
+		var gatewayErr *GatewayError
+		if !errors.As(err, &gatewayErr) || gatewayErr.IsRetriable() {
+			// Returning the error lets asynq retry with backoff
+			w.logger.Warn("Transient gateway error, payment will be retried",
+				zap.Uint("payment_id", payment.ID),
+				zap.Error(err))
+			return fmt.Errorf("gateway processing failed: %w", err)
+		}
+
+		// Permanent rejection: mark the payment failed and complete the task
+		w.logger.Warn("Payment rejected by gateway",
+			zap.Uint("payment_id", payment.ID),
+			zap.Int("gateway_status", gatewayErr.StatusCode),
+			zap.Error(err))
+		success = false
+	}
 
-	var newStatus string
-	if success {
-		newStatus = entity.PaymentStatusCompleted.String()
-	} else {
+	newStatus := entity.PaymentStatusCompleted.String()
+	if !success {
 		newStatus = entity.PaymentStatusFailed.String()
 	}
 
 	updateReq := &dto.UpdatePaymentRequest{
 		Status:      newStatus,
-		Description: fmt.Sprintf("Payment processed by worker at %s", time.Now().Format(time.RFC3339)),
+		Description: fmt.Sprintf("Payment processed by worker at %s", time.Now().UTC().Format(time.RFC3339)),
+		Actor:       entity.StatusActorWorker,
 	}
 
-	_, err = w.paymentService.UpdatePayment(payload.PaymentID, updateReq)
+	_, err := w.paymentService.UpdatePayment(payment.ID, updateReq)
 	if err != nil {
 		w.logger.Error("Failed to update payment after processing",
-			zap.Uint("payment_id", payload.PaymentID),
+			zap.Uint("payment_id", payment.ID),
 			zap.String("new_status", newStatus),
 			zap.Error(err))
 		return fmt.Errorf("failed to update payment: %w", err)
 	}
 
 	w.logger.Info("Payment processing completed",
-		zap.Uint("payment_id", payload.PaymentID),
+		zap.Uint("payment_id", payment.ID),
 		zap.String("final_status", newStatus),
 		zap.Bool("success", success))
 
@@ -173,10 +258,9 @@ func (w *PaymentWorker) HandleProcessPayment(ctx context.Context, task *asynq.Ta
 }
 
 func (w *PaymentWorker) SchedulePaymentStatusCheck(paymentID uint, delay time.Duration) error {
-	payload := CheckPaymentStatusPayload{PaymentID: paymentID}
-	payloadBytes, err := json.Marshal(payload)
+	payloadBytes, err := queue.MarshalPayload(CheckPaymentStatusPayload{PaymentID: paymentID})
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return err
 	}
 
 	task := asynq.NewTask(TypeCheckPaymentStatus, payloadBytes)
@@ -200,16 +284,9 @@ func (w *PaymentWorker) SchedulePaymentStatusCheck(paymentID uint, delay time.Du
 }
 
 func (w *PaymentWorker) SchedulePaymentProcessing(paymentID uint) error {
-	payload := ProcessPaymentPayload{PaymentID: paymentID}
-	payloadBytes, err := json.Marshal(payload)
+	task, opts, err := w.processPaymentTask(paymentID)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
-	}
-
-	task := asynq.NewTask(TypeProcessPayment, payloadBytes)
-	opts := []asynq.Option{
-		asynq.Queue("critical"),
-		asynq.MaxRetry(w.cfg.Worker.RetryMaxAttempts),
+		return err
 	}
 
 	info, err := w.client.Enqueue(task, opts...)
@@ -224,29 +301,39 @@ func (w *PaymentWorker) SchedulePaymentProcessing(paymentID uint) error {
 	return nil
 }
 
-// simulatePaymentGatewayCheck simulates checking payment status with external gateway
-func (w *PaymentWorker) simulatePaymentGatewayCheck(payment *dto.PaymentResponse) string {
-	// Simulate random status changes for demo purposes
-	// In real implementation, this would call actual payment gateway API
+// processPaymentTask builds the task that submits a payment to the gateway. It also serves
+// as the outbox route for newly created payments.
+func (w *PaymentWorker) processPaymentTask(paymentID uint) (*asynq.Task, []asynq.Option, error) {
+	payloadBytes, err := queue.MarshalPayload(ProcessPaymentPayload{PaymentID: paymentID})
+	if err != nil {
+		return nil, nil, err
+	}
 
-	elapsed := time.Since(payment.CreatedAt)
+	task := asynq.NewTask(TypeProcessPayment, payloadBytes)
+	opts := []asynq.Option{
+		asynq.Queue("critical"),
+		asynq.MaxRetry(w.cfg.Worker.RetryMaxAttempts),
+	}
+	return task, opts, nil
+}
 
-	// After 2 minutes, 80% chance to complete, 10% to fail, 10% stay pending
-	if elapsed > 2*time.Minute {
-		rand := time.Now().UnixNano() % 10
-		if rand < 8 {
-			return entity.PaymentStatusCompleted.String()
-		} else if rand < 9 {
-			return entity.PaymentStatusFailed.String()
-		}
+// ScheduleBatchProcessPending enqueues one run of the batched pending-payment processor
+func (w *PaymentWorker) ScheduleBatchProcessPending() error {
+	payloadBytes, err := queue.MarshalPayload(BatchProcessPendingPayload{})
+	if err != nil {
+		return err
 	}
 
-	return entity.PaymentStatusPending.String()
-}
+	task := asynq.NewTask(TypeBatchProcessPending, payloadBytes)
+	info, err := w.client.Enqueue(task,
+		asynq.Queue("default"),
+		asynq.MaxRetry(w.cfg.Worker.RetryMaxAttempts))
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
 
-// simulatePaymentProcessing simulates processing payment with external gateway
-func (w *PaymentWorker) simulatePaymentProcessing(payment *dto.PaymentResponse) bool {
-	// Simulate 90% success rate for demo purposes
-	rand := time.Now().UnixNano() % 10
-	return rand < 9
+	w.logger.Info("Scheduled batch processing of pending payments",
+		zap.String("task_id", info.ID))
+
+	return nil
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/service"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
 
@@ -66,6 +67,85 @@ func (m *MockPaymentService) GetPaymentsByUser(userID uint) ([]dto.PaymentRespon
 	return args.Get(0).([]dto.PaymentResponse), args.Error(1)
 }
 
+func (m *MockPaymentService) GetPaymentReceipt(id uint) (*dto.PaymentReceiptResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PaymentReceiptResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) RefundPayment(id uint, req *dto.RefundPaymentRequest) (*dto.PaymentResponse, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PaymentResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) ApplyGatewayEvent(event *dto.GatewayEvent) (*dto.GatewayEventResponse, error) {
+	args := m.Called(event)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.GatewayEventResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) ReprocessPayment(id uint) (*dto.PaymentResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PaymentResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) BulkUpdateStatus(req *dto.BulkStatusUpdateRequest) (*dto.BulkStatusUpdateResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.BulkStatusUpdateResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) BulkDeletePayments(req *dto.BulkDeleteRequest) (*dto.BulkDeleteResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.BulkDeleteResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) GetPaymentStatuses() []string {
+	args := m.Called()
+	return args.Get(0).([]string)
+}
+
+func (m *MockPaymentService) GetPaymentHistory(id uint) ([]dto.PaymentStatusChangeResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.PaymentStatusChangeResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) SetTaskScheduler(scheduler service.TaskScheduler) {
+	m.Called(scheduler)
+}
+
+type MockPaymentGateway struct {
+	mock.Mock
+}
+
+func (m *MockPaymentGateway) CheckStatus(ctx context.Context, payment *dto.PaymentResponse) (string, error) {
+	args := m.Called(payment.ID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockPaymentGateway) ProcessPayment(ctx context.Context, payment *dto.PaymentResponse) error {
+	args := m.Called(payment.ID)
+	return args.Error(0)
+}
+
 type MockAsynqClient struct {
 	mock.Mock
 }
@@ -78,26 +158,28 @@ func (m *MockAsynqClient) Enqueue(task *asynq.Task, opts ...asynq.Option) (*asyn
 	return args.Get(0).(*asynq.TaskInfo), args.Error(1)
 }
 
-func setupPaymentWorker() (*PaymentWorker, *MockPaymentService, *MockAsynqClient) {
+func setupPaymentWorker() (*PaymentWorker, *MockPaymentService, *MockAsynqClient, *MockPaymentGateway) {
 	mockService := &MockPaymentService{}
 	mockClient := &MockAsynqClient{}
+	mockGateway := &MockPaymentGateway{}
 	logger := testutil.NewSilentLogger()
 	cfg := &config.Config{
 		Worker: config.WorkerConfig{
 			PaymentCheckInterval: 5 * time.Minute,
 			RetryMaxAttempts:     3,
+			BatchProcessSize:     50,
 		},
 	}
 
-	worker := NewPaymentWorker(mockService, mockClient, logger, cfg)
+	worker := NewPaymentWorker(mockService, mockClient, mockGateway, logger, cfg)
 
-	return worker, mockService, mockClient
+	return worker, mockService, mockClient, mockGateway
 }
 
 func TestPaymentWorker_HandleCheckPaymentStatus(t *testing.T) {
 	t.Run("should handle check payment status successfully when status needs update", func(t *testing.T) {
 		// Setup
-		worker, mockService, _ := setupPaymentWorker()
+		worker, mockService, _, mockGateway := setupPaymentWorker()
 
 		paymentID := uint(1)
 		payload := CheckPaymentStatusPayload{PaymentID: paymentID}
@@ -126,6 +208,7 @@ func TestPaymentWorker_HandleCheckPaymentStatus(t *testing.T) {
 		}
 
 		mockService.On("GetPaymentByID", paymentID).Return(payment, nil)
+		mockGateway.On("CheckStatus", paymentID).Return(entity.PaymentStatusCompleted.String(), nil)
 		mockService.On("UpdatePayment", paymentID, mock.AnythingOfType("*dto.UpdatePaymentRequest")).Return(updatedPayment, nil)
 
 		// When
@@ -134,6 +217,7 @@ func TestPaymentWorker_HandleCheckPaymentStatus(t *testing.T) {
 		// Then
 		assert.NoError(t, err)
 		mockService.AssertExpectations(t)
+		mockGateway.AssertExpectations(t)
 
 		// Verify the update request has the correct status
 		updateCall := mockService.Calls[1]
@@ -144,7 +228,7 @@ func TestPaymentWorker_HandleCheckPaymentStatus(t *testing.T) {
 
 	t.Run("should skip check when payment is in final state", func(t *testing.T) {
 		// Setup
-		worker, mockService, _ := setupPaymentWorker()
+		worker, mockService, _, _ := setupPaymentWorker()
 
 		paymentID := uint(1)
 		payload := CheckPaymentStatusPayload{PaymentID: paymentID}
@@ -171,7 +255,7 @@ func TestPaymentWorker_HandleCheckPaymentStatus(t *testing.T) {
 
 	t.Run("should schedule next check when payment remains pending", func(t *testing.T) {
 		// Setup
-		worker, mockService, mockClient := setupPaymentWorker()
+		worker, mockService, mockClient, mockGateway := setupPaymentWorker()
 
 		paymentID := uint(1)
 		payload := CheckPaymentStatusPayload{PaymentID: paymentID}
@@ -188,6 +272,7 @@ func TestPaymentWorker_HandleCheckPaymentStatus(t *testing.T) {
 		taskInfo := &asynq.TaskInfo{ID: "task-123"}
 
 		mockService.On("GetPaymentByID", paymentID).Return(payment, nil)
+		mockGateway.On("CheckStatus", paymentID).Return(entity.PaymentStatusPending.String(), nil)
 		mockClient.On("Enqueue", mock.AnythingOfType("*asynq.Task"), mock.AnythingOfType("[]asynq.Option")).Return(taskInfo, nil)
 
 		// When
@@ -202,7 +287,7 @@ func TestPaymentWorker_HandleCheckPaymentStatus(t *testing.T) {
 
 	t.Run("should return error when payload is invalid", func(t *testing.T) {
 		// Setup
-		worker, mockService, _ := setupPaymentWorker()
+		worker, mockService, _, _ := setupPaymentWorker()
 
 		task := asynq.NewTask(TypeCheckPaymentStatus, []byte("invalid json"))
 
@@ -217,7 +302,7 @@ func TestPaymentWorker_HandleCheckPaymentStatus(t *testing.T) {
 
 	t.Run("should return error when payment not found", func(t *testing.T) {
 		// Setup
-		worker, mockService, _ := setupPaymentWorker()
+		worker, mockService, _, _ := setupPaymentWorker()
 
 		paymentID := uint(999)
 		payload := CheckPaymentStatusPayload{PaymentID: paymentID}
@@ -237,7 +322,7 @@ func TestPaymentWorker_HandleCheckPaymentStatus(t *testing.T) {
 
 	t.Run("should return error when update payment fails", func(t *testing.T) {
 		// Setup
-		worker, mockService, _ := setupPaymentWorker()
+		worker, mockService, _, mockGateway := setupPaymentWorker()
 
 		paymentID := uint(1)
 		payload := CheckPaymentStatusPayload{PaymentID: paymentID}
@@ -252,6 +337,7 @@ func TestPaymentWorker_HandleCheckPaymentStatus(t *testing.T) {
 		}
 
 		mockService.On("GetPaymentByID", paymentID).Return(payment, nil)
+		mockGateway.On("CheckStatus", paymentID).Return(entity.PaymentStatusCompleted.String(), nil)
 		mockService.On("UpdatePayment", paymentID, mock.AnythingOfType("*dto.UpdatePaymentRequest")).Return(nil, errors.New("update failed"))
 
 		// When
@@ -267,7 +353,7 @@ func TestPaymentWorker_HandleCheckPaymentStatus(t *testing.T) {
 func TestPaymentWorker_HandleProcessPayment(t *testing.T) {
 	t.Run("should process payment successfully", func(t *testing.T) {
 		// Setup
-		worker, mockService, _ := setupPaymentWorker()
+		worker, mockService, _, mockGateway := setupPaymentWorker()
 
 		paymentID := uint(1)
 		payload := ProcessPaymentPayload{PaymentID: paymentID}
@@ -295,6 +381,7 @@ func TestPaymentWorker_HandleProcessPayment(t *testing.T) {
 		}
 
 		mockService.On("GetPaymentByID", paymentID).Return(payment, nil)
+		mockGateway.On("ProcessPayment", paymentID).Return(nil)
 		mockService.On("UpdatePayment", paymentID, mock.AnythingOfType("*dto.UpdatePaymentRequest")).Return(processedPayment, nil)
 
 		// When
@@ -307,14 +394,77 @@ func TestPaymentWorker_HandleProcessPayment(t *testing.T) {
 		// Verify the update request
 		updateCall := mockService.Calls[1]
 		updateReq := updateCall.Arguments[1].(*dto.UpdatePaymentRequest)
-		// Status could be completed or failed based on simulation
-		assert.True(t, updateReq.Status == entity.PaymentStatusCompleted.String() || updateReq.Status == entity.PaymentStatusFailed.String())
+		assert.Equal(t, entity.PaymentStatusCompleted.String(), updateReq.Status)
 		assert.Contains(t, updateReq.Description, "Payment processed by worker")
 	})
 
+	t.Run("should return error so asynq retries on retriable gateway error", func(t *testing.T) {
+		// Setup
+		worker, mockService, _, mockGateway := setupPaymentWorker()
+
+		paymentID := uint(1)
+		payload := ProcessPaymentPayload{PaymentID: paymentID}
+		payloadBytes, _ := json.Marshal(payload)
+		task := asynq.NewTask(TypeProcessPayment, payloadBytes)
+
+		payment := &dto.PaymentResponse{
+			ID:        paymentID,
+			Status:    entity.PaymentStatusPending.String(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+
+		mockService.On("GetPaymentByID", paymentID).Return(payment, nil)
+		mockGateway.On("ProcessPayment", paymentID).Return(&GatewayError{StatusCode: 503, Message: "service unavailable"})
+
+		// When
+		err := worker.HandleProcessPayment(context.Background(), task)
+
+		// Then
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "gateway processing failed")
+		mockService.AssertExpectations(t)
+		mockService.AssertNotCalled(t, "UpdatePayment")
+	})
+
+	t.Run("should mark payment failed and succeed on permanent gateway error", func(t *testing.T) {
+		// Setup
+		worker, mockService, _, mockGateway := setupPaymentWorker()
+
+		paymentID := uint(1)
+		payload := ProcessPaymentPayload{PaymentID: paymentID}
+		payloadBytes, _ := json.Marshal(payload)
+		task := asynq.NewTask(TypeProcessPayment, payloadBytes)
+
+		payment := &dto.PaymentResponse{
+			ID:        paymentID,
+			Status:    entity.PaymentStatusPending.String(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		failedPayment := &dto.PaymentResponse{
+			ID:     paymentID,
+			Status: entity.PaymentStatusFailed.String(),
+		}
+
+		mockService.On("GetPaymentByID", paymentID).Return(payment, nil)
+		mockService.On("UpdatePayment", paymentID, mock.AnythingOfType("*dto.UpdatePaymentRequest")).Return(failedPayment, nil)
+		mockGateway.On("ProcessPayment", paymentID).Return(&GatewayError{StatusCode: 402, Message: "payment declined"})
+
+		// When
+		err := worker.HandleProcessPayment(context.Background(), task)
+
+		// Then
+		assert.NoError(t, err)
+		mockService.AssertExpectations(t)
+
+		updateReq := mockService.Calls[1].Arguments[1].(*dto.UpdatePaymentRequest)
+		assert.Equal(t, entity.PaymentStatusFailed.String(), updateReq.Status)
+	})
+
 	t.Run("should return error when payload is invalid", func(t *testing.T) {
 		// Setup
-		worker, mockService, _ := setupPaymentWorker()
+		worker, mockService, _, _ := setupPaymentWorker()
 
 		task := asynq.NewTask(TypeProcessPayment, []byte("invalid json"))
 
@@ -329,7 +479,7 @@ func TestPaymentWorker_HandleProcessPayment(t *testing.T) {
 
 	t.Run("should return error when payment not found", func(t *testing.T) {
 		// Setup
-		worker, mockService, _ := setupPaymentWorker()
+		worker, mockService, _, _ := setupPaymentWorker()
 
 		paymentID := uint(999)
 		payload := ProcessPaymentPayload{PaymentID: paymentID}
@@ -349,7 +499,7 @@ func TestPaymentWorker_HandleProcessPayment(t *testing.T) {
 
 	t.Run("should return error when update payment fails", func(t *testing.T) {
 		// Setup
-		worker, mockService, _ := setupPaymentWorker()
+		worker, mockService, _, mockGateway := setupPaymentWorker()
 
 		paymentID := uint(1)
 		payload := ProcessPaymentPayload{PaymentID: paymentID}
@@ -364,6 +514,7 @@ func TestPaymentWorker_HandleProcessPayment(t *testing.T) {
 		}
 
 		mockService.On("GetPaymentByID", paymentID).Return(payment, nil)
+		mockGateway.On("ProcessPayment", paymentID).Return(nil)
 		mockService.On("UpdatePayment", paymentID, mock.AnythingOfType("*dto.UpdatePaymentRequest")).Return(nil, errors.New("update failed"))
 
 		// When
@@ -376,10 +527,91 @@ func TestPaymentWorker_HandleProcessPayment(t *testing.T) {
 	})
 }
 
+func TestPaymentWorker_HandleBatchProcessPending(t *testing.T) {
+	t.Run("should attempt every payment when some fail", func(t *testing.T) {
+		// Setup
+		worker, mockService, _, mockGateway := setupPaymentWorker()
+
+		payloadBytes, _ := json.Marshal(BatchProcessPendingPayload{})
+		task := asynq.NewTask(TypeBatchProcessPending, payloadBytes)
+
+		pending := &dto.PaymentListResponse{
+			Data: []dto.PaymentResponse{
+				{ID: 1, Status: entity.PaymentStatusPending.String()},
+				{ID: 2, Status: entity.PaymentStatusPending.String()},
+				{ID: 3, Status: entity.PaymentStatusPending.String()},
+				{ID: 4, Status: entity.PaymentStatusPending.String()},
+			},
+			TotalCount: 4,
+		}
+
+		// Given
+		mockService.On("GetPayments", mock.MatchedBy(func(filter *dto.PaymentFilter) bool {
+			return filter.Status == entity.PaymentStatusPending.String() && filter.PageSize == 50
+		})).Return(pending, nil)
+		mockGateway.On("ProcessPayment", uint(1)).Return(nil)
+		mockGateway.On("ProcessPayment", uint(2)).Return(&GatewayError{StatusCode: 503, Message: "service unavailable"})
+		mockGateway.On("ProcessPayment", uint(3)).Return(nil)
+		mockGateway.On("ProcessPayment", uint(4)).Return(&GatewayError{StatusCode: 402, Message: "card declined"})
+		mockService.On("UpdatePayment", uint(1), mock.AnythingOfType("*dto.UpdatePaymentRequest")).Return(&dto.PaymentResponse{ID: 1}, nil)
+		mockService.On("UpdatePayment", uint(3), mock.AnythingOfType("*dto.UpdatePaymentRequest")).Return(nil, errors.New("update failed"))
+		mockService.On("UpdatePayment", uint(4), mock.AnythingOfType("*dto.UpdatePaymentRequest")).Return(&dto.PaymentResponse{ID: 4}, nil)
+
+		// When
+		err := worker.HandleBatchProcessPending(context.Background(), task)
+
+		// Then
+		assert.NoError(t, err)
+		mockService.AssertExpectations(t)
+		mockGateway.AssertExpectations(t)
+		mockGateway.AssertNumberOfCalls(t, "ProcessPayment", 4)
+		mockService.AssertNotCalled(t, "UpdatePayment", uint(2), mock.Anything)
+	})
+
+	t.Run("should use the batch size from the payload", func(t *testing.T) {
+		// Setup
+		worker, mockService, _, _ := setupPaymentWorker()
+
+		payloadBytes, _ := json.Marshal(BatchProcessPendingPayload{BatchSize: 5})
+		task := asynq.NewTask(TypeBatchProcessPending, payloadBytes)
+
+		// Given
+		mockService.On("GetPayments", mock.MatchedBy(func(filter *dto.PaymentFilter) bool {
+			return filter.PageSize == 5
+		})).Return(&dto.PaymentListResponse{Data: []dto.PaymentResponse{}}, nil)
+
+		// When
+		err := worker.HandleBatchProcessPending(context.Background(), task)
+
+		// Then
+		assert.NoError(t, err)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return error when the batch can't be fetched", func(t *testing.T) {
+		// Setup
+		worker, mockService, _, mockGateway := setupPaymentWorker()
+
+		payloadBytes, _ := json.Marshal(BatchProcessPendingPayload{})
+		task := asynq.NewTask(TypeBatchProcessPending, payloadBytes)
+
+		// Given
+		mockService.On("GetPayments", mock.AnythingOfType("*dto.PaymentFilter")).Return(nil, errors.New("database unavailable"))
+
+		// When
+		err := worker.HandleBatchProcessPending(context.Background(), task)
+
+		// Then
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch pending payments")
+		mockGateway.AssertNotCalled(t, "ProcessPayment", mock.Anything)
+	})
+}
+
 func TestPaymentWorker_SchedulePaymentStatusCheck(t *testing.T) {
 	t.Run("should schedule payment status check successfully", func(t *testing.T) {
 		// Setup
-		worker, _, mockClient := setupPaymentWorker()
+		worker, _, mockClient, _ := setupPaymentWorker()
 
 		paymentID := uint(1)
 		delay := 5 * time.Minute
@@ -407,7 +639,7 @@ func TestPaymentWorker_SchedulePaymentStatusCheck(t *testing.T) {
 
 	t.Run("should return error when enqueue fails", func(t *testing.T) {
 		// Setup
-		worker, _, mockClient := setupPaymentWorker()
+		worker, _, mockClient, _ := setupPaymentWorker()
 
 		paymentID := uint(1)
 		delay := 5 * time.Minute
@@ -427,7 +659,7 @@ func TestPaymentWorker_SchedulePaymentStatusCheck(t *testing.T) {
 func TestPaymentWorker_SchedulePaymentProcessing(t *testing.T) {
 	t.Run("should schedule payment processing successfully", func(t *testing.T) {
 		// Setup
-		worker, _, mockClient := setupPaymentWorker()
+		worker, _, mockClient, _ := setupPaymentWorker()
 
 		paymentID := uint(1)
 		taskInfo := &asynq.TaskInfo{ID: "task-456"}
@@ -454,7 +686,7 @@ func TestPaymentWorker_SchedulePaymentProcessing(t *testing.T) {
 
 	t.Run("should return error when enqueue fails", func(t *testing.T) {
 		// Setup
-		worker, _, mockClient := setupPaymentWorker()
+		worker, _, mockClient, _ := setupPaymentWorker()
 
 		paymentID := uint(1)
 
@@ -470,10 +702,10 @@ func TestPaymentWorker_SchedulePaymentProcessing(t *testing.T) {
 	})
 }
 
-func TestPaymentWorker_simulatePaymentGatewayCheck(t *testing.T) {
+func TestSimulatedGateway_CheckStatus(t *testing.T) {
 	t.Run("should return pending for recent payments", func(t *testing.T) {
 		// Setup
-		worker, _, _ := setupPaymentWorker()
+		gateway := NewSimulatedGateway()
 
 		payment := &dto.PaymentResponse{
 			ID:        1,
@@ -482,15 +714,16 @@ func TestPaymentWorker_simulatePaymentGatewayCheck(t *testing.T) {
 		}
 
 		// When
-		status := worker.simulatePaymentGatewayCheck(payment)
+		status, err := gateway.CheckStatus(context.Background(), payment)
 
 		// Then
+		assert.NoError(t, err)
 		assert.Equal(t, entity.PaymentStatusPending.String(), status)
 	})
 
-	t.Run("should return completed or failed for old payments", func(t *testing.T) {
+	t.Run("should return a known status for old payments", func(t *testing.T) {
 		// Setup
-		worker, _, _ := setupPaymentWorker()
+		gateway := NewSimulatedGateway()
 
 		payment := &dto.PaymentResponse{
 			ID:        1,
@@ -499,10 +732,10 @@ func TestPaymentWorker_simulatePaymentGatewayCheck(t *testing.T) {
 		}
 
 		// When
-		status := worker.simulatePaymentGatewayCheck(payment)
+		status, err := gateway.CheckStatus(context.Background(), payment)
 
 		// Then
-		// Should be either completed, failed, or pending (but most likely completed or failed)
+		assert.NoError(t, err)
 		validStatuses := []string{
 			entity.PaymentStatusPending.String(),
 			entity.PaymentStatusCompleted.String(),
@@ -512,10 +745,10 @@ func TestPaymentWorker_simulatePaymentGatewayCheck(t *testing.T) {
 	})
 }
 
-func TestPaymentWorker_simulatePaymentProcessing(t *testing.T) {
-	t.Run("should return boolean result", func(t *testing.T) {
+func TestSimulatedGateway_ProcessPayment(t *testing.T) {
+	t.Run("should succeed or return a permanent gateway error", func(t *testing.T) {
 		// Setup
-		worker, _, _ := setupPaymentWorker()
+		gateway := NewSimulatedGateway()
 
 		payment := &dto.PaymentResponse{
 			ID:     1,
@@ -523,10 +756,36 @@ func TestPaymentWorker_simulatePaymentProcessing(t *testing.T) {
 		}
 
 		// When
-		result := worker.simulatePaymentProcessing(payment)
+		err := gateway.ProcessPayment(context.Background(), payment)
 
 		// Then
-		// Should return either true or false (boolean)
-		assert.IsType(t, true, result)
+		if err != nil {
+			var gatewayErr *GatewayError
+			assert.ErrorAs(t, err, &gatewayErr)
+			assert.False(t, gatewayErr.IsRetriable())
+		}
 	})
 }
+
+func TestGatewayError_IsRetriable(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		retriable  bool
+	}{
+		{name: "network failure", statusCode: 0, retriable: true},
+		{name: "rate limited", statusCode: 429, retriable: true},
+		{name: "server error", statusCode: 500, retriable: true},
+		{name: "bad gateway", statusCode: 502, retriable: true},
+		{name: "bad request", statusCode: 400, retriable: false},
+		{name: "payment required", statusCode: 402, retriable: false},
+		{name: "not found", statusCode: 404, retriable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := &GatewayError{StatusCode: tt.statusCode, Message: tt.name}
+			assert.Equal(t, tt.retriable, err.IsRetriable())
+		})
+	}
+}
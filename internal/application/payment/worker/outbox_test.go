@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/repository"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/service"
+	userDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/outbox"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentOutbox(t *testing.T) {
+	t.Run("should drain a created payment's event into a processing task", func(t *testing.T) {
+		// Setup
+		db, err := testutil.SetupTestDB()
+		require.NoError(t, err)
+		testutil.WithCleanDB(t, db)
+		logger := testutil.NewSilentLogger()
+
+		mockUserService := &testutil.MockUserService{}
+		paymentService := service.NewPaymentService(
+			repository.NewPaymentRepository(db, logger), mockUserService, testutil.NewMockWalletService(),
+			testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+
+		mockClient := &MockAsynqClient{}
+		cfg := &config.Config{Worker: config.WorkerConfig{RetryMaxAttempts: 3, OutboxPollInterval: time.Second, OutboxBatchSize: 10}}
+		paymentWorker := NewPaymentWorker(paymentService, mockClient, &MockPaymentGateway{}, logger, cfg)
+		relay := outbox.NewRelay(db, mockClient, cfg, logger)
+		RegisterOutboxRoutes(relay, paymentWorker)
+
+		// Given: the queue is down while the payment is created
+		req := testutil.CreatePaymentRequestFixture()
+		mockUserService.On("GetUserByID", req.UserID).Return(&userDto.UserResponse{ID: req.UserID}, nil)
+		created, err := paymentService.CreatePayment(req)
+		require.NoError(t, err)
+
+		var message outbox.Message
+		require.NoError(t, db.Where("aggregate_id = ?", created.ID).First(&message).Error)
+		assert.Equal(t, entity.EventPaymentCreated, message.Topic)
+
+		mockClient.On("Enqueue", mock.AnythingOfType("*asynq.Task"), mock.Anything).Return(nil, errors.New("redis unavailable")).Once()
+		published, err := relay.Drain(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, published)
+
+		// When: the queue recovers
+		mockClient.On("Enqueue", mock.AnythingOfType("*asynq.Task"), mock.Anything).Return(&asynq.TaskInfo{}, nil).Once()
+		published, err = relay.Drain(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+		mockClient.AssertExpectations(t)
+
+		task := mockClient.Calls[1].Arguments[0].(*asynq.Task)
+		assert.Equal(t, TypeProcessPayment, task.Type())
+		payload, err := queue.UnmarshalPayload[ProcessPaymentPayload](task)
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, payload.PaymentID)
+
+		require.NoError(t, db.First(&message, message.ID).Error)
+		assert.NotNil(t, message.PublishedAt)
+		assert.Equal(t, 1, message.Attempts)
+	})
+}
@@ -1,6 +1,25 @@
 package worker
 
+import (
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/outbox"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
+)
+
 const (
-	TypeCheckPaymentStatus = "payment:check_status"
-	TypeProcessPayment     = "payment:process"
+	TypeCheckPaymentStatus  = "payment:check_status"
+	TypeProcessPayment      = "payment:process"
+	TypeBatchProcessPending = "payment:batch_process_pending"
 )
+
+// RegisterTasks records the payment task types and their payloads in the task registry
+func RegisterTasks(registry *queue.TaskRegistry) {
+	registry.Register(TypeCheckPaymentStatus, "Polls the gateway for a pending payment's status", CheckPaymentStatusPayload{})
+	registry.Register(TypeProcessPayment, "Submits a pending payment to the gateway", ProcessPaymentPayload{})
+	registry.Register(TypeBatchProcessPending, "Submits a page of pending payments to the gateway", BatchProcessPendingPayload{})
+}
+
+// RegisterOutboxRoutes maps payment outbox topics to the tasks the relay enqueues for them
+func RegisterOutboxRoutes(relay *outbox.Relay, paymentWorker *PaymentWorker) {
+	relay.Route(entity.EventPaymentCreated, paymentWorker.processPaymentTask)
+}
@@ -1,15 +1,20 @@
 package dto
 
-import "time"
+import (
+	"time"
 
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
+)
+
+// Names are stored trimmed; one that is blank once trimmed is rejected
 type CreateUserRequest struct {
-	Name     string `json:"name" binding:"required"`
+	Name     string `json:"name" binding:"required,notblank,max=100"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
 }
 
 type UpdateUserRequest struct {
-	Name  string `json:"name" binding:"required"`
+	Name  string `json:"name" binding:"required,notblank,max=100"`
 	Email string `json:"email" binding:"required,email"`
 }
 
@@ -24,6 +29,8 @@ type UserResponse struct {
 	Email     string    `json:"email"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt is only set on soft-deleted users listed with include_deleted
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type UserListResponse struct {
@@ -33,9 +40,38 @@ type UserListResponse struct {
 	PageSize   int            `json:"page_size"`
 }
 
+// InLocation formats created_at and updated_at in loc
+func (r *UserResponse) InLocation(loc *time.Location) {
+	r.CreatedAt = r.CreatedAt.In(loc)
+	r.UpdatedAt = r.UpdatedAt.In(loc)
+	if r.DeletedAt != nil {
+		deletedAt := r.DeletedAt.In(loc)
+		r.DeletedAt = &deletedAt
+	}
+}
+
+// InLocation formats every user's timestamps in loc
+func (r *UserListResponse) InLocation(loc *time.Location) {
+	for i := range r.Data {
+		r.Data[i].InLocation(loc)
+	}
+}
+
+// LastModified returns the latest updated_at on the page, zero for an empty page
+func (r *UserListResponse) LastModified() time.Time {
+	var latest time.Time
+	for _, user := range r.Data {
+		if user.UpdatedAt.After(latest) {
+			latest = user.UpdatedAt
+		}
+	}
+	return latest
+}
+
 type UserFilter struct {
-	Name     string `form:"name"`
-	Email    string `form:"email"`
-	Page     int    `form:"page"`
-	PageSize int    `form:"page_size"`
+	Name  string `form:"name"`
+	Email string `form:"email"`
+	// IncludeDeleted lists soft-deleted users too; only the admin listing honours it
+	IncludeDeleted bool `form:"include_deleted"`
+	pagination.Pagination
 }
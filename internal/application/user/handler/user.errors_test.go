@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserHandler_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		mock   func(m *testutil.MockUserService)
+		status int
+		code   string
+	}{
+		{
+			name:   "email already exists",
+			method: "POST",
+			path:   "/users",
+			body:   `{"name":"John Doe","email":"john@example.com","password":"password123"}`,
+			mock: func(m *testutil.MockUserService) {
+				m.On("CreateUser", mock.Anything).Return(nil, apperror.New(apperror.CodeEmailExists, "email already exists"))
+			},
+			status: http.StatusConflict,
+			code:   apperror.CodeEmailExists,
+		},
+		{
+			name:   "blocked email domain",
+			method: "POST",
+			path:   "/users",
+			body:   `{"name":"John Doe","email":"john@mailinator.com","password":"password123"}`,
+			mock: func(m *testutil.MockUserService) {
+				m.On("CreateUser", mock.Anything).Return(nil, apperror.New(apperror.CodeEmailDomainBlocked, "email domain is not allowed"))
+			},
+			status: http.StatusUnprocessableEntity,
+			code:   apperror.CodeEmailDomainBlocked,
+		},
+		{
+			name:   "weak password",
+			method: "POST",
+			path:   "/users",
+			body:   `{"name":"John Doe","email":"john@example.com","password":"password123"}`,
+			mock: func(m *testutil.MockUserService) {
+				m.On("CreateUser", mock.Anything).Return(nil, apperror.New(apperror.CodeWeakPassword, "password must contain an uppercase letter"))
+			},
+			status: http.StatusUnprocessableEntity,
+			code:   apperror.CodeWeakPassword,
+		},
+		{
+			name:   "validation failure",
+			method: "POST",
+			path:   "/users",
+			body:   `{"name":"John Doe","email":"not-an-email","password":"password123"}`,
+			status: http.StatusUnprocessableEntity,
+			code:   apperror.CodeValidationFailed,
+		},
+		{
+			name:   "blank name",
+			method: "POST",
+			path:   "/users",
+			body:   `{"name":"   ","email":"john@example.com","password":"password123"}`,
+			status: http.StatusUnprocessableEntity,
+			code:   apperror.CodeValidationFailed,
+		},
+		{
+			name:   "name too long",
+			method: "PUT",
+			path:   "/users/1",
+			body:   `{"name":"` + strings.Repeat("a", 101) + `","email":"john@example.com"}`,
+			status: http.StatusUnprocessableEntity,
+			code:   apperror.CodeValidationFailed,
+		},
+		{
+			name:   "malformed body",
+			method: "POST",
+			path:   "/users",
+			body:   `{"name":`,
+			status: http.StatusBadRequest,
+			code:   apperror.CodeInvalidRequest,
+		},
+		{
+			name:   "invalid user ID",
+			method: "GET",
+			path:   "/users/abc",
+			status: http.StatusBadRequest,
+			code:   apperror.CodeInvalidID,
+		},
+		{
+			name:   "invalid timezone",
+			method: "GET",
+			path:   "/users/1?tz=Mars/Olympus",
+			status: http.StatusBadRequest,
+			code:   apperror.CodeInvalidTimezone,
+		},
+		{
+			name:   "user not found",
+			method: "GET",
+			path:   "/users/99",
+			mock: func(m *testutil.MockUserService) {
+				m.On("GetUserByID", uint(99)).Return(nil, apperror.New(apperror.CodeUserNotFound, "user not found"))
+			},
+			status: http.StatusNotFound,
+			code:   apperror.CodeUserNotFound,
+		},
+		{
+			name:   "user not found on update",
+			method: "PUT",
+			path:   "/users/99",
+			body:   `{"name":"Jane Doe","email":"jane@example.com"}`,
+			mock: func(m *testutil.MockUserService) {
+				m.On("UpdateUser", uint(99), mock.Anything).Return(nil, apperror.New(apperror.CodeUserNotFound, "user not found"))
+			},
+			status: http.StatusNotFound,
+			code:   apperror.CodeUserNotFound,
+		},
+		{
+			name:   "current password is incorrect",
+			method: "PUT",
+			path:   "/users/1/password",
+			body:   `{"current_password":"wrongpassword","new_password":"newpassword123"}`,
+			mock: func(m *testutil.MockUserService) {
+				m.On("UpdateUserPassword", uint(1), mock.Anything).Return(apperror.New(apperror.CodeCurrentPasswordIncorrect, "current password is incorrect"))
+			},
+			status: http.StatusUnauthorized,
+			code:   apperror.CodeCurrentPasswordIncorrect,
+		},
+		{
+			name:   "weak new password",
+			method: "PUT",
+			path:   "/users/1/password",
+			body:   `{"current_password":"password123","new_password":"newpassword123"}`,
+			mock: func(m *testutil.MockUserService) {
+				m.On("UpdateUserPassword", uint(1), mock.Anything).Return(apperror.New(apperror.CodeWeakPassword, "password must contain a symbol"))
+			},
+			status: http.StatusUnprocessableEntity,
+			code:   apperror.CodeWeakPassword,
+		},
+		{
+			name:   "restoring a live user",
+			method: "POST",
+			path:   "/admin/users/1/restore",
+			mock: func(m *testutil.MockUserService) {
+				m.On("RestoreUser", uint(1)).Return(nil, apperror.New(apperror.CodeUserNotDeleted, "user is not deleted"))
+			},
+			status: http.StatusConflict,
+			code:   apperror.CodeUserNotDeleted,
+		},
+		{
+			name:   "unexpected failure",
+			method: "DELETE",
+			path:   "/users/1",
+			mock: func(m *testutil.MockUserService) {
+				m.On("DeleteUser", uint(1)).Return(assert.AnError)
+			},
+			status: http.StatusInternalServerError,
+			code:   apperror.CodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			handler, mockService := setupUserHandler()
+			if tt.mock != nil {
+				tt.mock(mockService)
+			}
+			router := gin.New()
+			handler.RegisterRoutes(router.Group(""))
+
+			// Given
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			// When
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Then
+			assert.Equal(t, tt.status, w.Code)
+			var result map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Equal(t, tt.code, result["code"])
+			assert.NotEmpty(t, result["error"])
+		})
+	}
+}
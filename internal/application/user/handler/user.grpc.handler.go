@@ -6,6 +6,8 @@ import (
 	"github.com/novriyantoAli/wallet-ms-backend/api/proto/user"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/service"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
@@ -39,6 +41,10 @@ func (h *UserGrpcHandler) CreateUser(
 	userResponse, err := h.userService.CreateUser(createReq)
 	if err != nil {
 		h.logger.Error("Failed to create user via gRPC", zap.Error(err))
+		switch apperror.Code(err) {
+		case apperror.CodeInvalidName, apperror.CodeEmailDomainBlocked, apperror.CodeWeakPassword:
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		return nil, status.Errorf(codes.Internal, "failed to create user: %v", err)
 	}
 
@@ -60,19 +66,12 @@ func (h *UserGrpcHandler) GetUser(ctx context.Context, req *user.GetUserRequest)
 }
 
 func (h *UserGrpcHandler) ListUsers(ctx context.Context, req *user.ListUsersRequest) (*user.ListUsersResponse, error) {
+	// Page defaults and limits are applied by the service
 	page := int(req.Page)
 	pageSize := int(req.PageSize)
 
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 10
-	}
-
 	filter := &dto.UserFilter{
-		Page:     page,
-		PageSize: pageSize,
+		Pagination: pagination.Pagination{Page: page, PageSize: pageSize},
 	}
 
 	listResponse, err := h.userService.GetUsers(filter)
@@ -106,6 +105,9 @@ func (h *UserGrpcHandler) UpdateUser(
 	userResponse, err := h.userService.UpdateUser(uint(req.Id), updateReq)
 	if err != nil {
 		h.logger.Error("Failed to update user via gRPC", zap.Uint32("id", req.Id), zap.Error(err))
+		if apperror.Code(err) == apperror.CodeInvalidName {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
 	}
 
@@ -141,6 +143,9 @@ func (h *UserGrpcHandler) UpdateUserPassword(
 	err := h.userService.UpdateUserPassword(uint(req.Id), updateReq)
 	if err != nil {
 		h.logger.Error("Failed to update user password via gRPC", zap.Uint32("id", req.Id), zap.Error(err))
+		if apperror.Code(err) == apperror.CodeWeakPassword {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		return nil, status.Errorf(codes.Internal, "failed to update password: %v", err)
 	}
 
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/api/proto/user"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func setupUserGrpcHandler() (*UserGrpcHandler, *testutil.MockUserService) {
+	mockService := &testutil.MockUserService{}
+	handler := NewUserGrpcHandler(mockService, testutil.NewSilentLogger())
+	return handler, mockService
+}
+
+func TestUserGrpcHandler_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(h *UserGrpcHandler) error
+		mock func(m *testutil.MockUserService)
+		code codes.Code
+	}{
+		{
+			name: "blank name on create",
+			call: func(h *UserGrpcHandler) error {
+				_, err := h.CreateUser(context.Background(), &user.CreateUserRequest{Name: "   ", Email: "jane@example.com", Password: "Secret123!"})
+				return err
+			},
+			mock: func(m *testutil.MockUserService) {
+				m.On("CreateUser", mock.Anything).Return(nil, apperror.New(apperror.CodeInvalidName, "name is required"))
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "101-rune name on update",
+			call: func(h *UserGrpcHandler) error {
+				_, err := h.UpdateUser(context.Background(), &user.UpdateUserRequest{Id: 1, Name: strings.Repeat("é", 101)})
+				return err
+			},
+			mock: func(m *testutil.MockUserService) {
+				m.On("UpdateUser", uint(1), mock.Anything).Return(nil, apperror.New(apperror.CodeInvalidName, "name is too long"))
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "weak password on create",
+			call: func(h *UserGrpcHandler) error {
+				_, err := h.CreateUser(context.Background(), &user.CreateUserRequest{Name: "Jane", Email: "jane@example.com", Password: "short"})
+				return err
+			},
+			mock: func(m *testutil.MockUserService) {
+				m.On("CreateUser", mock.Anything).Return(nil, apperror.New(apperror.CodeWeakPassword, "password is too short"))
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "blocked email domain on create",
+			call: func(h *UserGrpcHandler) error {
+				_, err := h.CreateUser(context.Background(), &user.CreateUserRequest{Name: "Jane", Email: "jane@blocked.test", Password: "Secret123!"})
+				return err
+			},
+			mock: func(m *testutil.MockUserService) {
+				m.On("CreateUser", mock.Anything).Return(nil, apperror.New(apperror.CodeEmailDomainBlocked, "email domain is not allowed"))
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "weak new password",
+			call: func(h *UserGrpcHandler) error {
+				_, err := h.UpdateUserPassword(context.Background(), &user.UpdateUserPasswordRequest{Id: 1, OldPassword: "Secret123!", NewPassword: "short"})
+				return err
+			},
+			mock: func(m *testutil.MockUserService) {
+				m.On("UpdateUserPassword", uint(1), mock.Anything).Return(apperror.New(apperror.CodeWeakPassword, "password is too short"))
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "unexpected failure on create",
+			call: func(h *UserGrpcHandler) error {
+				_, err := h.CreateUser(context.Background(), &user.CreateUserRequest{Name: "Jane", Email: "jane@example.com", Password: "Secret123!"})
+				return err
+			},
+			mock: func(m *testutil.MockUserService) {
+				m.On("CreateUser", mock.Anything).Return(nil, assert.AnError)
+			},
+			code: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			handler, mockService := setupUserGrpcHandler()
+
+			// Given
+			tt.mock(mockService)
+
+			// When
+			err := tt.call(handler)
+
+			// Then
+			assert.Equal(t, tt.code, status.Code(err))
+			mockService.AssertExpectations(t)
+		})
+	}
+}
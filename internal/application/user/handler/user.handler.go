@@ -6,6 +6,10 @@ import (
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/service"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/httpcache"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/timezone"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/validation"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -23,15 +27,21 @@ func NewUserHandler(service service.UserService, logger *zap.Logger) *UserHandle
 	}
 }
 
+// idempotentCreateHeader makes CreateUser return the existing user for a retried request
+const idempotentCreateHeader = "Idempotent-Create"
+
 // CreateUser godoc
 // @Summary Create a new user
-// @Description Create a new user with the provided information
+// @Description Create a new user with the provided information. With Idempotent-Create: true, a retry whose email and password match an existing user returns that user with 200 instead of a conflict.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param user body dto.CreateUserRequest true "User creation request"
+// @Param Idempotent-Create header bool false "Return the existing user when the request is a retry"
+// @Success 200 {object} map[string]interface{} "Existing user (idempotent create)"
 // @Success 201 {object} map[string]interface{} "Created user"
 // @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 422 {object} map[string]interface{} "Validation failed, with a message per field, email domain not allowed, or password too weak"
 // @Failure 409 {object} map[string]interface{} "Email already exists"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /users [post]
@@ -39,21 +49,37 @@ func (h *UserHandler) CreateUser(ctx *gin.Context) {
 	var req dto.CreateUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		h.logger.Error("Invalid request body", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(validation.BindErrorResponse(ctx, err))
 		return
 	}
 
-	user, err := h.service.CreateUser(&req)
+	idempotent, _ := strconv.ParseBool(ctx.GetHeader(idempotentCreateHeader))
+
+	var user *dto.UserResponse
+	var err error
+	created := true
+	if idempotent {
+		user, created, err = h.service.CreateUserOrGetExisting(&req)
+	} else {
+		user, err = h.service.CreateUser(&req)
+	}
 	if err != nil {
 		h.logger.Error("Failed to create user", zap.Error(err))
-		if err.Error() == "email already exists" {
-			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-			return
+		switch apperror.Code(err) {
+		case apperror.CodeEmailExists:
+			apperror.JSON(ctx, http.StatusConflict, err)
+		case apperror.CodeEmailDomainBlocked, apperror.CodeWeakPassword:
+			apperror.JSON(ctx, http.StatusUnprocessableEntity, err)
+		default:
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to create user")
 		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
+	if !created {
+		ctx.JSON(http.StatusOK, gin.H{"data": user})
+		return
+	}
 	ctx.JSON(http.StatusCreated, gin.H{"data": user})
 }
 
@@ -64,24 +90,32 @@ func (h *UserHandler) CreateUser(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param tz query string false "IANA time zone for timestamps, e.g. Asia/Jakarta" default(UTC)
 // @Success 200 {object} map[string]interface{} "User details"
-// @Failure 400 {object} map[string]interface{} "Invalid user ID"
+// @Failure 400 {object} map[string]interface{} "Invalid user ID or timezone"
 // @Failure 404 {object} map[string]interface{} "User not found"
 // @Router /users/{id} [get]
 func (h *UserHandler) GetUser(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid user ID")
+		return
+	}
+
+	loc, err := timezone.Parse(ctx.Query("tz"))
+	if err != nil {
+		apperror.JSON(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	user, err := h.service.GetUserByID(uint(id))
 	if err != nil {
 		h.logger.Error("Failed to get user", zap.Error(err))
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		apperror.Message(ctx, http.StatusNotFound, apperror.CodeUserNotFound, "User not found")
 		return
 	}
+	user.InLocation(loc)
 
 	ctx.JSON(http.StatusOK, gin.H{"data": user})
 }
@@ -96,22 +130,65 @@ func (h *UserHandler) GetUser(ctx *gin.Context) {
 // @Param email query string false "Filter by email"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Number of items per page" default(10)
+// @Param tz query string false "IANA time zone for timestamps, e.g. Asia/Jakarta" default(UTC)
+// @Param If-Modified-Since header string false "Last-Modified from an earlier response"
 // @Success 200 {object} dto.UserListResponse "List of users"
+// @Header 200 {string} Last-Modified "Latest update among the returned users"
+// @Success 304 "Nothing returned has changed since If-Modified-Since"
 // @Failure 400 {object} map[string]interface{} "Invalid query parameters"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /users [get]
 func (h *UserHandler) GetUsers(ctx *gin.Context) {
+	h.listUsers(ctx, false)
+}
+
+// GetUsersWithDeleted godoc
+// @Summary Get all users, including deleted ones
+// @Description Same as GET /users, but include_deleted=true also lists soft-deleted users with their deleted_at
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param name query string false "Filter by name"
+// @Param email query string false "Filter by email"
+// @Param include_deleted query bool false "Include soft-deleted users"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Number of items per page" default(10)
+// @Param tz query string false "IANA time zone for timestamps, e.g. Asia/Jakarta" default(UTC)
+// @Param If-Modified-Since header string false "Last-Modified from an earlier response"
+// @Success 200 {object} dto.UserListResponse "List of users"
+// @Header 200 {string} Last-Modified "Latest update among the returned users"
+// @Success 304 "Nothing returned has changed since If-Modified-Since"
+// @Failure 400 {object} map[string]interface{} "Invalid query parameters"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/users [get]
+func (h *UserHandler) GetUsersWithDeleted(ctx *gin.Context) {
+	h.listUsers(ctx, true)
+}
+
+// listUsers serves the user listings; include_deleted is ignored unless allowDeleted
+func (h *UserHandler) listUsers(ctx *gin.Context, allowDeleted bool) {
 	var filter dto.UserFilter
 	if err := ctx.ShouldBindQuery(&filter); err != nil {
 		h.logger.Error("Invalid query parameters", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apperror.JSON(ctx, http.StatusBadRequest, err)
+		return
+	}
+	filter.IncludeDeleted = filter.IncludeDeleted && allowDeleted
+
+	loc, err := timezone.Parse(ctx.Query("tz"))
+	if err != nil {
+		apperror.JSON(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	users, err := h.service.GetUsers(&filter)
 	if err != nil {
 		h.logger.Error("Failed to get users", zap.Error(err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get users"})
+		apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to get users")
+		return
+	}
+	users.InLocation(loc)
+	if httpcache.NotModified(ctx, users.LastModified()) {
 		return
 	}
 
@@ -128,6 +205,7 @@ func (h *UserHandler) GetUsers(ctx *gin.Context) {
 // @Param user body dto.UpdateUserRequest true "User update request"
 // @Success 200 {object} map[string]interface{} "Updated user"
 // @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 422 {object} map[string]interface{} "Validation failed, with a message per field, or email domain not allowed"
 // @Failure 404 {object} map[string]interface{} "User not found"
 // @Failure 409 {object} map[string]interface{} "Email already exists"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -136,29 +214,30 @@ func (h *UserHandler) UpdateUser(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid user ID")
 		return
 	}
 
 	var req dto.UpdateUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		h.logger.Error("Invalid request body", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(validation.BindErrorResponse(ctx, err))
 		return
 	}
 
 	user, err := h.service.UpdateUser(uint(id), &req)
 	if err != nil {
 		h.logger.Error("Failed to update user", zap.Error(err))
-		if err.Error() == "user not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-			return
-		}
-		if err.Error() == "email already exists" {
-			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-			return
+		switch apperror.Code(err) {
+		case apperror.CodeUserNotFound:
+			apperror.JSON(ctx, http.StatusNotFound, err)
+		case apperror.CodeEmailExists:
+			apperror.JSON(ctx, http.StatusConflict, err)
+		case apperror.CodeEmailDomainBlocked:
+			apperror.JSON(ctx, http.StatusUnprocessableEntity, err)
+		default:
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to update user")
 		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
 
@@ -177,35 +256,37 @@ func (h *UserHandler) UpdateUser(ctx *gin.Context) {
 // @Failure 400 {object} map[string]interface{} "Invalid request"
 // @Failure 401 {object} map[string]interface{} "Current password is incorrect"
 // @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 422 {object} map[string]interface{} "New password breaks the user.password rules"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /users/{id}/password [put]
 func (h *UserHandler) UpdateUserPassword(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid user ID")
 		return
 	}
 
 	var req dto.UpdateUserPasswordRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		h.logger.Error("Invalid request body", zap.Error(err))
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apperror.JSON(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	err = h.service.UpdateUserPassword(uint(id), &req)
 	if err != nil {
 		h.logger.Error("Failed to update user password", zap.Error(err))
-		if err.Error() == "user not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-			return
+		switch apperror.Code(err) {
+		case apperror.CodeUserNotFound:
+			apperror.JSON(ctx, http.StatusNotFound, err)
+		case apperror.CodeCurrentPasswordIncorrect:
+			apperror.JSON(ctx, http.StatusUnauthorized, err)
+		case apperror.CodeWeakPassword:
+			apperror.JSON(ctx, http.StatusUnprocessableEntity, err)
+		default:
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to update password")
 		}
-		if err.Error() == "current password is incorrect" {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
 		return
 	}
 
@@ -228,24 +309,64 @@ func (h *UserHandler) DeleteUser(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid user ID")
 		return
 	}
 
 	err = h.service.DeleteUser(uint(id))
 	if err != nil {
 		h.logger.Error("Failed to delete user", zap.Error(err))
-		if err.Error() == "user not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		if apperror.Code(err) == apperror.CodeUserNotFound {
+			apperror.JSON(ctx, http.StatusNotFound, err)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to delete user")
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
 
+// RestoreUser godoc
+// @Summary Restore a deleted user
+// @Description Undo the soft delete of a user. Served under /users and /admin/users; neither
+// @Description path has an admin guard, as the API has no authentication.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{} "Restored user"
+// @Failure 400 {object} map[string]interface{} "Invalid user ID"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 409 {object} map[string]interface{} "User is not deleted"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/{id}/restore [post]
+// @Router /admin/users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid user ID")
+		return
+	}
+
+	user, err := h.service.RestoreUser(uint(id))
+	if err != nil {
+		h.logger.Error("Failed to restore user", zap.Error(err))
+		switch apperror.Code(err) {
+		case apperror.CodeUserNotFound:
+			apperror.JSON(ctx, http.StatusNotFound, err)
+		case apperror.CodeUserNotDeleted:
+			apperror.JSON(ctx, http.StatusConflict, err)
+		default:
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to restore user")
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": user})
+}
+
 func (h *UserHandler) RegisterRoutes(api *gin.RouterGroup) {
 	users := api.Group("/users")
 	{
@@ -255,5 +376,12 @@ func (h *UserHandler) RegisterRoutes(api *gin.RouterGroup) {
 		users.PUT("/:id", h.UpdateUser)
 		users.DELETE("/:id", h.DeleteUser)
 		users.PUT("/:id/password", h.UpdateUserPassword)
+		users.POST("/:id/restore", h.RestoreUser)
+	}
+
+	admin := api.Group("/admin/users")
+	{
+		admin.GET("", h.GetUsersWithDeleted)
+		admin.POST("/:id/restore", h.RestoreUser)
 	}
 }
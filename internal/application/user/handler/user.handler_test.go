@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
 
 	"github.com/gin-gonic/gin"
@@ -64,6 +65,106 @@ func TestUserHandler_CreateUser(t *testing.T) {
 		assert.Equal(t, req.Email, data["email"])
 	})
 
+	t.Run("should return the existing user for an idempotent retry", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupUserHandler()
+
+		req := testutil.CreateUserRequestFixture()
+		existing := &dto.UserResponse{ID: 7, Name: req.Name, Email: req.Email}
+
+		mockService.On("CreateUserOrGetExisting", req).Return(existing, false, nil)
+
+		reqBody, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/users", bytes.NewBuffer(reqBody))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Request.Header.Set("Idempotent-Create", "true")
+
+		// When
+		handler.CreateUser(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(7), response["data"].(map[string]interface{})["id"])
+		mockService.AssertExpectations(t)
+		mockService.AssertNotCalled(t, "CreateUser", mock.Anything)
+	})
+
+	t.Run("should return created for an idempotent request with a new email", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupUserHandler()
+
+		req := testutil.CreateUserRequestFixture()
+		mockService.On("CreateUserOrGetExisting", req).Return(&dto.UserResponse{ID: 1, Email: req.Email}, true, nil)
+
+		reqBody, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/users", bytes.NewBuffer(reqBody))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Request.Header.Set("Idempotent-Create", "true")
+
+		// When
+		handler.CreateUser(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return conflict for an existing email without the header", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupUserHandler()
+
+		req := testutil.CreateUserRequestFixture()
+		mockService.On("CreateUser", req).Return(nil, apperror.New(apperror.CodeEmailExists, "email already exists"))
+
+		reqBody, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/users", bytes.NewBuffer(reqBody))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+
+		// When
+		handler.CreateUser(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+		mockService.AssertNotCalled(t, "CreateUserOrGetExisting", mock.Anything)
+	})
+
+	t.Run("should return per-field messages for invalid fields", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupUserHandler()
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/users", bytes.NewBufferString(`{"name":"John Doe","password":"short"}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+
+		// When
+		handler.CreateUser(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "validation failed", response["error"])
+		assert.Equal(t, map[string]interface{}{
+			"email":    "is required",
+			"password": "must be at least 8 characters",
+		}, response["fields"])
+		mockService.AssertNotCalled(t, "CreateUser", mock.Anything)
+	})
+
 	t.Run("should return bad request for invalid JSON", func(t *testing.T) {
 		// Setup
 		handler, mockService := setupUserHandler()
@@ -86,7 +187,7 @@ func TestUserHandler_CreateUser(t *testing.T) {
 		handler, mockService := setupUserHandler()
 
 		req := testutil.CreateUserRequestFixture()
-		mockService.On("CreateUser", mock.AnythingOfType("*dto.CreateUserRequest")).Return(nil, errors.New("email already exists"))
+		mockService.On("CreateUser", mock.AnythingOfType("*dto.CreateUserRequest")).Return(nil, apperror.New(apperror.CodeEmailExists, "email already exists"))
 
 		reqBody, _ := json.Marshal(req)
 		w := httptest.NewRecorder()
@@ -185,7 +286,7 @@ func TestUserHandler_GetUser(t *testing.T) {
 		handler, mockService := setupUserHandler()
 
 		userID := uint(999)
-		mockService.On("GetUserByID", userID).Return(nil, errors.New("user not found"))
+		mockService.On("GetUserByID", userID).Return(nil, apperror.New(apperror.CodeUserNotFound, "user not found"))
 
 		w := httptest.NewRecorder()
 		ctx, _ := gin.CreateTestContext(w)
@@ -340,7 +441,7 @@ func TestUserHandler_UpdateUser(t *testing.T) {
 
 		userID := uint(999)
 		req := testutil.CreateUpdateUserRequestFixture()
-		mockService.On("UpdateUser", userID, mock.AnythingOfType("*dto.UpdateUserRequest")).Return(nil, errors.New("user not found"))
+		mockService.On("UpdateUser", userID, mock.AnythingOfType("*dto.UpdateUserRequest")).Return(nil, apperror.New(apperror.CodeUserNotFound, "user not found"))
 
 		reqBody, _ := json.Marshal(req)
 		w := httptest.NewRecorder()
@@ -365,7 +466,7 @@ func TestUserHandler_UpdateUser(t *testing.T) {
 
 		userID := uint(1)
 		req := testutil.CreateUpdateUserRequestFixture()
-		mockService.On("UpdateUser", userID, mock.AnythingOfType("*dto.UpdateUserRequest")).Return(nil, errors.New("email already exists"))
+		mockService.On("UpdateUser", userID, mock.AnythingOfType("*dto.UpdateUserRequest")).Return(nil, apperror.New(apperror.CodeEmailExists, "email already exists"))
 
 		reqBody, _ := json.Marshal(req)
 		w := httptest.NewRecorder()
@@ -430,7 +531,7 @@ func TestUserHandler_UpdateUserPassword(t *testing.T) {
 			NewPassword:     "newpassword123",
 		}
 
-		mockService.On("UpdateUserPassword", userID, mock.AnythingOfType("*dto.UpdateUserPasswordRequest")).Return(errors.New("current password is incorrect"))
+		mockService.On("UpdateUserPassword", userID, mock.AnythingOfType("*dto.UpdateUserPasswordRequest")).Return(apperror.New(apperror.CodeCurrentPasswordIncorrect, "current password is incorrect"))
 
 		reqBody, _ := json.Marshal(req)
 		w := httptest.NewRecorder()
@@ -483,7 +584,7 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 		handler, mockService := setupUserHandler()
 
 		userID := uint(999)
-		mockService.On("DeleteUser", userID).Return(errors.New("user not found"))
+		mockService.On("DeleteUser", userID).Return(apperror.New(apperror.CodeUserNotFound, "user not found"))
 
 		w := httptest.NewRecorder()
 		ctx, _ := gin.CreateTestContext(w)
@@ -539,6 +640,9 @@ func TestUserHandler_RegisterRoutes(t *testing.T) {
 			"PUT /api/v1/users/:id",
 			"DELETE /api/v1/users/:id",
 			"PUT /api/v1/users/:id/password",
+			"POST /api/v1/users/:id/restore",
+			"GET /api/v1/admin/users",
+			"POST /api/v1/admin/users/:id/restore",
 		}
 
 		assert.Len(t, routes, len(expectedRoutes))
@@ -554,3 +658,142 @@ func TestUserHandler_RegisterRoutes(t *testing.T) {
 		}
 	})
 }
+
+func TestUserHandler_GetUsers_LastModified(t *testing.T) {
+	updatedAt := time.Date(2024, 3, 1, 20, 30, 0, 0, time.UTC)
+	response := &dto.UserListResponse{
+		Data: []dto.UserResponse{
+			{ID: 1, UpdatedAt: updatedAt.Add(-time.Hour)},
+			{ID: 2, UpdatedAt: updatedAt},
+		},
+		TotalCount: 2,
+	}
+
+	list := func(handler *UserHandler, ifModifiedSince string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/users", nil)
+		if ifModifiedSince != "" {
+			ctx.Request.Header.Set("If-Modified-Since", ifModifiedSince)
+		}
+		handler.GetUsers(ctx)
+		return w
+	}
+
+	t.Run("should report the latest update on the page", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupUserHandler()
+		mockService.On("GetUsers", mock.Anything).Return(response, nil)
+
+		// When
+		w := list(handler, "")
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Fri, 01 Mar 2024 20:30:00 GMT", w.Header().Get("Last-Modified"))
+	})
+
+	t.Run("should return 304 when nothing changed since", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupUserHandler()
+		mockService.On("GetUsers", mock.Anything).Return(response, nil)
+
+		// When
+		w := list(handler, "Fri, 01 Mar 2024 20:30:00 GMT")
+
+		// Then
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("should return the list when a user changed since", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupUserHandler()
+		mockService.On("GetUsers", mock.Anything).Return(response, nil)
+
+		// When
+		w := list(handler, "Fri, 01 Mar 2024 20:29:59 GMT")
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestUserHandler_RestoreUser(t *testing.T) {
+	t.Run("should restore a deleted user", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupUserHandler()
+		mockService.On("RestoreUser", uint(1)).Return(&dto.UserResponse{ID: 1, Name: "John Doe", Email: "john@example.com"}, nil)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/admin/users/1/restore", nil)
+		ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+		// When
+		handler.RestoreUser(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+		var result map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &result)
+		data := result["data"].(map[string]interface{})
+		assert.Equal(t, float64(1), data["id"])
+		assert.NotContains(t, data, "deleted_at")
+	})
+
+	t.Run("should return not found for an unknown user", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupUserHandler()
+		mockService.On("RestoreUser", uint(99)).Return(nil, apperror.New(apperror.CodeUserNotFound, "user not found"))
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/admin/users/99/restore", nil)
+		ctx.Params = gin.Params{{Key: "id", Value: "99"}}
+
+		// When
+		handler.RestoreUser(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestUserHandler_IncludeDeleted(t *testing.T) {
+	matchIncludeDeleted := func(want bool) interface{} {
+		return mock.MatchedBy(func(filter *dto.UserFilter) bool { return filter.IncludeDeleted == want })
+	}
+
+	t.Run("should ignore include_deleted on the public listing", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupUserHandler()
+		mockService.On("GetUsers", matchIncludeDeleted(false)).Return(&dto.UserListResponse{}, nil)
+		router := gin.New()
+		handler.RegisterRoutes(router.Group(""))
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/users?include_deleted=true", nil))
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should honour include_deleted on the admin listing", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupUserHandler()
+		mockService.On("GetUsers", matchIncludeDeleted(true)).Return(&dto.UserListResponse{}, nil)
+		router := gin.New()
+		handler.RegisterRoutes(router.Group(""))
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/users?include_deleted=true", nil))
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -15,6 +15,7 @@ var Module = fx.Options(
 		service.NewUserService,
 		handler.NewUserHandler,
 	),
+	fx.Decorate(service.NewCachedUserService),
 )
 
 // WorkerModule provides only worker dependencies for worker api
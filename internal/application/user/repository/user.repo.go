@@ -6,15 +6,19 @@ import (
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
 	Create(user *entity.User) error
 	GetByID(id uint) (*entity.User, error)
 	GetByEmail(email string) (*entity.User, error)
+	GetByIDs(ids []uint) ([]entity.User, error)
 	GetAll(filter *dto.UserFilter) ([]entity.User, int64, error)
 	Update(user *entity.User) error
 	Delete(id uint) error
+	GetByIDWithDeleted(id uint) (*entity.User, error)
+	Restore(id uint) error
 	EmailExists(email string) (bool, error)
 }
 
@@ -30,6 +34,14 @@ func NewUserRepository(db *gorm.DB, logger *zap.Logger) UserRepository {
 	}
 }
 
+// Lock takes the row lock of the user with userID inside tx, so transactions that check
+// a per-user total before writing run one after another for the same user. A missing
+// user is not an error; there is then nothing to lock.
+func Lock(tx *gorm.DB, userID uint) error {
+	var users []entity.User
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", userID).Find(&users).Error
+}
+
 func (r *userRepository) Create(user *entity.User) error {
 	r.logger.Info("Creating user", zap.String("email", user.Email))
 	return r.db.Create(user).Error
@@ -55,11 +67,28 @@ func (r *userRepository) GetByEmail(email string) (*entity.User, error) {
 	return &user, nil
 }
 
+func (r *userRepository) GetByIDs(ids []uint) ([]entity.User, error) {
+	users := []entity.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	err := r.db.Where("id IN ?", ids).Find(&users).Error
+	if err != nil {
+		r.logger.Error("Failed to get users by IDs", zap.Int("count", len(ids)), zap.Error(err))
+		return nil, err
+	}
+	return users, nil
+}
+
 func (r *userRepository) GetAll(filter *dto.UserFilter) ([]entity.User, int64, error) {
 	var users []entity.User
 	var totalCount int64
 
 	query := r.db.Model(&entity.User{})
+	if filter.IncludeDeleted {
+		query = r.db.Unscoped().Model(&entity.User{})
+	}
 
 	if filter.Name != "" {
 		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
@@ -71,8 +100,7 @@ func (r *userRepository) GetAll(filter *dto.UserFilter) ([]entity.User, int64, e
 	query.Count(&totalCount)
 
 	if filter.Page > 0 && filter.PageSize > 0 {
-		offset := (filter.Page - 1) * filter.PageSize
-		query = query.Offset(offset).Limit(filter.PageSize)
+		query = query.Offset(filter.Offset()).Limit(filter.PageSize)
 	}
 
 	err := query.Find(&users).Error
@@ -84,9 +112,21 @@ func (r *userRepository) GetAll(filter *dto.UserFilter) ([]entity.User, int64, e
 	return users, totalCount, nil
 }
 
+// Update writes every field of an existing user. Unlike Save it never inserts: it
+// returns gorm.ErrRecordNotFound when no live user has the ID, including ID 0.
 func (r *userRepository) Update(user *entity.User) error {
 	r.logger.Info("Updating user", zap.Uint("id", user.ID))
-	return r.db.Save(user).Error
+	result := r.db.Model(user).
+		Where("id = ?", user.ID).
+		Select("*").Omit("id", "created_at").
+		Updates(user)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
 func (r *userRepository) Delete(id uint) error {
@@ -94,6 +134,33 @@ func (r *userRepository) Delete(id uint) error {
 	return r.db.Delete(&entity.User{}, id).Error
 }
 
+// GetByIDWithDeleted finds a user whether or not it is soft-deleted
+func (r *userRepository) GetByIDWithDeleted(id uint) (*entity.User, error) {
+	var user entity.User
+	err := r.db.Unscoped().First(&user, id).Error
+	if err != nil {
+		r.logger.Error("Failed to get user by ID including deleted", zap.Uint("id", id), zap.Error(err))
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Restore clears a soft-deleted user's DeletedAt. It returns gorm.ErrRecordNotFound
+// when no deleted user has the ID.
+func (r *userRepository) Restore(id uint) error {
+	r.logger.Info("Restoring user", zap.Uint("id", id))
+	result := r.db.Unscoped().Model(&entity.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (r *userRepository) EmailExists(email string) (bool, error) {
 	var count int64
 	err := r.db.Model(&entity.User{}).Where("email = ?", email).Count(&count).Error
@@ -6,6 +6,7 @@ import (
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
 
 	"github.com/stretchr/testify/assert"
@@ -17,6 +18,7 @@ func TestUserRepository_Create(t *testing.T) {
 	// Setup
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 	logger := testutil.NewTestLogger(t)
 	repo := NewUserRepository(db, logger)
 
@@ -58,15 +60,13 @@ func TestUserRepository_Create(t *testing.T) {
 		assert.NoError(t, err1)
 		assert.Error(t, err2) // Should fail due to unique constraint
 	})
-
-	// Cleanup
-	testutil.CleanDB(db)
 }
 
 func TestUserRepository_GetByID(t *testing.T) {
 	// Setup
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 	logger := testutil.NewTestLogger(t)
 	repo := NewUserRepository(db, logger)
 
@@ -95,15 +95,13 @@ func TestUserRepository_GetByID(t *testing.T) {
 		assert.Error(t, err)
 		assert.Equal(t, gorm.ErrRecordNotFound, err)
 	})
-
-	// Cleanup
-	testutil.CleanDB(db)
 }
 
 func TestUserRepository_GetByEmail(t *testing.T) {
 	// Setup
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 	logger := testutil.NewTestLogger(t)
 	repo := NewUserRepository(db, logger)
 
@@ -132,15 +130,52 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 		assert.Error(t, err)
 		assert.Equal(t, gorm.ErrRecordNotFound, err)
 	})
+}
+
+func TestUserRepository_GetByIDs(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewTestLogger(t)
+	repo := NewUserRepository(db, logger)
+
+	t.Run("should return existing users and omit unknown IDs", func(t *testing.T) {
+		// Given
+		ids := make([]uint, 0, 3)
+		for i := 0; i < 3; i++ {
+			user := testutil.CreateUserFixture()
+			user.ID = 0
+			user.Email = fmt.Sprintf("user%d@example.com", i)
+			require.NoError(t, repo.Create(user))
+			ids = append(ids, user.ID)
+		}
+
+		// When
+		users, err := repo.GetByIDs([]uint{ids[0], ids[2], 999})
+
+		// Then
+		assert.NoError(t, err)
+		require.Len(t, users, 2)
+		found := []uint{users[0].ID, users[1].ID}
+		assert.ElementsMatch(t, []uint{ids[0], ids[2]}, found)
+	})
 
-	// Cleanup
-	testutil.CleanDB(db)
+	t.Run("should return empty list without querying for no IDs", func(t *testing.T) {
+		// When
+		users, err := repo.GetByIDs(nil)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Empty(t, users)
+	})
 }
 
 func TestUserRepository_GetAll(t *testing.T) {
 	// Setup
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 	logger := testutil.NewTestLogger(t)
 	repo := NewUserRepository(db, logger)
 
@@ -156,8 +191,7 @@ func TestUserRepository_GetAll(t *testing.T) {
 		}
 
 		filter := &dto.UserFilter{
-			Page:     1,
-			PageSize: 3,
+			Pagination: pagination.Pagination{Page: 1, PageSize: 3},
 		}
 
 		// When
@@ -198,15 +232,13 @@ func TestUserRepository_GetAll(t *testing.T) {
 		assert.Equal(t, int64(1), totalCount)
 		assert.Equal(t, "Alice Smith", users[0].Name)
 	})
-
-	// Cleanup
-	testutil.CleanDB(db)
 }
 
 func TestUserRepository_Update(t *testing.T) {
 	// Setup
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 	logger := testutil.NewTestLogger(t)
 	repo := NewUserRepository(db, logger)
 
@@ -233,14 +265,46 @@ func TestUserRepository_Update(t *testing.T) {
 		assert.Equal(t, "updated@example.com", dbUser.Email)
 	})
 
-	// Cleanup
-	testutil.CleanDB(db)
+	t.Run("should return not found instead of inserting a missing user", func(t *testing.T) {
+		for _, id := range []uint{0, 999} {
+			// Given
+			user := testutil.CreateUserFixture()
+			user.ID = id
+			user.Email = "ghost@example.com"
+
+			// When
+			err := repo.Update(user)
+
+			// Then
+			assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "id %d", id)
+			var count int64
+			require.NoError(t, db.Unscoped().Model(&entity.User{}).Where("email = ?", user.Email).Count(&count).Error)
+			assert.Zero(t, count, "id %d", id)
+		}
+	})
+
+	t.Run("should not update a soft-deleted user", func(t *testing.T) {
+		// Given
+		user := testutil.CreateUserFixture()
+		user.ID = 0
+		user.Email = "deleted@example.com"
+		require.NoError(t, repo.Create(user))
+		require.NoError(t, repo.Delete(user.ID))
+
+		// When
+		user.Name = "Revived"
+		err := repo.Update(user)
+
+		// Then
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
 }
 
 func TestUserRepository_Delete(t *testing.T) {
 	// Setup
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 	logger := testutil.NewTestLogger(t)
 	repo := NewUserRepository(db, logger)
 
@@ -263,15 +327,13 @@ func TestUserRepository_Delete(t *testing.T) {
 		assert.Error(t, err)
 		assert.Equal(t, gorm.ErrRecordNotFound, err)
 	})
-
-	// Cleanup
-	testutil.CleanDB(db)
 }
 
 func TestUserRepository_EmailExists(t *testing.T) {
 	// Setup
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 	logger := testutil.NewTestLogger(t)
 	repo := NewUserRepository(db, logger)
 
@@ -298,7 +360,4 @@ func TestUserRepository_EmailExists(t *testing.T) {
 		assert.NoError(t, err)
 		assert.False(t, exists)
 	})
-
-	// Cleanup
-	testutil.CleanDB(db)
 }
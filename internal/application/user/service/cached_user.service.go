@@ -0,0 +1,165 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
+
+	"go.uber.org/zap"
+)
+
+// emailCacheEntry is a cached GetUserByEmail result; a nil user records a miss
+type emailCacheEntry struct {
+	user      *dto.UserResponse
+	expiresAt time.Time
+}
+
+// cachedUserService caches GetUserByEmail lookups in memory in front of another
+// UserService. Misses are cached for a shorter TTL to blunt repeated lookups of
+// unknown addresses. Entries are dropped whenever a user is created, changed or deleted.
+// Turning off the user_cache flag bypasses the cache without a restart.
+type cachedUserService struct {
+	UserService
+	ttl         time.Duration
+	negativeTTL time.Duration
+	flags       *featureflag.Flags
+	logger      *zap.Logger
+	now         func() time.Time
+
+	mu      sync.Mutex
+	byEmail map[string]emailCacheEntry
+}
+
+// NewCachedUserService wraps next with the email lookup cache. It returns next
+// unchanged when caching is disabled with a zero TTL.
+func NewCachedUserService(
+	next UserService,
+	cfg *config.Config,
+	flags *featureflag.Flags,
+	logger *zap.Logger,
+) UserService {
+	if cfg.Cache.UserTTL <= 0 {
+		return next
+	}
+
+	return &cachedUserService{
+		UserService: next,
+		ttl:         cfg.Cache.UserTTL,
+		negativeTTL: cfg.Cache.UserNegativeTTL,
+		flags:       flags,
+		logger:      logger,
+		now:         time.Now,
+		byEmail:     make(map[string]emailCacheEntry),
+	}
+}
+
+func (s *cachedUserService) GetUserByEmail(email string) (*dto.UserResponse, error) {
+	if !s.flags.IsEnabled(featureflag.UserCache) {
+		return s.UserService.GetUserByEmail(email)
+	}
+
+	if entry, ok := s.lookup(email); ok {
+		if entry.user == nil {
+			return nil, apperror.New(apperror.CodeUserNotFound, "user not found")
+		}
+		user := *entry.user
+		return &user, nil
+	}
+
+	user, err := s.UserService.GetUserByEmail(email)
+	if err != nil {
+		if apperror.Code(err) == apperror.CodeUserNotFound && s.negativeTTL > 0 {
+			s.store(email, nil, s.negativeTTL)
+		}
+		return nil, err
+	}
+
+	cached := *user
+	s.store(email, &cached, s.ttl)
+	return user, nil
+}
+
+func (s *cachedUserService) CreateUser(req *dto.CreateUserRequest) (*dto.UserResponse, error) {
+	user, err := s.UserService.CreateUser(req)
+	s.invalidateEmail(req.Email)
+	return user, err
+}
+
+func (s *cachedUserService) CreateUserOrGetExisting(req *dto.CreateUserRequest) (*dto.UserResponse, bool, error) {
+	user, created, err := s.UserService.CreateUserOrGetExisting(req)
+	s.invalidateEmail(req.Email)
+	return user, created, err
+}
+
+func (s *cachedUserService) UpdateUser(id uint, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
+	user, err := s.UserService.UpdateUser(id, req)
+	s.invalidateUser(id)
+	s.invalidateEmail(req.Email)
+	return user, err
+}
+
+func (s *cachedUserService) UpdateUserPassword(id uint, req *dto.UpdateUserPasswordRequest) error {
+	err := s.UserService.UpdateUserPassword(id, req)
+	s.invalidateUser(id)
+	return err
+}
+
+func (s *cachedUserService) DeleteUser(id uint) error {
+	err := s.UserService.DeleteUser(id)
+	s.invalidateUser(id)
+	return err
+}
+
+func (s *cachedUserService) RestoreUser(id uint) (*dto.UserResponse, error) {
+	user, err := s.UserService.RestoreUser(id)
+	if err == nil {
+		// The email may be cached as a miss from while the user was deleted
+		s.invalidateEmail(user.Email)
+	}
+	return user, err
+}
+
+func (s *cachedUserService) lookup(email string) (emailCacheEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.byEmail[email]
+	if !ok {
+		return emailCacheEntry{}, false
+	}
+	if !s.now().Before(entry.expiresAt) {
+		delete(s.byEmail, email)
+		return emailCacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (s *cachedUserService) store(email string, user *dto.UserResponse, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.byEmail[email] = emailCacheEntry{user: user, expiresAt: s.now().Add(ttl)}
+}
+
+func (s *cachedUserService) invalidateEmail(email string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.byEmail, email)
+}
+
+// invalidateUser drops the cached entry of user id under whichever email it was cached
+func (s *cachedUserService) invalidateUser(id uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for email, entry := range s.byEmail {
+		if entry.user != nil && entry.user.ID == id {
+			delete(s.byEmail, email)
+		}
+	}
+}
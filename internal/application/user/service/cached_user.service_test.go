@@ -0,0 +1,228 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCachedUserService() (*cachedUserService, *testutil.MockUserService, *time.Time) {
+	cfg := testutil.NewTestConfig()
+	cfg.Cache.UserTTL = time.Minute
+	cfg.Cache.UserNegativeTTL = 10 * time.Second
+
+	mockService := &testutil.MockUserService{}
+	service := NewCachedUserService(mockService, cfg, featureflag.New(cfg), testutil.NewSilentLogger()).(*cachedUserService)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	return service, mockService, &now
+}
+
+func TestCachedUserService_GetUserByEmail(t *testing.T) {
+	t.Run("should serve repeated lookups from cache", func(t *testing.T) {
+		// Setup
+		service, mockService, _ := setupCachedUserService()
+		user := &dto.UserResponse{ID: 1, Name: "John Doe", Email: "john@example.com"}
+
+		// Mock expectations
+		mockService.On("GetUserByEmail", "john@example.com").Return(user, nil).Once()
+
+		// When
+		first, err := service.GetUserByEmail("john@example.com")
+		require.NoError(t, err)
+		second, err := service.GetUserByEmail("john@example.com")
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, first, second)
+		mockService.AssertNumberOfCalls(t, "GetUserByEmail", 1)
+
+		// Cached responses are copies and never carry a password hash
+		second.Name = "Changed"
+		third, _ := service.GetUserByEmail("john@example.com")
+		assert.Equal(t, "John Doe", third.Name)
+		body, _ := json.Marshal(third)
+		assert.NotContains(t, string(body), "password")
+	})
+
+	t.Run("should refetch after the TTL", func(t *testing.T) {
+		// Setup
+		service, mockService, now := setupCachedUserService()
+		user := &dto.UserResponse{ID: 1, Email: "john@example.com"}
+
+		// Mock expectations
+		mockService.On("GetUserByEmail", "john@example.com").Return(user, nil)
+
+		// When
+		service.GetUserByEmail("john@example.com")
+		*now = now.Add(time.Minute)
+		service.GetUserByEmail("john@example.com")
+
+		// Then
+		mockService.AssertNumberOfCalls(t, "GetUserByEmail", 2)
+	})
+
+	t.Run("should cache not found until the negative TTL expires", func(t *testing.T) {
+		// Setup
+		service, mockService, now := setupCachedUserService()
+
+		// Mock expectations
+		mockService.On("GetUserByEmail", "ghost@example.com").Return(nil, apperror.New(apperror.CodeUserNotFound, "user not found"))
+
+		// When
+		_, err := service.GetUserByEmail("ghost@example.com")
+		assert.EqualError(t, err, "user not found")
+		*now = now.Add(5 * time.Second)
+		_, err = service.GetUserByEmail("ghost@example.com")
+
+		// Then
+		assert.EqualError(t, err, "user not found")
+		mockService.AssertNumberOfCalls(t, "GetUserByEmail", 1)
+
+		*now = now.Add(5 * time.Second)
+		service.GetUserByEmail("ghost@example.com")
+		mockService.AssertNumberOfCalls(t, "GetUserByEmail", 2)
+	})
+
+	t.Run("should not cache other errors", func(t *testing.T) {
+		// Setup
+		service, mockService, _ := setupCachedUserService()
+
+		// Mock expectations
+		mockService.On("GetUserByEmail", "john@example.com").Return(nil, errors.New("database error"))
+
+		// When
+		service.GetUserByEmail("john@example.com")
+		service.GetUserByEmail("john@example.com")
+
+		// Then
+		mockService.AssertNumberOfCalls(t, "GetUserByEmail", 2)
+	})
+}
+
+func TestCachedUserService_Invalidation(t *testing.T) {
+	t.Run("should drop cached user on update", func(t *testing.T) {
+		// Setup
+		service, mockService, _ := setupCachedUserService()
+		user := &dto.UserResponse{ID: 1, Name: "John Doe", Email: "john@example.com"}
+		updated := &dto.UserResponse{ID: 1, Name: "John Updated", Email: "john@example.com"}
+		req := &dto.UpdateUserRequest{Name: "John Updated", Email: "john@example.com"}
+
+		// Mock expectations
+		mockService.On("GetUserByEmail", "john@example.com").Return(user, nil).Once()
+		mockService.On("UpdateUser", uint(1), req).Return(updated, nil)
+		mockService.On("GetUserByEmail", "john@example.com").Return(updated, nil).Once()
+
+		// When
+		service.GetUserByEmail("john@example.com")
+		_, err := service.UpdateUser(1, req)
+		require.NoError(t, err)
+		result, err := service.GetUserByEmail("john@example.com")
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, "John Updated", result.Name)
+		mockService.AssertNumberOfCalls(t, "GetUserByEmail", 2)
+	})
+
+	t.Run("should drop cached miss when the email is registered", func(t *testing.T) {
+		// Setup
+		service, mockService, _ := setupCachedUserService()
+		req := testutil.CreateUserRequestFixture()
+		created := &dto.UserResponse{ID: 1, Email: req.Email}
+
+		// Mock expectations
+		mockService.On("GetUserByEmail", req.Email).Return(nil, apperror.New(apperror.CodeUserNotFound, "user not found")).Once()
+		mockService.On("CreateUser", req).Return(created, nil)
+		mockService.On("GetUserByEmail", req.Email).Return(created, nil).Once()
+
+		// When
+		service.GetUserByEmail(req.Email)
+		service.CreateUser(req)
+		result, err := service.GetUserByEmail(req.Email)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), result.ID)
+	})
+
+	t.Run("should drop cached miss when an idempotent create registers the email", func(t *testing.T) {
+		// Setup
+		service, mockService, _ := setupCachedUserService()
+		req := testutil.CreateUserRequestFixture()
+		created := &dto.UserResponse{ID: 1, Email: req.Email}
+
+		// Mock expectations
+		mockService.On("GetUserByEmail", req.Email).Return(nil, apperror.New(apperror.CodeUserNotFound, "user not found")).Once()
+		mockService.On("CreateUserOrGetExisting", req).Return(created, true, nil)
+		mockService.On("GetUserByEmail", req.Email).Return(created, nil).Once()
+
+		// When
+		service.GetUserByEmail(req.Email)
+		service.CreateUserOrGetExisting(req)
+		result, err := service.GetUserByEmail(req.Email)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), result.ID)
+	})
+
+	t.Run("should drop cached user on delete", func(t *testing.T) {
+		// Setup
+		service, mockService, _ := setupCachedUserService()
+		user := &dto.UserResponse{ID: 1, Email: "john@example.com"}
+
+		// Mock expectations
+		mockService.On("GetUserByEmail", "john@example.com").Return(user, nil).Once()
+		mockService.On("DeleteUser", uint(1)).Return(nil)
+		mockService.On("GetUserByEmail", "john@example.com").Return(nil, apperror.New(apperror.CodeUserNotFound, "user not found")).Once()
+
+		// When
+		service.GetUserByEmail("john@example.com")
+		service.DeleteUser(1)
+		_, err := service.GetUserByEmail("john@example.com")
+
+		// Then
+		assert.EqualError(t, err, "user not found")
+	})
+}
+
+func TestNewCachedUserService_Disabled(t *testing.T) {
+	mockService := &testutil.MockUserService{}
+	cfg := testutil.NewTestConfig()
+
+	service := NewCachedUserService(mockService, cfg, featureflag.New(cfg), testutil.NewSilentLogger())
+
+	assert.Same(t, mockService, service)
+}
+
+func TestCachedUserService_FlagOverride(t *testing.T) {
+	// Setup
+	service, mockService, _ := setupCachedUserService()
+	user := &dto.UserResponse{ID: 1, Name: "John Doe", Email: "john@example.com"}
+
+	// Given: the cache is switched off at runtime
+	service.flags.Set(featureflag.UserCache, false)
+	mockService.On("GetUserByEmail", "john@example.com").Return(user, nil).Twice()
+
+	// When
+	_, err := service.GetUserByEmail("john@example.com")
+	require.NoError(t, err)
+	_, err = service.GetUserByEmail("john@example.com")
+	require.NoError(t, err)
+
+	// Then: both lookups reached the wrapped service and nothing was cached
+	mockService.AssertExpectations(t)
+	_, cached := service.lookup("john@example.com")
+	assert.False(t, cached)
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_NameNormalization(t *testing.T) {
+	t.Run("should store a created user's name trimmed", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), testutil.NewSilentLogger())
+
+		// Given
+		req := testutil.CreateUserRequestFixture()
+		req.Name = "  John  "
+		mockRepo.On("EmailExists", req.Email).Return(false, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(user *entity.User) bool {
+			return user.Name == "John"
+		})).Return(nil)
+
+		// When
+		response, err := service.CreateUser(req)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "John", response.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should store an updated user's name trimmed", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), testutil.NewSilentLogger())
+
+		// Given
+		user := testutil.CreateUserFixture()
+		mockRepo.On("GetByID", user.ID).Return(user, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(user *entity.User) bool {
+			return user.Name == "Jane"
+		})).Return(nil)
+
+		// When
+		response, err := service.UpdateUser(user.ID, &dto.UpdateUserRequest{Name: "\tJane ", Email: user.Email})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "Jane", response.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name    string
+		input   string
+		message string
+	}{
+		{name: "whitespace only", input: " \t\n ", message: "name is required"},
+		{name: "over 100 characters", input: strings.Repeat("a", 101), message: "name is too long"},
+	}
+
+	for _, tt := range tests {
+		t.Run("should reject a name that is "+tt.name, func(t *testing.T) {
+			// Setup
+			mockRepo := &testutil.MockUserRepository{}
+			service := NewUserService(mockRepo, testutil.NewTestConfig(), testutil.NewSilentLogger())
+
+			// Given
+			createReq := testutil.CreateUserRequestFixture()
+			createReq.Name = tt.input
+
+			// When
+			created, createErr := service.CreateUser(createReq)
+			updated, updateErr := service.UpdateUser(1, &dto.UpdateUserRequest{Name: tt.input, Email: "john@example.com"})
+
+			// Then
+			assert.Nil(t, created)
+			assert.Nil(t, updated)
+			assert.EqualError(t, createErr, tt.message)
+			assert.EqualError(t, updateErr, tt.message)
+			assert.Equal(t, apperror.CodeInvalidName, apperror.CodeOf(0, createErr))
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+			mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		})
+	}
+
+}
+
+func TestUserService_BlockedEmailDomains(t *testing.T) {
+	cfg := testutil.NewTestConfig()
+	cfg.User.BlockedEmailDomains = []string{"mailinator.com", "*.tempmail.io"}
+
+	t.Run("should reject a registration from a blocked domain", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		service := NewUserService(mockRepo, cfg, testutil.NewSilentLogger())
+
+		for _, email := range []string{"john@mailinator.com", "john@MAILINATOR.com", "john@inbox.tempmail.io"} {
+			// Given
+			req := testutil.CreateUserRequestFixture()
+			req.Email = email
+
+			// When
+			response, err := service.CreateUser(req)
+
+			// Then
+			assert.Nil(t, response, email)
+			assert.EqualError(t, err, "email domain is not allowed", email)
+			assert.Equal(t, apperror.CodeEmailDomainBlocked, apperror.CodeOf(0, err))
+		}
+		mockRepo.AssertNotCalled(t, "EmailExists", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("should register a user from an allowed domain", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		service := NewUserService(mockRepo, cfg, testutil.NewSilentLogger())
+
+		// Given
+		req := testutil.CreateUserRequestFixture()
+		req.Email = "john@example.com"
+		mockRepo.On("EmailExists", req.Email).Return(false, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*entity.User")).Return(nil)
+
+		// When
+		response, err := service.CreateUser(req)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "john@example.com", response.Email)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject changing the email to a blocked domain", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		service := NewUserService(mockRepo, cfg, testutil.NewSilentLogger())
+
+		// Given
+		user := testutil.CreateUserFixture()
+		mockRepo.On("GetByID", user.ID).Return(user, nil)
+
+		// When
+		response, err := service.UpdateUser(user.ID, &dto.UpdateUserRequest{Name: user.Name, Email: "john@mailinator.com"})
+
+		// Then
+		assert.Nil(t, response)
+		assert.EqualError(t, err, "email domain is not allowed")
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
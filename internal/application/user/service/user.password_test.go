@@ -0,0 +1,123 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCheckPassword(t *testing.T) {
+	policy := config.PasswordPolicy{
+		MinLength:     10,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		message  string
+	}{
+		{name: "too short", password: "Ab1!efgh", message: "password is too short"},
+		{name: "no uppercase", password: "abcdef12!x", message: "password must contain an uppercase letter"},
+		{name: "no lowercase", password: "ABCDEF12!X", message: "password must contain a lowercase letter"},
+		{name: "no digit", password: "Abcdefgh!x", message: "password must contain a digit"},
+		{name: "no symbol", password: "Abcdefgh12", message: "password must contain a symbol"},
+		{name: "compliant", password: "Abcdef12!x"},
+		{name: "compliant with multibyte characters", password: "Ábcdéf12€x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			err := checkPassword(policy, tt.password)
+
+			// Then
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.message)
+			assert.Equal(t, apperror.CodeWeakPassword, apperror.CodeOf(0, err))
+		})
+	}
+
+	t.Run("should accept anything with an empty policy", func(t *testing.T) {
+		assert.NoError(t, checkPassword(config.PasswordPolicy{}, "password123"))
+	})
+}
+
+func TestUserService_PasswordPolicy(t *testing.T) {
+	cfg := testutil.NewTestConfig()
+	cfg.User.Password = config.PasswordPolicy{MinLength: 8, RequireUpper: true, RequireDigit: true}
+
+	t.Run("should reject a registration with a weak password", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		service := NewUserService(mockRepo, cfg, testutil.NewSilentLogger())
+
+		// Given
+		req := testutil.CreateUserRequestFixture()
+		req.Password = "password123"
+
+		// When
+		response, err := service.CreateUser(req)
+
+		// Then
+		assert.Nil(t, response)
+		assert.EqualError(t, err, "password must contain an uppercase letter")
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("should register a user with a compliant password", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		service := NewUserService(mockRepo, cfg, testutil.NewSilentLogger())
+
+		// Given
+		req := testutil.CreateUserRequestFixture()
+		req.Password = "Password123"
+		mockRepo.On("EmailExists", req.Email).Return(false, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*entity.User")).Return(nil)
+
+		// When
+		_, err := service.CreateUser(req)
+
+		// Then
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject changing to a weak password", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		service := NewUserService(mockRepo, cfg, testutil.NewSilentLogger())
+
+		// Given
+		user := testutil.CreateUserFixture()
+		hashed, err := bcrypt.GenerateFromPassword([]byte("Password123"), bcrypt.MinCost)
+		require.NoError(t, err)
+		user.Password = string(hashed)
+		mockRepo.On("GetByID", user.ID).Return(user, nil)
+
+		// When
+		err = service.UpdateUserPassword(user.ID, &dto.UpdateUserPasswordRequest{
+			CurrentPassword: "Password123",
+			NewPassword:     "Passwordabc",
+		})
+
+		// Then
+		assert.EqualError(t, err, "password must contain a digit")
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/repository"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_RestoreUser(t *testing.T) {
+	// Setup: a real repository so soft delete and restore hit the deleted_at column
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewSilentLogger()
+	service := NewUserService(repository.NewUserRepository(db, logger), testutil.NewTestConfig(), logger)
+
+	list := func(includeDeleted bool) []dto.UserResponse {
+		users, err := service.GetUsers(&dto.UserFilter{
+			IncludeDeleted: includeDeleted,
+			Pagination:     pagination.Pagination{Page: 1, PageSize: 10},
+		})
+		require.NoError(t, err)
+		return users.Data
+	}
+
+	t.Run("should restore a deleted user", func(t *testing.T) {
+		// Given
+		created, err := service.CreateUser(testutil.CreateUserRequestFixture())
+		require.NoError(t, err)
+		require.NoError(t, service.DeleteUser(created.ID))
+
+		// Then: the user is only listed with include_deleted, marked as deleted
+		assert.Empty(t, list(false))
+		withDeleted := list(true)
+		require.Len(t, withDeleted, 1)
+		assert.Equal(t, created.ID, withDeleted[0].ID)
+		assert.NotNil(t, withDeleted[0].DeletedAt)
+		_, err = service.GetUserByID(created.ID)
+		assert.EqualError(t, err, "user not found")
+
+		// When
+		restored, err := service.RestoreUser(created.ID)
+
+		// Then: the user is live again
+		require.NoError(t, err)
+		assert.Equal(t, created.Email, restored.Email)
+		assert.Nil(t, restored.DeletedAt)
+		live, err := service.GetUserByID(created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, live.ID)
+		assert.Len(t, list(false), 1)
+	})
+
+	t.Run("should refuse to restore a live user", func(t *testing.T) {
+		// Given
+		req := testutil.CreateUserRequestFixture()
+		req.Email = "live@example.com"
+		created, err := service.CreateUser(req)
+		require.NoError(t, err)
+
+		// When
+		_, err = service.RestoreUser(created.ID)
+
+		// Then
+		assert.EqualError(t, err, "user is not deleted")
+	})
+
+	t.Run("should report an unknown user", func(t *testing.T) {
+		// When
+		_, err := service.RestoreUser(9999)
+
+		// Then
+		assert.EqualError(t, err, "user not found")
+	})
+}
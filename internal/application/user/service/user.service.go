@@ -2,47 +2,71 @@ package service
 
 import (
 	"errors"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/repository"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
 
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+// maxNameLength matches the max=100 binding on user names
+const maxNameLength = 100
+
 type UserService interface {
 	CreateUser(req *dto.CreateUserRequest) (*dto.UserResponse, error)
+	CreateUserOrGetExisting(req *dto.CreateUserRequest) (*dto.UserResponse, bool, error)
 	GetUserByID(id uint) (*dto.UserResponse, error)
 	GetUserByEmail(email string) (*dto.UserResponse, error)
+	GetUsersByIDs(ids []uint) (map[uint]*dto.UserResponse, error)
 	GetUsers(filter *dto.UserFilter) (*dto.UserListResponse, error)
 	UpdateUser(id uint, req *dto.UpdateUserRequest) (*dto.UserResponse, error)
 	UpdateUserPassword(id uint, req *dto.UpdateUserPasswordRequest) error
 	DeleteUser(id uint) error
+	RestoreUser(id uint) (*dto.UserResponse, error)
 }
 
 type userService struct {
 	repo   repository.UserRepository
+	cfg    *config.Config
 	logger *zap.Logger
 }
 
-func NewUserService(repo repository.UserRepository, logger *zap.Logger) UserService {
+func NewUserService(repo repository.UserRepository, cfg *config.Config, logger *zap.Logger) UserService {
 	return &userService{
 		repo:   repo,
+		cfg:    cfg,
 		logger: logger,
 	}
 }
 
 func (s *userService) CreateUser(req *dto.CreateUserRequest) (*dto.UserResponse, error) {
+	name, err := normalizeName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.User.EmailDomainBlocked(req.Email) {
+		return nil, errEmailDomainBlocked
+	}
+	if err := checkPassword(s.cfg.User.Password, req.Password); err != nil {
+		return nil, err
+	}
+
 	exists, err := s.repo.EmailExists(req.Email)
 	if err != nil {
 		s.logger.Error("Failed to check email existence", zap.Error(err))
 		return nil, err
 	}
 	if exists {
-		return nil, errors.New("email already exists")
+		return nil, apperror.New(apperror.CodeEmailExists, "email already exists")
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -52,11 +76,11 @@ func (s *userService) CreateUser(req *dto.CreateUserRequest) (*dto.UserResponse,
 	}
 
 	user := &entity.User{
-		Name:      req.Name,
+		Name:      name,
 		Email:     req.Email,
 		Password:  string(hashedPassword),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
 	}
 
 	err = s.repo.Create(user)
@@ -68,11 +92,38 @@ func (s *userService) CreateUser(req *dto.CreateUserRequest) (*dto.UserResponse,
 	return s.entityToResponse(user), nil
 }
 
+// CreateUserOrGetExisting makes retried creates safe: when req's email is taken by a
+// user whose password matches req's, that user is returned instead of a conflict. The
+// bool reports whether a new user was created.
+func (s *userService) CreateUserOrGetExisting(req *dto.CreateUserRequest) (*dto.UserResponse, bool, error) {
+	user, err := s.CreateUser(req)
+	if err == nil {
+		return user, true, nil
+	}
+	if err.Error() != "email already exists" {
+		return nil, false, err
+	}
+
+	existing, err := s.repo.GetByEmail(req.Email)
+	if err != nil {
+		s.logger.Error("Failed to load existing user for idempotent create", zap.Error(err))
+		return nil, false, err
+	}
+
+	// Only a retry of the same request may see the existing user, otherwise the
+	// endpoint would hand out any account by email
+	if bcrypt.CompareHashAndPassword([]byte(existing.Password), []byte(req.Password)) != nil {
+		return nil, false, apperror.New(apperror.CodeEmailExists, "email already exists")
+	}
+
+	return s.entityToResponse(existing), false, nil
+}
+
 func (s *userService) GetUserByID(id uint) (*dto.UserResponse, error) {
 	user, err := s.repo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, apperror.New(apperror.CodeUserNotFound, "user not found")
 		}
 		return nil, err
 	}
@@ -84,7 +135,7 @@ func (s *userService) GetUserByEmail(email string) (*dto.UserResponse, error) {
 	user, err := s.repo.GetByEmail(email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, apperror.New(apperror.CodeUserNotFound, "user not found")
 		}
 		return nil, err
 	}
@@ -92,14 +143,25 @@ func (s *userService) GetUserByEmail(email string) (*dto.UserResponse, error) {
 	return s.entityToResponse(user), nil
 }
 
-func (s *userService) GetUsers(filter *dto.UserFilter) (*dto.UserListResponse, error) {
-	if filter.Page <= 0 {
-		filter.Page = 1
+// GetUsersByIDs resolves many users with a single query. Unknown IDs are absent from
+// the returned map rather than reported as errors.
+func (s *userService) GetUsersByIDs(ids []uint) (map[uint]*dto.UserResponse, error) {
+	users, err := s.repo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
 	}
-	if filter.PageSize <= 0 {
-		filter.PageSize = 10
+
+	result := make(map[uint]*dto.UserResponse, len(users))
+	for i := range users {
+		result[users[i].ID] = s.entityToResponse(&users[i])
 	}
 
+	return result, nil
+}
+
+func (s *userService) GetUsers(filter *dto.UserFilter) (*dto.UserListResponse, error) {
+	filter.Normalize(s.cfg.Pagination)
+
 	users, totalCount, err := s.repo.GetAll(filter)
 	if err != nil {
 		return nil, err
@@ -119,28 +181,36 @@ func (s *userService) GetUsers(filter *dto.UserFilter) (*dto.UserListResponse, e
 }
 
 func (s *userService) UpdateUser(id uint, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
+	name, err := normalizeName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := s.repo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, apperror.New(apperror.CodeUserNotFound, "user not found")
 		}
 		return nil, err
 	}
 
 	if req.Email != user.Email {
+		if s.cfg.User.EmailDomainBlocked(req.Email) {
+			return nil, errEmailDomainBlocked
+		}
 		exists, err := s.repo.EmailExists(req.Email)
 		if err != nil {
 			s.logger.Error("Failed to check email existence", zap.Error(err))
 			return nil, err
 		}
 		if exists {
-			return nil, errors.New("email already exists")
+			return nil, apperror.New(apperror.CodeEmailExists, "email already exists")
 		}
 	}
 
-	user.Name = req.Name
+	user.Name = name
 	user.Email = req.Email
-	user.UpdatedAt = time.Now()
+	user.UpdatedAt = time.Now().UTC()
 
 	err = s.repo.Update(user)
 	if err != nil {
@@ -155,14 +225,17 @@ func (s *userService) UpdateUserPassword(id uint, req *dto.UpdateUserPasswordReq
 	user, err := s.repo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("user not found")
+			return apperror.New(apperror.CodeUserNotFound, "user not found")
 		}
 		return err
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword))
 	if err != nil {
-		return errors.New("current password is incorrect")
+		return apperror.New(apperror.CodeCurrentPasswordIncorrect, "current password is incorrect")
+	}
+	if err := checkPassword(s.cfg.User.Password, req.NewPassword); err != nil {
+		return err
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
@@ -172,7 +245,7 @@ func (s *userService) UpdateUserPassword(id uint, req *dto.UpdateUserPasswordReq
 	}
 
 	user.Password = string(hashedPassword)
-	user.UpdatedAt = time.Now()
+	user.UpdatedAt = time.Now().UTC()
 
 	return s.repo.Update(user)
 }
@@ -181,7 +254,7 @@ func (s *userService) DeleteUser(id uint) error {
 	_, err := s.repo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("user not found")
+			return apperror.New(apperror.CodeUserNotFound, "user not found")
 		}
 		return err
 	}
@@ -189,12 +262,93 @@ func (s *userService) DeleteUser(id uint) error {
 	return s.repo.Delete(id)
 }
 
+// RestoreUser undoes DeleteUser. Restoring a user that is not deleted is an error.
+func (s *userService) RestoreUser(id uint) (*dto.UserResponse, error) {
+	user, err := s.repo.GetByIDWithDeleted(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.CodeUserNotFound, "user not found")
+		}
+		return nil, err
+	}
+	if !user.DeletedAt.Valid {
+		return nil, apperror.New(apperror.CodeUserNotDeleted, "user is not deleted")
+	}
+
+	if err := s.repo.Restore(id); err != nil {
+		// Restored by a concurrent request between the read and the update
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.CodeUserNotDeleted, "user is not deleted")
+		}
+		s.logger.Error("Failed to restore user", zap.Error(err))
+		return nil, err
+	}
+
+	user.DeletedAt = gorm.DeletedAt{}
+	return s.entityToResponse(user), nil
+}
+
 func (s *userService) entityToResponse(user *entity.User) *dto.UserResponse {
-	return &dto.UserResponse{
+	response := &dto.UserResponse{
 		ID:        user.ID,
 		Name:      user.Name,
 		Email:     user.Email,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
+	if user.DeletedAt.Valid {
+		deletedAt := user.DeletedAt.Time
+		response.DeletedAt = &deletedAt
+	}
+	return response
+}
+
+// errEmailDomainBlocked rejects an email whose domain is in user.blocked_email_domains
+var errEmailDomainBlocked = apperror.New(apperror.CodeEmailDomainBlocked, "email domain is not allowed")
+
+// checkPassword returns a WEAK_PASSWORD error naming the first rule of policy that
+// password breaks
+func checkPassword(policy config.PasswordPolicy, password string) error {
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		return apperror.New(apperror.CodeWeakPassword, "password is too short")
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	switch {
+	case policy.RequireUpper && !upper:
+		return apperror.New(apperror.CodeWeakPassword, "password must contain an uppercase letter")
+	case policy.RequireLower && !lower:
+		return apperror.New(apperror.CodeWeakPassword, "password must contain a lowercase letter")
+	case policy.RequireDigit && !digit:
+		return apperror.New(apperror.CodeWeakPassword, "password must contain a digit")
+	case policy.RequireSymbol && !symbol:
+		return apperror.New(apperror.CodeWeakPassword, "password must contain a symbol")
+	}
+	return nil
+}
+
+// normalizeName trims a user name, rejecting one that is blank or longer than
+// maxNameLength characters once trimmed
+func normalizeName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", apperror.New(apperror.CodeInvalidName, "name is required")
+	}
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return "", apperror.New(apperror.CodeInvalidName, "name is too long")
+	}
+	return name, nil
 }
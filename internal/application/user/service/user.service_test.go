@@ -7,6 +7,7 @@ import (
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
 
 	"github.com/stretchr/testify/assert"
@@ -20,7 +21,7 @@ func TestUserService_CreateUser(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		req := testutil.CreateUserRequestFixture()
 
@@ -47,7 +48,7 @@ func TestUserService_CreateUser(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		req := testutil.CreateUserRequestFixture()
 
@@ -68,7 +69,7 @@ func TestUserService_CreateUser(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		req := testutil.CreateUserRequestFixture()
 
@@ -89,7 +90,7 @@ func TestUserService_CreateUser(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		req := testutil.CreateUserRequestFixture()
 
@@ -108,12 +109,92 @@ func TestUserService_CreateUser(t *testing.T) {
 	})
 }
 
+func TestUserService_CreateUserOrGetExisting(t *testing.T) {
+	t.Run("should create the user when the email is free", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		logger := testutil.NewSilentLogger()
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
+
+		req := testutil.CreateUserRequestFixture()
+
+		// Mock expectations
+		mockRepo.On("EmailExists", req.Email).Return(false, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*entity.User")).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(0).(*entity.User).ID = 1
+		})
+
+		// When
+		response, created, err := service.CreateUserOrGetExisting(req)
+
+		// Then
+		assert.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, uint(1), response.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return the existing user for a retried request", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		logger := testutil.NewSilentLogger()
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
+
+		req := testutil.CreateUserRequestFixture()
+		hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.MinCost)
+		assert.NoError(t, err)
+		existing := testutil.CreateUserFixture()
+		existing.Email = req.Email
+		existing.Password = string(hashed)
+
+		// Mock expectations
+		mockRepo.On("EmailExists", req.Email).Return(true, nil)
+		mockRepo.On("GetByEmail", req.Email).Return(existing, nil)
+
+		// When
+		response, created, err := service.CreateUserOrGetExisting(req)
+
+		// Then
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, existing.ID, response.ID)
+		assert.Equal(t, existing.Email, response.Email)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("should keep the conflict when the password differs", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		logger := testutil.NewSilentLogger()
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
+
+		req := testutil.CreateUserRequestFixture()
+		hashed, err := bcrypt.GenerateFromPassword([]byte("someone-else"), bcrypt.MinCost)
+		assert.NoError(t, err)
+		existing := testutil.CreateUserFixture()
+		existing.Email = req.Email
+		existing.Password = string(hashed)
+
+		// Mock expectations
+		mockRepo.On("EmailExists", req.Email).Return(true, nil)
+		mockRepo.On("GetByEmail", req.Email).Return(existing, nil)
+
+		// When
+		response, created, err := service.CreateUserOrGetExisting(req)
+
+		// Then
+		assert.Nil(t, response)
+		assert.False(t, created)
+		assert.EqualError(t, err, "email already exists")
+	})
+}
+
 func TestUserService_GetUserByID(t *testing.T) {
 	t.Run("should get user by ID successfully", func(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		userID := uint(1)
 		user := testutil.CreateUserFixture()
@@ -138,7 +219,7 @@ func TestUserService_GetUserByID(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		userID := uint(999)
 
@@ -159,7 +240,7 @@ func TestUserService_GetUserByID(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		userID := uint(1)
 
@@ -182,7 +263,7 @@ func TestUserService_GetUserByEmail(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		email := "test@example.com"
 		user := testutil.CreateUserFixture()
@@ -206,7 +287,7 @@ func TestUserService_GetUserByEmail(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		email := "nonexistent@example.com"
 
@@ -224,16 +305,60 @@ func TestUserService_GetUserByEmail(t *testing.T) {
 	})
 }
 
+func TestUserService_GetUsersByIDs(t *testing.T) {
+	t.Run("should map existing users by ID and omit unknown IDs", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		logger := testutil.NewSilentLogger()
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
+
+		users := []entity.User{
+			{ID: 1, Name: "John Doe", Email: "john@example.com", Password: "hash"},
+			{ID: 3, Name: "Jane Doe", Email: "jane@example.com", Password: "hash"},
+		}
+
+		// Mock expectations
+		mockRepo.On("GetByIDs", []uint{1, 2, 3}).Return(users, nil)
+
+		// When
+		result, err := service.GetUsersByIDs([]uint{1, 2, 3})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "John Doe", result[1].Name)
+		assert.Equal(t, "jane@example.com", result[3].Email)
+		assert.NotContains(t, result, uint(2))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return error when repository fails", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		logger := testutil.NewSilentLogger()
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
+
+		// Mock expectations
+		mockRepo.On("GetByIDs", []uint{1}).Return(nil, errors.New("database error"))
+
+		// When
+		result, err := service.GetUsersByIDs([]uint{1})
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
 func TestUserService_GetUsers(t *testing.T) {
 	t.Run("should get users with pagination successfully", func(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		filter := &dto.UserFilter{
-			Page:     1,
-			PageSize: 10,
+			Pagination: pagination.Pagination{Page: 1, PageSize: 10},
 		}
 
 		users := []entity.User{
@@ -264,16 +389,14 @@ func TestUserService_GetUsers(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		filter := &dto.UserFilter{
-			Page:     0,
-			PageSize: 0,
+			Pagination: pagination.Pagination{Page: 0, PageSize: 0},
 		}
 
 		expectedFilter := &dto.UserFilter{
-			Page:     1,
-			PageSize: 10,
+			Pagination: pagination.Pagination{Page: 1, PageSize: 10},
 		}
 
 		// Mock expectations
@@ -290,15 +413,39 @@ func TestUserService_GetUsers(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("should apply configured default and max page size", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockUserRepository{}
+		logger := testutil.NewSilentLogger()
+		cfg := testutil.NewTestConfig()
+		cfg.Pagination.DefaultPageSize = 25
+		cfg.Pagination.MaxPageSize = 50
+		service := NewUserService(mockRepo, cfg, logger)
+
+		// Mock expectations
+		mockRepo.On("GetAll", &dto.UserFilter{Pagination: pagination.Pagination{Page: 1, PageSize: 25}}).Return([]entity.User{}, int64(0), nil)
+		mockRepo.On("GetAll", &dto.UserFilter{Pagination: pagination.Pagination{Page: 1, PageSize: 50}}).Return([]entity.User{}, int64(0), nil)
+
+		// When
+		defaultResponse, err := service.GetUsers(&dto.UserFilter{})
+		assert.NoError(t, err)
+		cappedResponse, err := service.GetUsers(&dto.UserFilter{Pagination: pagination.Pagination{Page: 1, PageSize: 500}})
+		assert.NoError(t, err)
+
+		// Then
+		assert.Equal(t, 25, defaultResponse.PageSize)
+		assert.Equal(t, 50, cappedResponse.PageSize)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("should return error when repository fails", func(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		filter := &dto.UserFilter{
-			Page:     1,
-			PageSize: 10,
+			Pagination: pagination.Pagination{Page: 1, PageSize: 10},
 		}
 
 		// Mock expectations
@@ -320,7 +467,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		userID := uint(1)
 		existingUser := testutil.CreateUserFixture()
@@ -351,7 +498,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		userID := uint(999)
 		req := testutil.CreateUpdateUserRequestFixture()
@@ -373,7 +520,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		userID := uint(1)
 		existingUser := testutil.CreateUserFixture()
@@ -401,7 +548,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		userID := uint(1)
 		existingUser := testutil.CreateUserFixture()
@@ -431,7 +578,7 @@ func TestUserService_UpdateUserPassword(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		userID := uint(1)
 		currentPassword := "currentpassword"
@@ -462,7 +609,7 @@ func TestUserService_UpdateUserPassword(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		userID := uint(999)
 		req := &dto.UpdateUserPasswordRequest{
@@ -486,7 +633,7 @@ func TestUserService_UpdateUserPassword(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		userID := uint(1)
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpassword"), bcrypt.DefaultCost)
@@ -518,7 +665,7 @@ func TestUserService_DeleteUser(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		userID := uint(1)
 		user := testutil.CreateUserFixture()
@@ -540,7 +687,7 @@ func TestUserService_DeleteUser(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		userID := uint(999)
 
@@ -560,7 +707,7 @@ func TestUserService_DeleteUser(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger)
 
 		userID := uint(1)
 		user := testutil.CreateUserFixture()
@@ -585,7 +732,7 @@ func TestUserService_entityToResponse(t *testing.T) {
 		// Setup
 		mockRepo := &testutil.MockUserRepository{}
 		logger := testutil.NewSilentLogger()
-		service := NewUserService(mockRepo, logger).(*userService)
+		service := NewUserService(mockRepo, testutil.NewTestConfig(), logger).(*userService)
 
 		user := testutil.CreateUserFixture()
 		user.ID = 1
@@ -0,0 +1,92 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
+)
+
+// BalanceChangeRequest is the body of deposit and withdrawal requests
+type BalanceChangeRequest struct {
+	Amount   float64 `json:"amount" binding:"required,gt=0,finite"`
+	Currency string  `json:"currency" binding:"required,len=3"`
+}
+
+type WalletResponse struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Currency  string    `json:"currency"`
+	Balance   float64   `json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TransactionResponse struct {
+	ID           uint      `json:"id"`
+	WalletID     uint      `json:"wallet_id"`
+	Type         string    `json:"type"`
+	Amount       float64   `json:"amount"`
+	Currency     string    `json:"currency"`
+	BalanceAfter float64   `json:"balance_after"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// BalanceChangeResponse is the wallet after a deposit or withdrawal with its ledger entry
+type BalanceChangeResponse struct {
+	Wallet      WalletResponse      `json:"wallet"`
+	Transaction TransactionResponse `json:"transaction"`
+}
+
+type TransactionListResponse struct {
+	Data       []TransactionResponse `json:"data"`
+	TotalCount int64                 `json:"total_count"`
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"page_size"`
+	// NextCursor fetches the entries after this page when passed as cursor; it is left
+	// out once the ledger is exhausted. It is opaque and signed, so it can't be edited.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// InLocation formats created_at and updated_at in loc
+func (r *WalletResponse) InLocation(loc *time.Location) {
+	r.CreatedAt = r.CreatedAt.In(loc)
+	r.UpdatedAt = r.UpdatedAt.In(loc)
+}
+
+// InLocation formats every ledger entry's created_at in loc
+func (r *TransactionListResponse) InLocation(loc *time.Location) {
+	for i := range r.Data {
+		r.Data[i].CreatedAt = r.Data[i].CreatedAt.In(loc)
+	}
+}
+
+// LastModified returns the newest entry's created_at, zero for an empty page. Ledger
+// entries are never updated, so creation is their last modification.
+func (r *TransactionListResponse) LastModified() time.Time {
+	var latest time.Time
+	for _, transaction := range r.Data {
+		if transaction.CreatedAt.After(latest) {
+			latest = transaction.CreatedAt
+		}
+	}
+	return latest
+}
+
+// DefaultTransactionSort lists the ledger newest first
+const DefaultTransactionSort = "-created_at"
+
+// TransactionFilter narrows a wallet's ledger; CreatedFrom and CreatedTo are inclusive
+// RFC 3339 timestamps and are ignored when zero. Sort names a field, prefixed with "-"
+// for descending order. Cursor, the next_cursor of an earlier page, continues the
+// default-sorted listing after that page instead of using page; unlike offsets, it
+// neither skips nor repeats entries while new ones are added. The service verifies the
+// cursor's signature and sets AfterID to the entry it points at.
+type TransactionFilter struct {
+	Type        string    `form:"type" binding:"omitempty,oneof=credit debit"`
+	CreatedFrom time.Time `form:"created_from"`
+	CreatedTo   time.Time `form:"created_to"`
+	Sort        string    `form:"sort" binding:"omitempty,oneof=created_at -created_at amount -amount"`
+	Cursor      string    `form:"cursor"`
+	AfterID     uint      `form:"-"`
+	pagination.Pagination
+}
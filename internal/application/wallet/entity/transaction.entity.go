@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"time"
+)
+
+type TransactionType string
+
+const (
+	TransactionTypeDeposit    TransactionType = "deposit"
+	TransactionTypeWithdrawal TransactionType = "withdrawal"
+)
+
+func (tt TransactionType) String() string {
+	return string(tt)
+}
+
+// Transaction directions group ledger entry types by their effect on the balance
+const (
+	TransactionDirectionCredit = "credit"
+	TransactionDirectionDebit  = "debit"
+)
+
+// TransactionTypesForDirection returns the entry types that credit or debit a wallet
+func TransactionTypesForDirection(direction string) []TransactionType {
+	switch direction {
+	case TransactionDirectionCredit:
+		return []TransactionType{TransactionTypeDeposit}
+	case TransactionDirectionDebit:
+		return []TransactionType{TransactionTypeWithdrawal}
+	default:
+		return nil
+	}
+}
+
+// Transaction is an immutable ledger entry recording a single balance change of a wallet
+type Transaction struct {
+	ID           uint            `json:"id" gorm:"primaryKey"`
+	WalletID     uint            `json:"wallet_id" gorm:"not null;index"`
+	Type         TransactionType `json:"type" gorm:"size:20;not null"`
+	Amount       float64         `json:"amount" gorm:"not null"`
+	Currency     string          `json:"currency" gorm:"size:3;not null"`
+	BalanceAfter float64         `json:"balance_after" gorm:"not null"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+func (t Transaction) TableName() string {
+	return "transactions"
+}
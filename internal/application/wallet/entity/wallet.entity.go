@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Wallet holds a user's balance in a single currency; a user has at most one wallet per currency
+type Wallet struct {
+	ID       uint    `json:"id" gorm:"primaryKey"`
+	UserID   uint    `json:"user_id" gorm:"not null;uniqueIndex:idx_wallets_user_currency"`
+	Currency string  `json:"currency" gorm:"size:3;not null;uniqueIndex:idx_wallets_user_currency"`
+	Balance  float64 `json:"balance" gorm:"not null;default:0"`
+	// Version increases with every balance change so concurrent writers can detect each other
+	Version   uint           `json:"version" gorm:"not null;default:0"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+func (w Wallet) TableName() string {
+	return "wallets"
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/repository"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletHandler_ErrorCodes(t *testing.T) {
+	balanceChange := `{"amount":5,"currency":"USD"}`
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		mock   func(m *MockWalletService)
+		status int
+		code   string
+	}{
+		{
+			name:   "user not found",
+			method: "GET",
+			path:   "/users/99/wallets",
+			mock: func(m *MockWalletService) {
+				m.On("GetWalletsByUser", uint(99)).Return(nil, apperror.New(apperror.CodeUserNotFound, "user not found"))
+			},
+			status: http.StatusNotFound,
+			code:   apperror.CodeUserNotFound,
+		},
+		{
+			name:   "wallet not found",
+			method: "POST",
+			path:   "/wallets/99/deposit",
+			body:   balanceChange,
+			mock: func(m *MockWalletService) {
+				m.On("Deposit", uint(99), mock.Anything).Return(nil, apperror.New(apperror.CodeWalletNotFound, "wallet not found"))
+			},
+			status: http.StatusNotFound,
+			code:   apperror.CodeWalletNotFound,
+		},
+		{
+			name:   "insufficient funds",
+			method: "POST",
+			path:   "/wallets/1/withdraw",
+			body:   balanceChange,
+			mock: func(m *MockWalletService) {
+				m.On("Withdraw", uint(1), mock.Anything).Return(nil, apperror.New(apperror.CodeInsufficientFunds, "insufficient funds"))
+			},
+			status: http.StatusUnprocessableEntity,
+			code:   apperror.CodeInsufficientFunds,
+		},
+		{
+			name:   "currency does not match wallet",
+			method: "POST",
+			path:   "/wallets/1/deposit",
+			body:   balanceChange,
+			mock: func(m *MockWalletService) {
+				m.On("Deposit", uint(1), mock.Anything).Return(nil, apperror.New(apperror.CodeCurrencyMismatch, "currency does not match wallet"))
+			},
+			status: http.StatusBadRequest,
+			code:   apperror.CodeCurrencyMismatch,
+		},
+		{
+			name:   "concurrent update",
+			method: "POST",
+			path:   "/wallets/1/withdraw",
+			body:   balanceChange,
+			mock: func(m *MockWalletService) {
+				m.On("Withdraw", uint(1), mock.Anything).Return(nil, repository.ErrConcurrentUpdate)
+			},
+			status: http.StatusConflict,
+			code:   apperror.CodeConcurrentUpdate,
+		},
+		{
+			name:   "cursor with a custom sort",
+			method: "GET",
+			path:   "/wallets/1/transactions?cursor=5&sort=amount",
+			mock: func(m *MockWalletService) {
+				m.On("GetTransactions", uint(1), mock.Anything).Return(nil, apperror.New(apperror.CodeCursorRequiresSort, "cursor requires the default sort"))
+			},
+			status: http.StatusBadRequest,
+			code:   apperror.CodeCursorRequiresSort,
+		},
+		{
+			name:   "tampered cursor",
+			method: "GET",
+			path:   "/wallets/1/transactions?cursor=5",
+			mock: func(m *MockWalletService) {
+				m.On("GetTransactions", uint(1), mock.Anything).Return(nil, apperror.New(apperror.CodeInvalidCursor, "invalid cursor"))
+			},
+			status: http.StatusBadRequest,
+			code:   apperror.CodeInvalidCursor,
+		},
+		{
+			name:   "invalid wallet ID",
+			method: "GET",
+			path:   "/wallets/abc/transactions",
+			status: http.StatusBadRequest,
+			code:   apperror.CodeInvalidID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			handler, mockService := setupWalletHandler()
+			if tt.mock != nil {
+				tt.mock(mockService)
+			}
+			router := gin.New()
+			handler.RegisterRoutes(router.Group(""))
+
+			// Given
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			// When
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Then
+			assert.Equal(t, tt.status, w.Code)
+			var result map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Equal(t, tt.code, result["code"])
+			assert.NotEmpty(t, result["error"])
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/service"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/httpcache"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/idempotency"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/timezone"
+	// registers the finite binding tag used by BalanceChangeRequest
+	_ "github.com/novriyantoAli/wallet-ms-backend/internal/pkg/validation"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// idempotencyKeyHeader makes a retried deposit replay its first response instead of
+// crediting the wallet again
+const idempotencyKeyHeader = "Idempotency-Key"
+
+type WalletHandler struct {
+	service     service.WalletService
+	idempotency idempotency.Store
+	logger      *zap.Logger
+}
+
+func NewWalletHandler(service service.WalletService, store idempotency.Store, logger *zap.Logger) *WalletHandler {
+	return &WalletHandler{
+		service:     service,
+		idempotency: store,
+		logger:      logger,
+	}
+}
+
+// GetWalletsByUser godoc
+// @Summary Get wallets by user ID
+// @Description Get all wallets of a user with their balances, one per currency
+// @Tags wallets
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param tz query string false "IANA time zone for timestamps, e.g. Asia/Jakarta" default(UTC)
+// @Param If-Modified-Since header string false "Last-Modified from an earlier response"
+// @Success 200 {object} map[string]interface{} "List of wallets for the user"
+// @Header 200 {string} Last-Modified "Latest update among the returned wallets"
+// @Success 304 "Nothing returned has changed since If-Modified-Since"
+// @Failure 400 {object} map[string]interface{} "Invalid user ID or timezone"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/{id}/wallets [get]
+func (h *WalletHandler) GetWalletsByUser(ctx *gin.Context) {
+	userIDStr := ctx.Param("id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid user ID")
+		return
+	}
+
+	loc, err := timezone.Parse(ctx.Query("tz"))
+	if err != nil {
+		apperror.JSON(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	wallets, err := h.service.GetWalletsByUser(uint(userID))
+	if err != nil {
+		h.logger.Error("Failed to get wallets by user", zap.Error(err))
+		if apperror.Code(err) == apperror.CodeUserNotFound {
+			apperror.JSON(ctx, http.StatusNotFound, err)
+			return
+		}
+		apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to get wallets")
+		return
+	}
+	var lastModified time.Time
+	for i := range wallets {
+		wallets[i].InLocation(loc)
+		lastModified = httpcache.Latest(lastModified, wallets[i].UpdatedAt)
+	}
+	if httpcache.NotModified(ctx, lastModified) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": wallets})
+}
+
+// Deposit godoc
+// @Summary Deposit into a wallet
+// @Description Add funds to a wallet and record a deposit transaction. With an Idempotency-Key, a retry
+// @Description of the same deposit returns the first response without crediting the wallet again.
+// @Tags wallets
+// @Accept json
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param deposit body dto.BalanceChangeRequest true "Deposit request"
+// @Param Idempotency-Key header string false "Unique key for the deposit, reused on retries"
+// @Success 200 {object} map[string]interface{} "Updated wallet and ledger transaction"
+// @Failure 400 {object} map[string]interface{} "Invalid request or currency mismatch"
+// @Failure 404 {object} map[string]interface{} "Wallet not found"
+// @Failure 409 {object} map[string]interface{} "Wallet was modified concurrently, or Idempotency-Key reused with a different deposit or still held by a deposit in progress"
+// @Failure 503 {object} map[string]interface{} "Too many concurrent transactions"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /wallets/{id}/deposit [post]
+func (h *WalletHandler) Deposit(ctx *gin.Context) {
+	h.changeBalance(ctx, ctx.GetHeader(idempotencyKeyHeader), h.service.Deposit)
+}
+
+// Withdraw godoc
+// @Summary Withdraw from a wallet
+// @Description Remove funds from a wallet and record a withdrawal transaction
+// @Tags wallets
+// @Accept json
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param withdrawal body dto.BalanceChangeRequest true "Withdrawal request"
+// @Success 200 {object} map[string]interface{} "Updated wallet and ledger transaction"
+// @Failure 400 {object} map[string]interface{} "Invalid request or currency mismatch"
+// @Failure 404 {object} map[string]interface{} "Wallet not found"
+// @Failure 409 {object} map[string]interface{} "Wallet was modified concurrently"
+// @Failure 503 {object} map[string]interface{} "Too many concurrent transactions"
+// @Failure 422 {object} map[string]interface{} "Insufficient funds"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /wallets/{id}/withdraw [post]
+func (h *WalletHandler) Withdraw(ctx *gin.Context) {
+	h.changeBalance(ctx, "", h.service.Withdraw)
+}
+
+// GetTransactions godoc
+// @Summary Get wallet transactions
+// @Description Get a wallet's ledger transactions, newest first, with optional filtering and pagination. Responses carry next_cursor while more entries follow; pass it as cursor to walk the whole ledger without skipping or repeating entries.
+// @Tags wallets
+// @Accept json
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param type query string false "Filter by direction" Enums(credit, debit)
+// @Param created_from query string false "Only transactions created at or after this RFC 3339 time"
+// @Param created_to query string false "Only transactions created at or before this RFC 3339 time"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Number of items per page" default(10)
+// @Param sort query string false "Sort field, prefixed with - for descending; ties are ordered by ID" Enums(created_at, -created_at, amount, -amount) default(-created_at)
+// @Param cursor query string false "next_cursor from the previous page; replaces page and needs the default sort"
+// @Param tz query string false "IANA time zone for timestamps, e.g. Asia/Jakarta" default(UTC)
+// @Param If-Modified-Since header string false "Last-Modified from an earlier response"
+// @Success 200 {object} dto.TransactionListResponse "List of transactions"
+// @Header 200 {string} Last-Modified "Latest update among the returned transactions"
+// @Success 304 "Nothing returned has changed since If-Modified-Since"
+// @Failure 400 {object} map[string]interface{} "Invalid wallet ID, query parameters or timezone, or a tampered cursor or one with a non-default sort"
+// @Failure 404 {object} map[string]interface{} "Wallet not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /wallets/{id}/transactions [get]
+func (h *WalletHandler) GetTransactions(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid wallet ID")
+		return
+	}
+
+	var filter dto.TransactionFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		h.logger.Error("Invalid query parameters", zap.Error(err))
+		apperror.JSON(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	loc, err := timezone.Parse(ctx.Query("tz"))
+	if err != nil {
+		apperror.JSON(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	transactions, err := h.service.GetTransactions(uint(id), &filter)
+	if err != nil {
+		h.logger.Error("Failed to get wallet transactions", zap.Error(err))
+		switch apperror.Code(err) {
+		case apperror.CodeWalletNotFound:
+			apperror.JSON(ctx, http.StatusNotFound, err)
+			return
+		case apperror.CodeCursorRequiresSort, apperror.CodeInvalidCursor:
+			apperror.JSON(ctx, http.StatusBadRequest, err)
+			return
+		}
+		apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to get transactions")
+		return
+	}
+	transactions.InLocation(loc)
+	if httpcache.NotModified(ctx, transactions.LastModified()) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transactions)
+}
+
+// changeBalance applies a deposit or withdrawal. A non-empty idempotency key is claimed
+// before the change runs, so a concurrent retry gets 409 instead of a second change, and
+// later retries replay the recorded response. A failed change releases the key, so the
+// attempt can be retried with it.
+func (h *WalletHandler) changeBalance(
+	ctx *gin.Context,
+	idempotencyKey string,
+	apply func(walletID uint, req *dto.BalanceChangeRequest) (*dto.BalanceChangeResponse, error),
+) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		apperror.Message(ctx, http.StatusBadRequest, apperror.CodeInvalidID, "Invalid wallet ID")
+		return
+	}
+
+	var req dto.BalanceChangeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Invalid request body", zap.Error(err))
+		apperror.JSON(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var requestHash string
+	if idempotencyKey != "" {
+		// Hash the bound request rather than the raw body, so formatting differences in a
+		// retry still match
+		canonical, err := json.Marshal(req)
+		if err != nil {
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to update wallet balance")
+			return
+		}
+		requestHash = idempotency.HashRequest([]byte(ctx.Request.Method), []byte(ctx.Request.URL.Path), canonical)
+
+		record, err := idempotency.Claim(ctx.Request.Context(), h.idempotency, idempotencyKey, requestHash)
+		if errors.Is(err, idempotency.ErrKeyReused) {
+			apperror.Message(ctx, http.StatusConflict, apperror.CodeIdempotencyKeyReused, err.Error())
+			return
+		}
+		if errors.Is(err, idempotency.ErrInProgress) {
+			apperror.Message(ctx, http.StatusConflict, apperror.CodeIdempotencyKeyInProgress, err.Error())
+			return
+		}
+		if err != nil {
+			h.logger.Error("Failed to claim idempotency key", zap.Error(err))
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to update wallet balance")
+			return
+		}
+		if record != nil {
+			h.logger.Info("Replaying idempotent balance change", zap.Uint64("wallet_id", id))
+			ctx.Data(record.StatusCode, "application/json; charset=utf-8", record.Response)
+			return
+		}
+	}
+
+	result, err := apply(uint(id), &req)
+	if err != nil {
+		h.logger.Error("Failed to change wallet balance", zap.Error(err))
+		if idempotencyKey != "" {
+			if err := h.idempotency.Release(ctx.Request.Context(), idempotencyKey); err != nil {
+				h.logger.Warn("Failed to release idempotency key", zap.String("key", idempotencyKey), zap.Error(err))
+			}
+		}
+		switch apperror.Code(err) {
+		case apperror.CodeWalletNotFound:
+			apperror.JSON(ctx, http.StatusNotFound, err)
+		case apperror.CodeInsufficientFunds:
+			apperror.JSON(ctx, http.StatusUnprocessableEntity, err)
+		case apperror.CodeConcurrentUpdate:
+			apperror.JSON(ctx, http.StatusConflict, err)
+		case apperror.CodeInvalidAmount, apperror.CodeCurrencyMismatch:
+			apperror.JSON(ctx, http.StatusBadRequest, err)
+		case apperror.CodeTooManyTransactions:
+			apperror.JSON(ctx, http.StatusServiceUnavailable, err)
+		default:
+			apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to update wallet balance")
+		}
+		return
+	}
+
+	// From here on the balance has changed, so the key stays claimed even if recording the
+	// response fails: a retry then gets 409 rather than a second change
+	response, err := json.Marshal(gin.H{"data": result})
+	if err != nil {
+		apperror.Message(ctx, http.StatusInternalServerError, apperror.CodeInternal, "Failed to update wallet balance")
+		return
+	}
+	if idempotencyKey != "" {
+		err := h.idempotency.Complete(ctx.Request.Context(), &idempotency.Record{
+			Key:         idempotencyKey,
+			RequestHash: requestHash,
+			StatusCode:  http.StatusOK,
+			Response:    response,
+		})
+		if err != nil {
+			h.logger.Warn("Failed to record idempotency key response", zap.String("key", idempotencyKey), zap.Error(err))
+		}
+	}
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", response)
+}
+
+func (h *WalletHandler) RegisterRoutes(api *gin.RouterGroup) {
+	wallets := api.Group("/wallets")
+	{
+		wallets.POST("/:id/deposit", h.Deposit)
+		wallets.POST("/:id/withdraw", h.Withdraw)
+		wallets.GET("/:id/transactions", h.GetTransactions)
+	}
+
+	users := api.Group("/users")
+	{
+		users.GET("/:id/wallets", h.GetWalletsByUser)
+	}
+}
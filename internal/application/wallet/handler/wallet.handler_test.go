@@ -0,0 +1,691 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/database"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/idempotency"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockWalletService is a mock implementation of WalletService
+type MockWalletService struct {
+	mock.Mock
+}
+
+func (m *MockWalletService) GetWalletsByUser(userID uint) ([]dto.WalletResponse, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.WalletResponse), args.Error(1)
+}
+
+func (m *MockWalletService) RequireWallet(userID uint, currency string) (bool, error) {
+	args := m.Called(userID, currency)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWalletService) Deposit(walletID uint, req *dto.BalanceChangeRequest) (*dto.BalanceChangeResponse, error) {
+	args := m.Called(walletID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.BalanceChangeResponse), args.Error(1)
+}
+
+func (m *MockWalletService) Withdraw(walletID uint, req *dto.BalanceChangeRequest) (*dto.BalanceChangeResponse, error) {
+	args := m.Called(walletID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.BalanceChangeResponse), args.Error(1)
+}
+
+func (m *MockWalletService) GetTransactions(walletID uint, filter *dto.TransactionFilter) (*dto.TransactionListResponse, error) {
+	args := m.Called(walletID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TransactionListResponse), args.Error(1)
+}
+
+func setupWalletHandler() (*WalletHandler, *MockWalletService) {
+	gin.SetMode(gin.TestMode)
+	mockService := &MockWalletService{}
+	logger := testutil.NewSilentLogger()
+	handler := NewWalletHandler(mockService, nil, logger)
+	return handler, mockService
+}
+
+func TestWalletHandler_GetWalletsByUser(t *testing.T) {
+	t.Run("should return wallets for user", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		wallets := []dto.WalletResponse{
+			{ID: 1, UserID: 1, Currency: "EUR", Balance: 10},
+			{ID: 2, UserID: 1, Currency: "USD", Balance: 250.75},
+		}
+		mockService.On("GetWalletsByUser", uint(1)).Return(wallets, nil)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/users/1/wallets", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.GetWalletsByUser(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+
+		var result map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &result)
+		data := result["data"].([]interface{})
+		assert.Len(t, data, 2)
+		assert.Equal(t, "USD", data[1].(map[string]interface{})["currency"])
+	})
+
+	t.Run("should return empty list when user has no wallets", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		mockService.On("GetWalletsByUser", uint(1)).Return([]dto.WalletResponse{}, nil)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/users/1/wallets", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.GetWalletsByUser(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":[]}`, w.Body.String())
+	})
+
+	t.Run("should return not found when user does not exist", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		mockService.On("GetWalletsByUser", uint(999)).Return(nil, apperror.New(apperror.CodeUserNotFound, "user not found"))
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/users/999/wallets", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "999"},
+		}
+
+		// When
+		handler.GetWalletsByUser(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return bad request for invalid user ID", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/users/invalid/wallets", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "invalid"},
+		}
+
+		// When
+		handler.GetWalletsByUser(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetWalletsByUser")
+	})
+}
+
+func TestWalletHandler_Deposit(t *testing.T) {
+	t.Run("should deposit successfully", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		result := &dto.BalanceChangeResponse{
+			Wallet:      dto.WalletResponse{ID: 1, UserID: 1, Currency: "USD", Balance: 150},
+			Transaction: dto.TransactionResponse{ID: 1, WalletID: 1, Type: "deposit", Amount: 50, Currency: "USD", BalanceAfter: 150},
+		}
+		mockService.On("Deposit", uint(1), &dto.BalanceChangeRequest{Amount: 50, Currency: "USD"}).Return(result, nil)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/wallets/1/deposit", bytes.NewBufferString(`{"amount":50,"currency":"USD"}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.Deposit(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		data := body["data"].(map[string]interface{})
+		assert.Equal(t, float64(150), data["wallet"].(map[string]interface{})["balance"])
+		assert.Equal(t, "deposit", data["transaction"].(map[string]interface{})["type"])
+	})
+
+	t.Run("should return bad request for negative amount", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/wallets/1/deposit", bytes.NewBufferString(`{"amount":-50,"currency":"USD"}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.Deposit(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "Deposit")
+	})
+
+	t.Run("should return not found when wallet does not exist", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		mockService.On("Deposit", uint(999), mock.AnythingOfType("*dto.BalanceChangeRequest")).
+			Return(nil, apperror.New(apperror.CodeWalletNotFound, "wallet not found"))
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/wallets/999/deposit", bytes.NewBufferString(`{"amount":50,"currency":"USD"}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "999"},
+		}
+
+		// When
+		handler.Deposit(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestWalletHandler_Deposit_IdempotencyKey(t *testing.T) {
+	// Setup: a real store so recorded responses are replayed from the database
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+
+	gin.SetMode(gin.TestMode)
+	mockService := &MockWalletService{}
+	handler := NewWalletHandler(mockService, idempotency.NewDBStore(db, time.Hour), testutil.NewSilentLogger())
+	router := gin.New()
+	handler.RegisterRoutes(router.Group(""))
+
+	deposit := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/wallets/1/deposit", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "topup-123")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Given
+	result := &dto.BalanceChangeResponse{
+		Wallet:      dto.WalletResponse{ID: 1, UserID: 1, Currency: "USD", Balance: 150},
+		Transaction: dto.TransactionResponse{ID: 7, WalletID: 1, Type: "deposit", Amount: 50, Currency: "USD", BalanceAfter: 150},
+	}
+	mockService.On("Deposit", uint(1), &dto.BalanceChangeRequest{Amount: 50, Currency: "USD"}).Return(result, nil).Once()
+
+	t.Run("should credit the first deposit", func(t *testing.T) {
+		// When
+		w := deposit(`{"amount":50,"currency":"USD"}`)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		data := body["data"].(map[string]interface{})
+		assert.Equal(t, float64(150), data["wallet"].(map[string]interface{})["balance"])
+	})
+
+	t.Run("should replay an exact retry without crediting again", func(t *testing.T) {
+		// When
+		first := deposit(`{"amount":50,"currency":"USD"}`)
+		second := deposit(`{ "currency": "USD", "amount": 50 }`)
+
+		// Then
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.JSONEq(t, first.Body.String(), second.Body.String())
+		assert.Contains(t, first.Body.String(), `"balance_after":150`)
+		mockService.AssertNumberOfCalls(t, "Deposit", 1)
+	})
+
+	t.Run("should reject the key with a different amount", func(t *testing.T) {
+		// When
+		w := deposit(`{"amount":75,"currency":"USD"}`)
+
+		// Then
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), apperror.CodeIdempotencyKeyReused)
+		mockService.AssertNumberOfCalls(t, "Deposit", 1)
+	})
+}
+
+func TestWalletHandler_Deposit_ConcurrentRetry(t *testing.T) {
+	// Setup: a real store, on one connection so every request sees the same in-memory database
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	gin.SetMode(gin.TestMode)
+	mockService := &MockWalletService{}
+	handler := NewWalletHandler(mockService, idempotency.NewDBStore(db, time.Hour), testutil.NewSilentLogger())
+	router := gin.New()
+	handler.RegisterRoutes(router.Group(""))
+
+	deposit := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/wallets/1/deposit", bytes.NewBufferString(`{"amount":50,"currency":"USD"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "topup-concurrent")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Given - the first deposit blocks inside the service until released
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	result := &dto.BalanceChangeResponse{
+		Wallet:      dto.WalletResponse{ID: 1, UserID: 1, Currency: "USD", Balance: 150},
+		Transaction: dto.TransactionResponse{ID: 7, WalletID: 1, Type: "deposit", Amount: 50, Currency: "USD", BalanceAfter: 150},
+	}
+	mockService.On("Deposit", uint(1), &dto.BalanceChangeRequest{Amount: 50, Currency: "USD"}).
+		Run(func(mock.Arguments) {
+			close(entered)
+			<-release
+		}).
+		Return(result, nil).Once()
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- deposit() }()
+	<-entered
+
+	// When - retries arrive while the first deposit is still running
+	retries := make([]*httptest.ResponseRecorder, 5)
+	var wg sync.WaitGroup
+	for i := range retries {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			retries[i] = deposit()
+		}(i)
+	}
+	wg.Wait()
+	close(release)
+	firstResponse := <-first
+	replay := deposit()
+
+	// Then
+	for _, w := range retries {
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), apperror.CodeIdempotencyKeyInProgress)
+	}
+	assert.Equal(t, http.StatusOK, firstResponse.Code)
+	assert.Equal(t, http.StatusOK, replay.Code)
+	assert.JSONEq(t, firstResponse.Body.String(), replay.Body.String())
+	mockService.AssertNumberOfCalls(t, "Deposit", 1)
+}
+
+func TestWalletHandler_Deposit_FailedAttemptReleasesKey(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+
+	gin.SetMode(gin.TestMode)
+	mockService := &MockWalletService{}
+	handler := NewWalletHandler(mockService, idempotency.NewDBStore(db, time.Hour), testutil.NewSilentLogger())
+	router := gin.New()
+	handler.RegisterRoutes(router.Group(""))
+
+	deposit := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/wallets/1/deposit", bytes.NewBufferString(`{"amount":50,"currency":"USD"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "topup-retry")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Given - the first attempt fails, the second succeeds
+	req := &dto.BalanceChangeRequest{Amount: 50, Currency: "USD"}
+	mockService.On("Deposit", uint(1), req).Return(nil, errors.New("database unavailable")).Once()
+	mockService.On("Deposit", uint(1), req).Return(&dto.BalanceChangeResponse{}, nil).Once()
+
+	// When
+	failed := deposit()
+	retried := deposit()
+
+	// Then
+	assert.Equal(t, http.StatusInternalServerError, failed.Code)
+	assert.Equal(t, http.StatusOK, retried.Code)
+	mockService.AssertNumberOfCalls(t, "Deposit", 2)
+}
+
+func TestWalletHandler_Withdraw(t *testing.T) {
+	t.Run("should return unprocessable entity on insufficient funds", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		mockService.On("Withdraw", uint(1), mock.AnythingOfType("*dto.BalanceChangeRequest")).
+			Return(nil, apperror.New(apperror.CodeInsufficientFunds, "insufficient funds"))
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/wallets/1/withdraw", bytes.NewBufferString(`{"amount":500,"currency":"USD"}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.Withdraw(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return conflict when the wallet keeps changing", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		mockService.On("Withdraw", uint(1), mock.AnythingOfType("*dto.BalanceChangeRequest")).
+			Return(nil, apperror.New(apperror.CodeConcurrentUpdate, "wallet was modified concurrently"))
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/wallets/1/withdraw", bytes.NewBufferString(`{"amount":5,"currency":"USD"}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.Withdraw(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return service unavailable when transactions are saturated", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		mockService.On("Withdraw", uint(1), mock.AnythingOfType("*dto.BalanceChangeRequest")).
+			Return(nil, database.ErrTooManyTransactions)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/wallets/1/withdraw", bytes.NewBufferString(`{"amount":5,"currency":"USD"}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.Withdraw(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.JSONEq(t, `{"error":"too many concurrent transactions","code":"TOO_MANY_TRANSACTIONS"}`, w.Body.String())
+	})
+
+	t.Run("should return bad request on currency mismatch", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		mockService.On("Withdraw", uint(1), mock.AnythingOfType("*dto.BalanceChangeRequest")).
+			Return(nil, apperror.New(apperror.CodeCurrencyMismatch, "currency does not match wallet"))
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("POST", "/wallets/1/withdraw", bytes.NewBufferString(`{"amount":5,"currency":"EUR"}`))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.Withdraw(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestWalletHandler_GetTransactions(t *testing.T) {
+	t.Run("should bind filter and return transactions", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		expectedFilter := &dto.TransactionFilter{Type: "debit", CreatedFrom: from, Pagination: pagination.Pagination{Page: 2, PageSize: 5}}
+		result := &dto.TransactionListResponse{
+			Data:       []dto.TransactionResponse{{ID: 3, WalletID: 1, Type: "withdrawal", Amount: 5}},
+			TotalCount: 6,
+			Page:       2,
+			PageSize:   5,
+		}
+		mockService.On("GetTransactions", uint(1), expectedFilter).Return(result, nil)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET",
+			"/wallets/1/transactions?type=debit&created_from=2024-01-01T00:00:00Z&page=2&page_size=5", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.GetTransactions(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+
+		var body dto.TransactionListResponse
+		json.Unmarshal(w.Body.Bytes(), &body)
+		assert.Equal(t, int64(6), body.TotalCount)
+		assert.Len(t, body.Data, 1)
+	})
+
+	t.Run("should bind the cursor and return next_cursor", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		expectedFilter := &dto.TransactionFilter{Cursor: "c2lnbmVkLTQw", Pagination: pagination.Pagination{PageSize: 2}}
+		mockService.On("GetTransactions", uint(1), expectedFilter).Return(&dto.TransactionListResponse{
+			Data:       []dto.TransactionResponse{{ID: 39}, {ID: 38}},
+			TotalCount: 50,
+			Page:       1,
+			PageSize:   2,
+			NextCursor: "c2lnbmVkLTM4",
+		}, nil)
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/wallets/1/transactions?cursor=c2lnbmVkLTQw&page_size=2", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.GetTransactions(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+
+		var body dto.TransactionListResponse
+		json.Unmarshal(w.Body.Bytes(), &body)
+		assert.Equal(t, "c2lnbmVkLTM4", body.NextCursor)
+	})
+
+	t.Run("should return bad request for a cursor with a non-default sort", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		mockService.On("GetTransactions", uint(1), mock.AnythingOfType("*dto.TransactionFilter")).
+			Return(nil, apperror.New(apperror.CodeCursorRequiresSort, "cursor requires the default sort"))
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/wallets/1/transactions?cursor=40&sort=amount", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.GetTransactions(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should return bad request for a sort outside the allowlist", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/wallets/1/transactions?sort=balance_after", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.GetTransactions(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetTransactions")
+	})
+
+	t.Run("should return bad request for unknown type", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/wallets/1/transactions?type=refund", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "1"},
+		}
+
+		// When
+		handler.GetTransactions(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetTransactions")
+	})
+
+	t.Run("should return not found when wallet does not exist", func(t *testing.T) {
+		// Setup
+		handler, mockService := setupWalletHandler()
+
+		mockService.On("GetTransactions", uint(999), mock.AnythingOfType("*dto.TransactionFilter")).
+			Return(nil, apperror.New(apperror.CodeWalletNotFound, "wallet not found"))
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/wallets/999/transactions", nil)
+		ctx.Params = gin.Params{
+			{Key: "id", Value: "999"},
+		}
+
+		// When
+		handler.GetTransactions(ctx)
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestWalletHandler_RegisterRoutes(t *testing.T) {
+	t.Run("should register all routes correctly", func(t *testing.T) {
+		// Setup
+		handler, _ := setupWalletHandler()
+		router := gin.New()
+		api := router.Group("/api/v1")
+
+		// When
+		handler.RegisterRoutes(api)
+
+		// Then
+		routes := router.Routes()
+		expectedRoutes := []string{
+			"POST /api/v1/wallets/:id/deposit",
+			"POST /api/v1/wallets/:id/withdraw",
+			"GET /api/v1/wallets/:id/transactions",
+			"GET /api/v1/users/:id/wallets",
+		}
+
+		assert.Len(t, routes, len(expectedRoutes))
+		for _, expectedRoute := range expectedRoutes {
+			found := false
+			for _, route := range routes {
+				if route.Method+" "+route.Path == expectedRoute {
+					found = true
+					break
+				}
+			}
+			assert.True(t, found, "Route %s not found", expectedRoute)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TransactionRepository interface {
+	GetByWallet(walletID uint, filter *dto.TransactionFilter) ([]entity.Transaction, int64, error)
+}
+
+type transactionRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewTransactionRepository(db *gorm.DB, logger *zap.Logger) TransactionRepository {
+	return &transactionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// transactionSorts is the sort allowlist, mapping each accepted sort value to its ORDER BY.
+// Every order ends with id so entries sharing a timestamp or amount page deterministically.
+var transactionSorts = map[string]string{
+	"created_at":  "created_at ASC, id ASC",
+	"-created_at": "created_at DESC, id DESC",
+	"amount":      "amount ASC, id ASC",
+	"-amount":     "amount DESC, id DESC",
+}
+
+// GetByWallet returns a page of the wallet's ledger, newest first unless the filter sorts
+// otherwise, with the total number of entries matching the filter
+func (r *transactionRepository) GetByWallet(walletID uint, filter *dto.TransactionFilter) ([]entity.Transaction, int64, error) {
+	var transactions []entity.Transaction
+	var totalCount int64
+
+	query := r.db.Model(&entity.Transaction{}).Where("wallet_id = ?", walletID)
+
+	if filter.Type != "" {
+		query = query.Where("type IN ?", entity.TransactionTypesForDirection(filter.Type))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at <= ?", filter.CreatedTo)
+	}
+
+	query.Count(&totalCount)
+
+	if filter.AfterID > 0 {
+		// Continue after the cursor entry in (created_at, id) order, the default sort
+		cursorCreatedAt := r.db.Model(&entity.Transaction{}).Select("created_at").Where("id = ?", filter.AfterID)
+		query = query.Where("(created_at < (?) OR (created_at = (?) AND id < ?))",
+			cursorCreatedAt, cursorCreatedAt, filter.AfterID)
+		if filter.PageSize > 0 {
+			query = query.Limit(filter.PageSize)
+		}
+	} else if filter.Page > 0 && filter.PageSize > 0 {
+		query = query.Offset(filter.Offset()).Limit(filter.PageSize)
+	}
+
+	order, ok := transactionSorts[filter.Sort]
+	if !ok {
+		order = transactionSorts[dto.DefaultTransactionSort]
+	}
+
+	err := query.Order(order).Find(&transactions).Error
+	if err != nil {
+		r.logger.Error("Failed to get wallet transactions", zap.Uint("wallet_id", walletID), zap.Error(err))
+		return nil, 0, err
+	}
+
+	return transactions, totalCount, nil
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionRepository_GetByWallet(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewTestLogger(t)
+	repo := NewTransactionRepository(db, logger)
+
+	// Given: five entries on consecutive days in wallet 1 and one in wallet 2
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	types := []entity.TransactionType{
+		entity.TransactionTypeDeposit,
+		entity.TransactionTypeWithdrawal,
+		entity.TransactionTypeDeposit,
+		entity.TransactionTypeWithdrawal,
+		entity.TransactionTypeDeposit,
+	}
+	for i, txType := range types {
+		require.NoError(t, db.Create(&entity.Transaction{
+			WalletID:  1,
+			Type:      txType,
+			Amount:    float64(i + 1),
+			Currency:  "USD",
+			CreatedAt: start.AddDate(0, 0, i),
+		}).Error)
+	}
+	require.NoError(t, db.Create(&entity.Transaction{
+		WalletID:  2,
+		Type:      entity.TransactionTypeDeposit,
+		Amount:    100,
+		Currency:  "USD",
+		CreatedAt: start,
+	}).Error)
+
+	t.Run("should return all wallet transactions newest first", func(t *testing.T) {
+		// When
+		transactions, total, err := repo.GetByWallet(1, &dto.TransactionFilter{})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), total)
+		require.Len(t, transactions, 5)
+		assert.Equal(t, 5.0, transactions[0].Amount)
+		assert.Equal(t, 1.0, transactions[4].Amount)
+	})
+
+	t.Run("should filter by credit", func(t *testing.T) {
+		// When
+		transactions, total, err := repo.GetByWallet(1, &dto.TransactionFilter{Type: entity.TransactionDirectionCredit})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		for _, transaction := range transactions {
+			assert.Equal(t, entity.TransactionTypeDeposit, transaction.Type)
+		}
+	})
+
+	t.Run("should filter by debit and date range", func(t *testing.T) {
+		// When
+		transactions, total, err := repo.GetByWallet(1, &dto.TransactionFilter{
+			Type:        entity.TransactionDirectionDebit,
+			CreatedFrom: start.AddDate(0, 0, 2),
+			CreatedTo:   start.AddDate(0, 0, 4),
+		})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, transactions, 1)
+		assert.Equal(t, 4.0, transactions[0].Amount)
+	})
+
+	t.Run("should include both ends of the date range", func(t *testing.T) {
+		// When
+		_, total, err := repo.GetByWallet(1, &dto.TransactionFilter{
+			CreatedFrom: start.AddDate(0, 0, 1),
+			CreatedTo:   start.AddDate(0, 0, 3),
+		})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+	})
+
+	t.Run("should paginate with total of all matching rows", func(t *testing.T) {
+		// When
+		transactions, total, err := repo.GetByWallet(1, &dto.TransactionFilter{Pagination: pagination.Pagination{Page: 2, PageSize: 2}})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), total)
+		require.Len(t, transactions, 2)
+		assert.Equal(t, 3.0, transactions[0].Amount)
+		assert.Equal(t, 2.0, transactions[1].Amount)
+	})
+
+	t.Run("should continue after the cursor", func(t *testing.T) {
+		// Given: the ID of the third newest entry
+		all, _, err := repo.GetByWallet(1, &dto.TransactionFilter{})
+		require.NoError(t, err)
+		cursor := all[2].ID
+
+		// When
+		transactions, total, err := repo.GetByWallet(1, &dto.TransactionFilter{
+			AfterID:    cursor,
+			Pagination: pagination.Pagination{Page: 5, PageSize: 10},
+		})
+
+		// Then: the cursor replaces the page and the total still counts every match
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), total)
+		require.Len(t, transactions, 2)
+		assert.Equal(t, 2.0, transactions[0].Amount)
+		assert.Equal(t, 1.0, transactions[1].Amount)
+	})
+}
+
+func TestTransactionRepository_GetByWallet_Sort(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	repo := NewTransactionRepository(db, testutil.NewTestLogger(t))
+
+	// Given: two entries sharing a timestamp between an older and a newer one, inserted
+	// out of time order so ID and created_at disagree
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	seed := func(amount float64, createdAt time.Time) uint {
+		transaction := &entity.Transaction{
+			WalletID:  1,
+			Type:      entity.TransactionTypeDeposit,
+			Amount:    amount,
+			Currency:  "USD",
+			CreatedAt: createdAt,
+		}
+		require.NoError(t, db.Create(transaction).Error)
+		return transaction.ID
+	}
+	newest := seed(30, start.Add(2*time.Hour))
+	tieFirst := seed(10, start.Add(time.Hour))
+	tieSecond := seed(20, start.Add(time.Hour))
+	oldest := seed(40, start)
+
+	ids := func(transactions []entity.Transaction) []uint {
+		result := make([]uint, 0, len(transactions))
+		for _, transaction := range transactions {
+			result = append(result, transaction.ID)
+		}
+		return result
+	}
+
+	t.Run("should default to newest first with ties broken by id", func(t *testing.T) {
+		// When
+		transactions, _, err := repo.GetByWallet(1, &dto.TransactionFilter{})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, []uint{newest, tieSecond, tieFirst, oldest}, ids(transactions))
+	})
+
+	t.Run("should sort by an allowlisted field", func(t *testing.T) {
+		// When
+		ascending, _, err := repo.GetByWallet(1, &dto.TransactionFilter{Sort: "amount"})
+		require.NoError(t, err)
+		oldestFirst, _, err := repo.GetByWallet(1, &dto.TransactionFilter{Sort: "created_at"})
+		require.NoError(t, err)
+
+		// Then
+		assert.Equal(t, []uint{tieFirst, tieSecond, newest, oldest}, ids(ascending))
+		assert.Equal(t, []uint{oldest, tieFirst, tieSecond, newest}, ids(oldestFirst))
+	})
+
+	t.Run("should continue a cursor across entries sharing a timestamp", func(t *testing.T) {
+		// When
+		transactions, _, err := repo.GetByWallet(1, &dto.TransactionFilter{
+			AfterID:    tieSecond,
+			Pagination: pagination.Pagination{PageSize: 10},
+		})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, []uint{tieFirst, oldest}, ids(transactions))
+	})
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/database"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrConcurrentUpdate is returned when a wallet's balance changed between reading and
+// writing it; the change can be retried against the fresh balance
+var ErrConcurrentUpdate = apperror.New(apperror.CodeConcurrentUpdate, "wallet was modified concurrently")
+
+type WalletRepository interface {
+	Create(wallet *entity.Wallet) error
+	GetByID(id uint) (*entity.Wallet, error)
+	GetByUserID(userID uint) ([]entity.Wallet, error)
+	GetByUserAndCurrency(userID uint, currency string) (*entity.Wallet, error)
+	ApplyBalanceChange(
+		walletID uint,
+		change func(wallet *entity.Wallet) (*entity.Transaction, error),
+	) (*entity.Wallet, *entity.Transaction, error)
+}
+
+type walletRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewWalletRepository(db *gorm.DB, logger *zap.Logger) WalletRepository {
+	return &walletRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *walletRepository) Create(wallet *entity.Wallet) error {
+	r.logger.Info("Creating wallet", zap.Uint("user_id", wallet.UserID), zap.String("currency", wallet.Currency))
+	return r.db.Create(wallet).Error
+}
+
+func (r *walletRepository) GetByID(id uint) (*entity.Wallet, error) {
+	var wallet entity.Wallet
+	err := r.db.First(&wallet, id).Error
+	if err != nil {
+		r.logger.Error("Failed to get wallet by ID", zap.Uint("id", id), zap.Error(err))
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+func (r *walletRepository) GetByUserID(userID uint) ([]entity.Wallet, error) {
+	wallets := []entity.Wallet{}
+	err := r.db.Where("user_id = ?", userID).Order("currency").Find(&wallets).Error
+	if err != nil {
+		r.logger.Error("Failed to get wallets by user ID", zap.Uint("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	return wallets, nil
+}
+
+// GetByUserAndCurrency returns the user's wallet in currency through the
+// idx_wallets_user_currency index, or gorm.ErrRecordNotFound when there is none
+func (r *walletRepository) GetByUserAndCurrency(userID uint, currency string) (*entity.Wallet, error) {
+	var wallet entity.Wallet
+	err := r.db.Where("user_id = ? AND currency = ?", userID, strings.ToUpper(currency)).First(&wallet).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("Failed to get wallet by user and currency",
+				zap.Uint("user_id", userID),
+				zap.String("currency", currency),
+				zap.Error(err))
+		}
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// CreateMissing creates an empty wallet for the user in currency inside tx, unless one
+// already exists, so it commits or rolls back with the rest of the caller's transaction
+func CreateMissing(tx *gorm.DB, userID uint, currency string) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entity.Wallet{UserID: userID, Currency: strings.ToUpper(currency)}).Error
+}
+
+// ApplyBalanceChange locks the wallet row, lets change adjust it and persists the wallet
+// together with the ledger entry change returns, all in a single database transaction.
+// An error from change aborts the transaction and is returned as is.
+//
+// The write is conditional on the version read, so an update that raced with another
+// writer (on databases without row locks) fails with ErrConcurrentUpdate instead of
+// silently overwriting it.
+func (r *walletRepository) ApplyBalanceChange(
+	walletID uint,
+	change func(wallet *entity.Wallet) (*entity.Transaction, error),
+) (*entity.Wallet, *entity.Transaction, error) {
+	var wallet entity.Wallet
+	var transaction *entity.Transaction
+
+	err := database.WithTransaction(context.Background(), r.db, func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&wallet, walletID).Error
+		if err != nil {
+			return err
+		}
+
+		transaction, err = change(&wallet)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&entity.Wallet{}).
+			Where("id = ? AND version = ?", wallet.ID, wallet.Version).
+			Updates(map[string]interface{}{
+				"balance":    wallet.Balance,
+				"version":    gorm.Expr("version + 1"),
+				"updated_at": time.Now().UTC(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrConcurrentUpdate
+		}
+		wallet.Version++
+
+		transaction.WalletID = wallet.ID
+		return tx.Create(transaction).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to apply wallet balance change", zap.Uint("wallet_id", walletID), zap.Error(err))
+		return nil, nil, err
+	}
+
+	return &wallet, transaction, nil
+}
@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestWalletRepository_Create(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewTestLogger(t)
+	repo := NewWalletRepository(db, logger)
+
+	t.Run("should create wallet successfully", func(t *testing.T) {
+		// Given
+		wallet := testutil.CreateWalletFixture()
+		wallet.ID = 0
+
+		// When
+		err := repo.Create(wallet)
+
+		// Then
+		assert.NoError(t, err)
+		assert.NotZero(t, wallet.ID)
+	})
+
+	t.Run("should reject a second wallet in the same currency", func(t *testing.T) {
+		// Given
+		wallet := testutil.CreateWalletFixture()
+		wallet.ID = 0
+
+		// When
+		err := repo.Create(wallet)
+
+		// Then
+		assert.Error(t, err)
+	})
+}
+
+func TestWalletRepository_GetByID(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewTestLogger(t)
+	repo := NewWalletRepository(db, logger)
+
+	t.Run("should get wallet by ID successfully", func(t *testing.T) {
+		// Given
+		wallet := testutil.CreateWalletFixture()
+		wallet.ID = 0
+		require.NoError(t, repo.Create(wallet))
+
+		// When
+		result, err := repo.GetByID(wallet.ID)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, wallet.Currency, result.Currency)
+		assert.Equal(t, wallet.Balance, result.Balance)
+	})
+
+	t.Run("should return error when wallet not found", func(t *testing.T) {
+		// When
+		result, err := repo.GetByID(999)
+
+		// Then
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func TestWalletRepository_GetByUserID(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewTestLogger(t)
+	repo := NewWalletRepository(db, logger)
+
+	t.Run("should return all wallets of the user", func(t *testing.T) {
+		// Given
+		for _, currency := range []string{"USD", "EUR"} {
+			require.NoError(t, repo.Create(&entity.Wallet{UserID: 1, Currency: currency}))
+		}
+		require.NoError(t, repo.Create(&entity.Wallet{UserID: 2, Currency: "USD"}))
+
+		// When
+		wallets, err := repo.GetByUserID(1)
+
+		// Then
+		assert.NoError(t, err)
+		require.Len(t, wallets, 2)
+		assert.Equal(t, "EUR", wallets[0].Currency)
+		assert.Equal(t, "USD", wallets[1].Currency)
+	})
+
+	t.Run("should return empty list for user without wallets", func(t *testing.T) {
+		// When
+		wallets, err := repo.GetByUserID(999)
+
+		// Then
+		assert.NoError(t, err)
+		assert.NotNil(t, wallets)
+		assert.Empty(t, wallets)
+	})
+}
+
+func TestWalletRepository_GetByUserAndCurrency(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewTestLogger(t)
+	repo := NewWalletRepository(db, logger)
+	for _, currency := range []string{"USD", "EUR"} {
+		require.NoError(t, repo.Create(&entity.Wallet{UserID: 1, Currency: currency}))
+	}
+
+	t.Run("should return the user's wallet in the currency, in any letter case", func(t *testing.T) {
+		// When
+		wallet, err := repo.GetByUserAndCurrency(1, "eur")
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", wallet.Currency)
+	})
+
+	t.Run("should return not found for a currency without a wallet", func(t *testing.T) {
+		// When
+		wallet, err := repo.GetByUserAndCurrency(1, "JPY")
+
+		// Then
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, wallet)
+	})
+}
+
+func TestCreateMissing(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+
+	// Given
+	require.NoError(t, db.Create(&entity.Wallet{UserID: 1, Currency: "USD", Balance: 25}).Error)
+
+	// When
+	existingErr := CreateMissing(db, 1, "usd")
+	missingErr := CreateMissing(db, 1, "eur")
+
+	// Then
+	require.NoError(t, existingErr)
+	require.NoError(t, missingErr)
+	var wallets []entity.Wallet
+	require.NoError(t, db.Where("user_id = ?", 1).Order("currency").Find(&wallets).Error)
+	require.Len(t, wallets, 2)
+	assert.Equal(t, "EUR", wallets[0].Currency)
+	assert.Zero(t, wallets[0].Balance)
+	assert.Equal(t, 25.0, wallets[1].Balance)
+}
+
+func TestWalletRepository_ApplyBalanceChange(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	logger := testutil.NewTestLogger(t)
+	repo := NewWalletRepository(db, logger)
+
+	wallet := testutil.CreateWalletFixture()
+	wallet.ID = 0
+	wallet.Balance = 100
+	require.NoError(t, repo.Create(wallet))
+
+	t.Run("should persist balance and ledger entry together", func(t *testing.T) {
+		// When
+		updated, transaction, err := repo.ApplyBalanceChange(wallet.ID, func(w *entity.Wallet) (*entity.Transaction, error) {
+			w.Balance += 25
+			return &entity.Transaction{
+				Type:         entity.TransactionTypeDeposit,
+				Amount:       25,
+				Currency:     w.Currency,
+				BalanceAfter: w.Balance,
+			}, nil
+		})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, 125.0, updated.Balance)
+		assert.NotZero(t, transaction.ID)
+		assert.Equal(t, wallet.ID, transaction.WalletID)
+
+		var dbWallet entity.Wallet
+		require.NoError(t, db.First(&dbWallet, wallet.ID).Error)
+		assert.Equal(t, 125.0, dbWallet.Balance)
+
+		var count int64
+		db.Model(&entity.Transaction{}).Where("wallet_id = ?", wallet.ID).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("should leave wallet untouched when change fails", func(t *testing.T) {
+		// When
+		_, _, err := repo.ApplyBalanceChange(wallet.ID, func(w *entity.Wallet) (*entity.Transaction, error) {
+			w.Balance -= 1000
+			return nil, errors.New("insufficient funds")
+		})
+
+		// Then
+		assert.EqualError(t, err, "insufficient funds")
+
+		var dbWallet entity.Wallet
+		require.NoError(t, db.First(&dbWallet, wallet.ID).Error)
+		assert.Equal(t, 125.0, dbWallet.Balance)
+	})
+
+	t.Run("should fail with conflict when the wallet changed underneath", func(t *testing.T) {
+		// When: another writer bumps the version between the read and the write
+		_, _, err := repo.ApplyBalanceChange(wallet.ID, func(w *entity.Wallet) (*entity.Transaction, error) {
+			w.Version--
+			w.Balance += 10
+			return &entity.Transaction{Type: entity.TransactionTypeDeposit, Amount: 10, Currency: w.Currency}, nil
+		})
+
+		// Then
+		assert.ErrorIs(t, err, ErrConcurrentUpdate)
+
+		var dbWallet entity.Wallet
+		require.NoError(t, db.First(&dbWallet, wallet.ID).Error)
+		assert.Equal(t, 125.0, dbWallet.Balance)
+		assert.Equal(t, uint(1), dbWallet.Version)
+	})
+
+	t.Run("should return not found for unknown wallet", func(t *testing.T) {
+		// When
+		_, _, err := repo.ApplyBalanceChange(999, func(w *entity.Wallet) (*entity.Transaction, error) {
+			t.Fatal("change must not be called")
+			return nil, nil
+		})
+
+		// Then
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
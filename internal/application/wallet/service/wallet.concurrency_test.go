@@ -0,0 +1,70 @@
+package service
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/repository"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestWalletService_ConcurrentDeposits(t *testing.T) {
+	// Setup: a file database so every goroutine's connection sees the same wallet.
+	// SQLite has no row locks, so immediate transactions stand in for FOR UPDATE.
+	dsn := filepath.Join(t.TempDir(), "wallet.db") + "?_busy_timeout=5000&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&entity.Wallet{}, &entity.Transaction{}))
+
+	log := testutil.NewSilentLogger()
+	walletRepo := repository.NewWalletRepository(db, log)
+	service := NewWalletService(walletRepo, repository.NewTransactionRepository(db, log),
+		&testutil.MockUserService{}, testutil.NewTestConfig(), log)
+
+	wallet := &entity.Wallet{UserID: 1, Currency: "USD"}
+	require.NoError(t, walletRepo.Create(wallet))
+
+	// When
+	const workers, depositsPerWorker = 8, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*depositsPerWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < depositsPerWorker; i++ {
+				_, err := service.Deposit(wallet.ID, &dto.BalanceChangeRequest{Amount: 1, Currency: "USD"})
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Then: every successful deposit is reflected exactly once in the balance
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+
+	var result entity.Wallet
+	require.NoError(t, db.First(&result, wallet.ID).Error)
+	var entries int64
+	db.Model(&entity.Transaction{}).Where("wallet_id = ?", wallet.ID).Count(&entries)
+
+	assert.Equal(t, workers*depositsPerWorker, succeeded)
+	assert.Equal(t, float64(succeeded), result.Balance)
+	assert.Equal(t, int64(succeeded), entries)
+	assert.Equal(t, uint(succeeded), result.Version)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/repository"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletService_GetTransactions_Cursor(t *testing.T) {
+	// Setup: real repositories so the cursors run against the ledger table
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	log := testutil.NewSilentLogger()
+	walletRepo := repository.NewWalletRepository(db, log)
+	service := NewWalletService(walletRepo, repository.NewTransactionRepository(db, log),
+		&testutil.MockUserService{}, testutil.NewTestConfig(), log)
+
+	user := testutil.CreateUserFixture()
+	user.ID = 0
+	require.NoError(t, db.Create(user).Error)
+	wallet := &entity.Wallet{UserID: user.ID, Currency: "USD"}
+	require.NoError(t, walletRepo.Create(wallet))
+
+	// Given: seven ledger entries
+	const entries = 7
+	for i := 0; i < entries; i++ {
+		_, err := service.Deposit(wallet.ID, &dto.BalanceChangeRequest{Amount: float64(i + 1), Currency: "USD"})
+		require.NoError(t, err)
+	}
+
+	t.Run("should walk the whole ledger without duplicates", func(t *testing.T) {
+		// When: follow next_cursor from the first page until it runs out
+		seen := map[uint]bool{}
+		var order []uint
+		filter := &dto.TransactionFilter{Pagination: pagination.Pagination{PageSize: 3}}
+		for pages := 0; ; pages++ {
+			require.Less(t, pages, entries, "cursor walk did not terminate")
+
+			result, err := service.GetTransactions(wallet.ID, filter)
+			require.NoError(t, err)
+			assert.Equal(t, int64(entries), result.TotalCount)
+			for _, transaction := range result.Data {
+				assert.False(t, seen[transaction.ID], "transaction %d returned twice", transaction.ID)
+				seen[transaction.ID] = true
+				order = append(order, transaction.ID)
+			}
+
+			if result.NextCursor == "" {
+				break
+			}
+			filter = &dto.TransactionFilter{Cursor: result.NextCursor, Pagination: pagination.Pagination{PageSize: 3}}
+		}
+
+		// Then: every entry once, newest first
+		require.Len(t, order, entries)
+		for i := 1; i < len(order); i++ {
+			assert.Greater(t, order[i-1], order[i])
+		}
+	})
+
+	t.Run("should reject a cursor with a non-default sort", func(t *testing.T) {
+		// When
+		result, err := service.GetTransactions(wallet.ID, &dto.TransactionFilter{Cursor: "opaque", Sort: "amount"})
+
+		// Then
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "cursor requires the default sort")
+	})
+
+	t.Run("should omit next_cursor on the last offset page", func(t *testing.T) {
+		// When
+		result, err := service.GetTransactions(wallet.ID, &dto.TransactionFilter{Pagination: pagination.Pagination{Page: 3, PageSize: 3}})
+
+		// Then
+		require.NoError(t, err)
+		assert.Len(t, result.Data, 1)
+		assert.Empty(t, result.NextCursor)
+	})
+
+	t.Run("should not return entries added after the walk started", func(t *testing.T) {
+		// Given: the first page, then a new deposit
+		first, err := service.GetTransactions(wallet.ID, &dto.TransactionFilter{Pagination: pagination.Pagination{PageSize: 3}})
+		require.NoError(t, err)
+		require.NotEmpty(t, first.NextCursor)
+		_, err = service.Deposit(wallet.ID, &dto.BalanceChangeRequest{Amount: 100, Currency: "USD"})
+		require.NoError(t, err)
+
+		// When
+		second, err := service.GetTransactions(wallet.ID, &dto.TransactionFilter{
+			Cursor:     first.NextCursor,
+			Pagination: pagination.Pagination{PageSize: 3},
+		})
+
+		// Then: the page continues where the first ended instead of shifting by one
+		require.NoError(t, err)
+		require.NotEmpty(t, second.Data)
+		assert.Equal(t, first.Data[len(first.Data)-1].ID-1, second.Data[0].ID)
+	})
+
+	t.Run("should accept a server-issued cursor and reject a modified one", func(t *testing.T) {
+		// Given
+		first, err := service.GetTransactions(wallet.ID, &dto.TransactionFilter{Pagination: pagination.Pagination{PageSize: 3}})
+		require.NoError(t, err)
+		require.NotEmpty(t, first.NextCursor)
+
+		// Flip one character of the signed cursor
+		tampered := []byte(first.NextCursor)
+		if tampered[0] == 'A' {
+			tampered[0] = 'B'
+		} else {
+			tampered[0] = 'A'
+		}
+		otherWallet := &entity.Wallet{UserID: user.ID, Currency: "EUR"}
+		require.NoError(t, walletRepo.Create(otherWallet))
+
+		for name, tt := range map[string]struct {
+			walletID uint
+			cursor   string
+		}{
+			"issued":         {walletID: wallet.ID, cursor: first.NextCursor},
+			"tampered":       {walletID: wallet.ID, cursor: string(tampered)},
+			"crafted ID":     {walletID: wallet.ID, cursor: "5"},
+			"another wallet": {walletID: otherWallet.ID, cursor: first.NextCursor},
+		} {
+			// When
+			result, err := service.GetTransactions(tt.walletID, &dto.TransactionFilter{
+				Cursor:     tt.cursor,
+				Pagination: pagination.Pagination{PageSize: 3},
+			})
+
+			// Then
+			if name == "issued" {
+				require.NoError(t, err, name)
+				assert.NotEmpty(t, result.Data, name)
+				continue
+			}
+			assert.Nil(t, result, name)
+			assert.EqualError(t, err, "invalid cursor", name)
+		}
+	})
+}
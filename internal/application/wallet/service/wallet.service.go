@@ -0,0 +1,265 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/service"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/repository"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/money"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxBalanceChangeAttempts bounds retries of a balance change that lost a race
+const maxBalanceChangeAttempts = 3
+
+type WalletService interface {
+	GetWalletsByUser(userID uint) ([]dto.WalletResponse, error)
+	RequireWallet(userID uint, currency string) (bool, error)
+	Deposit(walletID uint, req *dto.BalanceChangeRequest) (*dto.BalanceChangeResponse, error)
+	Withdraw(walletID uint, req *dto.BalanceChangeRequest) (*dto.BalanceChangeResponse, error)
+	GetTransactions(walletID uint, filter *dto.TransactionFilter) (*dto.TransactionListResponse, error)
+}
+
+type walletService struct {
+	repo            repository.WalletRepository
+	transactionRepo repository.TransactionRepository
+	userService     service.UserService
+	cursors         *pagination.CursorSigner
+	cfg             *config.Config
+	logger          *zap.Logger
+}
+
+func NewWalletService(
+	repo repository.WalletRepository,
+	transactionRepo repository.TransactionRepository,
+	userService service.UserService,
+	cfg *config.Config,
+	logger *zap.Logger,
+) WalletService {
+	if cfg.Pagination.CursorSecret == "" {
+		logger.Warn("pagination.cursor_secret is not set; transaction cursors are signed with a per-process key")
+	}
+	return &walletService{
+		repo:            repo,
+		transactionRepo: transactionRepo,
+		userService:     userService,
+		cursors:         pagination.NewCursorSigner(cfg.Pagination.CursorSecret),
+		cfg:             cfg,
+		logger:          logger,
+	}
+}
+
+// GetWalletsByUser returns every wallet of the user, one per currency.
+// A user without wallets gets an empty list.
+func (s *walletService) GetWalletsByUser(userID uint) ([]dto.WalletResponse, error) {
+	_, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		s.logger.Error("User not found for wallet lookup", zap.Uint("user_id", userID), zap.Error(err))
+		return nil, apperror.New(apperror.CodeUserNotFound, "user not found")
+	}
+
+	wallets, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.WalletResponse, 0, len(wallets))
+	for _, wallet := range wallets {
+		responses = append(responses, *s.entityToResponse(&wallet))
+	}
+
+	return responses, nil
+}
+
+// RequireWallet checks that the user has a wallet in currency before a payment in it.
+// It returns true when the wallet is missing and wallet.auto_create lets the caller create
+// it together with the payment. Otherwise a missing wallet is rejected with
+// CodeCurrencyMismatch, and one in an unknown currency with CodeUnsupportedCurrency.
+func (s *walletService) RequireWallet(userID uint, currency string) (bool, error) {
+	_, err := s.repo.GetByUserAndCurrency(userID, currency)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	if !s.cfg.Wallet.AutoCreate {
+		return false, apperror.New(apperror.CodeCurrencyMismatch, "user has no wallet in the payment currency")
+	}
+	if !money.IsCurrency(currency) {
+		return false, apperror.New(apperror.CodeUnsupportedCurrency, "unsupported currency")
+	}
+	return true, nil
+}
+
+func (s *walletService) Deposit(walletID uint, req *dto.BalanceChangeRequest) (*dto.BalanceChangeResponse, error) {
+	return s.changeBalance(walletID, req, entity.TransactionTypeDeposit)
+}
+
+func (s *walletService) Withdraw(walletID uint, req *dto.BalanceChangeRequest) (*dto.BalanceChangeResponse, error) {
+	return s.changeBalance(walletID, req, entity.TransactionTypeWithdrawal)
+}
+
+// changeBalance validates the request and applies it to the locked wallet, writing a
+// ledger entry of the given type
+func (s *walletService) changeBalance(
+	walletID uint,
+	req *dto.BalanceChangeRequest,
+	txType entity.TransactionType,
+) (*dto.BalanceChangeResponse, error) {
+	if req.Amount <= 0 || math.IsNaN(req.Amount) || math.IsInf(req.Amount, 0) {
+		return nil, apperror.New(apperror.CodeInvalidAmount, "amount must be positive")
+	}
+
+	change := func(wallet *entity.Wallet) (*entity.Transaction, error) {
+		if !strings.EqualFold(wallet.Currency, req.Currency) {
+			return nil, apperror.New(apperror.CodeCurrencyMismatch, "currency does not match wallet")
+		}
+
+		switch txType {
+		case entity.TransactionTypeDeposit:
+			wallet.Balance += req.Amount
+		case entity.TransactionTypeWithdrawal:
+			if wallet.Balance-req.Amount < s.balanceFloor() {
+				return nil, apperror.New(apperror.CodeInsufficientFunds, "insufficient funds")
+			}
+			wallet.Balance -= req.Amount
+		}
+
+		return &entity.Transaction{
+			Type:         txType,
+			Amount:       req.Amount,
+			Currency:     wallet.Currency,
+			BalanceAfter: wallet.Balance,
+		}, nil
+	}
+
+	var wallet *entity.Wallet
+	var transaction *entity.Transaction
+	var err error
+	for attempt := 1; attempt <= maxBalanceChangeAttempts; attempt++ {
+		wallet, transaction, err = s.repo.ApplyBalanceChange(walletID, change)
+		if !errors.Is(err, repository.ErrConcurrentUpdate) {
+			break
+		}
+		s.logger.Warn("Concurrent wallet update, retrying",
+			zap.Uint("wallet_id", walletID),
+			zap.Int("attempt", attempt))
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.CodeWalletNotFound, "wallet not found")
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Wallet balance changed",
+		zap.Uint("wallet_id", wallet.ID),
+		zap.String("type", txType.String()),
+		zap.Float64("amount", req.Amount),
+		zap.Float64("balance", wallet.Balance))
+
+	return &dto.BalanceChangeResponse{
+		Wallet:      *s.entityToResponse(wallet),
+		Transaction: *s.transactionToResponse(transaction),
+	}, nil
+}
+
+func (s *walletService) GetTransactions(walletID uint, filter *dto.TransactionFilter) (*dto.TransactionListResponse, error) {
+	_, err := s.repo.GetByID(walletID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.New(apperror.CodeWalletNotFound, "wallet not found")
+		}
+		return nil, err
+	}
+
+	if filter.Cursor != "" {
+		if filter.Sort != "" && filter.Sort != dto.DefaultTransactionSort {
+			return nil, apperror.New(apperror.CodeCursorRequiresSort, "cursor requires the default sort")
+		}
+		filter.AfterID, err = s.cursors.Decode(transactionCursorScope(walletID), filter.Cursor)
+		if err != nil {
+			return nil, apperror.New(apperror.CodeInvalidCursor, "invalid cursor")
+		}
+	}
+
+	filter.Normalize(s.cfg.Pagination)
+
+	transactions, totalCount, err := s.transactionRepo.GetByWallet(walletID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.TransactionResponse, 0, len(transactions))
+	for _, transaction := range transactions {
+		responses = append(responses, *s.transactionToResponse(&transaction))
+	}
+
+	response := &dto.TransactionListResponse{
+		Data:       responses,
+		TotalCount: totalCount,
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+	}
+	if len(transactions) > 0 && hasMoreTransactions(filter, len(transactions), totalCount) {
+		response.NextCursor = s.cursors.Encode(transactionCursorScope(walletID), transactions[len(transactions)-1].ID)
+	}
+	return response, nil
+}
+
+// transactionCursorScope ties a ledger cursor to its wallet, so it can't page through
+// another wallet's ledger
+func transactionCursorScope(walletID uint) string {
+	return fmt.Sprintf("wallet:%d:transactions", walletID)
+}
+
+// hasMoreTransactions reports whether entries follow a page of count entries. A cursor
+// page does not know its position in the total, so a full one is assumed to have more
+// and the last cursor may return an empty page.
+func hasMoreTransactions(filter *dto.TransactionFilter, count int, totalCount int64) bool {
+	if filter.AfterID > 0 {
+		return count == filter.PageSize
+	}
+	return int64(filter.Offset()+count) < totalCount
+}
+
+// balanceFloor is the lowest balance a debit may leave: the configured minimum
+// balance less any overdraft allowance
+func (s *walletService) balanceFloor() float64 {
+	return s.cfg.Wallet.MinBalance - s.cfg.Wallet.OverdraftLimit
+}
+
+func (s *walletService) entityToResponse(wallet *entity.Wallet) *dto.WalletResponse {
+	return &dto.WalletResponse{
+		ID:        wallet.ID,
+		UserID:    wallet.UserID,
+		Currency:  wallet.Currency,
+		Balance:   wallet.Balance,
+		CreatedAt: wallet.CreatedAt,
+		UpdatedAt: wallet.UpdatedAt,
+	}
+}
+
+func (s *walletService) transactionToResponse(transaction *entity.Transaction) *dto.TransactionResponse {
+	return &dto.TransactionResponse{
+		ID:           transaction.ID,
+		WalletID:     transaction.WalletID,
+		Type:         transaction.Type.String(),
+		Amount:       transaction.Amount,
+		Currency:     transaction.Currency,
+		BalanceAfter: transaction.BalanceAfter,
+		CreatedAt:    transaction.CreatedAt,
+	}
+}
@@ -0,0 +1,431 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	userDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/repository"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestWalletService_GetWalletsByUser(t *testing.T) {
+	t.Run("should return wallets in every currency", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		wallets := []entity.Wallet{
+			{ID: 1, UserID: 1, Currency: "EUR", Balance: 10},
+			{ID: 2, UserID: 1, Currency: "USD", Balance: 250.75},
+		}
+
+		// Mock expectations
+		mockUserService.On("GetUserByID", uint(1)).Return(&userDto.UserResponse{ID: 1}, nil)
+		mockRepo.On("GetByUserID", uint(1)).Return(wallets, nil)
+
+		// When
+		result, err := service.GetWalletsByUser(1)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "EUR", result[0].Currency)
+		assert.Equal(t, 10.0, result[0].Balance)
+		assert.Equal(t, "USD", result[1].Currency)
+		assert.Equal(t, 250.75, result[1].Balance)
+		mockRepo.AssertExpectations(t)
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("should return empty list when user has no wallets", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		// Mock expectations
+		mockUserService.On("GetUserByID", uint(1)).Return(&userDto.UserResponse{ID: 1}, nil)
+		mockRepo.On("GetByUserID", uint(1)).Return([]entity.Wallet{}, nil)
+
+		// When
+		result, err := service.GetWalletsByUser(1)
+
+		// Then
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+		assert.Empty(t, result)
+	})
+
+	t.Run("should return error when user not found", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		// Mock expectations
+		mockUserService.On("GetUserByID", uint(999)).Return(nil, errors.New("user not found"))
+
+		// When
+		result, err := service.GetWalletsByUser(999)
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "user not found", err.Error())
+		mockRepo.AssertNotCalled(t, "GetByUserID", uint(999))
+	})
+
+	t.Run("should return error when repository fails", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		// Mock expectations
+		mockUserService.On("GetUserByID", uint(1)).Return(&userDto.UserResponse{ID: 1}, nil)
+		mockRepo.On("GetByUserID", uint(1)).Return(nil, errors.New("database error"))
+
+		// When
+		result, err := service.GetWalletsByUser(1)
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestWalletService_Deposit(t *testing.T) {
+	t.Run("should deposit and record transaction", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		wallet := testutil.CreateWalletFixture()
+		wallet.Balance = 100
+
+		// Mock expectations
+		mockRepo.On("ApplyBalanceChange", wallet.ID).Return(wallet, nil)
+
+		// When
+		result, err := service.Deposit(wallet.ID, &dto.BalanceChangeRequest{Amount: 50, Currency: "USD"})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, 150.0, result.Wallet.Balance)
+		assert.Equal(t, entity.TransactionTypeDeposit.String(), result.Transaction.Type)
+		assert.Equal(t, 50.0, result.Transaction.Amount)
+		assert.Equal(t, 150.0, result.Transaction.BalanceAfter)
+		assert.Equal(t, wallet.ID, result.Transaction.WalletID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject negative amount", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		// When
+		result, err := service.Deposit(1, &dto.BalanceChangeRequest{Amount: -10, Currency: "USD"})
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "amount must be positive", err.Error())
+		mockRepo.AssertNotCalled(t, "ApplyBalanceChange", uint(1))
+	})
+
+	t.Run("should reject non-finite amounts", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		for _, amount := range []float64{math.NaN(), math.Inf(1)} {
+			// When
+			result, err := service.Deposit(1, &dto.BalanceChangeRequest{Amount: amount, Currency: "USD"})
+
+			// Then
+			assert.EqualError(t, err, "amount must be positive")
+			assert.Nil(t, result)
+		}
+		mockRepo.AssertNotCalled(t, "ApplyBalanceChange", uint(1))
+	})
+
+	t.Run("should reject currency mismatch", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		wallet := testutil.CreateWalletFixture()
+		balance := wallet.Balance
+
+		// Mock expectations
+		mockRepo.On("ApplyBalanceChange", wallet.ID).Return(wallet, nil)
+
+		// When
+		result, err := service.Deposit(wallet.ID, &dto.BalanceChangeRequest{Amount: 10, Currency: "EUR"})
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "currency does not match wallet", err.Error())
+		assert.Equal(t, balance, wallet.Balance)
+	})
+
+	t.Run("should return error when wallet not found", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		// Mock expectations
+		mockRepo.On("ApplyBalanceChange", uint(999)).Return(nil, gorm.ErrRecordNotFound)
+
+		// When
+		result, err := service.Deposit(999, &dto.BalanceChangeRequest{Amount: 10, Currency: "USD"})
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "wallet not found", err.Error())
+	})
+}
+
+func TestWalletService_ConcurrentUpdateRetry(t *testing.T) {
+	t.Run("should retry a change that lost a race", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		wallet := testutil.CreateWalletFixture()
+		wallet.Balance = 100
+
+		// Mock expectations
+		mockRepo.On("ApplyBalanceChange", wallet.ID).Return(nil, repository.ErrConcurrentUpdate).Once()
+		mockRepo.On("ApplyBalanceChange", wallet.ID).Return(wallet, nil).Once()
+
+		// When
+		result, err := service.Deposit(wallet.ID, &dto.BalanceChangeRequest{Amount: 5, Currency: "USD"})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, 105.0, result.Wallet.Balance)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should give up after repeated conflicts", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		// Mock expectations
+		mockRepo.On("ApplyBalanceChange", uint(1)).Return(nil, repository.ErrConcurrentUpdate)
+
+		// When
+		result, err := service.Deposit(1, &dto.BalanceChangeRequest{Amount: 5, Currency: "USD"})
+
+		// Then
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "wallet was modified concurrently")
+		mockRepo.AssertNumberOfCalls(t, "ApplyBalanceChange", maxBalanceChangeAttempts)
+	})
+}
+
+func TestWalletService_Withdraw(t *testing.T) {
+	t.Run("should withdraw and record transaction", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		wallet := testutil.CreateWalletFixture()
+		wallet.Balance = 100
+
+		// Mock expectations
+		mockRepo.On("ApplyBalanceChange", wallet.ID).Return(wallet, nil)
+
+		// When
+		result, err := service.Withdraw(wallet.ID, &dto.BalanceChangeRequest{Amount: 100, Currency: "USD"})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, result.Wallet.Balance)
+		assert.Equal(t, entity.TransactionTypeWithdrawal.String(), result.Transaction.Type)
+		assert.Equal(t, 0.0, result.Transaction.BalanceAfter)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject withdrawal with insufficient funds", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		wallet := testutil.CreateWalletFixture()
+		wallet.Balance = 20
+
+		// Mock expectations
+		mockRepo.On("ApplyBalanceChange", wallet.ID).Return(wallet, nil)
+
+		// When
+		result, err := service.Withdraw(wallet.ID, &dto.BalanceChangeRequest{Amount: 20.01, Currency: "USD"})
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "insufficient funds", err.Error())
+		assert.Equal(t, 20.0, wallet.Balance)
+	})
+
+	t.Run("should reject withdrawal breaching the minimum balance", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		cfg := testutil.NewTestConfig()
+		cfg.Wallet.MinBalance = 10
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, cfg, logger)
+
+		wallet := testutil.CreateWalletFixture()
+		wallet.Balance = 50
+
+		// Mock expectations
+		mockRepo.On("ApplyBalanceChange", wallet.ID).Return(wallet, nil)
+
+		// When
+		result, err := service.Withdraw(wallet.ID, &dto.BalanceChangeRequest{Amount: 45, Currency: "USD"})
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "insufficient funds", err.Error())
+		assert.Equal(t, 50.0, wallet.Balance)
+	})
+
+	t.Run("should allow overdraft down to the configured floor", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		cfg := testutil.NewTestConfig()
+		cfg.Wallet.OverdraftLimit = 100
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, cfg, logger)
+
+		wallet := testutil.CreateWalletFixture()
+		wallet.Balance = 50
+
+		// Mock expectations
+		mockRepo.On("ApplyBalanceChange", wallet.ID).Return(wallet, nil)
+
+		// When
+		result, err := service.Withdraw(wallet.ID, &dto.BalanceChangeRequest{Amount: 150, Currency: "USD"})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, -100.0, result.Wallet.Balance)
+
+		// Going past the floor is rejected
+		_, err = service.Withdraw(wallet.ID, &dto.BalanceChangeRequest{Amount: 0.01, Currency: "USD"})
+		assert.Error(t, err)
+		assert.Equal(t, "insufficient funds", err.Error())
+	})
+
+	t.Run("should reject negative amount", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, &testutil.MockTransactionRepository{}, mockUserService, testutil.NewTestConfig(), logger)
+
+		// When
+		result, err := service.Withdraw(1, &dto.BalanceChangeRequest{Amount: -5, Currency: "USD"})
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "amount must be positive", err.Error())
+		mockRepo.AssertNotCalled(t, "ApplyBalanceChange", uint(1))
+	})
+}
+
+func TestWalletService_GetTransactions(t *testing.T) {
+	t.Run("should apply default page size", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockTransactionRepo := &testutil.MockTransactionRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, mockTransactionRepo, mockUserService, testutil.NewTestConfig(), logger)
+
+		wallet := testutil.CreateWalletFixture()
+		transactions := []entity.Transaction{
+			{ID: 2, WalletID: wallet.ID, Type: entity.TransactionTypeWithdrawal, Amount: 5, Currency: "USD"},
+			{ID: 1, WalletID: wallet.ID, Type: entity.TransactionTypeDeposit, Amount: 10, Currency: "USD"},
+		}
+
+		// Mock expectations
+		mockRepo.On("GetByID", wallet.ID).Return(wallet, nil)
+		mockTransactionRepo.On("GetByWallet", wallet.ID, &dto.TransactionFilter{Pagination: pagination.Pagination{Page: 1, PageSize: 10}}).
+			Return(transactions, int64(2), nil)
+
+		// When
+		result, err := service.GetTransactions(wallet.ID, &dto.TransactionFilter{})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Len(t, result.Data, 2)
+		assert.Equal(t, "withdrawal", result.Data[0].Type)
+		assert.Equal(t, int64(2), result.TotalCount)
+		assert.Equal(t, 1, result.Page)
+		assert.Equal(t, 10, result.PageSize)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return error when wallet not found", func(t *testing.T) {
+		// Setup
+		mockRepo := &testutil.MockWalletRepository{}
+		mockTransactionRepo := &testutil.MockTransactionRepository{}
+		mockUserService := &testutil.MockUserService{}
+		logger := testutil.NewSilentLogger()
+		service := NewWalletService(mockRepo, mockTransactionRepo, mockUserService, testutil.NewTestConfig(), logger)
+
+		// Mock expectations
+		mockRepo.On("GetByID", uint(999)).Return(nil, gorm.ErrRecordNotFound)
+
+		// When
+		result, err := service.GetTransactions(999, &dto.TransactionFilter{})
+
+		// Then
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "wallet not found", err.Error())
+		mockTransactionRepo.AssertNotCalled(t, "GetByWallet")
+	})
+}
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/i18n"
+
 	"github.com/spf13/viper"
 )
 
@@ -11,12 +16,27 @@ const (
 	DefaultStopTimeout  = 10 * time.Second
 )
 
+// redactedValue replaces secrets in Redacted copies of the config
+const redactedValue = "[REDACTED]"
+
 type Config struct {
-	Server   ServerConfig   `mapstructure:"api"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Logger   LoggerConfig   `mapstructure:"logger"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Worker   WorkerConfig   `mapstructure:"worker"`
+	Server     ServerConfig     `mapstructure:"api"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	Logger     LoggerConfig     `mapstructure:"logger"`
+	Redis      RedisConfig      `mapstructure:"redis"`
+	Worker     WorkerConfig     `mapstructure:"worker"`
+	Pagination PaginationConfig `mapstructure:"pagination"`
+	Wallet     WalletConfig     `mapstructure:"wallet"`
+	Cache      CacheConfig      `mapstructure:"cache"`
+	User       UserConfig       `mapstructure:"user"`
+	Webhook    WebhookConfig    `mapstructure:"webhook"`
+	Payment    PaymentConfig    `mapstructure:"payment"`
+	// Idempotency configures where Idempotency-Key responses are kept and for how long
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
+	// Purge configures how long soft-deleted rows are kept before the worker removes them
+	Purge PurgeConfig `mapstructure:"purge"`
+	// FeatureFlags switches optional behaviors on or off by name; see the featureflag package
+	FeatureFlags map[string]bool `mapstructure:"feature_flags"`
 }
 
 type ServerConfig struct {
@@ -25,6 +45,28 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	// DisableKeepAlives closes each connection after one request instead of keeping it
+	// open for reuse for up to IdleTimeout
+	DisableKeepAlives bool `mapstructure:"disable_keep_alives"`
+	// MaxHeaderBytes caps the size of request headers
+	MaxHeaderBytes int `mapstructure:"max_header_bytes"`
+	// MaxConnections caps simultaneous connections, idle keep-alive ones included;
+	// connections beyond it wait in the accept backlog. 0 means no limit.
+	MaxConnections int `mapstructure:"max_connections"`
+	// LogRequestBodies adds redacted request and response bodies to request logs
+	LogRequestBodies bool `mapstructure:"log_request_bodies"`
+	// Network is "tcp" to listen on Host:Port or "unix" to listen on UnixSocketPath,
+	// e.g. behind a reverse proxy on the same machine
+	Network        string `mapstructure:"network"`
+	UnixSocketPath string `mapstructure:"unix_socket_path"`
+	// EnablePprof mounts net/http/pprof under /debug/pprof. Leave it off on listeners
+	// reachable from outside, since the profiles expose internals.
+	EnablePprof bool `mapstructure:"enable_pprof"`
+	// HealthCheckTimeout bounds each dependency check behind GET /health
+	HealthCheckTimeout time.Duration `mapstructure:"health_check_timeout"`
+	// DefaultLocale is the language of error messages for clients whose Accept-Language
+	// names no supported locale: "en" or "id"
+	DefaultLocale string `mapstructure:"default_locale"`
 }
 
 type DatabaseConfig struct {
@@ -34,12 +76,24 @@ type DatabaseConfig struct {
 	Password string `mapstructure:"password"`
 	DBName   string `mapstructure:"db_name"`
 	SSLMode  string `mapstructure:"ssl_mode"`
+	// ReplicaDSN routes read-only queries to a replica when set; empty falls back to the primary.
+	ReplicaDSN         string        `mapstructure:"replica_dsn"`
+	PrepareStmt        bool          `mapstructure:"prepare_stmt"`
+	SlowQueryThreshold time.Duration `mapstructure:"slow_query_threshold"`
+	LogLevel           string        `mapstructure:"log_level"`
+	// MaxConcurrentTransactions caps transactions in flight at once; zero means no cap
+	MaxConcurrentTransactions int `mapstructure:"max_concurrent_transactions"`
+	// TransactionWaitTimeout is how long a transaction waits for a slot under the cap
+	// before failing; zero fails immediately
+	TransactionWaitTimeout time.Duration `mapstructure:"transaction_wait_timeout"`
 }
 
 type LoggerConfig struct {
 	Level      string `mapstructure:"level"`
 	Format     string `mapstructure:"format"`
 	OutputPath string `mapstructure:"output_path"`
+	// RedactKeys are JSON keys whose values are masked wherever payloads are logged
+	RedactKeys []string `mapstructure:"redact_keys"`
 }
 
 type RedisConfig struct {
@@ -47,6 +101,9 @@ type RedisConfig struct {
 	Port     int    `mapstructure:"port"`
 	Password string `mapstructure:"password"`
 	DB       int    `mapstructure:"db"`
+	// ConnectRetries is how many times startup re-pings Redis before giving up
+	ConnectRetries    int           `mapstructure:"connect_retries"`
+	ConnectRetryDelay time.Duration `mapstructure:"connect_retry_delay"`
 }
 
 type WorkerConfig struct {
@@ -54,6 +111,139 @@ type WorkerConfig struct {
 	PaymentCheckInterval time.Duration `mapstructure:"payment_check_interval"`
 	RetryMaxAttempts     int           `mapstructure:"retry_max_attempts"`
 	RetryDelay           time.Duration `mapstructure:"retry_delay"`
+	OutboxPollInterval   time.Duration `mapstructure:"outbox_poll_interval"`
+	OutboxBatchSize      int           `mapstructure:"outbox_batch_size"`
+	// BatchProcessSize is how many pending payments one batch processing run picks up
+	BatchProcessSize int `mapstructure:"batch_process_size"`
+	// ReadinessMaxPending reports the API not ready while more tasks than this wait in
+	// the queues, so autoscalers can react to a backlog; zero disables the check
+	ReadinessMaxPending int `mapstructure:"readiness_max_pending"`
+	// DrainTimeouts is how long shutdown waits for each queue's in-flight tasks before
+	// they are requeued; a queue missing here gets 8s
+	DrainTimeouts map[string]time.Duration `mapstructure:"drain_timeouts"`
+}
+
+type PaginationConfig struct {
+	DefaultPageSize int `mapstructure:"default_page_size"`
+	MaxPageSize     int `mapstructure:"max_page_size"`
+	// CursorSecret signs pagination cursors; replicas must share it. When empty, a random
+	// key is used and cursors stop working after a restart.
+	CursorSecret string `mapstructure:"cursor_secret"`
+}
+
+// placeholderCursorSecret is the value older sample configs shipped with
+const placeholderCursorSecret = "change-me"
+
+// validate rejects a cursor secret left at the sample placeholder, which anyone could
+// use to forge cursors
+func (c PaginationConfig) validate() error {
+	if c.CursorSecret == placeholderCursorSecret {
+		return fmt.Errorf("pagination.cursor_secret: replace the %q placeholder with a random secret", placeholderCursorSecret)
+	}
+	return nil
+}
+
+type WalletConfig struct {
+	// MinBalance is the balance debits may not take a wallet below
+	MinBalance float64 `mapstructure:"min_balance"`
+	// OverdraftLimit lets debits go this far below MinBalance
+	OverdraftLimit float64 `mapstructure:"overdraft_limit"`
+	// DefaultCurrency is the currency of wallets created for users by the backfill
+	DefaultCurrency string `mapstructure:"default_currency"`
+	// AutoCreate creates a missing wallet in a new payment's currency instead of rejecting the payment
+	AutoCreate bool `mapstructure:"auto_create"`
+}
+
+type CacheConfig struct {
+	// UserTTL is how long user lookups by email are cached; zero disables the cache
+	UserTTL time.Duration `mapstructure:"user_ttl"`
+	// UserNegativeTTL is how long a lookup of an unknown email is remembered
+	UserNegativeTTL time.Duration `mapstructure:"user_negative_ttl"`
+}
+
+type UserConfig struct {
+	// BlockedEmailDomains rejects registrations from these domains, e.g. disposable
+	// email providers. "*.example.com" blocks every subdomain of example.com but not
+	// example.com itself.
+	BlockedEmailDomains []string `mapstructure:"blocked_email_domains"`
+	// Password is the complexity new passwords must meet on registration and change
+	Password PasswordPolicy `mapstructure:"password"`
+}
+
+// PasswordPolicy lists the rules a new password must satisfy. Request validation
+// already requires 8 characters, so MinLength only takes effect above that.
+type PasswordPolicy struct {
+	MinLength     int  `mapstructure:"min_length"`
+	RequireUpper  bool `mapstructure:"require_upper"`
+	RequireLower  bool `mapstructure:"require_lower"`
+	RequireDigit  bool `mapstructure:"require_digit"`
+	RequireSymbol bool `mapstructure:"require_symbol"`
+}
+
+// EmailDomainBlocked reports whether email's domain is in BlockedEmailDomains, ignoring case
+func (c UserConfig) EmailDomainBlocked(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(strings.TrimSuffix(email[at+1:], "."))
+
+	for _, blocked := range c.BlockedEmailDomains {
+		blocked = strings.ToLower(strings.TrimSpace(blocked))
+		if parent, ok := strings.CutPrefix(blocked, "*."); ok {
+			if strings.HasSuffix(domain, "."+parent) {
+				return true
+			}
+			continue
+		}
+		if domain == blocked {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookConfig struct {
+	// Secrets maps a gateway name to the secret its callbacks are signed with; callbacks
+	// from gateways without a secret are rejected
+	Secrets map[string]string `mapstructure:"secrets"`
+}
+
+type PurgeConfig struct {
+	// Retention is how long a soft-deleted user or payment is kept; zero disables the
+	// periodic purge
+	Retention time.Duration `mapstructure:"retention"`
+	// Interval is how often the worker runs the purge
+	Interval time.Duration `mapstructure:"interval"`
+	// BatchSize is how many rows of a table one purge transaction removes
+	BatchSize int `mapstructure:"batch_size"`
+}
+
+type IdempotencyConfig struct {
+	// Store is "database" for durable keys or "redis" for faster, ephemeral ones
+	Store string `mapstructure:"store"`
+	// TTL is how long a key replays the response of its first request
+	TTL time.Duration `mapstructure:"ttl"`
+	// CleanupInterval is how often the worker deletes expired keys from the database
+	// store; zero disables the cleanup
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+	// CleanupBatchSize is how many expired keys one delete statement removes
+	CleanupBatchSize int `mapstructure:"cleanup_batch_size"`
+}
+
+type PaymentConfig struct {
+	// MaxActivePerUser caps how many pending payments a user may have; zero means no cap
+	MaxActivePerUser int `mapstructure:"max_active_per_user"`
+	// MaxActivePerUserOverrides replaces the cap for specific users, keyed by user ID
+	MaxActivePerUserOverrides map[string]int `mapstructure:"max_active_per_user_overrides"`
+}
+
+// MaxActivePayments returns the pending payment cap for userID, zero meaning no cap
+func (c PaymentConfig) MaxActivePayments(userID uint) int {
+	if limit, ok := c.MaxActivePerUserOverrides[strconv.FormatUint(uint64(userID), 10)]; ok {
+		return limit
+	}
+	return c.MaxActivePerUser
 }
 
 func NewConfig() (*Config, error) {
@@ -67,6 +257,15 @@ func NewConfig() (*Config, error) {
 	viper.SetDefault("api.read_timeout", "10s")
 	viper.SetDefault("api.write_timeout", "10s")
 	viper.SetDefault("api.idle_timeout", "60s")
+	viper.SetDefault("api.disable_keep_alives", false)
+	viper.SetDefault("api.max_header_bytes", 1<<20)
+	viper.SetDefault("api.max_connections", 0)
+	viper.SetDefault("api.log_request_bodies", false)
+	viper.SetDefault("api.network", "tcp")
+	viper.SetDefault("api.unix_socket_path", "")
+	viper.SetDefault("api.enable_pprof", false)
+	viper.SetDefault("api.health_check_timeout", "2s")
+	viper.SetDefault("api.default_locale", i18n.English)
 
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
@@ -74,20 +273,71 @@ func NewConfig() (*Config, error) {
 	viper.SetDefault("database.password", "postgres")
 	viper.SetDefault("database.db_name", "vibe_db")
 	viper.SetDefault("database.ssl_mode", "disable")
+	viper.SetDefault("database.replica_dsn", "")
+	viper.SetDefault("database.prepare_stmt", true)
+	viper.SetDefault("database.slow_query_threshold", "200ms")
+	viper.SetDefault("database.log_level", "warn")
+	viper.SetDefault("database.max_concurrent_transactions", 0)
+	viper.SetDefault("database.transaction_wait_timeout", "2s")
 
 	viper.SetDefault("logger.level", "info")
 	viper.SetDefault("logger.format", "json")
 	viper.SetDefault("logger.output_path", "stdout")
+	viper.SetDefault("logger.redact_keys", []string{"password", "current_password", "new_password", "token", "secret"})
 
 	viper.SetDefault("redis.host", "localhost")
 	viper.SetDefault("redis.port", 6379)
 	viper.SetDefault("redis.password", "")
 	viper.SetDefault("redis.db", 0)
+	viper.SetDefault("redis.connect_retries", 3)
+	viper.SetDefault("redis.connect_retry_delay", "1s")
 
 	viper.SetDefault("worker.concurrency", 10)
 	viper.SetDefault("worker.payment_check_interval", "5m")
 	viper.SetDefault("worker.retry_max_attempts", 3)
 	viper.SetDefault("worker.retry_delay", "30s")
+	viper.SetDefault("worker.outbox_poll_interval", "1s")
+	viper.SetDefault("worker.outbox_batch_size", 100)
+	viper.SetDefault("worker.batch_process_size", 50)
+	viper.SetDefault("worker.readiness_max_pending", 0)
+	viper.SetDefault("worker.drain_timeouts", map[string]string{
+		"critical": "30s",
+		"default":  "10s",
+		"low":      "2s",
+	})
+
+	viper.SetDefault("pagination.default_page_size", 10)
+	viper.SetDefault("pagination.max_page_size", 100)
+	viper.SetDefault("pagination.cursor_secret", "")
+
+	viper.SetDefault("wallet.min_balance", 0)
+	viper.SetDefault("wallet.overdraft_limit", 0)
+	viper.SetDefault("wallet.default_currency", "USD")
+	viper.SetDefault("wallet.auto_create", true)
+
+	viper.SetDefault("cache.user_ttl", "1m")
+	viper.SetDefault("cache.user_negative_ttl", "10s")
+
+	viper.SetDefault("user.blocked_email_domains", []string{})
+	viper.SetDefault("user.password.min_length", 8)
+	viper.SetDefault("user.password.require_upper", false)
+	viper.SetDefault("user.password.require_lower", false)
+	viper.SetDefault("user.password.require_digit", false)
+	viper.SetDefault("user.password.require_symbol", false)
+
+	viper.SetDefault("webhook.secrets", map[string]string{})
+
+	viper.SetDefault("payment.max_active_per_user", 0)
+	viper.SetDefault("payment.max_active_per_user_overrides", map[string]int{})
+	viper.SetDefault("idempotency.store", "database")
+	viper.SetDefault("idempotency.ttl", "24h")
+	viper.SetDefault("idempotency.cleanup_interval", "1h")
+	viper.SetDefault("idempotency.cleanup_batch_size", 1000)
+	viper.SetDefault("purge.retention", "720h")
+	viper.SetDefault("purge.interval", "24h")
+	viper.SetDefault("purge.batch_size", 500)
+
+	viper.SetDefault("feature_flags", map[string]bool{})
 
 	viper.AutomaticEnv()
 
@@ -101,6 +351,36 @@ func NewConfig() (*Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, err
 	}
+	if !i18n.Supported(config.Server.DefaultLocale) {
+		return nil, fmt.Errorf("api.default_locale: unsupported locale %q", config.Server.DefaultLocale)
+	}
+	if err := config.Pagination.validate(); err != nil {
+		return nil, err
+	}
 
 	return &config, nil
 }
+
+// Redacted returns a copy of the config that is safe to log: passwords and DSNs that
+// may embed credentials are masked. Empty secrets stay empty so missing values remain visible.
+func (c *Config) Redacted() Config {
+	redacted := *c
+	redacted.Database.Password = redact(c.Database.Password)
+	redacted.Database.ReplicaDSN = redact(c.Database.ReplicaDSN)
+	redacted.Redis.Password = redact(c.Redis.Password)
+	redacted.Pagination.CursorSecret = redact(c.Pagination.CursorSecret)
+	if c.Webhook.Secrets != nil {
+		redacted.Webhook.Secrets = make(map[string]string, len(c.Webhook.Secrets))
+		for gateway, secret := range c.Webhook.Secrets {
+			redacted.Webhook.Secrets[gateway] = redact(secret)
+		}
+	}
+	return redacted
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedValue
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Redacted(t *testing.T) {
+	t.Run("should mask secrets and keep other fields", func(t *testing.T) {
+		// Given
+		cfg := &Config{
+			Database: DatabaseConfig{
+				Host:       "db.internal",
+				User:       "wallet",
+				Password:   "s3cret",
+				DBName:     "wallet_db",
+				ReplicaDSN: "host=replica user=wallet password=s3cret",
+			},
+			Redis: RedisConfig{
+				Host:     "redis.internal",
+				Port:     6379,
+				Password: "r3dis",
+			},
+			Server:     ServerConfig{Port: 8080},
+			Pagination: PaginationConfig{MaxPageSize: 100, CursorSecret: "curs0r"},
+			Webhook:    WebhookConfig{Secrets: map[string]string{"simulated": "whsec"}},
+		}
+
+		// When
+		redacted := cfg.Redacted()
+
+		// Then
+		assert.Equal(t, redactedValue, redacted.Database.Password)
+		assert.Equal(t, redactedValue, redacted.Database.ReplicaDSN)
+		assert.Equal(t, redactedValue, redacted.Redis.Password)
+		assert.Equal(t, redactedValue, redacted.Pagination.CursorSecret)
+		assert.Equal(t, 100, redacted.Pagination.MaxPageSize)
+		assert.Equal(t, map[string]string{"simulated": redactedValue}, redacted.Webhook.Secrets)
+		assert.Equal(t, "db.internal", redacted.Database.Host)
+		assert.Equal(t, "wallet", redacted.Database.User)
+		assert.Equal(t, "wallet_db", redacted.Database.DBName)
+		assert.Equal(t, "redis.internal", redacted.Redis.Host)
+		assert.Equal(t, 8080, redacted.Server.Port)
+	})
+
+	t.Run("should not modify the original config", func(t *testing.T) {
+		// Given
+		cfg := &Config{
+			Database: DatabaseConfig{Password: "s3cret"},
+			Webhook:  WebhookConfig{Secrets: map[string]string{"simulated": "whsec"}},
+		}
+
+		// When
+		_ = cfg.Redacted()
+
+		// Then
+		assert.Equal(t, "s3cret", cfg.Database.Password)
+		assert.Equal(t, "whsec", cfg.Webhook.Secrets["simulated"])
+	})
+
+	t.Run("should leave empty secrets empty", func(t *testing.T) {
+		// Given
+		cfg := &Config{}
+
+		// When
+		redacted := cfg.Redacted()
+
+		// Then
+		assert.Empty(t, redacted.Redis.Password)
+		assert.Empty(t, redacted.Database.ReplicaDSN)
+	})
+}
+
+func TestPaymentConfig_MaxActivePayments(t *testing.T) {
+	// Given
+	cfg := PaymentConfig{
+		MaxActivePerUser:          5,
+		MaxActivePerUserOverrides: map[string]int{"7": 20, "8": 0},
+	}
+
+	// Then
+	assert.Equal(t, 5, cfg.MaxActivePayments(1))
+	assert.Equal(t, 20, cfg.MaxActivePayments(7))
+	assert.Equal(t, 0, cfg.MaxActivePayments(8), "an override of zero lifts the cap")
+}
+
+func TestUserConfig_EmailDomainBlocked(t *testing.T) {
+	// Given
+	cfg := UserConfig{BlockedEmailDomains: []string{"mailinator.com", "*.Tempmail.io"}}
+
+	// Then
+	assert.True(t, cfg.EmailDomainBlocked("john@mailinator.com"))
+	assert.True(t, cfg.EmailDomainBlocked("John@MailInator.COM"), "domains match regardless of case")
+	assert.True(t, cfg.EmailDomainBlocked("john@inbox.tempmail.io"))
+	assert.True(t, cfg.EmailDomainBlocked("john@a.b.TEMPMAIL.io"))
+	assert.False(t, cfg.EmailDomainBlocked("john@tempmail.io"), "a wildcard only covers subdomains")
+	assert.False(t, cfg.EmailDomainBlocked("john@sub.mailinator.com"), "an exact entry does not cover subdomains")
+	assert.False(t, cfg.EmailDomainBlocked("john@notmailinator.com"))
+	assert.False(t, cfg.EmailDomainBlocked("john@example.com"))
+	assert.False(t, UserConfig{}.EmailDomainBlocked("john@mailinator.com"))
+}
+
+func TestPaginationConfig_Validate(t *testing.T) {
+	assert.NoError(t, PaginationConfig{}.validate(), "an empty secret falls back to a per-process key")
+	assert.NoError(t, PaginationConfig{CursorSecret: "9f2c4e7a1b"}.validate())
+	assert.Error(t, PaginationConfig{CursorSecret: "change-me"}.validate())
+}
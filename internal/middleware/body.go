@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxLoggedBodySize caps how much of a body is kept for logging
+const maxLoggedBodySize = 8 << 10
+
+// bodyCapture keeps the first maxLoggedBodySize bytes written to it
+type bodyCapture struct {
+	buf        bytes.Buffer
+	truncated  bool
+	redactKeys []string
+}
+
+func (b *bodyCapture) Write(p []byte) (int, error) {
+	if room := maxLoggedBodySize - b.buf.Len(); room < len(p) {
+		b.buf.Write(p[:max(room, 0)])
+		b.truncated = true
+	} else {
+		b.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+// loggable renders the captured body for a log line. JSON is redacted; other content
+// types are summarised because sensitive values in them cannot be masked reliably.
+func (b *bodyCapture) loggable(contentType string) string {
+	if b.buf.Len() == 0 {
+		return ""
+	}
+	if b.truncated {
+		return "[body truncated]"
+	}
+	if !strings.Contains(contentType, "json") {
+		return "[non-JSON body omitted]"
+	}
+	return string(logger.RedactJSON(b.buf.Bytes(), b.redactKeys))
+}
+
+// teeBody copies everything the handler reads from body into capture, leaving the
+// stream itself untouched for the handler
+func teeBody(body io.ReadCloser, capture *bodyCapture) io.ReadCloser {
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.TeeReader(body, capture), body}
+}
+
+// bodyCaptureWriter copies the response body into capture as it is written
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	capture *bodyCapture
+}
+
+func (w *bodyCaptureWriter) Write(p []byte) (int, error) {
+	w.capture.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.capture.Write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
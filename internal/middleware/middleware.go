@@ -1,19 +1,38 @@
 package middleware
 
 import (
+	"io"
 	"net/http"
+	"strconv"
+	"sync/atomic"
 	"time"
 
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/i18n"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/requestid"
+
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-func Logger(logger *zap.Logger) gin.HandlerFunc {
+// Logger logs every request. With logBodies set it also captures the request and
+// response bodies, truncated and with the values of redactKeys masked, for debugging.
+func Logger(logger *zap.Logger, logBodies bool, redactKeys []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
 		raw := c.Request.URL.RawQuery
 
+		var requestBody, responseBody *bodyCapture
+		if logBodies {
+			requestBody = &bodyCapture{redactKeys: redactKeys}
+			if c.Request.Body != nil {
+				c.Request.Body = teeBody(c.Request.Body, requestBody)
+			}
+			responseBody = &bodyCapture{redactKeys: redactKeys}
+			c.Writer = &bodyCaptureWriter{ResponseWriter: c.Writer, capture: responseBody}
+		}
+
 		c.Next()
 
 		latency := time.Since(start)
@@ -25,42 +44,115 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		logger.Info("HTTP Request",
+		fields := []zap.Field{
 			zap.String("method", method),
 			zap.String("path", path),
 			zap.Int("status", statusCode),
 			zap.Duration("latency", latency),
 			zap.String("client_ip", clientIP),
-		)
+		}
+		if id := c.GetString(requestid.ContextKey); id != "" {
+			fields = append(fields, zap.String("request_id", id))
+		}
+		if logBodies {
+			fields = append(fields,
+				zap.String("request_body", requestBody.loggable(c.ContentType())),
+				zap.String("response_body", responseBody.loggable(c.Writer.Header().Get("Content-Type"))),
+			)
+		}
+
+		logger.Info("HTTP Request", fields...)
+	}
+}
+
+// RequestID gives every request an ID, echoed in the X-Request-ID response header and
+// in error bodies. A valid ID sent by an upstream proxy is kept so logs line up across
+// services; otherwise a new one is generated.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		c.Set(requestid.ContextKey, id)
+		c.Header(requestid.Header, id)
+		c.Next()
+	}
+}
+
+// Locale negotiates the language of error messages from Accept-Language, falling back
+// to defaultLocale when the client sends none that is supported
+func Locale(defaultLocale string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.GetHeader("Accept-Language"), defaultLocale)
+		c.Set(i18n.ContextKey, locale)
+		c.Header("Content-Language", locale)
+		c.Next()
 	}
 }
 
-func Recovery(logger *zap.Logger) gin.HandlerFunc {
+// Recovery turns a panic into a 500 and logs it with the request ID, route, method and
+// the request body, truncated and with the values of redactKeys masked, so the panic
+// can be traced back to the request that caused it
+func Recovery(logger *zap.Logger, redactKeys []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		requestBody := &bodyCapture{redactKeys: redactKeys}
+		if c.Request.Body != nil {
+			c.Request.Body = teeBody(c.Request.Body, requestBody)
+		}
+
 		defer func() {
 			if err := recover(); err != nil {
+				// The handler may have panicked before reading the body; read enough of
+				// the rest to fill the capture
+				if c.Request.Body != nil {
+					io.CopyN(io.Discard, c.Request.Body, maxLoggedBodySize+1)
+				}
 				logger.Error("Panic recovered",
 					zap.Any("error", err),
+					zap.String("request_id", c.GetString(requestid.ContextKey)),
+					zap.String("route", c.FullPath()),
 					zap.String("path", c.Request.URL.Path),
 					zap.String("method", c.Request.Method),
+					zap.String("request_body", requestBody.loggable(c.ContentType())),
+					zap.Stack("stack"),
 				)
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Internal domain error",
-				})
-				c.Abort()
+				c.AbortWithStatusJSON(http.StatusInternalServerError,
+					apperror.Body(c, apperror.CodeInternal, "Internal domain error"))
 			}
 		}()
 		c.Next()
 	}
 }
 
+// RejectWhenShuttingDown answers 503 with a Retry-After header once shuttingDown is set,
+// so requests arriving during the drain are retried elsewhere instead of failing mid-flight
+func RejectWhenShuttingDown(shuttingDown *atomic.Bool, retryAfter time.Duration) gin.HandlerFunc {
+	seconds := int(retryAfter.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	retryAfterValue := strconv.Itoa(seconds)
+
+	return func(c *gin.Context) {
+		if shuttingDown.Load() {
+			c.Header("Retry-After", retryAfterValue)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable,
+				apperror.Body(c, apperror.CodeUnavailable, "server is shutting down"))
+			return
+		}
+		c.Next()
+	}
+}
+
 func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers",
-			"Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+			"Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-None-Match, If-Modified-Since, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, Last-Modified, X-Request-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -0,0 +1,311 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/requestid"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func setupLoggerRouter(logBodies bool, handler gin.HandlerFunc) (*gin.Engine, *observer.ObservedLogs) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zapcore.InfoLevel)
+	router := gin.New()
+	router.Use(Logger(zap.New(core), logBodies, testutil.NewTestConfig().Logger.RedactKeys))
+	router.POST("/users", handler)
+	return router, logs
+}
+
+func TestLogger_Bodies(t *testing.T) {
+	t.Run("should mask passwords and still pass the body to the handler", func(t *testing.T) {
+		// Setup
+		var received string
+		router, logs := setupLoggerRouter(true, func(c *gin.Context) {
+			body, _ := io.ReadAll(c.Request.Body)
+			received = string(body)
+			c.JSON(http.StatusCreated, gin.H{"data": gin.H{"email": "john@example.com"}})
+		})
+		body := `{"email":"john@example.com","password":"hunter22"}`
+
+		// When
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, body, received)
+
+		entries := logs.FilterMessage("HTTP Request").All()
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, `{"email":"john@example.com","password":"[REDACTED]"}`, fields["request_body"])
+		assert.NotContains(t, fields["request_body"], "hunter22")
+		assert.Contains(t, fields["response_body"], "john@example.com")
+		assert.Equal(t, int64(http.StatusCreated), fields["status"])
+	})
+
+	t.Run("should mask nested password fields", func(t *testing.T) {
+		// Setup
+		router, logs := setupLoggerRouter(true, func(c *gin.Context) {
+			io.ReadAll(c.Request.Body)
+			c.Status(http.StatusNoContent)
+		})
+
+		// When
+		req := httptest.NewRequest("POST", "/users",
+			strings.NewReader(`{"user":{"current_password":"old","new_password":"new"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		// Then
+		fields := logs.FilterMessage("HTTP Request").All()[0].ContextMap()
+		assert.Equal(t, `{"user":{"current_password":"[REDACTED]","new_password":"[REDACTED]"}}`, fields["request_body"])
+	})
+
+	t.Run("should not log bodies unless enabled", func(t *testing.T) {
+		// Setup
+		router, logs := setupLoggerRouter(false, func(c *gin.Context) {
+			io.ReadAll(c.Request.Body)
+			c.Status(http.StatusNoContent)
+		})
+
+		// When
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"password":"hunter22"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		// Then
+		fields := logs.FilterMessage("HTTP Request").All()[0].ContextMap()
+		assert.NotContains(t, fields, "request_body")
+		assert.NotContains(t, fields, "response_body")
+		assert.Equal(t, "POST", fields["method"])
+	})
+
+	t.Run("should omit non-JSON bodies", func(t *testing.T) {
+		// Setup
+		router, logs := setupLoggerRouter(true, func(c *gin.Context) {
+			io.ReadAll(c.Request.Body)
+			c.Status(http.StatusNoContent)
+		})
+
+		// When
+		req := httptest.NewRequest("POST", "/users", strings.NewReader("password=hunter22"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		// Then
+		fields := logs.FilterMessage("HTTP Request").All()[0].ContextMap()
+		assert.Equal(t, "[non-JSON body omitted]", fields["request_body"])
+	})
+}
+
+func TestBodyCapture_Truncates(t *testing.T) {
+	capture := &bodyCapture{}
+
+	n, err := capture.Write([]byte(strings.Repeat("a", maxLoggedBodySize+10)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, maxLoggedBodySize+10, n)
+	assert.Equal(t, maxLoggedBodySize, capture.buf.Len())
+	assert.Equal(t, "[body truncated]", capture.loggable("application/json"))
+}
+
+func TestRecovery(t *testing.T) {
+	t.Run("should log the panic with the request context", func(t *testing.T) {
+		// Setup
+		gin.SetMode(gin.TestMode)
+		core, logs := observer.New(zapcore.InfoLevel)
+		router := gin.New()
+		router.Use(RequestID())
+		router.Use(Recovery(zap.New(core), testutil.NewTestConfig().Logger.RedactKeys))
+		router.PUT("/users/:id/password", func(c *gin.Context) {
+			panic("boom")
+		})
+
+		// Given
+		id := requestid.New()
+		req := httptest.NewRequest("PUT", "/users/7/password",
+			strings.NewReader(`{"current_password":"old","new_password":"new"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(requestid.Header, id)
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		entries := logs.FilterMessage("Panic recovered").All()
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "boom", fields["error"])
+		assert.Equal(t, id, fields["request_id"])
+		assert.Equal(t, "/users/:id/password", fields["route"])
+		assert.Equal(t, "/users/7/password", fields["path"])
+		assert.Equal(t, "PUT", fields["method"])
+		// the handler never read the body, so recovery read it for the log
+		assert.Equal(t, `{"current_password":"[REDACTED]","new_password":"[REDACTED]"}`, fields["request_body"])
+		assert.NotEmpty(t, fields["stack"])
+	})
+}
+
+func TestRejectWhenShuttingDown(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	var shuttingDown atomic.Bool
+	router := gin.New()
+	router.Use(RejectWhenShuttingDown(&shuttingDown, 10*time.Second))
+	router.GET("/payments", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": "ok"})
+	})
+
+	t.Run("should serve requests before shutdown begins", func(t *testing.T) {
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/payments", nil))
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Retry-After"))
+	})
+
+	t.Run("should reject new requests while shutting down", func(t *testing.T) {
+		// Given
+		shuttingDown.Store(true)
+		defer shuttingDown.Store(false)
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/payments", nil))
+
+		// Then
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "10", w.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{"error":"server is shutting down","code":"SERVICE_UNAVAILABLE"}`, w.Body.String())
+	})
+
+	t.Run("should serve requests again once the flag is cleared", func(t *testing.T) {
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/payments", nil))
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func setupRequestIDRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/users/:id", func(c *gin.Context) {
+		apperror.Message(c, http.StatusNotFound, apperror.CodeUserNotFound, "User not found")
+	})
+	return router
+}
+
+func TestRequestID(t *testing.T) {
+	t.Run("should put the header's request ID in error bodies", func(t *testing.T) {
+		// Setup
+		router := setupRequestIDRouter()
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/users/99", nil))
+
+		// Then
+		id := w.Header().Get(requestid.Header)
+		require.Len(t, id, 32)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, id, body["request_id"])
+		assert.Equal(t, apperror.CodeUserNotFound, body["code"])
+	})
+
+	t.Run("should keep a valid ID from upstream", func(t *testing.T) {
+		// Setup
+		router := setupRequestIDRouter()
+		req := httptest.NewRequest("GET", "/users/99", nil)
+		req.Header.Set(requestid.Header, "edge-7f3a.1")
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, "edge-7f3a.1", w.Header().Get(requestid.Header))
+		assert.Contains(t, w.Body.String(), `"request_id":"edge-7f3a.1"`)
+	})
+
+	t.Run("should replace an unsafe ID from upstream", func(t *testing.T) {
+		// Setup
+		router := setupRequestIDRouter()
+		req := httptest.NewRequest("GET", "/users/99", nil)
+		req.Header.Set(requestid.Header, "bad id\" injected")
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Then
+		id := w.Header().Get(requestid.Header)
+		assert.NotEqual(t, "bad id\" injected", id)
+		assert.True(t, requestid.Valid(id))
+	})
+}
+
+func TestLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		defaultLocale  string
+		acceptLanguage string
+		message        string
+		locale         string
+	}{
+		{name: "supported locale", defaultLocale: "en", acceptLanguage: "id-ID,id;q=0.9", message: "Pengguna tidak ditemukan", locale: "id"},
+		{name: "unsupported locale falls back", defaultLocale: "en", acceptLanguage: "fr-FR", message: "User not found", locale: "en"},
+		{name: "no header uses the default", defaultLocale: "id", acceptLanguage: "", message: "Pengguna tidak ditemukan", locale: "id"},
+		{name: "explicit English over the default", defaultLocale: "id", acceptLanguage: "en-US", message: "User not found", locale: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(Locale(tt.defaultLocale))
+			router.GET("/users/:id", func(c *gin.Context) {
+				apperror.Message(c, http.StatusNotFound, apperror.CodeUserNotFound, "User not found")
+			})
+			req := httptest.NewRequest("GET", "/users/99", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+
+			// When
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Then
+			assert.JSONEq(t, `{"error":"`+tt.message+`","code":"USER_NOT_FOUND"}`, w.Body.String())
+			assert.Equal(t, tt.locale, w.Header().Get("Content-Language"))
+		})
+	}
+}
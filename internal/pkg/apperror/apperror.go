@@ -0,0 +1,155 @@
+// Package apperror attaches stable, machine-readable codes to domain errors and writes
+// the JSON error envelope handlers return:
+//
+//	{"error": "<message>", "code": "<CODE>", "request_id": "<ID>"}
+//
+// Messages are for people and may change; clients should branch on the code. The
+// request ID matches the X-Request-ID response header and the request's log lines.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/i18n"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/requestid"
+)
+
+// Codes for the domain errors services return
+const (
+	CodeUserNotFound             = "USER_NOT_FOUND"
+	CodeEmailExists              = "EMAIL_EXISTS"
+	CodeCurrentPasswordIncorrect = "CURRENT_PASSWORD_INCORRECT"
+	CodeUserNotDeleted           = "USER_NOT_DELETED"
+	CodeInvalidName              = "INVALID_NAME"
+	CodeEmailDomainBlocked       = "EMAIL_DOMAIN_BLOCKED"
+	CodeWeakPassword             = "WEAK_PASSWORD"
+
+	CodePaymentNotFound         = "PAYMENT_NOT_FOUND"
+	CodeInvalidAmount           = "INVALID_AMOUNT"
+	CodeAmountPrecision         = "AMOUNT_PRECISION"
+	CodeActivePaymentLimit      = "ACTIVE_PAYMENT_LIMIT"
+	CodeInvalidPaymentStatus    = "INVALID_PAYMENT_STATUS"
+	CodePaymentStatusLocked     = "PAYMENT_STATUS_LOCKED"
+	CodeInvalidStatusTransition = "INVALID_STATUS_TRANSITION"
+	CodeReceiptNotAvailable     = "RECEIPT_NOT_AVAILABLE"
+	CodePaymentNotRefundable    = "PAYMENT_NOT_REFUNDABLE"
+	CodeRefundExceedsAmount     = "REFUND_EXCEEDS_AMOUNT"
+	CodePaymentNotReprocessable = "PAYMENT_NOT_REPROCESSABLE"
+	CodeSchedulerUnavailable    = "SCHEDULER_UNAVAILABLE"
+	CodeInvalidGatewayEvent     = "INVALID_GATEWAY_EVENT"
+	CodeEmptyUpdate             = "EMPTY_UPDATE"
+
+	CodeWalletNotFound      = "WALLET_NOT_FOUND"
+	CodeInsufficientFunds   = "INSUFFICIENT_FUNDS"
+	CodeCurrencyMismatch    = "CURRENCY_MISMATCH"
+	CodeUnsupportedCurrency = "UNSUPPORTED_CURRENCY"
+	CodeConcurrentUpdate    = "CONCURRENT_UPDATE"
+	CodeCursorRequiresSort  = "CURSOR_REQUIRES_DEFAULT_SORT"
+	CodeInvalidCursor       = "INVALID_CURSOR"
+	CodeTooManyTransactions = "TOO_MANY_TRANSACTIONS"
+
+	CodeInvalidTimezone = "INVALID_TIMEZONE"
+	CodeInvalidFields   = "INVALID_FIELDS"
+)
+
+// Codes for failures handlers detect themselves, before or around the service call
+const (
+	CodeInvalidRequest           = "INVALID_REQUEST"
+	CodeValidationFailed         = "VALIDATION_FAILED"
+	CodeInvalidID                = "INVALID_ID"
+	CodeNotFound                 = "NOT_FOUND"
+	CodeMethodNotAllowed         = "METHOD_NOT_ALLOWED"
+	CodeUnauthorized             = "UNAUTHORIZED"
+	CodeInvalidSignature         = "INVALID_SIGNATURE"
+	CodePayloadTooLarge          = "PAYLOAD_TOO_LARGE"
+	CodeUnavailable              = "SERVICE_UNAVAILABLE"
+	CodeInternal                 = "INTERNAL_ERROR"
+	CodeIdempotencyKeyReused     = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInProgress = "IDEMPOTENCY_KEY_IN_PROGRESS"
+)
+
+// Error is a domain error with a code. Handlers branch on the code; the message is
+// only shown to the client.
+type Error struct {
+	Code    string
+	Message string
+}
+
+// New returns an error carrying code and message
+func New(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Code returns the code attached to err, looking through wrapped errors, or "" when it
+// has none
+func Code(err error) string {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return ""
+}
+
+// CodeOf returns the code attached to err, looking through wrapped errors. Errors without
+// one get a code derived from the response status, e.g. NOT_FOUND for 404.
+func CodeOf(status int, err error) string {
+	if code := Code(err); code != "" {
+		return code
+	}
+	return StatusCode(status)
+}
+
+// StatusCode derives a fallback code from an HTTP status
+func StatusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case http.StatusInternalServerError:
+		return CodeInternal
+	}
+	text := http.StatusText(status)
+	if text == "" {
+		return CodeInternal
+	}
+	return strings.ToUpper(strings.ReplaceAll(text, " ", "_"))
+}
+
+// Context is the part of gin.Context needed to find the request ID
+type Context interface {
+	GetString(key string) string
+}
+
+// Responder is the part of gin.Context needed to write a response
+type Responder interface {
+	Context
+	JSON(code int, obj any)
+}
+
+// Body builds the error envelope, including the request ID when ctx has one. The
+// message is translated to the locale negotiated for the request, if any.
+func Body(ctx Context, code, message string) map[string]any {
+	message = i18n.Translate(ctx.GetString(i18n.ContextKey), message)
+	body := map[string]any{"error": message, "code": code}
+	if id := ctx.GetString(requestid.ContextKey); id != "" {
+		body["request_id"] = id
+	}
+	return body
+}
+
+// JSON writes err's message and code with the given status
+func JSON(ctx Responder, status int, err error) {
+	ctx.JSON(status, Body(ctx, CodeOf(status, err), err.Error()))
+}
+
+// Message writes a handler's own message with an explicit code
+func Message(ctx Responder, status int, code, message string) {
+	ctx.JSON(status, Body(ctx, code, message))
+}
@@ -0,0 +1,98 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/requestid"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	status int
+	body   any
+	keys   map[string]string
+}
+
+func (r *recorder) GetString(key string) string {
+	return r.keys[key]
+}
+
+func (r *recorder) JSON(code int, obj any) {
+	r.status = code
+	r.body = obj
+}
+
+func TestCodeOf(t *testing.T) {
+	t.Run("should return the code of a typed error", func(t *testing.T) {
+		// Given
+		err := New(CodeWalletNotFound, "wallet not found")
+
+		// When
+		code := CodeOf(http.StatusNotFound, err)
+
+		// Then
+		assert.Equal(t, CodeWalletNotFound, code)
+		assert.Equal(t, "wallet not found", err.Error())
+	})
+
+	t.Run("should find the code through wrapping", func(t *testing.T) {
+		// Given
+		err := fmt.Errorf("withdraw: %w", New(CodeInsufficientFunds, "insufficient funds"))
+
+		// When
+		code := CodeOf(http.StatusUnprocessableEntity, err)
+
+		// Then
+		assert.Equal(t, CodeInsufficientFunds, code)
+	})
+
+	t.Run("should fall back to the status for untyped errors", func(t *testing.T) {
+		assert.Equal(t, CodeInvalidRequest, CodeOf(http.StatusBadRequest, errors.New("bad")))
+		assert.Equal(t, CodeValidationFailed, CodeOf(http.StatusUnprocessableEntity, errors.New("bad")))
+		assert.Equal(t, CodeInternal, CodeOf(http.StatusInternalServerError, errors.New("boom")))
+		assert.Equal(t, "NOT_FOUND", CodeOf(http.StatusNotFound, errors.New("missing")))
+		assert.Equal(t, "TOO_MANY_REQUESTS", CodeOf(http.StatusTooManyRequests, errors.New("slow down")))
+		assert.Equal(t, CodeInternal, CodeOf(599, errors.New("odd")))
+	})
+}
+
+func TestJSON(t *testing.T) {
+	t.Run("should write the message and code", func(t *testing.T) {
+		// Setup
+		r := &recorder{}
+
+		// When
+		JSON(r, http.StatusConflict, New(CodeEmailExists, "email already exists"))
+
+		// Then
+		assert.Equal(t, http.StatusConflict, r.status)
+		assert.Equal(t, map[string]any{"error": "email already exists", "code": CodeEmailExists}, r.body)
+	})
+
+	t.Run("should write a handler message with an explicit code", func(t *testing.T) {
+		// Setup
+		r := &recorder{}
+
+		// When
+		Message(r, http.StatusBadRequest, CodeInvalidID, "Invalid user ID")
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, r.status)
+		assert.Equal(t, map[string]any{"error": "Invalid user ID", "code": CodeInvalidID}, r.body)
+	})
+
+	t.Run("should include the request ID when the context has one", func(t *testing.T) {
+		// Setup
+		r := &recorder{keys: map[string]string{requestid.ContextKey: "req-123"}}
+
+		// When
+		JSON(r, http.StatusNotFound, New(CodeUserNotFound, "user not found"))
+
+		// Then
+		assert.Equal(t, map[string]any{"error": "user not found", "code": CodeUserNotFound, "request_id": "req-123"}, r.body)
+	})
+}
@@ -1,20 +1,31 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
 	userEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	walletEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/idempotency"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/outbox"
 
+	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 )
 
+// nowUTC stamps gorm-managed timestamps in UTC whatever the server's zone
+func nowUTC() time.Time {
+	return time.Now().UTC()
+}
+
 func NewDatabase(cfg *config.Config, log *zap.Logger) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=UTC",
 		cfg.Database.Host,
 		cfg.Database.User,
 		cfg.Database.Password,
@@ -24,7 +35,13 @@ func NewDatabase(cfg *config.Config, log *zap.Logger) (*gorm.DB, error) {
 	)
 
 	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
+		Logger: newGormZapLogger(
+			log,
+			parseLogLevel(cfg.Database.LogLevel),
+			cfg.Database.SlowQueryThreshold,
+		),
+		PrepareStmt: cfg.Database.PrepareStmt,
+		NowFunc:     nowUTC,
 	}
 
 	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
@@ -36,12 +53,64 @@ func NewDatabase(cfg *config.Config, log *zap.Logger) (*gorm.DB, error) {
 	err = db.AutoMigrate(
 		&userEntity.User{},
 		&entity.Payment{},
+		&entity.PaymentTag{},
+		&entity.ProcessedEvent{},
+		&entity.PaymentStatusChange{},
+		&entity.PaymentSequence{},
+		&walletEntity.Wallet{},
+		&walletEntity.Transaction{},
+		&outbox.Message{},
+		&idempotency.Key{},
 	)
 	if err != nil {
 		log.Error("Failed to migrate database", zap.Error(err))
 		return nil, err
 	}
 
-	log.Info("Database connected and migrated successfully")
+	if cfg.Database.ReplicaDSN != "" {
+		if err := RegisterReadReplica(db, postgres.Open(cfg.Database.ReplicaDSN)); err != nil {
+			log.Error("Failed to register read replica", zap.Error(err))
+			return nil, err
+		}
+		log.Info("Read replica registered for queries")
+	}
+
+	if limit := cfg.Database.MaxConcurrentTransactions; limit > 0 {
+		if err := db.Use(NewTransactionLimiter(limit, cfg.Database.TransactionWaitTimeout)); err != nil {
+			log.Error("Failed to register transaction limiter", zap.Error(err))
+			return nil, err
+		}
+		log.Info("Concurrent transactions capped",
+			zap.Int("max", limit),
+			zap.Duration("wait_timeout", cfg.Database.TransactionWaitTimeout))
+	}
+
+	log.Info("Database connected",
+		zap.String("host", cfg.Database.Host),
+		zap.Int("port", cfg.Database.Port),
+		zap.String("db_name", cfg.Database.DBName))
 	return db, nil
 }
+
+// CloseOnStop closes the primary connection pool when the app stops
+func CloseOnStop(lifecycle fx.Lifecycle, db *gorm.DB, log *zap.Logger) {
+	lifecycle.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			log.Info("Closing database connections")
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		},
+	})
+}
+
+// RegisterReadReplica routes read-only queries (First, Find, Count, Raw, ...) to the
+// given replica while writes and transactions keep using the primary connection.
+func RegisterReadReplica(db *gorm.DB, replica gorm.Dialector) error {
+	return db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: []gorm.Dialector{replica},
+		Policy:   dbresolver.RandomPolicy{},
+	}))
+}
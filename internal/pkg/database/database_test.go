@@ -0,0 +1,60 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/repository"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openSQLite(t *testing.T, path string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&entity.User{}))
+	return db
+}
+
+func TestRegisterReadReplica(t *testing.T) {
+	t.Run("should route reads to replica and writes to primary", func(t *testing.T) {
+		dir := t.TempDir()
+		primaryPath := filepath.Join(dir, "primary.db")
+		replicaPath := filepath.Join(dir, "replica.db")
+
+		primary := openSQLite(t, primaryPath)
+		replica := openSQLite(t, replicaPath)
+
+		// Seed a row that only exists on the replica
+		replicaUser := &entity.User{Name: "Replica User", Email: "replica@example.com", Password: "hashed"}
+		require.NoError(t, replica.Create(replicaUser).Error)
+
+		require.NoError(t, RegisterReadReplica(primary, sqlite.Open(replicaPath)))
+		repo := repository.NewUserRepository(primary, testutil.NewSilentLogger())
+
+		// Reads go to the replica
+		found, err := repo.GetByID(replicaUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "replica@example.com", found.Email)
+
+		// Writes go to the primary
+		primaryUser := &entity.User{Name: "Primary User", Email: "primary@example.com", Password: "hashed"}
+		require.NoError(t, repo.Create(primaryUser))
+
+		var primaryCount, replicaCount int64
+		require.NoError(t, openSQLite(t, primaryPath).Model(&entity.User{}).
+			Where("email = ?", "primary@example.com").Count(&primaryCount).Error)
+		require.NoError(t, replica.Model(&entity.User{}).
+			Where("email = ?", "primary@example.com").Count(&replicaCount).Error)
+		assert.Equal(t, int64(1), primaryCount)
+		assert.Equal(t, int64(0), replicaCount)
+	})
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+)
+
+// ErrTooManyTransactions is returned when no transaction slot frees up in time
+var ErrTooManyTransactions = apperror.New(apperror.CodeTooManyTransactions, "too many concurrent transactions")
+
+const transactionLimiterName = "transaction_limiter"
+
+// TransactionLimiter caps how many WithTransaction calls run at once against a DB, so
+// bursts of transactional work cannot take every pooled connection. It is a gorm plugin:
+// register it with db.Use and WithTransaction picks it up. Transactions must not nest,
+// since an inner call would wait for a slot its caller holds.
+type TransactionLimiter struct {
+	slots chan struct{}
+	wait  time.Duration
+}
+
+// NewTransactionLimiter allows max transactions at once. Callers beyond that wait up to
+// wait for a slot; with a zero wait they fail immediately.
+func NewTransactionLimiter(max int, wait time.Duration) *TransactionLimiter {
+	return &TransactionLimiter{
+		slots: make(chan struct{}, max),
+		wait:  wait,
+	}
+}
+
+func (l *TransactionLimiter) Name() string {
+	return transactionLimiterName
+}
+
+func (l *TransactionLimiter) Initialize(*gorm.DB) error {
+	return nil
+}
+
+// InFlight returns how many transactions currently hold a slot
+func (l *TransactionLimiter) InFlight() int {
+	return len(l.slots)
+}
+
+func (l *TransactionLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	if l.wait <= 0 {
+		return ErrTooManyTransactions
+	}
+
+	timer := time.NewTimer(l.wait)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return ErrTooManyTransactions
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *TransactionLimiter) release() {
+	<-l.slots
+}
+
+// transactionLimiter returns the limiter registered on db, if any
+func transactionLimiter(db *gorm.DB) (*TransactionLimiter, bool) {
+	limiter, ok := db.Config.Plugins[transactionLimiterName].(*TransactionLimiter)
+	return limiter, ok
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupLimitedDB opens a file database, which unlike :memory: lets several connections
+// hold transactions at once, and registers a limiter on it
+func setupLimitedDB(t *testing.T, max int, wait time.Duration) (*gorm.DB, *TransactionLimiter) {
+	dsn := filepath.Join(t.TempDir(), "limiter.db") + "?_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+
+	limiter := NewTransactionLimiter(max, wait)
+	require.NoError(t, db.Use(limiter))
+	return db, limiter
+}
+
+func TestTransactionLimiter(t *testing.T) {
+	t.Run("should never run more transactions than the cap", func(t *testing.T) {
+		// Setup
+		const limit, workers = 3, 12
+		db, limiter := setupLimitedDB(t, limit, 5*time.Second)
+
+		// When
+		var inFlight, peak atomic.Int32
+		var wg sync.WaitGroup
+		errs := make(chan error, workers)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- WithTransaction(context.Background(), db, func(tx *gorm.DB) error {
+					current := inFlight.Add(1)
+					defer inFlight.Add(-1)
+					for {
+						seen := peak.Load()
+						if current <= seen || peak.CompareAndSwap(seen, current) {
+							break
+						}
+					}
+					time.Sleep(20 * time.Millisecond)
+					return nil
+				})
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		// Then: every transaction ran, queued behind the cap rather than failing
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+		assert.LessOrEqual(t, peak.Load(), int32(limit))
+		assert.Positive(t, peak.Load())
+		assert.Zero(t, limiter.InFlight())
+	})
+
+	t.Run("should fail fast when full and the wait is zero", func(t *testing.T) {
+		// Setup
+		db, limiter := setupLimitedDB(t, 1, 0)
+		holding, done := make(chan struct{}), make(chan struct{})
+		go func() {
+			_ = WithTransaction(context.Background(), db, func(tx *gorm.DB) error {
+				close(holding)
+				<-done
+				return nil
+			})
+		}()
+		<-holding
+
+		// When
+		called := false
+		err := WithTransaction(context.Background(), db, func(tx *gorm.DB) error {
+			called = true
+			return nil
+		})
+
+		// Then
+		assert.ErrorIs(t, err, ErrTooManyTransactions)
+		assert.False(t, called)
+		assert.Equal(t, 1, limiter.InFlight())
+
+		// When the slot frees up, the next transaction runs
+		close(done)
+		require.Eventually(t, func() bool { return limiter.InFlight() == 0 }, time.Second, time.Millisecond)
+		assert.NoError(t, WithTransaction(context.Background(), db, func(tx *gorm.DB) error { return nil }))
+	})
+
+	t.Run("should give up after the wait timeout", func(t *testing.T) {
+		// Setup
+		db, _ := setupLimitedDB(t, 1, 20*time.Millisecond)
+		holding, done := make(chan struct{}), make(chan struct{})
+		defer close(done)
+		go func() {
+			_ = WithTransaction(context.Background(), db, func(tx *gorm.DB) error {
+				close(holding)
+				<-done
+				return nil
+			})
+		}()
+		<-holding
+
+		// When
+		start := time.Now()
+		err := WithTransaction(context.Background(), db, func(tx *gorm.DB) error { return nil })
+
+		// Then
+		assert.ErrorIs(t, err, ErrTooManyTransactions)
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+
+	t.Run("should stop waiting when the context is canceled", func(t *testing.T) {
+		// Setup
+		db, _ := setupLimitedDB(t, 1, time.Minute)
+		holding, done := make(chan struct{}), make(chan struct{})
+		defer close(done)
+		go func() {
+			_ = WithTransaction(context.Background(), db, func(tx *gorm.DB) error {
+				close(holding)
+				<-done
+				return nil
+			})
+		}()
+		<-holding
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// When
+		err := WithTransaction(ctx, db, func(tx *gorm.DB) error { return nil })
+
+		// Then
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
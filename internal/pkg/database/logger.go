@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// gormZapLogger bridges gorm's logger.Interface to the application zap logger
+type gormZapLogger struct {
+	logger        *zap.Logger
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newGormZapLogger(log *zap.Logger, level logger.LogLevel, slowThreshold time.Duration) logger.Interface {
+	return &gormZapLogger{
+		logger:        log.Named("gorm"),
+		level:         level,
+		slowThreshold: slowThreshold,
+	}
+}
+
+// parseLogLevel maps DatabaseConfig.LogLevel to a gorm log level, defaulting to warn
+func parseLogLevel(level string) logger.LogLevel {
+	switch strings.ToLower(level) {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "info":
+		return logger.Info
+	default:
+		return logger.Warn
+	}
+}
+
+func (l *gormZapLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *gormZapLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		l.logger.Info(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *gormZapLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		l.logger.Warn(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *gormZapLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		l.logger.Error(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace logs failed queries at error level, queries that take at least slowThreshold
+// at warn level and, in info mode, every other query at debug level.
+// A slow threshold of zero reports every query as slow; a negative one disables it.
+func (l *gormZapLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		l.logger.Error("Query failed",
+			zap.String("sql", sql),
+			zap.Duration("duration", elapsed),
+			zap.Int64("rows", rows),
+			zap.Error(err))
+	case l.slowThreshold >= 0 && elapsed >= l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		l.logger.Warn("Slow query",
+			zap.String("sql", sql),
+			zap.Duration("duration", elapsed),
+			zap.Duration("threshold", l.slowThreshold),
+			zap.Int64("rows", rows))
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		l.logger.Debug("Query executed",
+			zap.String("sql", sql),
+			zap.Duration("duration", elapsed),
+			zap.Int64("rows", rows))
+	}
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormZapLogger_SlowQuery(t *testing.T) {
+	t.Run("should log slow query at warn level", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+			Logger: newGormZapLogger(zap.New(core), logger.Warn, 0),
+		})
+		require.NoError(t, err)
+
+		var result int
+		require.NoError(t, db.Raw("SELECT 1").Scan(&result).Error)
+
+		entries := logs.FilterMessage("Slow query").All()
+		require.NotEmpty(t, entries)
+		assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+		assert.Equal(t, "SELECT 1", entries[0].ContextMap()["sql"])
+		assert.Contains(t, entries[0].ContextMap(), "duration")
+	})
+
+	t.Run("should not log queries under the threshold", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+			Logger: newGormZapLogger(zap.New(core), logger.Warn, time.Hour),
+		})
+		require.NoError(t, err)
+
+		_ = db.Exec("SELECT 1")
+
+		assert.Empty(t, logs.FilterMessage("Slow query").All())
+	})
+}
+
+func TestGormZapLogger_FailedQuery(t *testing.T) {
+	t.Run("should log failed query through zap at error level", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+			Logger: newGormZapLogger(zap.New(core), logger.Error, time.Hour),
+		})
+		require.NoError(t, err)
+
+		err = db.Exec("SELECT * FROM missing_table").Error
+		require.Error(t, err)
+
+		entries := logs.FilterMessage("Query failed").All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.ErrorLevel, entries[0].Level)
+		assert.Equal(t, "SELECT * FROM missing_table", entries[0].ContextMap()["sql"])
+		assert.Contains(t, entries[0].ContextMap()["error"], "missing_table")
+	})
+
+	t.Run("should not log anything in silent mode", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+			Logger: newGormZapLogger(zap.New(core), logger.Silent, 0),
+		})
+		require.NoError(t, err)
+
+		_ = db.Exec("SELECT * FROM missing_table")
+
+		assert.Zero(t, logs.Len())
+	})
+}
+
+func TestParseLogLevel(t *testing.T) {
+	assert.Equal(t, logger.Silent, parseLogLevel("silent"))
+	assert.Equal(t, logger.Error, parseLogLevel("ERROR"))
+	assert.Equal(t, logger.Warn, parseLogLevel("warn"))
+	assert.Equal(t, logger.Info, parseLogLevel("info"))
+	assert.Equal(t, logger.Warn, parseLogLevel(""))
+}
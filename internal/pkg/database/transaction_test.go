@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func countUsers(t *testing.T, db *gorm.DB) int64 {
+	var count int64
+	require.NoError(t, db.Model(&entity.User{}).Count(&count).Error)
+	return count
+}
+
+func TestWithTransaction(t *testing.T) {
+	t.Run("should commit on success", func(t *testing.T) {
+		db, err := testutil.SetupTestDB()
+		require.NoError(t, err)
+		testutil.WithCleanDB(t, db)
+
+		err = WithTransaction(context.Background(), db, func(tx *gorm.DB) error {
+			if err := tx.Create(&entity.User{Name: "A", Email: "a@example.com", Password: "x"}).Error; err != nil {
+				return err
+			}
+			return tx.Create(&entity.User{Name: "B", Email: "b@example.com", Password: "x"}).Error
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), countUsers(t, db))
+	})
+
+	t.Run("should rollback on error", func(t *testing.T) {
+		db, err := testutil.SetupTestDB()
+		require.NoError(t, err)
+		testutil.WithCleanDB(t, db)
+
+		expectedErr := errors.New("second step failed")
+		err = WithTransaction(context.Background(), db, func(tx *gorm.DB) error {
+			if err := tx.Create(&entity.User{Name: "A", Email: "a@example.com", Password: "x"}).Error; err != nil {
+				return err
+			}
+			return expectedErr
+		})
+
+		assert.ErrorIs(t, err, expectedErr)
+		assert.Equal(t, int64(0), countUsers(t, db))
+	})
+
+	t.Run("should rollback and re-panic on panic", func(t *testing.T) {
+		db, err := testutil.SetupTestDB()
+		require.NoError(t, err)
+		testutil.WithCleanDB(t, db)
+
+		assert.PanicsWithValue(t, "boom", func() {
+			_ = WithTransaction(context.Background(), db, func(tx *gorm.DB) error {
+				tx.Create(&entity.User{Name: "A", Email: "a@example.com", Password: "x"})
+				panic("boom")
+			})
+		})
+		assert.Equal(t, int64(0), countUsers(t, db))
+	})
+}
@@ -0,0 +1,120 @@
+// Package featureflag toggles behaviors at runtime. Flags start from the feature_flags
+// config section and can be overridden while the process runs.
+package featureflag
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+)
+
+const (
+	// ScheduleOnCreate requests processing for a payment as soon as it is created
+	ScheduleOnCreate = "schedule_on_create"
+	// UserCache serves user lookups by email from the in-memory cache
+	UserCache = "user_cache"
+)
+
+// defaults apply to known flags the config leaves out; unknown flags are disabled
+var defaults = map[string]bool{
+	ScheduleOnCreate: true,
+	UserCache:        true,
+}
+
+// Flags answers whether a flag is enabled. Runtime overrides take precedence over the
+// config, which takes precedence over the defaults. Overrides are held in memory, so they
+// apply to the current process only and are lost on restart.
+type Flags struct {
+	mu         sync.RWMutex
+	configured map[string]bool
+	overrides  map[string]bool
+}
+
+func New(cfg *config.Config) *Flags {
+	return &Flags{
+		configured: configuredFlags(cfg),
+		overrides:  make(map[string]bool),
+	}
+}
+
+func configuredFlags(cfg *config.Config) map[string]bool {
+	configured := make(map[string]bool, len(defaults)+len(cfg.FeatureFlags))
+	for name, enabled := range defaults {
+		configured[name] = enabled
+	}
+	for name, enabled := range cfg.FeatureFlags {
+		configured[name] = enabled
+	}
+	return configured
+}
+
+// Reload replaces the configured values with those in cfg. Runtime overrides are kept and
+// still take precedence.
+func (f *Flags) Reload(cfg *config.Config) {
+	configured := configuredFlags(cfg)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.configured = configured
+}
+
+// IsEnabled reports whether the named flag is on
+func (f *Flags) IsEnabled(name string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if enabled, ok := f.overrides[name]; ok {
+		return enabled
+	}
+	return f.configured[name]
+}
+
+// Known reports whether name is a default or configured flag
+func (f *Flags) Known(name string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	_, ok := f.configured[name]
+	return ok
+}
+
+// Set overrides the named flag until Reset is called or the process restarts
+func (f *Flags) Set(name string, enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.overrides[name] = enabled
+}
+
+// Reset drops the runtime override so the flag falls back to its configured value
+func (f *Flags) Reset(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.overrides, name)
+}
+
+// Names lists every known flag: the defaults, configured flags and overrides
+func (f *Flags) Names() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	names := make([]string, 0, len(f.configured)+len(f.overrides))
+	for name := range f.configured {
+		names = append(names, name)
+	}
+	for name := range f.overrides {
+		if _, ok := f.configured[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// All returns the effective value of every known flag
+func (f *Flags) All() map[string]bool {
+	all := make(map[string]bool)
+	for _, name := range f.Names() {
+		all[name] = f.IsEnabled(name)
+	}
+	return all
+}
@@ -0,0 +1,92 @@
+package featureflag
+
+import (
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlags_IsEnabled(t *testing.T) {
+	t.Run("should fall back to defaults for flags missing from config", func(t *testing.T) {
+		// Given
+		flags := New(&config.Config{})
+
+		// Then
+		assert.True(t, flags.IsEnabled(ScheduleOnCreate))
+		assert.True(t, flags.IsEnabled(UserCache))
+	})
+
+	t.Run("should prefer configured values over defaults", func(t *testing.T) {
+		// Given
+		flags := New(&config.Config{FeatureFlags: map[string]bool{
+			ScheduleOnCreate: false,
+			"beta_reports":   true,
+		}})
+
+		// Then
+		assert.False(t, flags.IsEnabled(ScheduleOnCreate))
+		assert.True(t, flags.IsEnabled(UserCache))
+		assert.True(t, flags.IsEnabled("beta_reports"))
+	})
+
+	t.Run("should treat unknown flags as disabled", func(t *testing.T) {
+		// Given
+		flags := New(&config.Config{})
+
+		// Then
+		assert.False(t, flags.IsEnabled("no_such_flag"))
+		assert.False(t, flags.Known("no_such_flag"))
+	})
+}
+
+func TestFlags_Override(t *testing.T) {
+	t.Run("should override and reset at runtime", func(t *testing.T) {
+		// Given
+		flags := New(&config.Config{FeatureFlags: map[string]bool{UserCache: true}})
+
+		// When
+		flags.Set(UserCache, false)
+
+		// Then
+		assert.False(t, flags.IsEnabled(UserCache))
+		assert.False(t, flags.All()[UserCache])
+
+		// When
+		flags.Reset(UserCache)
+
+		// Then
+		assert.True(t, flags.IsEnabled(UserCache))
+	})
+
+	t.Run("should list every known flag in order", func(t *testing.T) {
+		// Given
+		flags := New(&config.Config{FeatureFlags: map[string]bool{"beta_reports": false}})
+
+		// Then
+		assert.Equal(t,
+			[]string{"beta_reports", ScheduleOnCreate, UserCache},
+			flags.Names())
+	})
+}
+
+func TestFlags_Reload(t *testing.T) {
+	t.Run("should replace configured values and keep overrides", func(t *testing.T) {
+		// Given
+		flags := New(&config.Config{})
+		flags.Set(ScheduleOnCreate, true)
+
+		// When
+		flags.Reload(&config.Config{FeatureFlags: map[string]bool{
+			ScheduleOnCreate: false,
+			UserCache:        false,
+			"beta_reports":   true,
+		}})
+
+		// Then
+		assert.True(t, flags.IsEnabled(ScheduleOnCreate))
+		assert.False(t, flags.IsEnabled(UserCache))
+		assert.True(t, flags.Known("beta_reports"))
+	})
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	up := func(context.Context) error { return nil }
+
+	t.Run("should be healthy when every check is up", func(t *testing.T) {
+		// When
+		report := Run(context.Background(), time.Second, Check{Name: "database", Run: up}, Check{Name: "redis", Run: up})
+
+		// Then
+		assert.True(t, report.Healthy())
+		assert.Equal(t, StatusUp, report.Checks["database"].Status)
+		assert.Equal(t, StatusUp, report.Checks["redis"].Status)
+	})
+
+	t.Run("should be unhealthy and report each failure when some checks are down", func(t *testing.T) {
+		// Given
+		down := func(context.Context) error { return errors.New("connection refused") }
+		hung := func(context.Context) error {
+			// Ignores its context, so only the runner's timeout ends the wait
+			time.Sleep(time.Second)
+			return nil
+		}
+
+		// When
+		start := time.Now()
+		report := Run(context.Background(), 50*time.Millisecond,
+			Check{Name: "database", Run: up},
+			Check{Name: "redis", Run: down},
+			Check{Name: "queue", Run: hung},
+		)
+
+		// Then
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Equal(t, StatusUnhealthy, report.Status)
+		require.Len(t, report.Checks, 3)
+		assert.Equal(t, Result{Status: StatusUp, LatencyMS: report.Checks["database"].LatencyMS}, report.Checks["database"])
+		assert.Equal(t, StatusDown, report.Checks["redis"].Status)
+		assert.Equal(t, "connection refused", report.Checks["redis"].Error)
+		assert.Equal(t, StatusDown, report.Checks["queue"].Status)
+		assert.Contains(t, report.Checks["queue"].Error, "check timed out")
+		assert.GreaterOrEqual(t, report.Checks["queue"].LatencyMS, float64(50))
+	})
+
+	t.Run("should run the checks concurrently", func(t *testing.T) {
+		// Given
+		slow := func(context.Context) error {
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		}
+
+		// When
+		start := time.Now()
+		report := Run(context.Background(), time.Second,
+			Check{Name: "a", Run: slow}, Check{Name: "b", Run: slow}, Check{Name: "c", Run: slow})
+
+		// Then
+		assert.True(t, report.Healthy())
+		assert.Less(t, time.Since(start), 250*time.Millisecond)
+	})
+}
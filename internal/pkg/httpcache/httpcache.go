@@ -0,0 +1,77 @@
+// Package httpcache implements the validators behind conditional GETs: entity tags for
+// If-None-Match and modification times for If-Modified-Since.
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag returns a strong entity tag over parts. Callers pass whatever identifies the
+// representation, such as the resource ID, its last update and any formatting options.
+func ETag(parts ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return `"` + hex.EncodeToString(hash[:16]) + `"`
+}
+
+// MatchesETag reports whether an If-None-Match header value matches etag. The header
+// may list several tags or be "*"; weak tags compare by their opaque value.
+func MatchesETag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Latest returns the most recent of times, or the zero time when there are none
+func Latest(times ...time.Time) time.Time {
+	var latest time.Time
+	for _, t := range times {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// ModifiedSince reports whether a resource last modified at lastModified changed after
+// the If-Modified-Since header value. HTTP dates have second precision, so lastModified
+// is truncated before comparing. A missing or malformed header counts as modified.
+func ModifiedSince(ifModifiedSince string, lastModified time.Time) bool {
+	if ifModifiedSince == "" {
+		return true
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return true
+	}
+	return lastModified.Truncate(time.Second).After(since)
+}
+
+// NotModified sets Last-Modified from lastModified and, when the request's
+// If-Modified-Since shows the client already has this version, writes 304 and returns
+// true. A zero lastModified, such as for an empty list, sets no header.
+func NotModified(ctx *gin.Context, lastModified time.Time) bool {
+	if lastModified.IsZero() {
+		return false
+	}
+
+	ctx.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	if ModifiedSince(ctx.GetHeader("If-Modified-Since"), lastModified) {
+		return false
+	}
+
+	ctx.AbortWithStatus(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,136 @@
+package httpcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	t.Run("should be stable for the same parts", func(t *testing.T) {
+		assert.Equal(t, ETag("1", "2024-03-01T20:30:00Z"), ETag("1", "2024-03-01T20:30:00Z"))
+	})
+
+	t.Run("should change with any part", func(t *testing.T) {
+		base := ETag("1", "2024-03-01T20:30:00Z", "UTC")
+		assert.NotEqual(t, base, ETag("2", "2024-03-01T20:30:00Z", "UTC"))
+		assert.NotEqual(t, base, ETag("1", "2024-03-01T20:30:01Z", "UTC"))
+		assert.NotEqual(t, base, ETag("1", "2024-03-01T20:30:00Z", "Asia/Jakarta"))
+		assert.NotEqual(t, ETag("ab", "c"), ETag("a", "bc"))
+	})
+
+	t.Run("should be a quoted strong tag", func(t *testing.T) {
+		etag := ETag("1")
+		assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+	})
+}
+
+func TestMatchesETag(t *testing.T) {
+	etag := ETag("1")
+
+	cases := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{"empty header", "", false},
+		{"exact match", etag, true},
+		{"weak match", "W/" + etag, true},
+		{"listed among others", `"stale", ` + etag, true},
+		{"wildcard", "*", true},
+		{"stale tag", `"stale"`, false},
+		{"unquoted value", etag[1 : len(etag)-1], false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, MatchesETag(c.ifNoneMatch, etag))
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	early := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	assert.Equal(t, late, Latest(early, late, early))
+	assert.True(t, Latest().IsZero())
+}
+
+func TestModifiedSince(t *testing.T) {
+	lastModified := time.Date(2024, 3, 1, 20, 30, 0, 500_000_000, time.UTC)
+	header := lastModified.Format(http.TimeFormat)
+
+	cases := []struct {
+		name            string
+		ifModifiedSince string
+		want            bool
+	}{
+		{"no header", "", true},
+		{"malformed header", "yesterday", true},
+		{"same second despite sub-second precision", header, false},
+		{"later than the change", lastModified.Add(time.Minute).Format(http.TimeFormat), false},
+		{"earlier than the change", lastModified.Add(-time.Second).Format(http.TimeFormat), true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, ModifiedSince(c.ifModifiedSince, lastModified))
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lastModified := time.Date(2024, 3, 1, 20, 30, 0, 0, time.UTC)
+
+	newContext := func(ifModifiedSince string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest("GET", "/payments", nil)
+		if ifModifiedSince != "" {
+			ctx.Request.Header.Set("If-Modified-Since", ifModifiedSince)
+		}
+		return ctx, w
+	}
+
+	t.Run("should set Last-Modified and continue without a condition", func(t *testing.T) {
+		// Given
+		ctx, w := newContext("")
+
+		// When
+		notModified := NotModified(ctx, lastModified)
+
+		// Then
+		assert.False(t, notModified)
+		assert.Equal(t, "Fri, 01 Mar 2024 20:30:00 GMT", w.Header().Get("Last-Modified"))
+	})
+
+	t.Run("should write 304 when unchanged", func(t *testing.T) {
+		// Given
+		ctx, w := newContext("Fri, 01 Mar 2024 20:30:00 GMT")
+
+		// When
+		notModified := NotModified(ctx, lastModified)
+
+		// Then
+		assert.True(t, notModified)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("should skip the header for an empty list", func(t *testing.T) {
+		// Given
+		ctx, w := newContext("Fri, 01 Mar 2024 20:30:00 GMT")
+
+		// When
+		notModified := NotModified(ctx, time.Time{})
+
+		// Then
+		assert.False(t, notModified)
+		assert.Empty(t, w.Header().Get("Last-Modified"))
+	})
+}
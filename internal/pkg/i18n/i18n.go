@@ -0,0 +1,75 @@
+// Package i18n localizes the error messages sent to clients. Catalogs are keyed by the
+// English message, like gettext msgids, so code keeps returning English errors and only
+// the response body is translated. Messages missing from a catalog stay in English.
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	English    = "en"
+	Indonesian = "id"
+
+	// ContextKey is where the middleware stores the negotiated locale on the gin context
+	ContextKey = "locale"
+)
+
+// catalogs maps a locale to its translations; English is the source language and
+// needs none
+var catalogs = map[string]map[string]string{
+	English:    {},
+	Indonesian: indonesian,
+}
+
+// Supported reports whether locale has a catalog
+func Supported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Negotiate picks the supported locale the client prefers most in an Accept-Language
+// header such as "id-ID,id;q=0.9,en;q=0.8", matching on the primary language subtag
+// and ignoring case. It returns fallback when nothing matches.
+func Negotiate(header, fallback string) string {
+	type candidate struct {
+		locale string
+		q      float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if q > 0 && Supported(primary) {
+			candidates = append(candidates, candidate{locale: primary, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return fallback
+	}
+
+	// Equal weights keep the client's order
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].locale
+}
+
+// Translate returns message in locale, or message itself when the locale or the
+// message has no translation
+func Translate(locale, message string) string {
+	if translated, ok := catalogs[locale][message]; ok {
+		return translated
+	}
+	return message
+}
@@ -0,0 +1,53 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{header: "", expected: English},
+		{header: "id", expected: Indonesian},
+		{header: "ID-id", expected: Indonesian},
+		{header: "fr-FR,id;q=0.8,en;q=0.5", expected: Indonesian},
+		{header: "en;q=0.4,id;q=0.6", expected: Indonesian},
+		{header: "en,id", expected: English},
+		{header: "id;q=0", expected: English},
+		{header: "de, fr;q=0.9", expected: English},
+		{header: "id;q=abc", expected: English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			// When
+			locale := Negotiate(tt.header, English)
+
+			// Then
+			assert.Equal(t, tt.expected, locale)
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "saldo tidak mencukupi", Translate(Indonesian, "insufficient funds"))
+	assert.Equal(t, "insufficient funds", Translate(English, "insufficient funds"))
+	assert.Equal(t, "insufficient funds", Translate("", "insufficient funds"))
+	assert.Equal(t, "insufficient funds", Translate("fr", "insufficient funds"))
+	assert.Equal(t, "Failed to create user", Translate(Indonesian, "Failed to create user"),
+		"messages missing from the catalog stay in English")
+}
+
+func TestCatalogs_KeepFormatVerbs(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for message, translated := range catalog {
+			assert.Equal(t, strings.Count(message, "%"), strings.Count(translated, "%"),
+				"%s translation of %q", locale, message)
+		}
+	}
+}
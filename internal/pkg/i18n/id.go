@@ -0,0 +1,77 @@
+package i18n
+
+// indonesian translates the client-facing domain and validation messages. Format verbs
+// must match the English message they translate.
+var indonesian = map[string]string{
+	// Envelope messages
+	"validation failed":      "validasi gagal",
+	"route not found":        "rute tidak ditemukan",
+	"method not allowed":     "metode tidak diizinkan",
+	"invalid timezone":       "zona waktu tidak valid",
+	"invalid signature":      "tanda tangan tidak valid",
+	"request body too large": "isi permintaan terlalu besar",
+	"Invalid user ID":        "ID pengguna tidak valid",
+	"Invalid payment ID":     "ID pembayaran tidak valid",
+	"Invalid wallet ID":      "ID dompet tidak valid",
+	"User not found":         "Pengguna tidak ditemukan",
+	"Payment not found":      "Pembayaran tidak ditemukan",
+	"invalid cursor":         "kursor tidak valid",
+	"invalid dry_run value":  "nilai dry_run tidak valid",
+	"invalid force value":    "nilai force tidak valid",
+
+	// Users
+	"user not found":                            "pengguna tidak ditemukan",
+	"email already exists":                      "email sudah terdaftar",
+	"email domain is not allowed":               "domain email tidak diizinkan",
+	"current password is incorrect":             "kata sandi saat ini salah",
+	"user is not deleted":                       "pengguna tidak dalam keadaan terhapus",
+	"name is required":                          "nama wajib diisi",
+	"name is too long":                          "nama terlalu panjang",
+	"password is too short":                     "kata sandi terlalu pendek",
+	"password must contain an uppercase letter": "kata sandi harus mengandung huruf besar",
+	"password must contain a lowercase letter":  "kata sandi harus mengandung huruf kecil",
+	"password must contain a digit":             "kata sandi harus mengandung angka",
+	"password must contain a symbol":            "kata sandi harus mengandung simbol",
+
+	// Payments
+	"payment not found":                           "pembayaran tidak ditemukan",
+	"invalid amount":                              "jumlah tidak valid",
+	"amount exceeds currency precision":           "jumlah melebihi presisi mata uang",
+	"invalid payment status":                      "status pembayaran tidak valid",
+	"payment status cannot change":                "status pembayaran tidak dapat diubah",
+	"payment cannot move to the requested status": "pembayaran tidak dapat berpindah ke status yang diminta",
+	"payment is not refundable":                   "pembayaran tidak dapat dikembalikan dananya",
+	"refund amount exceeds refundable amount":     "jumlah pengembalian melebihi jumlah yang dapat dikembalikan",
+	"payment cannot be reprocessed":               "pembayaran tidak dapat diproses ulang",
+	"receipt not available for payment status":    "kuitansi tidak tersedia untuk status pembayaran ini",
+	"active payment limit reached":                "batas pembayaran aktif telah tercapai",
+	"no fields to update":                         "tidak ada kolom yang diperbarui",
+
+	// Wallets
+	"wallet not found":                           "dompet tidak ditemukan",
+	"insufficient funds":                         "saldo tidak mencukupi",
+	"amount must be positive":                    "jumlah harus positif",
+	"currency does not match wallet":             "mata uang tidak sesuai dengan dompet",
+	"wallet was modified concurrently":           "dompet diubah secara bersamaan",
+	"too many concurrent transactions":           "terlalu banyak transaksi bersamaan",
+	"cursor requires the default sort":           "kursor memerlukan urutan bawaan",
+	"user has no wallet in the payment currency": "pengguna tidak memiliki dompet dalam mata uang pembayaran",
+	"unsupported currency":                       "mata uang tidak didukung",
+
+	// Field validation
+	"is required":                                      "wajib diisi",
+	"must be a valid email address":                    "harus berupa alamat email yang valid",
+	"must be at least %s characters":                   "minimal %s karakter",
+	"must be at least %s":                              "minimal %s",
+	"must be at most %s characters":                    "maksimal %s karakter",
+	"must be at most %s":                               "maksimal %s",
+	"must be exactly %s characters":                    "harus tepat %s karakter",
+	"must have length %s":                              "panjang harus %s",
+	"must be greater than %s":                          "harus lebih besar dari %s",
+	"must be a finite number":                          "harus berupa angka terhingga",
+	"must not be blank":                                "tidak boleh kosong",
+	"must be one of: %s":                               "harus salah satu dari: %s",
+	"failed the %s validation":                         "gagal validasi %s",
+	"must be a finite number greater than 0":           "harus berupa angka terhingga lebih besar dari 0",
+	"has more decimal places than the currency allows": "memiliki lebih banyak desimal daripada yang diizinkan mata uang",
+}
@@ -0,0 +1,86 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TypeCleanupIdempotencyKeys deletes expired keys from the database store
+const TypeCleanupIdempotencyKeys = "idempotency:cleanup_keys"
+
+// defaultCleanupBatchSize applies when idempotency.cleanup_batch_size is not positive
+const defaultCleanupBatchSize = 1000
+
+// CleanupKeysPayload is empty: every run deletes whatever has expired by then
+type CleanupKeysPayload struct{}
+
+// TaskRegistry is the part of queue.TaskRegistry RegisterTasks uses
+type TaskRegistry interface {
+	Register(taskType, description string, payload interface{})
+}
+
+// Scheduler is the part of queue.Scheduler ScheduleCleanup uses
+type Scheduler interface {
+	Every(interval time.Duration, task *asynq.Task, opts ...asynq.Option) error
+}
+
+// RegisterTasks records the idempotency task types in the task registry
+func RegisterTasks(registry TaskRegistry) {
+	registry.Register(TypeCleanupIdempotencyKeys, "Deletes expired idempotency keys from the database", CleanupKeysPayload{})
+}
+
+// Cleaner handles TypeCleanupIdempotencyKeys. Expired rows are already ignored by the
+// database store, so this only keeps the table from growing without bound.
+type Cleaner struct {
+	store     *DBStore
+	batchSize int
+	logger    *zap.Logger
+}
+
+func NewCleaner(db *gorm.DB, cfg *config.Config, logger *zap.Logger) *Cleaner {
+	batchSize := cfg.Idempotency.CleanupBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultCleanupBatchSize
+	}
+	return &Cleaner{
+		store:     NewDBStore(db, cfg.Idempotency.TTL),
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+func (c *Cleaner) HandleCleanupKeys(ctx context.Context, task *asynq.Task) error {
+	deleted, err := c.store.DeleteExpired(ctx, c.batchSize)
+	if err != nil {
+		c.logger.Error("Failed to delete expired idempotency keys",
+			zap.Int64("deleted", deleted),
+			zap.Error(err))
+		return err
+	}
+
+	c.logger.Info("Expired idempotency keys deleted", zap.Int64("deleted", deleted))
+	return nil
+}
+
+// ScheduleCleanup enqueues TypeCleanupIdempotencyKeys every idempotency.cleanup_interval
+// while keys are kept in the database; Redis expires its keys by itself. A zero interval
+// disables the cleanup.
+func ScheduleCleanup(scheduler Scheduler, cfg *config.Config, logger *zap.Logger) error {
+	interval := cfg.Idempotency.CleanupInterval
+	if cfg.Idempotency.Store != "database" || interval <= 0 {
+		logger.Info("Idempotency key cleanup disabled",
+			zap.String("store", cfg.Idempotency.Store),
+			zap.Duration("interval", interval))
+		return nil
+	}
+
+	return scheduler.Every(interval, asynq.NewTask(TypeCleanupIdempotencyKeys, nil),
+		asynq.Queue("low"),
+		asynq.Unique(interval))
+}
@@ -0,0 +1,62 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCleaner_HandleCleanupKeys(t *testing.T) {
+	t.Run("should delete only expired keys, in batches", func(t *testing.T) {
+		// Setup
+		clk := &clock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+		store := newTestDBStore(t, clk).(*DBStore)
+		cleaner := &Cleaner{store: store, batchSize: 2, logger: zap.NewNop()}
+		ctx := context.Background()
+
+		// Given - five keys saved an hour and a half ago have expired, two fresh ones have not
+		for i := 0; i < 5; i++ {
+			require.NoError(t, store.Save(ctx, &Record{Key: fmt.Sprintf("expired-%d", i), RequestHash: "hash", StatusCode: 200, Response: []byte(`{}`)}))
+		}
+		clk.now = clk.now.Add(90 * time.Minute)
+		for i := 0; i < 2; i++ {
+			require.NoError(t, store.Save(ctx, &Record{Key: fmt.Sprintf("fresh-%d", i), RequestHash: "hash", StatusCode: 200, Response: []byte(`{}`)}))
+		}
+
+		// When
+		err := cleaner.HandleCleanupKeys(ctx, asynq.NewTask(TypeCleanupIdempotencyKeys, nil))
+
+		// Then
+		require.NoError(t, err)
+		var keys []string
+		require.NoError(t, store.db.Model(&Key{}).Order("key").Pluck("key", &keys).Error)
+		assert.Equal(t, []string{"fresh-0", "fresh-1"}, keys)
+	})
+
+	t.Run("should succeed when nothing has expired", func(t *testing.T) {
+		// Setup
+		clk := &clock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+		store := newTestDBStore(t, clk).(*DBStore)
+		cleaner := &Cleaner{store: store, batchSize: 2, logger: zap.NewNop()}
+		ctx := context.Background()
+
+		// Given
+		require.NoError(t, store.Save(ctx, &Record{Key: "fresh", RequestHash: "hash", StatusCode: 200, Response: []byte(`{}`)}))
+
+		// When
+		deleted, err := store.DeleteExpired(ctx, cleaner.batchSize)
+
+		// Then
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+		record, err := store.Get(ctx, "fresh")
+		require.NoError(t, err)
+		assert.Equal(t, "hash", record.RequestHash)
+	})
+}
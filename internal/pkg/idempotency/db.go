@@ -0,0 +1,128 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Key is the table row behind the database store
+type Key struct {
+	Key         string `gorm:"primaryKey;size:255"`
+	RequestHash string `gorm:"size:64;not null"`
+	StatusCode  int    `gorm:"not null"`
+	Response    []byte `gorm:"not null"`
+	CreatedAt   time.Time
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
+
+func (k Key) TableName() string {
+	return "idempotency_keys"
+}
+
+// DBStore keeps idempotency records in the database, so they survive restarts and
+// Redis flushes. Expired rows are ignored and replaced on the next save of their key.
+type DBStore struct {
+	db  *gorm.DB
+	ttl time.Duration
+	now func() time.Time
+}
+
+func NewDBStore(db *gorm.DB, ttl time.Duration) *DBStore {
+	return &DBStore{
+		db:  db,
+		ttl: ttl,
+		now: func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *DBStore) Get(ctx context.Context, key string) (*Record, error) {
+	var row Key
+	err := s.db.WithContext(ctx).
+		Where("key = ? AND expires_at > ?", key, s.now()).
+		First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Record{
+		Key:         row.Key,
+		RequestHash: row.RequestHash,
+		StatusCode:  row.StatusCode,
+		Response:    row.Response,
+		ExpiresAt:   row.ExpiresAt,
+	}, nil
+}
+
+func (s *DBStore) Save(ctx context.Context, record *Record) error {
+	now := s.now()
+	record.ExpiresAt = now.Add(s.ttl)
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("key = ? AND expires_at <= ?", record.Key, now).Delete(&Key{}).Error
+		if err != nil {
+			return err
+		}
+
+		// A concurrent save of the same key loses on the primary key instead of failing
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&Key{
+			Key:         record.Key,
+			RequestHash: record.RequestHash,
+			StatusCode:  record.StatusCode,
+			Response:    record.Response,
+			CreatedAt:   now,
+			ExpiresAt:   record.ExpiresAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrExists
+		}
+		return nil
+	})
+}
+
+func (s *DBStore) Complete(ctx context.Context, record *Record) error {
+	result := s.db.WithContext(ctx).Model(&Key{}).
+		Where("key = ? AND request_hash = ? AND expires_at > ?", record.Key, record.RequestHash, s.now()).
+		Updates(map[string]interface{}{
+			"status_code": record.StatusCode,
+			"response":    record.Response,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *DBStore) Release(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("key = ? AND status_code = 0", key).Delete(&Key{}).Error
+}
+
+// DeleteExpired removes expired rows batchSize at a time, so a large backlog is not
+// deleted under one long-held lock, and returns how many rows it removed
+func (s *DBStore) DeleteExpired(ctx context.Context, batchSize int) (int64, error) {
+	now := s.now()
+	var total int64
+	for {
+		expired := s.db.Model(&Key{}).Select("key").Where("expires_at <= ?", now).Limit(batchSize)
+		result := s.db.WithContext(ctx).Where("key IN (?)", expired).Delete(&Key{})
+		if result.Error != nil {
+			return total, result.Error
+		}
+		total += result.RowsAffected
+		if result.RowsAffected < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces idempotency keys in the shared Redis database
+const keyPrefix = "idempotency:"
+
+// RedisClient is the part of the go-redis client the Redis store uses
+type RedisClient interface {
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
+	SetXX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+// RedisStore keeps idempotency records in Redis with the TTL as key expiry. It is faster
+// than the database store, but keys are lost if Redis is flushed or evicts them.
+type RedisStore struct {
+	client RedisClient
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewRedisStore(client RedisClient, ttl time.Duration) *RedisStore {
+	return &RedisStore{
+		client: client,
+		ttl:    ttl,
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) (*Record, error) {
+	value, err := s.client.Get(ctx, keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record Record
+	if err := json.Unmarshal(value, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *RedisStore) Save(ctx context.Context, record *Record) error {
+	record.ExpiresAt = s.now().Add(s.ttl)
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	stored, err := s.client.SetNX(ctx, keyPrefix+record.Key, value, s.ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !stored {
+		return ErrExists
+	}
+	return nil
+}
+
+func (s *RedisStore) Complete(ctx context.Context, record *Record) error {
+	claimed, err := s.Get(ctx, record.Key)
+	if err != nil {
+		return err
+	}
+	record.ExpiresAt = claimed.ExpiresAt
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	stored, err := s.client.SetXX(ctx, keyPrefix+record.Key, value, redis.KeepTTL).Result()
+	if err != nil {
+		return err
+	}
+	if !stored {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, keyPrefix+key).Err()
+}
@@ -0,0 +1,143 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound is returned by Store.Get for a key that was never saved or has expired
+	ErrNotFound = errors.New("idempotency key not found")
+	// ErrExists is returned by Store.Save when an unexpired record already holds the key
+	ErrExists = errors.New("idempotency key already exists")
+	// ErrKeyReused is returned by Lookup when a key is sent again with a different request
+	ErrKeyReused = errors.New("idempotency key reused with a different request")
+	// ErrInProgress is returned by Claim while another request holding the key has not finished
+	ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// Record is the response stored for an idempotency key, replayed when the same request
+// is retried with the key
+type Record struct {
+	Key         string    `json:"key"`
+	RequestHash string    `json:"request_hash"`
+	StatusCode  int       `json:"status_code"`
+	Response    []byte    `json:"response"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Pending reports whether the record only claims its key for a request still running
+func (r *Record) Pending() bool {
+	return r.StatusCode == 0
+}
+
+// Store keeps idempotency records for a fixed TTL
+type Store interface {
+	// Get returns the unexpired record for key, or ErrNotFound
+	Get(ctx context.Context, key string) (*Record, error)
+	// Save stores record under its key until the TTL passes and sets its ExpiresAt. The
+	// first save wins: ErrExists is returned while an unexpired record holds the key.
+	Save(ctx context.Context, record *Record) error
+	// Complete stores the status code and response of the request that claimed the key,
+	// keeping the claim's expiry. ErrNotFound is returned once the claim has expired.
+	Complete(ctx context.Context, record *Record) error
+	// Release drops a pending claim so the request can be retried with the key
+	Release(ctx context.Context, key string) error
+}
+
+// Lookup returns the record stored for key when requestHash matches the request first
+// sent with it. A new or expired key returns nil without an error.
+func Lookup(ctx context.Context, store Store, key, requestHash string) (*Record, error) {
+	record, err := store.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.RequestHash != requestHash {
+		return nil, ErrKeyReused
+	}
+	return record, nil
+}
+
+// Claim reserves key for a request before it runs, so concurrent retries can't both
+// apply it. It returns nil without an error when the caller now holds the key and must
+// Complete or Release it. A retry of a finished request gets the record to replay; a
+// retry of a request still running gets ErrInProgress, and a different request under the
+// key gets ErrKeyReused.
+//
+// A claim that is never completed, because the process died mid-request, keeps the key
+// in progress until the TTL passes rather than risk applying the request twice.
+func Claim(ctx context.Context, store Store, key, requestHash string) (*Record, error) {
+	err := store.Save(ctx, &Record{Key: key, RequestHash: requestHash, Response: []byte{}})
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, ErrExists) {
+		return nil, err
+	}
+
+	record, err := Lookup(ctx, store, key, requestHash)
+	if err != nil {
+		return nil, err
+	}
+	// A nil record means the holder released the key between the save and the lookup
+	if record == nil || record.Pending() {
+		return nil, ErrInProgress
+	}
+	return record, nil
+}
+
+// HashRequest returns a hex SHA-256 of the parts that identify a request, such as the
+// route and body
+func HashRequest(parts ...[]byte) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		// Length-prefix each part so ("ab", "c") and ("a", "bc") hash differently
+		fmt.Fprintf(hash, "%d:", len(part))
+		hash.Write(part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// NewStore returns the store selected by idempotency.store: "database" keeps keys
+// durably in Postgres, "redis" is faster but loses keys if Redis is flushed
+func NewStore(lifecycle fx.Lifecycle, cfg *config.Config, db *gorm.DB, logger *zap.Logger) (Store, error) {
+	ttl := cfg.Idempotency.TTL
+	if ttl <= 0 {
+		return nil, fmt.Errorf("idempotency.ttl must be positive, got %s", ttl)
+	}
+
+	switch cfg.Idempotency.Store {
+	case "", "database":
+		logger.Info("Idempotency keys stored in the database", zap.Duration("ttl", ttl))
+		return NewDBStore(db, ttl), nil
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		lifecycle.Append(fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				return client.Close()
+			},
+		})
+		logger.Info("Idempotency keys stored in Redis", zap.Duration("ttl", ttl))
+		return NewRedisStore(client, ttl), nil
+	default:
+		return nil, fmt.Errorf("unsupported idempotency.store %q, use database or redis", cfg.Idempotency.Store)
+	}
+}
@@ -0,0 +1,289 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testTTL = time.Hour
+
+// clock is a settable time source shared by a store and its fake backend
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+type fakeEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// fakeRedis implements RedisClient in memory, expiring keys by the shared clock
+type fakeRedis struct {
+	clock   *clock
+	entries map[string]fakeEntry
+}
+
+func (f *fakeRedis) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	if entry, ok := f.entries[key]; ok && f.clock.Now().Before(entry.expiresAt) {
+		return redis.NewBoolResult(false, nil)
+	}
+	f.entries[key] = fakeEntry{value: string(value.([]byte)), expiresAt: f.clock.Now().Add(expiration)}
+	return redis.NewBoolResult(true, nil)
+}
+
+func (f *fakeRedis) SetXX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	entry, ok := f.entries[key]
+	if !ok || !f.clock.Now().Before(entry.expiresAt) {
+		return redis.NewBoolResult(false, nil)
+	}
+	// Only redis.KeepTTL is used, so the entry keeps its expiry
+	f.entries[key] = fakeEntry{value: string(value.([]byte)), expiresAt: entry.expiresAt}
+	return redis.NewBoolResult(true, nil)
+}
+
+func (f *fakeRedis) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	var deleted int64
+	for _, key := range keys {
+		if _, ok := f.entries[key]; ok {
+			delete(f.entries, key)
+			deleted++
+		}
+	}
+	return redis.NewIntResult(deleted, nil)
+}
+
+func (f *fakeRedis) Get(ctx context.Context, key string) *redis.StringCmd {
+	entry, ok := f.entries[key]
+	if !ok || !f.clock.Now().Before(entry.expiresAt) {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(entry.value, nil)
+}
+
+func newTestDBStore(t *testing.T, clk *clock) Store {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	// One connection, since each connection to :memory: opens a separate database
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.AutoMigrate(&Key{}))
+
+	store := NewDBStore(db, testTTL)
+	store.now = clk.Now
+	return store
+}
+
+func newTestRedisStore(t *testing.T, clk *clock) Store {
+	t.Helper()
+	store := NewRedisStore(&fakeRedis{clock: clk, entries: map[string]fakeEntry{}}, testTTL)
+	store.now = clk.Now
+	return store
+}
+
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T, clk *clock) Store{
+		"database": newTestDBStore,
+		"redis":    newTestRedisStore,
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			setup := func(t *testing.T) (Store, *clock) {
+				clk := &clock{now: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)}
+				return newStore(t, clk), clk
+			}
+
+			t.Run("should store and retrieve a record", func(t *testing.T) {
+				// Setup
+				store, clk := setup(t)
+
+				// Given
+				record := &Record{Key: "key-1", RequestHash: "hash-1", StatusCode: 201, Response: []byte(`{"data":{"id":1}}`)}
+
+				// When
+				require.NoError(t, store.Save(ctx, record))
+				stored, err := store.Get(ctx, "key-1")
+
+				// Then
+				require.NoError(t, err)
+				assert.Equal(t, "hash-1", stored.RequestHash)
+				assert.Equal(t, 201, stored.StatusCode)
+				assert.JSONEq(t, `{"data":{"id":1}}`, string(stored.Response))
+				assert.True(t, clk.Now().Add(testTTL).Equal(stored.ExpiresAt))
+			})
+
+			t.Run("should report unknown keys as not found", func(t *testing.T) {
+				// Setup
+				store, _ := setup(t)
+
+				// When
+				_, err := store.Get(ctx, "missing")
+
+				// Then
+				assert.ErrorIs(t, err, ErrNotFound)
+			})
+
+			t.Run("should expire records after the TTL", func(t *testing.T) {
+				// Setup
+				store, clk := setup(t)
+				require.NoError(t, store.Save(ctx, &Record{Key: "key-1", RequestHash: "hash-1", StatusCode: 201, Response: []byte(`{}`)}))
+
+				// When
+				clk.now = clk.now.Add(testTTL + time.Second)
+				_, err := store.Get(ctx, "key-1")
+
+				// Then
+				assert.ErrorIs(t, err, ErrNotFound)
+
+				// An expired key can be used again
+				require.NoError(t, store.Save(ctx, &Record{Key: "key-1", RequestHash: "hash-2", StatusCode: 200, Response: []byte(`{}`)}))
+				stored, err := store.Get(ctx, "key-1")
+				require.NoError(t, err)
+				assert.Equal(t, "hash-2", stored.RequestHash)
+			})
+
+			t.Run("should keep the first record saved under a key", func(t *testing.T) {
+				// Setup
+				store, _ := setup(t)
+				require.NoError(t, store.Save(ctx, &Record{Key: "key-1", RequestHash: "hash-1", StatusCode: 201, Response: []byte(`{}`)}))
+
+				// When
+				err := store.Save(ctx, &Record{Key: "key-1", RequestHash: "hash-2", StatusCode: 500, Response: []byte(`{}`)})
+
+				// Then
+				assert.ErrorIs(t, err, ErrExists)
+				stored, err := store.Get(ctx, "key-1")
+				require.NoError(t, err)
+				assert.Equal(t, "hash-1", stored.RequestHash)
+			})
+
+			t.Run("should replay the same request and reject a different body", func(t *testing.T) {
+				// Setup
+				store, _ := setup(t)
+				hash := HashRequest([]byte("POST /wallets/1/deposit"), []byte(`{"amount":10}`))
+				require.NoError(t, store.Save(ctx, &Record{Key: "key-1", RequestHash: hash, StatusCode: 201, Response: []byte(`{}`)}))
+
+				// When
+				replay, replayErr := Lookup(ctx, store, "key-1", hash)
+				otherHash := HashRequest([]byte("POST /wallets/1/deposit"), []byte(`{"amount":20}`))
+				conflict, conflictErr := Lookup(ctx, store, "key-1", otherHash)
+				fresh, freshErr := Lookup(ctx, store, "key-2", hash)
+
+				// Then
+				require.NoError(t, replayErr)
+				assert.Equal(t, 201, replay.StatusCode)
+				assert.ErrorIs(t, conflictErr, ErrKeyReused)
+				assert.Nil(t, conflict)
+				assert.NoError(t, freshErr)
+				assert.Nil(t, fresh)
+			})
+
+			t.Run("should let one claim hold a key until it completes", func(t *testing.T) {
+				// Setup
+				store, _ := setup(t)
+
+				// When
+				first, firstErr := Claim(ctx, store, "key-1", "hash-1")
+				_, retryErr := Claim(ctx, store, "key-1", "hash-1")
+				_, otherErr := Claim(ctx, store, "key-1", "hash-2")
+				completeErr := store.Complete(ctx, &Record{Key: "key-1", RequestHash: "hash-1", StatusCode: 200, Response: []byte(`{"ok":true}`)})
+				replay, replayErr := Claim(ctx, store, "key-1", "hash-1")
+
+				// Then
+				require.NoError(t, firstErr)
+				assert.Nil(t, first)
+				assert.ErrorIs(t, retryErr, ErrInProgress)
+				assert.ErrorIs(t, otherErr, ErrKeyReused)
+				require.NoError(t, completeErr)
+				require.NoError(t, replayErr)
+				assert.Equal(t, 200, replay.StatusCode)
+				assert.JSONEq(t, `{"ok":true}`, string(replay.Response))
+			})
+
+			t.Run("should free a released claim for a retry", func(t *testing.T) {
+				// Setup
+				store, _ := setup(t)
+				_, err := Claim(ctx, store, "key-1", "hash-1")
+				require.NoError(t, err)
+
+				// When
+				releaseErr := store.Release(ctx, "key-1")
+				record, claimErr := Claim(ctx, store, "key-1", "hash-1")
+
+				// Then
+				require.NoError(t, releaseErr)
+				require.NoError(t, claimErr)
+				assert.Nil(t, record)
+			})
+
+			t.Run("should not complete an expired claim", func(t *testing.T) {
+				// Setup
+				store, clk := setup(t)
+				_, err := Claim(ctx, store, "key-1", "hash-1")
+				require.NoError(t, err)
+
+				// When
+				clk.now = clk.now.Add(testTTL + time.Second)
+				err = store.Complete(ctx, &Record{Key: "key-1", RequestHash: "hash-1", StatusCode: 200, Response: []byte(`{}`)})
+
+				// Then
+				assert.ErrorIs(t, err, ErrNotFound)
+			})
+		})
+	}
+}
+
+func TestHashRequest(t *testing.T) {
+	assert.Equal(t, HashRequest([]byte("a"), []byte("bc")), HashRequest([]byte("a"), []byte("bc")))
+	assert.NotEqual(t, HashRequest([]byte("ab"), []byte("c")), HashRequest([]byte("a"), []byte("bc")))
+}
+
+func TestNewStore(t *testing.T) {
+	newConfig := func(store string, ttl time.Duration) *config.Config {
+		return &config.Config{
+			Redis:       config.RedisConfig{Host: "localhost", Port: 6379},
+			Idempotency: config.IdempotencyConfig{Store: store, TTL: ttl},
+		}
+	}
+
+	t.Run("should select the store from config", func(t *testing.T) {
+		dbStore, err := NewStore(fxtest.NewLifecycle(t), newConfig("database", testTTL), &gorm.DB{}, zap.NewNop())
+		require.NoError(t, err)
+		assert.IsType(t, &DBStore{}, dbStore)
+
+		redisStore, err := NewStore(fxtest.NewLifecycle(t), newConfig("redis", testTTL), nil, zap.NewNop())
+		require.NoError(t, err)
+		assert.IsType(t, &RedisStore{}, redisStore)
+	})
+
+	t.Run("should reject an unknown store", func(t *testing.T) {
+		_, err := NewStore(fxtest.NewLifecycle(t), newConfig("memcached", testTTL), nil, zap.NewNop())
+
+		assert.EqualError(t, err, `unsupported idempotency.store "memcached", use database or redis`)
+	})
+
+	t.Run("should reject a non-positive TTL", func(t *testing.T) {
+		_, err := NewStore(fxtest.NewLifecycle(t), newConfig("database", 0), nil, zap.NewNop())
+
+		assert.EqualError(t, err, "idempotency.ttl must be positive, got 0s")
+	})
+}
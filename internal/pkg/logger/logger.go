@@ -1,13 +1,18 @@
 package logger
 
 import (
+	"context"
+
 	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
 
+	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-func NewLogger(cfg *config.Config) (*zap.Logger, error) {
+// NewLogger builds the application logger. The returned AtomicLevel controls the
+// logger's level and can be changed while the application runs.
+func NewLogger(cfg *config.Config) (*zap.Logger, zap.AtomicLevel, error) {
 	var zapConfig zap.Config
 
 	if cfg.Logger.Format == "json" {
@@ -18,7 +23,7 @@ func NewLogger(cfg *config.Config) (*zap.Logger, error) {
 
 	level, err := zapcore.ParseLevel(cfg.Logger.Level)
 	if err != nil {
-		return nil, err
+		return nil, zap.AtomicLevel{}, err
 	}
 	zapConfig.Level = zap.NewAtomicLevelAt(level)
 
@@ -28,8 +33,25 @@ func NewLogger(cfg *config.Config) (*zap.Logger, error) {
 
 	logger, err := zapConfig.Build()
 	if err != nil {
-		return nil, err
+		return nil, zap.AtomicLevel{}, err
 	}
 
-	return logger, nil
+	return logger, zapConfig.Level, nil
+}
+
+// LogEffectiveConfig records the loaded configuration, with secrets redacted, so
+// misconfiguration can be diagnosed from the startup logs
+func LogEffectiveConfig(cfg *config.Config, logger *zap.Logger) {
+	logger.Info("Effective configuration", zap.Any("config", cfg.Redacted()))
+}
+
+// LogReady logs "Application ready" from a start hook. Invoke it last so the hook runs
+// after every other component has started, marking the end of boot in the logs.
+func LogReady(lifecycle fx.Lifecycle, logger *zap.Logger) {
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logger.Info("Application ready")
+			return nil
+		},
+	})
 }
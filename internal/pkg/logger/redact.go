@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RedactedValue replaces the values of sensitive keys in logged payloads
+const RedactedValue = "[REDACTED]"
+
+// RedactJSON masks the values of keys (matched case-insensitively) at any depth of
+// body. Bodies that are not valid JSON are replaced entirely, since their sensitive
+// values cannot be located.
+func RedactJSON(body []byte, keys []string) []byte {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []byte("[invalid JSON body omitted]")
+	}
+
+	redacted, err := json.Marshal(redactValue(value, keys))
+	if err != nil {
+		return []byte("[invalid JSON body omitted]")
+	}
+	return redacted
+}
+
+func redactValue(value interface{}, keys []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isSensitiveKey(key, keys) {
+				v[key] = RedactedValue
+			} else {
+				v[key] = redactValue(item, keys)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item, keys)
+		}
+	}
+	return value
+}
+
+func isSensitiveKey(key string, keys []string) bool {
+	for _, sensitive := range keys {
+		if strings.EqualFold(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactJSON(t *testing.T) {
+	keys := []string{"password", "token", "secret"}
+
+	t.Run("should mask matched keys at any depth and keep others", func(t *testing.T) {
+		// Given
+		body := []byte(`{
+			"email": "john@example.com",
+			"password": "hunter22",
+			"profile": {"name": "John", "api_token": "keep", "Token": "abc"},
+			"sessions": [{"token": "t1", "device": "phone"}, {"secret": {"nested": true}}],
+			"count": 2
+		}`)
+
+		// When
+		redacted := RedactJSON(body, keys)
+
+		// Then
+		assert.JSONEq(t, `{
+			"email": "john@example.com",
+			"password": "[REDACTED]",
+			"profile": {"name": "John", "api_token": "keep", "Token": "[REDACTED]"},
+			"sessions": [{"token": "[REDACTED]", "device": "phone"}, {"secret": "[REDACTED]"}],
+			"count": 2
+		}`, string(redacted))
+	})
+
+	t.Run("should handle top-level arrays", func(t *testing.T) {
+		redacted := RedactJSON([]byte(`[{"password":"p"},"plain"]`), keys)
+
+		assert.JSONEq(t, `[{"password":"[REDACTED]"},"plain"]`, string(redacted))
+	})
+
+	t.Run("should leave payloads without sensitive keys unchanged", func(t *testing.T) {
+		redacted := RedactJSON([]byte(`{"error":"payment not found"}`), keys)
+
+		assert.JSONEq(t, `{"error":"payment not found"}`, string(redacted))
+	})
+
+	t.Run("should replace invalid JSON", func(t *testing.T) {
+		redacted := RedactJSON([]byte(`password=hunter22`), keys)
+
+		assert.NotContains(t, string(redacted), "hunter22")
+	})
+}
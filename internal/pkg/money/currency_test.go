@@ -0,0 +1,14 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsCurrency(t *testing.T) {
+	assert.True(t, IsCurrency("USD"))
+	assert.True(t, IsCurrency("idr"))
+	assert.False(t, IsCurrency("ABC"))
+	assert.False(t, IsCurrency(""))
+}
@@ -0,0 +1,51 @@
+package money
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// currencySymbols lists the display symbols of common currencies; others are shown
+// with their code
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"KRW": "₩",
+	"IDR": "Rp",
+	"INR": "₹",
+	"SGD": "S$",
+	"AUD": "A$",
+}
+
+// Format renders amount for display in currency, rounded half up to the currency's
+// decimals with thousands grouped: 1234.5 USD is "$1,234.50", 1000 JPY is "¥1,000" and
+// 5 CHF, which has no symbol listed, is "CHF 5.00".
+func Format(amount float64, currency string) string {
+	currency = strings.ToUpper(currency)
+	decimals := CurrencyDecimals(currency)
+	digits := strconv.FormatFloat(math.Abs(RoundToCurrency(amount, currency, HalfUp)), 'f', decimals, 64)
+
+	whole, fraction, _ := strings.Cut(digits, ".")
+	var b strings.Builder
+	if amount < 0 && strings.Trim(digits, "0.") != "" {
+		b.WriteByte('-')
+	}
+	if symbol, ok := currencySymbols[currency]; ok {
+		b.WriteString(symbol)
+	} else {
+		b.WriteString(currency + " ")
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString("." + fraction)
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		expected string
+	}{
+		{amount: 100.5, currency: "USD", expected: "$100.50"},
+		{amount: 1234567.891, currency: "USD", expected: "$1,234,567.89"},
+		{amount: 0.005, currency: "usd", expected: "$0.01"},
+		{amount: -42, currency: "USD", expected: "-$42.00"},
+		{amount: 99.99, currency: "EUR", expected: "€99.99"},
+		{amount: 1000.5, currency: "EUR", expected: "€1,000.50"},
+		{amount: 1000, currency: "JPY", expected: "¥1,000"},
+		{amount: 1234.5, currency: "JPY", expected: "¥1,235"},
+		{amount: 12.3456, currency: "KWD", expected: "KWD 12.346"},
+		{amount: 5, currency: "CHF", expected: "CHF 5.00"},
+		{amount: -0.001, currency: "USD", expected: "$0.00"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Format(tt.amount, tt.currency), "%v %s", tt.amount, tt.currency)
+	}
+}
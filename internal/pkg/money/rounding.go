@@ -0,0 +1,101 @@
+// Package money holds the amount helpers shared by domains: currency precision,
+// rounding to it and formatting amounts for display.
+package money
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode decides which way an amount between two representable values goes
+type RoundingMode string
+
+const (
+	// HalfUp rounds halves away from zero: 2.345 -> 2.35, -2.345 -> -2.35
+	HalfUp RoundingMode = "half_up"
+	// HalfEven rounds halves to the even neighbour (bankers' rounding): 2.345 -> 2.34,
+	// 2.355 -> 2.36. It avoids the upward drift of HalfUp over many amounts.
+	HalfEven RoundingMode = "half_even"
+	// Floor rounds toward negative infinity: 2.349 -> 2.34, -2.341 -> -2.35
+	Floor RoundingMode = "floor"
+)
+
+// ParseRoundingMode validates a configured mode
+func ParseRoundingMode(mode string) (RoundingMode, error) {
+	switch RoundingMode(mode) {
+	case HalfUp, HalfEven, Floor:
+		return RoundingMode(mode), nil
+	}
+	return "", fmt.Errorf("unsupported rounding mode %q, use half_up, half_even or floor", mode)
+}
+
+// Round rounds amount to decimals places. It works on the shortest decimal form of
+// amount, so 2.675, stored in binary as 2.67499999..., is treated as the half it was
+// written as. An empty or unknown mode rounds HalfUp.
+func Round(amount float64, decimals int, mode RoundingMode) float64 {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return amount
+	}
+
+	negative := amount < 0
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', -1, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if len(fraction) <= decimals {
+		return amount
+	}
+	kept, dropped := whole+fraction[:decimals], fraction[decimals:]
+
+	if roundsAway(kept, dropped, negative, mode) {
+		kept = increment(kept)
+	}
+
+	rounded, _ := strconv.ParseFloat(kept[:len(kept)-decimals]+"."+kept[len(kept)-decimals:], 64)
+	if negative {
+		return -rounded
+	}
+	return rounded
+}
+
+// RoundToCurrency rounds amount to the precision of currency
+func RoundToCurrency(amount float64, currency string, mode RoundingMode) float64 {
+	return Round(amount, CurrencyDecimals(currency), mode)
+}
+
+// roundsAway reports whether the magnitude kept should grow by one unit given the
+// dropped digits
+func roundsAway(kept, dropped string, negative bool, mode RoundingMode) bool {
+	if strings.Trim(dropped, "0") == "" {
+		return false
+	}
+
+	switch mode {
+	case Floor:
+		return negative
+	case HalfEven:
+		if dropped[0] != '5' {
+			return dropped[0] > '5'
+		}
+		if strings.Trim(dropped[1:], "0") != "" {
+			return true
+		}
+		// Exactly half: go to the even neighbour
+		return (kept[len(kept)-1]-'0')%2 == 1
+	default:
+		return dropped[0] >= '5'
+	}
+}
+
+// increment adds one to a string of decimal digits
+func increment(digits string) string {
+	b := []byte(digits)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < '9' {
+			b[i]++
+			return string(b)
+		}
+		b[i] = '0'
+	}
+	return "1" + string(b)
+}
@@ -0,0 +1,76 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		decimals int
+		halfUp   float64
+		halfEven float64
+		floor    float64
+	}{
+		// Exact halves: the modes disagree
+		{amount: 2.345, decimals: 2, halfUp: 2.35, halfEven: 2.34, floor: 2.34},
+		{amount: 2.355, decimals: 2, halfUp: 2.36, halfEven: 2.36, floor: 2.35},
+		{amount: 2.675, decimals: 2, halfUp: 2.68, halfEven: 2.68, floor: 2.67},
+		{amount: 0.125, decimals: 2, halfUp: 0.13, halfEven: 0.12, floor: 0.12},
+		{amount: 2.5, decimals: 0, halfUp: 3, halfEven: 2, floor: 2},
+		{amount: 3.5, decimals: 0, halfUp: 4, halfEven: 4, floor: 3},
+		{amount: 1.0005, decimals: 3, halfUp: 1.001, halfEven: 1, floor: 1},
+		{amount: -2.345, decimals: 2, halfUp: -2.35, halfEven: -2.34, floor: -2.35},
+		{amount: -2.5, decimals: 0, halfUp: -3, halfEven: -2, floor: -3},
+		// Past the half every nearest mode rounds away; below it none does
+		{amount: 2.3451, decimals: 2, halfUp: 2.35, halfEven: 2.35, floor: 2.34},
+		{amount: 2.3449, decimals: 2, halfUp: 2.34, halfEven: 2.34, floor: 2.34},
+		// Carries through nines
+		{amount: 9.995, decimals: 2, halfUp: 10, halfEven: 10, floor: 9.99},
+		// Already at precision: unchanged
+		{amount: 10.5, decimals: 2, halfUp: 10.5, halfEven: 10.5, floor: 10.5},
+		{amount: -7, decimals: 0, halfUp: -7, halfEven: -7, floor: -7},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.halfUp, Round(tt.amount, tt.decimals, HalfUp), "half_up %v", tt.amount)
+		assert.Equal(t, tt.halfEven, Round(tt.amount, tt.decimals, HalfEven), "half_even %v", tt.amount)
+		assert.Equal(t, tt.floor, Round(tt.amount, tt.decimals, Floor), "floor %v", tt.amount)
+	}
+}
+
+func TestRound_FloatError(t *testing.T) {
+	t.Run("should clear float error from sums of amounts at precision", func(t *testing.T) {
+		// Given
+		sum := 0.1 + 0.2
+
+		// Then
+		assert.Equal(t, 0.3, Round(sum, 2, HalfUp))
+		assert.Equal(t, 0.3, Round(sum, 2, HalfEven))
+		assert.Equal(t, 0.3, Round(sum, 2, Floor))
+	})
+
+	t.Run("should round half up for an empty mode", func(t *testing.T) {
+		assert.Equal(t, 2.35, Round(2.345, 2, ""))
+	})
+}
+
+func TestRoundToCurrency(t *testing.T) {
+	assert.Equal(t, 100.0, RoundToCurrency(99.5, "JPY", HalfUp))
+	assert.Equal(t, 1.234, RoundToCurrency(1.2345, "KWD", HalfEven))
+	assert.Equal(t, 1.23, RoundToCurrency(1.2345, "usd", Floor))
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for _, mode := range []string{"half_up", "half_even", "floor"} {
+		parsed, err := ParseRoundingMode(mode)
+		require.NoError(t, err)
+		assert.Equal(t, RoundingMode(mode), parsed)
+	}
+
+	_, err := ParseRoundingMode("ceiling")
+	assert.EqualError(t, err, `unsupported rounding mode "ceiling", use half_up, half_even or floor`)
+}
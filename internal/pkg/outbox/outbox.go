@@ -0,0 +1,30 @@
+package outbox
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Message is an event recorded in the same transaction as the state change it announces.
+// The relay turns unpublished messages into queue tasks, so an event is never lost to a
+// queue outage between the commit and the enqueue.
+type Message struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Topic       string     `json:"topic" gorm:"size:100;not null"`
+	AggregateID uint       `json:"aggregate_id" gorm:"not null;index"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	LastError   string     `json:"last_error" gorm:"size:500"`
+	CreatedAt   time.Time  `json:"created_at"`
+	PublishedAt *time.Time `json:"published_at" gorm:"index"`
+}
+
+func (m Message) TableName() string {
+	return "outbox_messages"
+}
+
+// Write records an event for aggregateID. Pass the transaction that makes the state change
+// so the event commits or rolls back with it.
+func Write(tx *gorm.DB, topic string, aggregateID uint) error {
+	return tx.Create(&Message{Topic: topic, AggregateID: aggregateID}).Error
+}
@@ -0,0 +1,202 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Enqueuer is the part of the queue client the relay needs
+type Enqueuer interface {
+	Enqueue(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
+}
+
+// TaskBuilder turns an outbox event into the queue task that handles it
+type TaskBuilder func(aggregateID uint) (*asynq.Task, []asynq.Option, error)
+
+// maxErrorLength matches the last_error column size
+const maxErrorLength = 500
+
+// Relay polls for unpublished outbox messages and enqueues the task routed for each topic
+type Relay struct {
+	db        *gorm.DB
+	client    Enqueuer
+	interval  time.Duration
+	batchSize int
+	logger    *zap.Logger
+
+	mu     sync.RWMutex
+	routes map[string]TaskBuilder
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func NewRelay(db *gorm.DB, client Enqueuer, cfg *config.Config, logger *zap.Logger) *Relay {
+	return &Relay{
+		db:        db,
+		client:    client,
+		interval:  cfg.Worker.OutboxPollInterval,
+		batchSize: cfg.Worker.OutboxBatchSize,
+		logger:    logger,
+		routes:    make(map[string]TaskBuilder),
+	}
+}
+
+// Route registers the task builder for a topic. Messages without a route stay unpublished.
+func (r *Relay) Route(topic string, build TaskBuilder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes[topic] = build
+}
+
+func (r *Relay) route(topic string) (TaskBuilder, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	build, ok := r.routes[topic]
+	return build, ok
+}
+
+// Drain publishes one batch of unpublished messages in insertion order and reports how
+// many were published. It stops at the first enqueue failure, since the queue is likely
+// down and the rest of the batch would fail the same way. Failures are counted on the
+// message and retried on the next drain.
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	published := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var messages []Message
+		// SKIP LOCKED lets several worker replicas drain without publishing a message twice
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL").
+			Order("id").
+			Limit(r.batchSize).
+			Find(&messages).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range messages {
+			message := &messages[i]
+			publishErr := r.publish(message)
+			if publishErr == nil {
+				now := time.Now().UTC()
+				if err := tx.Model(message).Update("published_at", now).Error; err != nil {
+					return err
+				}
+				published++
+				continue
+			}
+
+			r.logger.Warn("Failed to publish outbox message",
+				zap.Uint("message_id", message.ID),
+				zap.String("topic", message.Topic),
+				zap.Error(publishErr))
+			if err := r.recordFailure(tx, message, publishErr); err != nil {
+				return err
+			}
+			if errors.Is(publishErr, errEnqueue) {
+				return nil
+			}
+		}
+		return nil
+	})
+	return published, err
+}
+
+var (
+	errNoRoute = errors.New("no route for topic")
+	errEnqueue = errors.New("enqueue failed")
+)
+
+func (r *Relay) publish(message *Message) error {
+	build, ok := r.route(message.Topic)
+	if !ok {
+		return fmt.Errorf("%w %s", errNoRoute, message.Topic)
+	}
+
+	task, opts, err := build(message.AggregateID)
+	if err != nil {
+		return err
+	}
+
+	// A stable task ID makes a retry after a failed published_at update a no-op while the
+	// first task is still queued
+	opts = append(opts, asynq.TaskID(fmt.Sprintf("outbox:%d", message.ID)))
+	if _, err := r.client.Enqueue(task, opts...); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return fmt.Errorf("%w: %w", errEnqueue, err)
+	}
+	return nil
+}
+
+func (r *Relay) recordFailure(tx *gorm.DB, message *Message, publishErr error) error {
+	lastError := publishErr.Error()
+	if len(lastError) > maxErrorLength {
+		lastError = lastError[:maxErrorLength]
+	}
+	return tx.Model(message).Updates(map[string]interface{}{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": lastError,
+	}).Error
+}
+
+// Start polls on the configured interval until the app stops
+func (r *Relay) Start(lifecycle fx.Lifecycle) {
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			r.stop = make(chan struct{})
+			r.done = make(chan struct{})
+			go r.run()
+			r.logger.Info("Outbox relay started", zap.Duration("interval", r.interval))
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(r.stop)
+			select {
+			case <-r.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			r.logger.Info("Outbox relay stopped")
+			return nil
+		},
+	})
+}
+
+func (r *Relay) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			// Keep draining while full batches come back so a backlog clears quickly
+			for {
+				select {
+				case <-r.stop:
+					return
+				default:
+				}
+				published, err := r.Drain(context.Background())
+				if err != nil {
+					r.logger.Error("Outbox drain failed", zap.Error(err))
+				}
+				if err != nil || published < r.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type MockEnqueuer struct {
+	mock.Mock
+}
+
+func (m *MockEnqueuer) Enqueue(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	args := m.Called(task.Type(), string(task.Payload()))
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*asynq.TaskInfo), args.Error(1)
+}
+
+func setupRelay(t *testing.T, batchSize int) (*Relay, *MockEnqueuer, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	// One connection, since each connection to :memory: opens a separate database
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.AutoMigrate(&Message{}))
+
+	client := &MockEnqueuer{}
+	cfg := &config.Config{Worker: config.WorkerConfig{OutboxPollInterval: 10 * time.Millisecond, OutboxBatchSize: batchSize}}
+	relay := NewRelay(db, client, cfg, zap.NewNop())
+	relay.Route("thing.created", func(aggregateID uint) (*asynq.Task, []asynq.Option, error) {
+		return asynq.NewTask("thing:process", []byte(strconv.FormatUint(uint64(aggregateID), 10))), nil, nil
+	})
+	return relay, client, db
+}
+
+func unpublished(t *testing.T, db *gorm.DB) []Message {
+	t.Helper()
+	var messages []Message
+	require.NoError(t, db.Where("published_at IS NULL").Order("id").Find(&messages).Error)
+	return messages
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("should roll back with the surrounding transaction", func(t *testing.T) {
+		// Setup
+		_, _, db := setupRelay(t, 10)
+
+		// When
+		err := db.Transaction(func(tx *gorm.DB) error {
+			require.NoError(t, Write(tx, "thing.created", 1))
+			return errors.New("state change failed")
+		})
+
+		// Then
+		assert.Error(t, err)
+		var count int64
+		require.NoError(t, db.Model(&Message{}).Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
+
+func TestRelay_Drain(t *testing.T) {
+	t.Run("should enqueue routed messages in order and mark them published", func(t *testing.T) {
+		// Setup
+		relay, client, db := setupRelay(t, 10)
+		require.NoError(t, Write(db, "thing.created", 1))
+		require.NoError(t, Write(db, "thing.created", 2))
+
+		var order []string
+		client.On("Enqueue", "thing:process", mock.Anything).Return(&asynq.TaskInfo{}, nil).
+			Run(func(args mock.Arguments) { order = append(order, args.String(1)) })
+
+		// When
+		published, err := relay.Drain(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 2, published)
+		assert.Equal(t, []string{"1", "2"}, order)
+		assert.Empty(t, unpublished(t, db))
+
+		// And a second drain has nothing left to publish
+		published, err = relay.Drain(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, published)
+		client.AssertNumberOfCalls(t, "Enqueue", 2)
+	})
+
+	t.Run("should keep messages and stop the batch when the queue is down", func(t *testing.T) {
+		// Setup
+		relay, client, db := setupRelay(t, 10)
+		require.NoError(t, Write(db, "thing.created", 1))
+		require.NoError(t, Write(db, "thing.created", 2))
+		client.On("Enqueue", "thing:process", "1").Return(nil, errors.New("redis unavailable"))
+
+		// When
+		published, err := relay.Drain(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Zero(t, published)
+		client.AssertNumberOfCalls(t, "Enqueue", 1)
+
+		messages := unpublished(t, db)
+		require.Len(t, messages, 2)
+		assert.Equal(t, 1, messages[0].Attempts)
+		assert.Contains(t, messages[0].LastError, "redis unavailable")
+		assert.Zero(t, messages[1].Attempts)
+	})
+
+	t.Run("should treat an already queued task as published", func(t *testing.T) {
+		// Setup
+		relay, client, db := setupRelay(t, 10)
+		require.NoError(t, Write(db, "thing.created", 1))
+		client.On("Enqueue", "thing:process", "1").Return(nil, asynq.ErrTaskIDConflict)
+
+		// When
+		published, err := relay.Drain(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+		assert.Empty(t, unpublished(t, db))
+	})
+
+	t.Run("should skip unrouted topics without blocking the rest", func(t *testing.T) {
+		// Setup
+		relay, client, db := setupRelay(t, 10)
+		require.NoError(t, Write(db, "unknown.topic", 1))
+		require.NoError(t, Write(db, "thing.created", 2))
+		client.On("Enqueue", "thing:process", "2").Return(&asynq.TaskInfo{}, nil)
+
+		// When
+		published, err := relay.Drain(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+		messages := unpublished(t, db)
+		require.Len(t, messages, 1)
+		assert.Equal(t, "unknown.topic", messages[0].Topic)
+		assert.Contains(t, messages[0].LastError, "no route for topic unknown.topic")
+	})
+
+	t.Run("should publish at most one batch per drain", func(t *testing.T) {
+		// Setup
+		relay, client, db := setupRelay(t, 2)
+		for id := uint(1); id <= 3; id++ {
+			require.NoError(t, Write(db, "thing.created", id))
+		}
+		client.On("Enqueue", "thing:process", mock.Anything).Return(&asynq.TaskInfo{}, nil)
+
+		// When
+		published, err := relay.Drain(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 2, published)
+		assert.Len(t, unpublished(t, db), 1)
+	})
+}
+
+func TestRelay_Start(t *testing.T) {
+	t.Run("should drain in the background until stopped", func(t *testing.T) {
+		// Setup
+		relay, client, db := setupRelay(t, 10)
+		require.NoError(t, Write(db, "thing.created", 1))
+		client.On("Enqueue", "thing:process", "1").Return(&asynq.TaskInfo{}, nil)
+
+		lifecycle := fxtest.NewLifecycle(t)
+		relay.Start(lifecycle)
+		lifecycle.RequireStart()
+
+		// Then
+		assert.Eventually(t, func() bool {
+			var count int64
+			db.Model(&Message{}).Where("published_at IS NULL").Count(&count)
+			return count == 0
+		}, time.Second, 10*time.Millisecond)
+
+		lifecycle.RequireStop()
+	})
+}
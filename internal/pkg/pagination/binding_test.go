@@ -0,0 +1,60 @@
+package pagination_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	paymentDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
+	userDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
+	walletDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/dto"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFilters_SharePaginationBinding binds the same query strings into every list filter
+// and expects identical pagination values and validation outcomes
+func TestFilters_SharePaginationBinding(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected pagination.Pagination
+		wantErr  bool
+	}{
+		{query: "", expected: pagination.Pagination{}},
+		{query: "page=2&page_size=25", expected: pagination.Pagination{Page: 2, PageSize: 25}},
+		{query: "page=0&page_size=0", expected: pagination.Pagination{}},
+		{query: "page=-1", wantErr: true},
+		{query: "page_size=-5", wantErr: true},
+		{query: "page=abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run("query "+tt.query, func(t *testing.T) {
+			// Given
+			req := httptest.NewRequest("GET", "/?"+tt.query, nil)
+			var payments paymentDto.PaymentFilter
+			var users userDto.UserFilter
+			var transactions walletDto.TransactionFilter
+
+			// When
+			paymentErr := binding.Query.Bind(req, &payments)
+			userErr := binding.Query.Bind(req, &users)
+			transactionErr := binding.Query.Bind(req, &transactions)
+
+			// Then
+			if tt.wantErr {
+				assert.Error(t, paymentErr)
+				assert.Error(t, userErr)
+				assert.Error(t, transactionErr)
+				return
+			}
+			assert.NoError(t, paymentErr)
+			assert.NoError(t, userErr)
+			assert.NoError(t, transactionErr)
+			assert.Equal(t, tt.expected, payments.Pagination)
+			assert.Equal(t, tt.expected, users.Pagination)
+			assert.Equal(t, tt.expected, transactions.Pagination)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+)
+
+// cursorMACSize is how many bytes of the HMAC-SHA256 a cursor carries
+const cursorMACSize = 16
+
+// ErrInvalidCursor is returned for a cursor the server did not issue for the listing
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorSigner turns row IDs into opaque cursors signed with a server secret, so a
+// client can neither read the ID nor craft a cursor to probe other rows
+type CursorSigner struct {
+	key []byte
+}
+
+// NewCursorSigner signs with secret. An empty secret gets a random key, which works for
+// a single instance but invalidates cursors on restart and across replicas.
+func NewCursorSigner(secret string) *CursorSigner {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, sha256.Size)
+		if _, err := rand.Read(key); err != nil {
+			panic(err)
+		}
+	}
+	return &CursorSigner{key: key}
+}
+
+// Encode returns the cursor for id. Scope names the listing, e.g. one wallet's ledger,
+// so a cursor is only accepted by the listing that issued it.
+func (s *CursorSigner) Encode(scope string, id uint) string {
+	payload := make([]byte, 8, 8+cursorMACSize)
+	binary.BigEndian.PutUint64(payload, uint64(id))
+	payload = append(payload, s.mac(scope, payload)...)
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// Decode returns the ID in cursor, or ErrInvalidCursor when it was not issued by Encode
+// with the same scope and secret
+func (s *CursorSigner) Decode(scope, cursor string) (uint, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(payload) != 8+cursorMACSize {
+		return 0, ErrInvalidCursor
+	}
+	if !hmac.Equal(payload[8:], s.mac(scope, payload[:8])) {
+		return 0, ErrInvalidCursor
+	}
+	id := binary.BigEndian.Uint64(payload[:8])
+	if id == 0 || uint64(uint(id)) != id {
+		return 0, ErrInvalidCursor
+	}
+	return uint(id), nil
+}
+
+func (s *CursorSigner) mac(scope string, id []byte) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(scope))
+	mac.Write([]byte{0})
+	mac.Write(id)
+	return mac.Sum(nil)[:cursorMACSize]
+}
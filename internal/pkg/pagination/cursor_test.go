@@ -0,0 +1,52 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorSigner(t *testing.T) {
+	signer := NewCursorSigner("secret")
+
+	t.Run("should decode a cursor it issued", func(t *testing.T) {
+		// When
+		id, err := signer.Decode("wallet:1", signer.Encode("wallet:1", 42))
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, uint(42), id)
+	})
+
+	t.Run("should reject modified and foreign cursors", func(t *testing.T) {
+		// Given
+		cursor := signer.Encode("wallet:1", 42)
+		flipped := []byte(cursor)
+		flipped[3] ^= 1
+
+		tests := map[string]struct {
+			signer *CursorSigner
+			scope  string
+			cursor string
+		}{
+			"modified":       {signer: signer, scope: "wallet:1", cursor: string(flipped)},
+			"truncated":      {signer: signer, scope: "wallet:1", cursor: cursor[:len(cursor)-2]},
+			"plain ID":       {signer: signer, scope: "wallet:1", cursor: "42"},
+			"not base64":     {signer: signer, scope: "wallet:1", cursor: "!!!"},
+			"other scope":    {signer: signer, scope: "wallet:2", cursor: cursor},
+			"other secret":   {signer: NewCursorSigner("other"), scope: "wallet:1", cursor: cursor},
+			"random key":     {signer: NewCursorSigner(""), scope: "wallet:1", cursor: cursor},
+			"forged zero ID": {signer: signer, scope: "wallet:1", cursor: signer.Encode("wallet:1", 0)},
+			"empty cursor":   {signer: signer, scope: "wallet:1", cursor: ""},
+		}
+		for name, tt := range tests {
+			// When
+			id, err := tt.signer.Decode(tt.scope, tt.cursor)
+
+			// Then
+			assert.ErrorIs(t, err, ErrInvalidCursor, name)
+			assert.Zero(t, id, name)
+		}
+	})
+}
@@ -0,0 +1,33 @@
+// Package pagination holds the page query parameters shared by the list endpoints.
+package pagination
+
+import "github.com/novriyantoAli/wallet-ms-backend/internal/config"
+
+// Pagination is embedded in list filters so every endpoint parses and validates
+// page and page_size the same way
+type Pagination struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1"`
+}
+
+// Normalize defaults an unset page to 1 and an unset page size to the configured
+// default, and caps the page size at the configured maximum
+func (p *Pagination) Normalize(cfg config.PaginationConfig) {
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	if p.PageSize <= 0 {
+		p.PageSize = cfg.DefaultPageSize
+	}
+	if cfg.MaxPageSize > 0 && p.PageSize > cfg.MaxPageSize {
+		p.PageSize = cfg.MaxPageSize
+	}
+}
+
+// Offset returns the number of rows before the current page
+func (p Pagination) Offset() int {
+	if p.Page <= 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.PageSize
+}
@@ -0,0 +1,54 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPagination_Normalize(t *testing.T) {
+	cfg := config.PaginationConfig{DefaultPageSize: 20, MaxPageSize: 100}
+
+	tests := []struct {
+		name     string
+		given    Pagination
+		expected Pagination
+	}{
+		{"should default an unset page and size", Pagination{}, Pagination{Page: 1, PageSize: 20}},
+		{"should keep values within limits", Pagination{Page: 3, PageSize: 50}, Pagination{Page: 3, PageSize: 50}},
+		{"should cap the page size", Pagination{Page: 2, PageSize: 500}, Pagination{Page: 2, PageSize: 100}},
+		{"should reset negative values", Pagination{Page: -1, PageSize: -5}, Pagination{Page: 1, PageSize: 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			p := tt.given
+
+			// When
+			p.Normalize(cfg)
+
+			// Then
+			assert.Equal(t, tt.expected, p)
+		})
+	}
+
+	t.Run("should not cap when no maximum is configured", func(t *testing.T) {
+		// Given
+		p := Pagination{Page: 1, PageSize: 500}
+
+		// When
+		p.Normalize(config.PaginationConfig{DefaultPageSize: 10})
+
+		// Then
+		assert.Equal(t, 500, p.PageSize)
+	})
+}
+
+func TestPagination_Offset(t *testing.T) {
+	assert.Equal(t, 0, Pagination{Page: 1, PageSize: 10}.Offset())
+	assert.Equal(t, 20, Pagination{Page: 3, PageSize: 10}.Offset())
+	assert.Equal(t, 0, Pagination{}.Offset())
+}
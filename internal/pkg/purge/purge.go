@@ -0,0 +1,202 @@
+// Package purge hard-deletes rows that have been soft-deleted for longer than the
+// retention period, so deleted users and payments do not stay in the database forever.
+package purge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	paymentEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	userEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/database"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TypePurgeSoftDeleted hard-deletes users and payments soft-deleted before the retention
+const TypePurgeSoftDeleted = "maintenance:purge_soft_deleted"
+
+// defaultBatchSize applies when purge.batch_size is not positive
+const defaultBatchSize = 500
+
+// PurgeSoftDeletedPayload optionally overrides purge.retention for one run
+type PurgeSoftDeletedPayload struct {
+	// Retention is a Go duration such as "720h"; empty uses purge.retention
+	Retention string `json:"retention,omitempty"`
+}
+
+// Result counts the rows one purge removed
+type Result struct {
+	Payments int64 `json:"payments"`
+	Users    int64 `json:"users"`
+}
+
+// RegisterTasks records the purge task type in the task registry
+func RegisterTasks(registry *queue.TaskRegistry) {
+	registry.Register(TypePurgeSoftDeleted, "Hard-deletes users and payments soft-deleted longer than the retention", PurgeSoftDeletedPayload{})
+}
+
+// NewTask builds a purge task; a zero retention leaves purge.retention in effect
+func NewTask(retention time.Duration) (*asynq.Task, error) {
+	payload := PurgeSoftDeletedPayload{}
+	if retention > 0 {
+		payload.Retention = retention.String()
+	}
+	data, err := queue.MarshalPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypePurgeSoftDeleted, data), nil
+}
+
+// Schedule enqueues TypePurgeSoftDeleted every purge.interval. A zero interval or
+// retention disables the periodic purge.
+func Schedule(scheduler *queue.Scheduler, cfg *config.Config, logger *zap.Logger) error {
+	interval, retention := cfg.Purge.Interval, cfg.Purge.Retention
+	if interval <= 0 || retention <= 0 {
+		logger.Info("Soft-deleted row purge disabled",
+			zap.Duration("interval", interval),
+			zap.Duration("retention", retention))
+		return nil
+	}
+
+	task, err := NewTask(0)
+	if err != nil {
+		return err
+	}
+	return scheduler.Every(interval, task, asynq.Queue("low"), asynq.Unique(interval))
+}
+
+// Purger handles TypePurgeSoftDeleted
+type Purger struct {
+	db        *gorm.DB
+	retention time.Duration
+	batchSize int
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+func NewPurger(db *gorm.DB, cfg *config.Config, logger *zap.Logger) *Purger {
+	batchSize := cfg.Purge.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return &Purger{
+		db:        db,
+		retention: cfg.Purge.Retention,
+		batchSize: batchSize,
+		logger:    logger,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (p *Purger) HandlePurgeSoftDeleted(ctx context.Context, task *asynq.Task) error {
+	payload, err := queue.UnmarshalPayload[PurgeSoftDeletedPayload](task)
+	if err != nil {
+		return err
+	}
+
+	retention := p.retention
+	if payload.Retention != "" {
+		retention, err = time.ParseDuration(payload.Retention)
+		if err != nil {
+			return fmt.Errorf("invalid retention %q: %w", payload.Retention, err)
+		}
+	}
+	// A manual run with purge.retention unset has nothing safe to go by
+	if retention <= 0 {
+		return fmt.Errorf("retention must be positive, got %s", retention)
+	}
+
+	result, err := p.Purge(ctx, retention)
+	if err != nil {
+		p.logger.Error("Failed to purge soft-deleted rows",
+			zap.Int64("payments", result.Payments),
+			zap.Int64("users", result.Users),
+			zap.Error(err))
+		return err
+	}
+
+	p.logger.Info("Soft-deleted rows purged",
+		zap.Duration("retention", retention),
+		zap.Int64("payments", result.Payments),
+		zap.Int64("users", result.Users))
+	return nil
+}
+
+// Purge hard-deletes payments, then users, soft-deleted more than retention ago, in
+// batches of purge.batch_size. Each payment goes together with the rows that refer to
+// it. A user is kept while any payment or wallet still refers to it, so purging never
+// leaves a dangling user_id behind.
+func (p *Purger) Purge(ctx context.Context, retention time.Duration) (Result, error) {
+	cutoff := p.now().Add(-retention)
+	var result Result
+
+	for {
+		var ids []uint
+		err := p.db.WithContext(ctx).Unscoped().Model(&paymentEntity.Payment{}).
+			Where("deleted_at IS NOT NULL AND deleted_at <= ?", cutoff).
+			Order("id").
+			Limit(p.batchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return result, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		err = database.WithTransaction(ctx, p.db, func(tx *gorm.DB) error {
+			children := []interface{}{
+				&paymentEntity.PaymentTag{},
+				&paymentEntity.PaymentStatusChange{},
+				&paymentEntity.ProcessedEvent{},
+			}
+			for _, child := range children {
+				if err := tx.Where("payment_id IN ?", ids).Delete(child).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Unscoped().Delete(&paymentEntity.Payment{}, ids).Error
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Payments += int64(len(ids))
+		if len(ids) < p.batchSize {
+			break
+		}
+	}
+
+	for {
+		var ids []uint
+		err := p.db.WithContext(ctx).Unscoped().Model(&userEntity.User{}).
+			Where("deleted_at IS NOT NULL AND deleted_at <= ?", cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM payments WHERE payments.user_id = users.id)").
+			Where("NOT EXISTS (SELECT 1 FROM wallets WHERE wallets.user_id = users.id)").
+			Order("id").
+			Limit(p.batchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return result, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		if err := p.db.WithContext(ctx).Unscoped().Delete(&userEntity.User{}, ids).Error; err != nil {
+			return result, err
+		}
+		result.Users += int64(len(ids))
+		if len(ids) < p.batchSize {
+			break
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package purge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	paymentEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	userEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	walletEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestPurger_HandlePurgeSoftDeleted(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+	old := now.Add(-retention - time.Hour)
+	recent := now.Add(-time.Hour)
+
+	setup := func(t *testing.T) (*gorm.DB, *Purger) {
+		db, err := testutil.SetupTestDB()
+		require.NoError(t, err)
+		testutil.WithCleanDB(t, db)
+		purger := &Purger{db: db, retention: retention, batchSize: 2, logger: testutil.NewSilentLogger(), now: func() time.Time { return now }}
+		return db, purger
+	}
+	createUser := func(t *testing.T, db *gorm.DB, email string, deletedAt *time.Time) *userEntity.User {
+		user := &userEntity.User{Name: "User", Email: email, Password: "hash"}
+		require.NoError(t, db.Create(user).Error)
+		if deletedAt != nil {
+			require.NoError(t, db.Unscoped().Model(user).UpdateColumn("deleted_at", *deletedAt).Error)
+		}
+		return user
+	}
+	createPayment := func(t *testing.T, db *gorm.DB, userID uint, deletedAt *time.Time) *paymentEntity.Payment {
+		payment := &paymentEntity.Payment{Amount: 100, Currency: "USD", UserID: userID, Tags: []paymentEntity.PaymentTag{{Tag: "rent"}}}
+		require.NoError(t, db.Create(payment).Error)
+		require.NoError(t, db.Create(&paymentEntity.PaymentStatusChange{PaymentID: payment.ID, ToStatus: "pending", Actor: "api"}).Error)
+		if deletedAt != nil {
+			require.NoError(t, db.Unscoped().Model(payment).UpdateColumn("deleted_at", *deletedAt).Error)
+		}
+		return payment
+	}
+	count := func(t *testing.T, db *gorm.DB, model interface{}, query string, args ...interface{}) int64 {
+		var n int64
+		require.NoError(t, db.Unscoped().Model(model).Where(query, args...).Count(&n).Error)
+		return n
+	}
+
+	t.Run("should purge rows past retention and keep recent soft-deletes", func(t *testing.T) {
+		// Setup
+		db, purger := setup(t)
+
+		// Given - three old deleted payments (more than one batch), one recent, one live
+		owner := createUser(t, db, "owner@example.com", nil)
+		var oldPayments []uint
+		for i := 0; i < 3; i++ {
+			oldPayments = append(oldPayments, createPayment(t, db, owner.ID, &old).ID)
+		}
+		recentPayment := createPayment(t, db, owner.ID, &recent)
+		livePayment := createPayment(t, db, owner.ID, nil)
+		oldUser := createUser(t, db, "old@example.com", &old)
+		recentUser := createUser(t, db, "recent@example.com", &recent)
+
+		// When
+		task, err := NewTask(0)
+		require.NoError(t, err)
+		err = purger.HandlePurgeSoftDeleted(context.Background(), task)
+
+		// Then
+		require.NoError(t, err)
+		assert.Zero(t, count(t, db, &paymentEntity.Payment{}, "id IN ?", oldPayments))
+		assert.Zero(t, count(t, db, &paymentEntity.PaymentTag{}, "payment_id IN ?", oldPayments))
+		assert.Zero(t, count(t, db, &paymentEntity.PaymentStatusChange{}, "payment_id IN ?", oldPayments))
+		assert.Equal(t, int64(1), count(t, db, &paymentEntity.Payment{}, "id = ?", recentPayment.ID))
+		assert.Equal(t, int64(1), count(t, db, &paymentEntity.PaymentTag{}, "payment_id = ?", recentPayment.ID))
+		assert.Equal(t, int64(1), count(t, db, &paymentEntity.Payment{}, "id = ?", livePayment.ID))
+		assert.Zero(t, count(t, db, &userEntity.User{}, "id = ?", oldUser.ID))
+		assert.Equal(t, int64(1), count(t, db, &userEntity.User{}, "id = ?", recentUser.ID))
+		assert.Equal(t, int64(1), count(t, db, &userEntity.User{}, "id = ?", owner.ID))
+	})
+
+	t.Run("should keep an old deleted user that payments or wallets still refer to", func(t *testing.T) {
+		// Setup
+		db, purger := setup(t)
+
+		// Given
+		withPayment := createUser(t, db, "payer@example.com", &old)
+		createPayment(t, db, withPayment.ID, &recent)
+		withWallet := createUser(t, db, "saver@example.com", &old)
+		require.NoError(t, db.Create(&walletEntity.Wallet{UserID: withWallet.ID, Currency: "USD"}).Error)
+
+		// When
+		result, err := purger.Purge(context.Background(), retention)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, Result{}, result)
+		assert.Equal(t, int64(2), count(t, db, &userEntity.User{}, "deleted_at IS NOT NULL"))
+	})
+
+	t.Run("should use the retention from the task payload", func(t *testing.T) {
+		// Setup
+		db, purger := setup(t)
+
+		// Given
+		user := createUser(t, db, "recent@example.com", &recent)
+
+		// When
+		task, err := NewTask(time.Minute)
+		require.NoError(t, err)
+		err = purger.HandlePurgeSoftDeleted(context.Background(), task)
+
+		// Then
+		require.NoError(t, err)
+		assert.Zero(t, count(t, db, &userEntity.User{}, "id = ?", user.ID))
+	})
+}
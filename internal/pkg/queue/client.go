@@ -1,11 +1,12 @@
 package queue
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
 
 	"github.com/hibiken/asynq"
+	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
 
@@ -15,13 +16,8 @@ type Client struct {
 }
 
 func NewClient(cfg *config.Config, logger *zap.Logger) *Client {
-	redisAddr := fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port)
-
-	redisOpt := asynq.RedisClientOpt{
-		Addr:     redisAddr,
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-	}
+	redisOpt := newRedisClientOpt(cfg)
+	redisAddr := redisOpt.Addr
 
 	client := asynq.NewClient(redisOpt)
 
@@ -39,6 +35,16 @@ func (c *Client) Close() error {
 	return c.client.Close()
 }
 
+// CloseClientOnStop closes the queue client when the app stops
+func CloseClientOnStop(lifecycle fx.Lifecycle, client *Client, logger *zap.Logger) {
+	lifecycle.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			logger.Info("Closing queue client")
+			return client.Close()
+		},
+	})
+}
+
 func (c *Client) GetClient() *asynq.Client {
 	return c.client
 }
@@ -0,0 +1,152 @@
+package queue
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// defaultDrainTimeout applies to a queue without a configured drain timeout; it matches
+// asynq's own default shutdown timeout
+const defaultDrainTimeout = 8 * time.Second
+
+// abandonTimeout is how long asynq's Shutdown waits once the per-queue drains are over;
+// tasks still running then are pushed back to their queue for another worker
+const abandonTimeout = time.Second
+
+// stopHeadroom is the part of the worker's stop timeout left to the other stop hooks
+// once the queues have drained
+var stopHeadroom = config.DefaultStopTimeout
+
+// StopTimeout is the fx stop timeout the worker needs to give every queue its full
+// worker.drain_timeouts entry, since the drain gives up once fx cancels the stop context
+func StopTimeout(cfg *config.Config) time.Duration {
+	var longest time.Duration
+	for _, queue := range queueNames(serverQueues) {
+		if timeout := drainTimeout(cfg.Worker.DrainTimeouts, queue); timeout > longest {
+			longest = timeout
+		}
+	}
+	return longest + abandonTimeout + stopHeadroom
+}
+
+// drainTracker counts the tasks each queue is running so shutdown can wait on a queue
+// by queue basis, which asynq's single ShutdownTimeout cannot do
+type drainTracker struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+	// changed is closed and replaced whenever a task finishes
+	changed chan struct{}
+}
+
+func newDrainTracker() *drainTracker {
+	return &drainTracker{
+		inFlight: make(map[string]int),
+		changed:  make(chan struct{}),
+	}
+}
+
+// middleware records every task the mux runs against the queue it came from
+func (t *drainTracker) middleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		queue, _ := asynq.GetQueueName(ctx)
+		t.begin(queue)
+		defer t.end(queue)
+		return next.ProcessTask(ctx, task)
+	})
+}
+
+func (t *drainTracker) begin(queue string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight[queue]++
+}
+
+func (t *drainTracker) end(queue string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight[queue]--
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// wait blocks until queue has no task in flight, deadline passes or ctx is done, and
+// returns how many tasks were still running
+func (t *drainTracker) wait(ctx context.Context, queue string, deadline time.Time) int {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	for {
+		t.mu.Lock()
+		inFlight, changed := t.inFlight[queue], t.changed
+		t.mu.Unlock()
+		if inFlight == 0 {
+			return 0
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return inFlight
+		case <-ctx.Done():
+			return inFlight
+		}
+	}
+}
+
+// drain waits for every queue in parallel, each up to its own timeout counted from the
+// call, and logs whether the queue emptied in time
+func (t *drainTracker) drain(ctx context.Context, queues []string, timeouts map[string]time.Duration, logger *zap.Logger) {
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, queue := range queues {
+		timeout := drainTimeout(timeouts, queue)
+		wg.Add(1)
+		go func(queue string) {
+			defer wg.Done()
+			remaining := t.wait(ctx, queue, start.Add(timeout))
+			if remaining > 0 && ctx.Err() != nil {
+				logger.Warn("Queue drain cut short by the stop timeout",
+					zap.String("queue", queue),
+					zap.Int("in_flight", remaining),
+					zap.Duration("elapsed", time.Since(start)),
+					zap.Duration("timeout", timeout))
+				return
+			}
+			if remaining > 0 {
+				logger.Warn("Queue drain timed out",
+					zap.String("queue", queue),
+					zap.Int("in_flight", remaining),
+					zap.Duration("timeout", timeout))
+				return
+			}
+			logger.Info("Queue drained",
+				zap.String("queue", queue),
+				zap.Duration("elapsed", time.Since(start)))
+		}(queue)
+	}
+	wg.Wait()
+}
+
+func drainTimeout(timeouts map[string]time.Duration, queue string) time.Duration {
+	if timeout, ok := timeouts[queue]; ok {
+		return timeout
+	}
+	return defaultDrainTimeout
+}
+
+// queueNames returns the queue names in a stable order for logging
+func queueNames(queues map[string]int) []string {
+	names := make([]string, 0, len(queues))
+	for name := range queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
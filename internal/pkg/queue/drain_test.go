@@ -0,0 +1,171 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDrainTracker_Drain(t *testing.T) {
+	timeouts := map[string]time.Duration{
+		"critical": 200 * time.Millisecond,
+		"default":  50 * time.Millisecond,
+		"low":      10 * time.Millisecond,
+	}
+	queues := []string{"critical", "default", "low"}
+
+	t.Run("should wait the critical timeout while a critical task is in flight", func(t *testing.T) {
+		// Setup
+		tracker := newDrainTracker()
+
+		// Given
+		tracker.begin("critical")
+
+		// When
+		start := time.Now()
+		tracker.drain(context.Background(), queues, timeouts, testutil.NewSilentLogger())
+
+		// Then
+		assert.GreaterOrEqual(t, time.Since(start), timeouts["critical"])
+	})
+
+	t.Run("should return once the in-flight task finishes", func(t *testing.T) {
+		// Setup
+		tracker := newDrainTracker()
+
+		// Given
+		tracker.begin("critical")
+		time.AfterFunc(20*time.Millisecond, func() { tracker.end("critical") })
+
+		// When
+		start := time.Now()
+		tracker.drain(context.Background(), queues, timeouts, testutil.NewSilentLogger())
+
+		// Then
+		assert.Less(t, time.Since(start), timeouts["critical"])
+	})
+
+	t.Run("should give a low task only the low timeout", func(t *testing.T) {
+		// Setup
+		tracker := newDrainTracker()
+
+		// Given
+		tracker.begin("low")
+
+		// When
+		start := time.Now()
+		remaining := tracker.wait(context.Background(), "low", start.Add(timeouts["low"]))
+
+		// Then
+		assert.Equal(t, 1, remaining)
+		assert.GreaterOrEqual(t, time.Since(start), timeouts["low"])
+		assert.Less(t, time.Since(start), timeouts["critical"])
+	})
+
+	t.Run("should stop waiting when the context is done", func(t *testing.T) {
+		// Setup
+		tracker := newDrainTracker()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Given
+		tracker.begin("critical")
+
+		// When
+		remaining := tracker.wait(ctx, "critical", time.Now().Add(time.Minute))
+
+		// Then
+		assert.Equal(t, 1, remaining)
+	})
+}
+
+func TestDrainTimeout(t *testing.T) {
+	timeouts := map[string]time.Duration{"critical": 30 * time.Second}
+
+	assert.Equal(t, 30*time.Second, drainTimeout(timeouts, "critical"))
+	assert.Equal(t, defaultDrainTimeout, drainTimeout(timeouts, "low"))
+}
+
+func TestStopTimeout(t *testing.T) {
+	// Given
+	cfg := &config.Config{Worker: config.WorkerConfig{DrainTimeouts: map[string]time.Duration{
+		"critical": 30 * time.Second,
+		"default":  10 * time.Second,
+	}}}
+
+	// When
+	timeout := StopTimeout(cfg)
+
+	// Then
+	assert.Equal(t, 30*time.Second+abandonTimeout+config.DefaultStopTimeout, timeout)
+	assert.Greater(t, timeout, config.DefaultStopTimeout)
+}
+
+func TestServer_StopUnderFxTimeout(t *testing.T) {
+	// A critical task that finishes after 300ms, with a 500ms critical drain timeout
+	cfg := &config.Config{Worker: config.WorkerConfig{DrainTimeouts: map[string]time.Duration{
+		"critical": 500 * time.Millisecond,
+		"default":  50 * time.Millisecond,
+		"low":      50 * time.Millisecond,
+	}}}
+
+	stopWith := func(t *testing.T, stopTimeout time.Duration) *observer.ObservedLogs {
+		// Setup
+		core, logs := observer.New(zapcore.InfoLevel)
+		server := NewServer(cfg, zap.New(core))
+		app := fx.New(
+			fx.NopLogger,
+			fx.Invoke(func(lifecycle fx.Lifecycle) {
+				lifecycle.Append(fx.Hook{OnStop: server.stop})
+			}),
+			fx.StopTimeout(stopTimeout),
+		)
+		require.NoError(t, app.Start(context.Background()))
+
+		// Given
+		server.drain.begin("critical")
+		time.AfterFunc(300*time.Millisecond, func() { server.drain.end("critical") })
+
+		// When - stopped the way cmd/worker does, bounded by the fx stop timeout
+		ctx, cancel := context.WithTimeout(context.Background(), app.StopTimeout())
+		defer cancel()
+		_ = app.Stop(ctx)
+		return logs
+	}
+
+	t.Run("should drain the critical queue within StopTimeout", func(t *testing.T) {
+		// Setup
+		defer func(headroom time.Duration) { stopHeadroom = headroom }(stopHeadroom)
+		stopHeadroom = 100 * time.Millisecond
+
+		// When
+		logs := stopWith(t, StopTimeout(cfg))
+
+		// Then
+		drained := logs.FilterMessage("Queue drained").FilterField(zap.String("queue", "critical"))
+		assert.Equal(t, 1, drained.Len())
+		assert.Zero(t, logs.FilterMessage("Queue drain cut short by the stop timeout").Len())
+	})
+
+	t.Run("should report a drain cut short by a shorter stop timeout", func(t *testing.T) {
+		// When
+		logs := stopWith(t, 100*time.Millisecond)
+
+		// Then - fx returns at its timeout, and the drain gives up right after
+		assert.Eventually(t, func() bool {
+			cut := logs.FilterMessage("Queue drain cut short by the stop timeout").FilterField(zap.String("queue", "critical"))
+			return cut.Len() == 1
+		}, time.Second, 10*time.Millisecond)
+		assert.Zero(t, logs.FilterMessage("Queue drain timed out").Len())
+	})
+}
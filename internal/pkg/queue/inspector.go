@@ -0,0 +1,49 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Inspector is the part of asynq.Inspector used to read queue sizes
+type Inspector interface {
+	Queues() ([]string, error)
+	GetQueueInfo(queue string) (*asynq.QueueInfo, error)
+}
+
+// NewInspector connects an asynq.Inspector to the configured Redis and closes it when
+// the app stops
+func NewInspector(lifecycle fx.Lifecycle, cfg *config.Config, logger *zap.Logger) Inspector {
+	inspector := asynq.NewInspector(newRedisClientOpt(cfg))
+	lifecycle.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			logger.Info("Closing queue inspector")
+			return inspector.Close()
+		},
+	})
+	return inspector
+}
+
+// PendingTasks sums the tasks waiting to be processed across every queue
+func PendingTasks(inspector Inspector) (int, error) {
+	queues, err := inspector.Queues()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list queues: %w", err)
+	}
+
+	pending := 0
+	for _, queue := range queues {
+		info, err := inspector.GetQueueInfo(queue)
+		if err != nil {
+			return 0, fmt.Errorf("failed to inspect queue %s: %w", queue, err)
+		}
+		pending += info.Pending
+	}
+	return pending, nil
+}
@@ -0,0 +1,27 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+)
+
+// MarshalPayload encodes a task payload as JSON
+func MarshalPayload[T any](v T) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalPayload decodes a task's JSON payload into T, naming the task type in the
+// error so malformed payloads read the same across handlers
+func UnmarshalPayload[T any](task *asynq.Task) (T, error) {
+	var payload T
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return payload, fmt.Errorf("json.Unmarshal failed for %s payload: %w", task.Type(), err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,59 @@
+package queue
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type paymentPayload struct {
+	PaymentID uint   `json:"payment_id"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+func TestPayloadRoundTrip(t *testing.T) {
+	t.Run("should decode what it encodes", func(t *testing.T) {
+		// Given
+		data, err := MarshalPayload(paymentPayload{PaymentID: 42, Reason: "retry"})
+		require.NoError(t, err)
+
+		// When
+		payload, err := UnmarshalPayload[paymentPayload](asynq.NewTask("payment:process", data))
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, paymentPayload{PaymentID: 42, Reason: "retry"}, payload)
+	})
+
+	t.Run("should report marshal failures", func(t *testing.T) {
+		_, err := MarshalPayload(map[string]interface{}{"ch": make(chan int)})
+
+		assert.ErrorContains(t, err, "failed to marshal payload")
+	})
+}
+
+func TestUnmarshalPayload(t *testing.T) {
+	t.Run("should name the task type for malformed payloads", func(t *testing.T) {
+		for _, taskType := range []string{"payment:process", "payment:check_status"} {
+			// When
+			_, err := UnmarshalPayload[paymentPayload](asynq.NewTask(taskType, []byte("invalid json")))
+
+			// Then
+			require.Error(t, err)
+			assert.Regexp(t, `^json.Unmarshal failed for `+taskType+` payload: `, err.Error())
+
+			var syntaxErr *json.SyntaxError
+			assert.ErrorAs(t, err, &syntaxErr)
+		}
+	})
+
+	t.Run("should report type mismatches the same way", func(t *testing.T) {
+		_, err := UnmarshalPayload[paymentPayload](asynq.NewTask("payment:process", []byte(`{"payment_id":"abc"}`)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "json.Unmarshal failed for payment:process payload: ")
+	})
+}
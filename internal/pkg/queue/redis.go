@@ -0,0 +1,71 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+type pingFunc func(ctx context.Context) error
+
+func newRedisClientOpt(cfg *config.Config) asynq.RedisClientOpt {
+	return asynq.RedisClientOpt{
+		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	}
+}
+
+// redisPinger returns a ping function issuing PING against the configured Redis
+func redisPinger(opt asynq.RedisClientOpt) pingFunc {
+	return func(ctx context.Context) error {
+		raw := opt.MakeRedisClient()
+		client, ok := raw.(redis.UniversalClient)
+		if !ok {
+			return fmt.Errorf("unexpected redis client type %T", raw)
+		}
+		defer client.Close()
+
+		return client.Ping(ctx).Err()
+	}
+}
+
+// RedisCheck returns a ping of the configured Redis for health reports
+func RedisCheck(cfg *config.Config) func(ctx context.Context) error {
+	return redisPinger(newRedisClientOpt(cfg))
+}
+
+// waitForRedis pings Redis until it answers, retrying with exponential backoff
+// starting at delay. It gives up after retries additional attempts.
+func waitForRedis(ctx context.Context, ping pingFunc, retries int, delay time.Duration, logger *zap.Logger) error {
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if err = ping(ctx); err == nil {
+			return nil
+		}
+
+		if attempt == retries {
+			break
+		}
+
+		logger.Warn("Redis not reachable, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Duration("backoff", delay),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("redis unreachable: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	return fmt.Errorf("redis unreachable after %d attempts: %w", retries+1, err)
+}
@@ -0,0 +1,135 @@
+package queue
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TaskInfo describes a registered task type and the JSON schema of its payload
+type TaskInfo struct {
+	Type        string                 `json:"type"`
+	Description string                 `json:"description"`
+	Schema      map[string]interface{} `json:"schema"`
+}
+
+// TaskRegistry records the task types domains enqueue and handle, so operators can see
+// what the workers accept without reading the code
+type TaskRegistry struct {
+	mu    sync.RWMutex
+	tasks map[string]TaskInfo
+}
+
+func NewTaskRegistry() *TaskRegistry {
+	return &TaskRegistry{tasks: make(map[string]TaskInfo)}
+}
+
+// Register adds a task type with an example payload value whose struct shape defines the
+// schema. Registering the same type twice is a programming error and panics at startup.
+func (r *TaskRegistry) Register(taskType, description string, payload interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tasks[taskType]; exists {
+		panic(fmt.Sprintf("queue: task type %q registered twice", taskType))
+	}
+	r.tasks[taskType] = TaskInfo{
+		Type:        taskType,
+		Description: description,
+		Schema:      PayloadSchema(payload),
+	}
+}
+
+// Tasks returns the registered task types sorted by name
+func (r *TaskRegistry) Tasks() []TaskInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]TaskInfo, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Type < tasks[j].Type })
+	return tasks
+}
+
+// PayloadSchema builds a JSON schema for the value's type from its json struct tags.
+// Fields without omitempty are listed as required.
+func PayloadSchema(payload interface{}) map[string]interface{} {
+	return typeSchema(reflect.TypeOf(payload))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		parts := strings.Split(field.Tag.Get("json"), ",")
+		name := parts[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = typeSchema(field.Type)
+		omitempty := false
+		for _, opt := range parts[1:] {
+			if opt == "omitempty" {
+				omitempty = true
+			}
+		}
+		if !omitempty {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
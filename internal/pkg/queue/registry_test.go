@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type samplePayload struct {
+	ID        uint              `json:"id"`
+	Amount    float64           `json:"amount"`
+	Note      string            `json:"note,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+	Meta      map[string]string `json:"meta,omitempty"`
+	Retry     *bool             `json:"retry,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	Internal  string            `json:"-"`
+	secret    string
+}
+
+func TestPayloadSchema(t *testing.T) {
+	t.Run("should describe fields by json name", func(t *testing.T) {
+		// When
+		schema := PayloadSchema(samplePayload{secret: "unused"})
+
+		// Then
+		assert.Equal(t, "object", schema["type"])
+		assert.Equal(t, map[string]interface{}{
+			"id":         map[string]interface{}{"type": "integer"},
+			"amount":     map[string]interface{}{"type": "number"},
+			"note":       map[string]interface{}{"type": "string"},
+			"tags":       map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			"meta":       map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+			"retry":      map[string]interface{}{"type": "boolean"},
+			"created_at": map[string]interface{}{"type": "string", "format": "date-time"},
+		}, schema["properties"])
+		assert.Equal(t, []string{"id", "amount", "created_at"}, schema["required"])
+	})
+
+	t.Run("should accept pointers to payloads", func(t *testing.T) {
+		assert.Equal(t, PayloadSchema(samplePayload{}), PayloadSchema(&samplePayload{}))
+	})
+}
+
+func TestTaskRegistry(t *testing.T) {
+	t.Run("should list tasks sorted by type", func(t *testing.T) {
+		// Setup
+		registry := NewTaskRegistry()
+
+		// Given
+		registry.Register("b:task", "second", samplePayload{})
+		registry.Register("a:task", "first", samplePayload{})
+
+		// When
+		tasks := registry.Tasks()
+
+		// Then
+		require.Len(t, tasks, 2)
+		assert.Equal(t, "a:task", tasks[0].Type)
+		assert.Equal(t, "first", tasks[0].Description)
+		assert.Equal(t, "b:task", tasks[1].Type)
+	})
+
+	t.Run("should panic on duplicate registration", func(t *testing.T) {
+		// Setup
+		registry := NewTaskRegistry()
+		registry.Register("a:task", "first", samplePayload{})
+
+		// When / Then
+		assert.Panics(t, func() { registry.Register("a:task", "again", samplePayload{}) })
+	})
+}
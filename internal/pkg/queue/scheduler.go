@@ -0,0 +1,67 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Scheduler enqueues tasks on a fixed interval. Every worker replica runs one, so
+// periodic tasks should carry asynq.Unique to be enqueued once per interval, not once
+// per replica.
+type Scheduler struct {
+	scheduler *asynq.Scheduler
+	logger    *zap.Logger
+}
+
+func NewScheduler(cfg *config.Config, logger *zap.Logger) *Scheduler {
+	scheduler := asynq.NewScheduler(newRedisClientOpt(cfg), &asynq.SchedulerOpts{
+		Location: time.UTC,
+		Logger:   NewAsynqLogger(logger),
+		EnqueueErrorHandler: func(task *asynq.Task, opts []asynq.Option, err error) {
+			// Another replica already enqueued this run
+			if errors.Is(err, asynq.ErrDuplicateTask) {
+				return
+			}
+			logger.Error("Periodic task enqueue failed",
+				zap.String("task_type", task.Type()),
+				zap.Error(err))
+		},
+	})
+
+	return &Scheduler{
+		scheduler: scheduler,
+		logger:    logger,
+	}
+}
+
+// Every enqueues task once per interval
+func (s *Scheduler) Every(interval time.Duration, task *asynq.Task, opts ...asynq.Option) error {
+	if _, err := s.scheduler.Register("@every "+interval.String(), task, opts...); err != nil {
+		return err
+	}
+	s.logger.Info("Periodic task scheduled",
+		zap.String("task_type", task.Type()),
+		zap.Duration("interval", interval))
+	return nil
+}
+
+func (s *Scheduler) Start(lifecycle fx.Lifecycle) {
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			s.logger.Info("Starting task scheduler")
+			return s.scheduler.Start()
+		},
+		OnStop: func(ctx context.Context) error {
+			s.logger.Info("Stopping task scheduler")
+			s.scheduler.Shutdown()
+			return nil
+		},
+	})
+}
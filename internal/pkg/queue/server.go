@@ -2,7 +2,6 @@ package queue
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
 
@@ -11,29 +10,34 @@ import (
 	"go.uber.org/zap"
 )
 
+// serverQueues maps each queue the worker serves to its priority weight
+var serverQueues = map[string]int{
+	"critical": 6,
+	"default":  3,
+	"low":      1,
+}
+
 type Server struct {
 	server *asynq.Server
 	mux    *asynq.ServeMux
+	queues map[string]int
+	drain  *drainTracker
+	ping   pingFunc
 	logger *zap.Logger
 	cfg    *config.Config
 }
 
 func NewServer(cfg *config.Config, logger *zap.Logger) *Server {
-	redisAddr := fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port)
-
-	redisOpt := asynq.RedisClientOpt{
-		Addr:     redisAddr,
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-	}
+	redisOpt := newRedisClientOpt(cfg)
+	redisAddr := redisOpt.Addr
 
+	queues := serverQueues
 	serverConfig := asynq.Config{
 		Concurrency: cfg.Worker.Concurrency,
-		Queues: map[string]int{
-			"critical": 6,
-			"default":  3,
-			"low":      1,
-		},
+		Queues:      queues,
+		// The per-queue drain runs before Shutdown, so asynq only needs a short wait
+		// before requeueing whatever is still running
+		ShutdownTimeout: abandonTimeout,
 		ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
 			logger.Error("Task processing failed",
 				zap.String("task_type", task.Type()),
@@ -45,6 +49,8 @@ func NewServer(cfg *config.Config, logger *zap.Logger) *Server {
 
 	server := asynq.NewServer(redisOpt, serverConfig)
 	mux := asynq.NewServeMux()
+	drain := newDrainTracker()
+	mux.Use(drain.middleware)
 
 	logger.Info("Queue api initialized",
 		zap.String("redis_addr", redisAddr),
@@ -53,6 +59,9 @@ func NewServer(cfg *config.Config, logger *zap.Logger) *Server {
 	return &Server{
 		server: server,
 		mux:    mux,
+		queues: queues,
+		drain:  drain,
+		ping:   redisPinger(redisOpt),
 		logger: logger,
 		cfg:    cfg,
 	}
@@ -65,18 +74,34 @@ func (s *Server) RegisterHandler(pattern string, handler asynq.Handler) {
 func (s *Server) Start(lifecycle fx.Lifecycle) {
 	lifecycle.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			go func() {
-				s.logger.Info("Starting queue api")
-				if err := s.server.Run(s.mux); err != nil {
-					s.logger.Fatal("Queue api failed", zap.Error(err))
-				}
-			}()
-			return nil
-		},
-		OnStop: func(ctx context.Context) error {
-			s.logger.Info("Stopping queue api")
-			s.server.Shutdown()
+			// Fail fast instead of letting asynq retry against an unreachable Redis forever
+			err := waitForRedis(ctx, s.ping, s.cfg.Redis.ConnectRetries, s.cfg.Redis.ConnectRetryDelay, s.logger)
+			if err != nil {
+				s.logger.Error("Queue api cannot reach Redis", zap.Error(err))
+				return err
+			}
+			s.logger.Info("Queue connected", zap.String("redis_addr", newRedisClientOpt(s.cfg).Addr))
+
+			// Start rather than Run, so shutdown follows the fx lifecycle instead of
+			// asynq's own signal handling
+			s.logger.Info("Starting queue api")
+			if err := s.server.Start(s.mux); err != nil {
+				s.logger.Error("Queue api failed", zap.Error(err))
+				return err
+			}
 			return nil
 		},
+		OnStop: s.stop,
 	})
 }
+
+// stop stops fetching new tasks, gives each queue its own time to finish the ones in
+// flight, then shuts down and requeues anything still running. The drain only gets its
+// full timeouts while the fx stop timeout is at least StopTimeout.
+func (s *Server) stop(ctx context.Context) error {
+	s.logger.Info("Stopping queue api")
+	s.server.Stop()
+	s.drain.drain(ctx, queueNames(s.queues), s.cfg.Worker.DrainTimeouts, s.logger)
+	s.server.Shutdown()
+	return nil
+}
@@ -0,0 +1,72 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestWaitForRedis(t *testing.T) {
+	t.Run("should succeed once redis answers", func(t *testing.T) {
+		calls := 0
+		ping := func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return errors.New("connection refused")
+			}
+			return nil
+		}
+
+		err := waitForRedis(context.Background(), ping, 3, time.Millisecond, testutil.NewSilentLogger())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("should give up after configured retries", func(t *testing.T) {
+		calls := 0
+		ping := func(ctx context.Context) error {
+			calls++
+			return errors.New("connection refused")
+		}
+
+		err := waitForRedis(context.Background(), ping, 2, time.Millisecond, testutil.NewSilentLogger())
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "redis unreachable after 3 attempts")
+		assert.Contains(t, err.Error(), "connection refused")
+		assert.Equal(t, 3, calls)
+	})
+}
+
+func TestServer_Start(t *testing.T) {
+	t.Run("should fail startup when redis is down", func(t *testing.T) {
+		cfg := &config.Config{
+			Redis: config.RedisConfig{
+				Host:              "localhost",
+				Port:              6379,
+				ConnectRetries:    1,
+				ConnectRetryDelay: time.Millisecond,
+			},
+			Worker: config.WorkerConfig{Concurrency: 1},
+		}
+		server := NewServer(cfg, testutil.NewSilentLogger())
+		server.ping = func(ctx context.Context) error {
+			return errors.New("dial tcp: connection refused")
+		}
+
+		lifecycle := fxtest.NewLifecycle(t)
+		server.Start(lifecycle)
+		err := lifecycle.Start(context.Background())
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "redis unreachable")
+	})
+}
@@ -0,0 +1,94 @@
+// Package reload re-reads the configuration on SIGHUP and applies the settings that can
+// change safely while the process runs.
+package reload
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Reloader applies the log level and feature flags from a freshly loaded config. Other
+// settings, such as addresses, pools and timeouts, are wired into long-lived clients at
+// startup and still need a restart.
+type Reloader struct {
+	load   func() (*config.Config, error)
+	level  zap.AtomicLevel
+	flags  *featureflag.Flags
+	logger *zap.Logger
+}
+
+func NewReloader(level zap.AtomicLevel, flags *featureflag.Flags, logger *zap.Logger) *Reloader {
+	return &Reloader{
+		load:   config.NewConfig,
+		level:  level,
+		flags:  flags,
+		logger: logger,
+	}
+}
+
+// Reload reads the config again and applies its safe fields. Nothing is applied when the
+// config cannot be loaded or holds an invalid log level.
+func (r *Reloader) Reload() error {
+	cfg, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	level, err := zapcore.ParseLevel(cfg.Logger.Level)
+	if err != nil {
+		return err
+	}
+
+	previous := r.level.Level()
+	r.level.SetLevel(level)
+	r.flags.Reload(cfg)
+
+	r.logger.Info("Configuration reloaded",
+		zap.String("previous_log_level", previous.String()),
+		zap.String("log_level", level.String()),
+		zap.Any("feature_flags", r.flags.All()))
+	return nil
+}
+
+// ListenForSIGHUP reloads the config each time the process receives SIGHUP, until the
+// application stops
+func ListenForSIGHUP(lifecycle fx.Lifecycle, reloader *Reloader, logger *zap.Logger) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			signal.Notify(signals, syscall.SIGHUP)
+			go func() {
+				defer close(done)
+				for range signals {
+					if err := reloader.Reload(); err != nil {
+						logger.Error("Configuration reload failed, keeping current settings", zap.Error(err))
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			signal.Stop(signals)
+			close(signals)
+			<-done
+			return nil
+		},
+	})
+}
+
+// Module provides the Reloader and installs the SIGHUP handler
+var Module = fx.Options(
+	fx.Provide(NewReloader),
+	fx.Invoke(ListenForSIGHUP),
+)
@@ -0,0 +1,110 @@
+package reload
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func setupReloader(next *config.Config, loadErr error) (*Reloader, zap.AtomicLevel, *featureflag.Flags) {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	flags := featureflag.New(&config.Config{})
+	reloader := NewReloader(level, flags, testutil.NewSilentLogger())
+	reloader.load = func() (*config.Config, error) { return next, loadErr }
+	return reloader, level, flags
+}
+
+func TestReloader_Reload(t *testing.T) {
+	t.Run("should apply the reloaded log level and feature flags", func(t *testing.T) {
+		// Setup
+		next := &config.Config{
+			Logger:       config.LoggerConfig{Level: "debug"},
+			FeatureFlags: map[string]bool{featureflag.UserCache: false},
+		}
+		reloader, level, flags := setupReloader(next, nil)
+
+		// When
+		err := reloader.Reload()
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, zapcore.DebugLevel, level.Level())
+		assert.False(t, flags.IsEnabled(featureflag.UserCache))
+	})
+
+	t.Run("should keep runtime flag overrides", func(t *testing.T) {
+		// Setup
+		next := &config.Config{
+			Logger:       config.LoggerConfig{Level: "info"},
+			FeatureFlags: map[string]bool{featureflag.ScheduleOnCreate: true},
+		}
+		reloader, _, flags := setupReloader(next, nil)
+		flags.Set(featureflag.ScheduleOnCreate, false)
+
+		// When
+		err := reloader.Reload()
+
+		// Then
+		require.NoError(t, err)
+		assert.False(t, flags.IsEnabled(featureflag.ScheduleOnCreate))
+	})
+
+	t.Run("should change nothing when the log level is invalid", func(t *testing.T) {
+		// Setup
+		next := &config.Config{
+			Logger:       config.LoggerConfig{Level: "chatty"},
+			FeatureFlags: map[string]bool{featureflag.UserCache: false},
+		}
+		reloader, level, flags := setupReloader(next, nil)
+
+		// When
+		err := reloader.Reload()
+
+		// Then
+		assert.Error(t, err)
+		assert.Equal(t, zapcore.InfoLevel, level.Level())
+		assert.True(t, flags.IsEnabled(featureflag.UserCache))
+	})
+
+	t.Run("should change nothing when the config cannot be loaded", func(t *testing.T) {
+		// Setup
+		reloader, level, _ := setupReloader(nil, errors.New("yaml: line 3: did not find expected key"))
+
+		// When
+		err := reloader.Reload()
+
+		// Then
+		assert.EqualError(t, err, "yaml: line 3: did not find expected key")
+		assert.Equal(t, zapcore.InfoLevel, level.Level())
+	})
+}
+
+func TestListenForSIGHUP(t *testing.T) {
+	t.Run("should reload when the process receives SIGHUP", func(t *testing.T) {
+		// Setup
+		reloader, level, _ := setupReloader(&config.Config{Logger: config.LoggerConfig{Level: "warn"}}, nil)
+		lifecycle := fxtest.NewLifecycle(t)
+		ListenForSIGHUP(lifecycle, reloader, testutil.NewSilentLogger())
+		lifecycle.RequireStart()
+		defer lifecycle.RequireStop()
+
+		// When
+		require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+
+		// Then
+		assert.Eventually(t, func() bool {
+			return level.Level() == zapcore.WarnLevel
+		}, time.Second, 10*time.Millisecond)
+	})
+}
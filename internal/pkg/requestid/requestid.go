@@ -0,0 +1,42 @@
+// Package requestid defines the per-request ID that ties a response, including its
+// error body, to the server's log lines for that request.
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+const (
+	// Header carries the ID on requests from upstream proxies and on every response
+	Header = "X-Request-ID"
+	// ContextKey is where the middleware stores the ID on the gin context
+	ContextKey = "request_id"
+
+	maxLength = 64
+)
+
+// New returns a random 32-character hex ID
+func New() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Valid reports whether an ID sent by a client is safe to reuse: non-empty, at most 64
+// characters, and only letters, digits, '-', '_' or '.', so it cannot forge log lines
+// or headers
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package requestid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("should return distinct valid IDs", func(t *testing.T) {
+		// When
+		first, second := New(), New()
+
+		// Then
+		assert.Len(t, first, 32)
+		assert.True(t, Valid(first))
+		assert.NotEqual(t, first, second)
+	})
+}
+
+func TestValid(t *testing.T) {
+	t.Run("should accept IDs from common proxies", func(t *testing.T) {
+		assert.True(t, Valid("3f2a9c1e-6b7d-4e21-9a0f-5c8d2b1e4f60"))
+		assert.True(t, Valid("Root_1.abc"))
+	})
+
+	t.Run("should reject empty, long or unsafe IDs", func(t *testing.T) {
+		assert.False(t, Valid(""))
+		assert.False(t, Valid(strings.Repeat("a", 65)))
+		assert.False(t, Valid("two words"))
+		assert.False(t, Valid("line\nbreak"))
+		assert.False(t, Valid(`quote"`))
+	})
+}
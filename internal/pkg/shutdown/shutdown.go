@@ -0,0 +1,32 @@
+// Package shutdown orders how shared resources are released when an application stops.
+package shutdown
+
+import (
+	"sync/atomic"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/database"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
+
+	"go.uber.org/fx"
+)
+
+// Flag is set when the application begins shutting down, before servers drain
+type Flag struct {
+	atomic.Bool
+}
+
+func NewFlag() *Flag {
+	return &Flag{}
+}
+
+// Module provides the shutdown Flag and registers the close hooks for the database and
+// the queue client. Include it before any server is started: fx runs OnStop hooks in
+// reverse registration order, so servers stop accepting work and drain first, then the
+// queue client closes, and the database closes last.
+var Module = fx.Options(
+	fx.Provide(NewFlag),
+	fx.Invoke(
+		database.CloseOnStop,
+		queue.CloseClientOnStop,
+	),
+)
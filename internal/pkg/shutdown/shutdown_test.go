@@ -0,0 +1,65 @@
+package shutdown
+
+import (
+	"context"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+)
+
+func TestModule(t *testing.T) {
+	t.Run("should stop servers before closing the queue client and then the database", func(t *testing.T) {
+		// Setup: record log messages in order, with a server registered after the module
+		core, logs := observer.New(zap.InfoLevel)
+		logger := zap.New(core)
+
+		db, err := testutil.SetupTestDB()
+		require.NoError(t, err)
+
+		app := fxtest.New(t,
+			fx.Supply(logger, db, &config.Config{}),
+			fx.Provide(queue.NewClient),
+			Module,
+			fx.Invoke(func(lifecycle fx.Lifecycle, _ *gorm.DB, _ *queue.Client) {
+				lifecycle.Append(fx.Hook{
+					OnStop: func(ctx context.Context) error {
+						logger.Info("Stopping server")
+						return nil
+					},
+				})
+			}),
+		)
+		app.RequireStart()
+
+		// When
+		app.RequireStop()
+
+		// Then
+		var sequence []string
+		for _, entry := range logs.All() {
+			switch entry.Message {
+			case "Stopping server", "Closing queue client", "Closing database connections":
+				sequence = append(sequence, entry.Message)
+			}
+		}
+		assert.Equal(t, []string{
+			"Stopping server",
+			"Closing queue client",
+			"Closing database connections",
+		}, sequence)
+
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+		assert.Error(t, sqlDB.Ping(), "database should be closed")
+	})
+}
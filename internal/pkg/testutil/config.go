@@ -0,0 +1,24 @@
+package testutil
+
+import (
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
+)
+
+// NewTestConfig creates a config populated with the application defaults
+func NewTestConfig() *config.Config {
+	return &config.Config{
+		Logger: config.LoggerConfig{
+			RedactKeys: []string{"password", "current_password", "new_password", "token", "secret"},
+		},
+		Pagination: config.PaginationConfig{
+			DefaultPageSize: 10,
+			MaxPageSize:     100,
+		},
+	}
+}
+
+// NewTestFlags creates feature flags at their defaults
+func NewTestFlags() *featureflag.Flags {
+	return featureflag.New(NewTestConfig())
+}
@@ -1,43 +1,100 @@
 package testutil
 
 import (
+	"fmt"
+	"testing"
+	"time"
+
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
 	userEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	walletEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/idempotency"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/outbox"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// models lists every managed entity with parents ahead of the tables that reference
+// them, so migrating in order and cleaning in reverse respects foreign keys
+func models() []interface{} {
+	return []interface{}{
+		&userEntity.User{},
+		&entity.Payment{},
+		&entity.PaymentTag{},
+		&entity.ProcessedEvent{},
+		&entity.PaymentStatusChange{},
+		&entity.PaymentSequence{},
+		&walletEntity.Wallet{},
+		&walletEntity.Transaction{},
+		&outbox.Message{},
+		&idempotency.Key{},
+	}
+}
+
 // SetupTestDB creates an in-memory SQLite database for testing
 func SetupTestDB() (*gorm.DB, error) {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
+		Logger:  logger.Default.LogMode(logger.Silent),
+		NowFunc: func() time.Time { return time.Now().UTC() },
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	// Auto-migrate all entities
-	err = db.AutoMigrate(
-		&userEntity.User{},
-		&entity.Payment{},
-	)
-	if err != nil {
+	if err := db.AutoMigrate(models()...); err != nil {
 		return nil, err
 	}
 
 	return db, nil
 }
 
-// CleanDB cleans all data from test database
-func CleanDB(db *gorm.DB) error {
-	// Delete in reverse order of dependencies
-	if err := db.Exec("DELETE FROM payments").Error; err != nil {
-		return err
+// TableNames returns the managed tables in the order CleanAll empties them
+func TableNames(db *gorm.DB) ([]string, error) {
+	all := models()
+	names := make([]string, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(all[i]); err != nil {
+			return nil, fmt.Errorf("parse %T: %w", all[i], err)
+		}
+		names = append(names, stmt.Schema.Table)
 	}
-	if err := db.Exec("DELETE FROM users").Error; err != nil {
+	return names, nil
+}
+
+// CleanAll empties every managed table, children first, and resets SQLite's
+// autoincrement counters so IDs start over like a truncate
+func CleanAll(db *gorm.DB) error {
+	tables, err := TableNames(db)
+	if err != nil {
 		return err
 	}
-	return nil
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range tables {
+			if err := tx.Exec("DELETE FROM " + tx.Statement.Quote(table)).Error; err != nil {
+				return fmt.Errorf("clean %s: %w", table, err)
+			}
+		}
+		if tx.Dialector.Name() == "sqlite" && tx.Migrator().HasTable("sqlite_sequence") {
+			if err := tx.Exec("DELETE FROM sqlite_sequence").Error; err != nil {
+				return fmt.Errorf("reset sequences: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// WithCleanDB empties the database when the test finishes, including when it stops
+// early on a failed assertion
+func WithCleanDB(t testing.TB, db *gorm.DB) {
+	t.Helper()
+	t.Cleanup(func() {
+		if err := CleanAll(db); err != nil {
+			t.Errorf("clean test database: %v", err)
+		}
+	})
 }
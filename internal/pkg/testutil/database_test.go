@@ -0,0 +1,120 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	walletEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func seedAll(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	user := CreateUserFixture()
+	user.ID = 0
+	require.NoError(t, db.Create(user).Error)
+
+	payment := CreatePaymentFixture()
+	payment.ID = 0
+	payment.UserID = user.ID
+	payment.ReferenceNumber = "PAY-000001"
+	require.NoError(t, db.Create(payment).Error)
+	require.NoError(t, db.Create(&entity.PaymentTag{PaymentID: payment.ID, Tag: "monthly"}).Error)
+	require.NoError(t, db.Create(&entity.ProcessedEvent{Gateway: "simulated", EventID: "evt_1", PaymentID: payment.ID}).Error)
+	require.NoError(t, db.Create(&entity.PaymentStatusChange{PaymentID: payment.ID, FromStatus: "pending", ToStatus: "completed", Actor: "system"}).Error)
+	require.NoError(t, db.Create(&entity.PaymentSequence{Name: "payment_reference", Value: 1}).Error)
+
+	wallet := CreateWalletFixture()
+	wallet.ID = 0
+	wallet.UserID = user.ID
+	require.NoError(t, db.Create(wallet).Error)
+	require.NoError(t, db.Create(&walletEntity.Transaction{WalletID: wallet.ID, Type: walletEntity.TransactionTypeDeposit, Amount: 10, Currency: wallet.Currency, BalanceAfter: 10}).Error)
+}
+
+func assertAllEmpty(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	tables, err := TableNames(db)
+	require.NoError(t, err)
+	for _, table := range tables {
+		var count int64
+		require.NoError(t, db.Table(table).Count(&count).Error)
+		assert.Zero(t, count, "table %s should be empty", table)
+	}
+}
+
+func TestCleanAll(t *testing.T) {
+	t.Run("should empty every managed table", func(t *testing.T) {
+		// Setup
+		db, err := SetupTestDB()
+		require.NoError(t, err)
+
+		// Given
+		seedAll(t, db)
+
+		// When
+		err = CleanAll(db)
+
+		// Then
+		require.NoError(t, err)
+		assertAllEmpty(t, db)
+	})
+
+	t.Run("should restart IDs after cleaning", func(t *testing.T) {
+		// Setup
+		db, err := SetupTestDB()
+		require.NoError(t, err)
+		seedAll(t, db)
+		require.NoError(t, CleanAll(db))
+
+		// When
+		user := CreateUserFixture()
+		user.ID = 0
+		require.NoError(t, db.Create(user).Error)
+
+		// Then
+		assert.Equal(t, uint(1), user.ID)
+	})
+
+	t.Run("should list children before their parents", func(t *testing.T) {
+		db, err := SetupTestDB()
+		require.NoError(t, err)
+
+		tables, err := TableNames(db)
+
+		require.NoError(t, err)
+		assert.Less(t, indexOf(tables, "transactions"), indexOf(tables, "wallets"))
+		assert.Less(t, indexOf(tables, "payments"), indexOf(tables, "users"))
+		assert.Less(t, indexOf(tables, "payment_tags"), indexOf(tables, "payments"))
+	})
+}
+
+func TestWithCleanDB(t *testing.T) {
+	t.Run("should clean up after the test finishes", func(t *testing.T) {
+		// Setup
+		db, err := SetupTestDB()
+		require.NoError(t, err)
+
+		// When
+		t.Run("seeding test", func(t *testing.T) {
+			WithCleanDB(t, db)
+			seedAll(t, db)
+		})
+
+		// Then
+		assertAllEmpty(t, db)
+	})
+}
+
+func indexOf(values []string, target string) int {
+	for i, v := range values {
+		if v == target {
+			return i
+		}
+	}
+	return -1
+}
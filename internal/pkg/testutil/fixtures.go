@@ -7,6 +7,8 @@ import (
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
 	userDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
 	userEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	walletEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/pagination"
 )
 
 // User fixtures
@@ -16,8 +18,8 @@ func CreateUserFixture() *userEntity.User {
 		Name:      "John Doe",
 		Email:     "john@example.com",
 		Password:  "$2a$10$example.hashed.password",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
 	}
 }
 
@@ -45,8 +47,8 @@ func CreatePaymentFixture() *entity.Payment {
 		Status:      entity.PaymentStatusPending,
 		Description: "Test payment",
 		UserID:      1,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
 	}
 }
 
@@ -68,10 +70,21 @@ func CreateUpdatePaymentRequestFixture() *dto.UpdatePaymentRequest {
 
 func CreatePaymentFilterFixture() *dto.PaymentFilter {
 	return &dto.PaymentFilter{
-		Status:   "pending",
-		Currency: "USD",
-		UserID:   1,
-		Page:     1,
-		PageSize: 10,
+		Status:     "pending",
+		Currency:   "USD",
+		UserID:     1,
+		Pagination: pagination.Pagination{Page: 1, PageSize: 10},
+	}
+}
+
+// Wallet fixtures
+func CreateWalletFixture() *walletEntity.Wallet {
+	return &walletEntity.Wallet{
+		ID:        1,
+		UserID:    1,
+		Currency:  "USD",
+		Balance:   250.75,
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
 	}
 }
@@ -1,10 +1,14 @@
 package testutil
 
 import (
+	"time"
+
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/dto"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
 	userDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/dto"
 	userEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	walletDto "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/dto"
+	walletEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -35,6 +39,15 @@ func (m *MockUserRepository) GetByEmail(email string) (*userEntity.User, error)
 	return args.Get(0).(*userEntity.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetByIDs(ids []uint) ([]userEntity.User, error) {
+	args := m.Called(ids)
+	var users []userEntity.User
+	if args.Get(0) != nil {
+		users = args.Get(0).([]userEntity.User)
+	}
+	return users, args.Error(1)
+}
+
 func (m *MockUserRepository) GetAll(filter *userDto.UserFilter) ([]userEntity.User, int64, error) {
 	args := m.Called(filter)
 	var users []userEntity.User
@@ -59,6 +72,19 @@ func (m *MockUserRepository) Delete(id uint) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) GetByIDWithDeleted(id uint) (*userEntity.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userEntity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Restore(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) EmailExists(email string) (bool, error) {
 	args := m.Called(email)
 	return args.Bool(0), args.Error(1)
@@ -69,8 +95,13 @@ type MockPaymentRepository struct {
 	mock.Mock
 }
 
-func (m *MockPaymentRepository) Create(payment *entity.Payment) error {
-	args := m.Called(payment)
+func (m *MockPaymentRepository) Create(payment *entity.Payment, events ...string) error {
+	args := m.Called(payment, events)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) CreateForUser(payment *entity.Payment, withWallet bool, maxActive int, events ...string) error {
+	args := m.Called(payment, withWallet, maxActive, events)
 	return args.Error(0)
 }
 
@@ -115,6 +146,146 @@ func (m *MockPaymentRepository) GetByUserID(userID uint) ([]entity.Payment, erro
 	return payments, args.Error(1)
 }
 
+func (m *MockPaymentRepository) GetStatusHistory(paymentID uint) ([]entity.PaymentStatusChange, error) {
+	args := m.Called(paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.PaymentStatusChange), args.Error(1)
+}
+
+func (m *MockPaymentRepository) BulkUpdateStatus(
+	ids []uint,
+	status entity.PaymentStatus,
+	actor string,
+) ([]entity.Payment, error) {
+	args := m.Called(ids, status, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) BulkDelete(ids []uint, force bool) ([]entity.Payment, error) {
+	args := m.Called(ids, force)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Payment), args.Error(1)
+}
+
+// UpdateLocked goes through GetByID and Update, so tests set their expectations on those
+func (m *MockPaymentRepository) UpdateLocked(
+	id uint,
+	apply func(payment *entity.Payment) (bool, error),
+) (*entity.Payment, error) {
+	payment, err := m.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := apply(payment)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		if err := m.Update(payment); err != nil {
+			return nil, err
+		}
+	}
+	return payment, nil
+}
+
+func (m *MockPaymentRepository) ApplyGatewayEvent(
+	event *entity.ProcessedEvent,
+	apply func(payment *entity.Payment) (bool, error),
+) (*entity.Payment, error) {
+	args := m.Called(event.Gateway, event.EventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	payment := args.Get(0).(*entity.Payment)
+	if _, err := apply(payment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// MockWalletRepository is a mock implementation of WalletRepository
+type MockWalletRepository struct {
+	mock.Mock
+}
+
+func (m *MockWalletRepository) Create(wallet *walletEntity.Wallet) error {
+	args := m.Called(wallet)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepository) GetByID(id uint) (*walletEntity.Wallet, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*walletEntity.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) GetByUserAndCurrency(userID uint, currency string) (*walletEntity.Wallet, error) {
+	args := m.Called(userID, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*walletEntity.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) GetByUserID(userID uint) ([]walletEntity.Wallet, error) {
+	args := m.Called(userID)
+	var wallets []walletEntity.Wallet
+	if args.Get(0) != nil {
+		wallets = args.Get(0).([]walletEntity.Wallet)
+	}
+	return wallets, args.Error(1)
+}
+
+// ApplyBalanceChange hands the configured wallet to change, standing in for the locked
+// read, so the service's balance rules run against it
+func (m *MockWalletRepository) ApplyBalanceChange(
+	walletID uint,
+	change func(wallet *walletEntity.Wallet) (*walletEntity.Transaction, error),
+) (*walletEntity.Wallet, *walletEntity.Transaction, error) {
+	args := m.Called(walletID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(1)
+	}
+	wallet := args.Get(0).(*walletEntity.Wallet)
+	transaction, err := change(wallet)
+	if err != nil {
+		return nil, nil, err
+	}
+	transaction.WalletID = wallet.ID
+	return wallet, transaction, nil
+}
+
+// MockTransactionRepository is a mock implementation of TransactionRepository
+type MockTransactionRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransactionRepository) GetByWallet(
+	walletID uint,
+	filter *walletDto.TransactionFilter,
+) ([]walletEntity.Transaction, int64, error) {
+	args := m.Called(walletID, filter)
+	var transactions []walletEntity.Transaction
+	if args.Get(0) != nil {
+		transactions = args.Get(0).([]walletEntity.Transaction)
+	}
+
+	var count int64
+	if args.Get(1) != nil {
+		count = args.Get(1).(int64)
+	}
+	return transactions, count, args.Error(2)
+}
+
 // MockUserService is a mock implementation of UserService
 type MockUserService struct {
 	mock.Mock
@@ -128,6 +299,14 @@ func (m *MockUserService) CreateUser(req *userDto.CreateUserRequest) (*userDto.U
 	return args.Get(0).(*userDto.UserResponse), args.Error(1)
 }
 
+func (m *MockUserService) CreateUserOrGetExisting(req *userDto.CreateUserRequest) (*userDto.UserResponse, bool, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*userDto.UserResponse), args.Bool(1), args.Error(2)
+}
+
 func (m *MockUserService) GetUserByID(id uint) (*userDto.UserResponse, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -144,6 +323,14 @@ func (m *MockUserService) GetUserByEmail(email string) (*userDto.UserResponse, e
 	return args.Get(0).(*userDto.UserResponse), args.Error(1)
 }
 
+func (m *MockUserService) GetUsersByIDs(ids []uint) (map[uint]*userDto.UserResponse, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uint]*userDto.UserResponse), args.Error(1)
+}
+
 func (m *MockUserService) GetUsers(filter *userDto.UserFilter) (*userDto.UserListResponse, error) {
 	args := m.Called(filter)
 	if args.Get(0) == nil {
@@ -169,3 +356,80 @@ func (m *MockUserService) DeleteUser(id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
+
+func (m *MockUserService) RestoreUser(id uint) (*userDto.UserResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userDto.UserResponse), args.Error(1)
+}
+
+// MockWalletService is a mock implementation of WalletService
+type MockWalletService struct {
+	mock.Mock
+}
+
+func (m *MockWalletService) GetWalletsByUser(userID uint) ([]walletDto.WalletResponse, error) {
+	args := m.Called(userID)
+	var wallets []walletDto.WalletResponse
+	if args.Get(0) != nil {
+		wallets = args.Get(0).([]walletDto.WalletResponse)
+	}
+	return wallets, args.Error(1)
+}
+
+func (m *MockWalletService) RequireWallet(userID uint, currency string) (bool, error) {
+	args := m.Called(userID, currency)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWalletService) Deposit(walletID uint, req *walletDto.BalanceChangeRequest) (*walletDto.BalanceChangeResponse, error) {
+	args := m.Called(walletID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*walletDto.BalanceChangeResponse), args.Error(1)
+}
+
+func (m *MockWalletService) Withdraw(walletID uint, req *walletDto.BalanceChangeRequest) (*walletDto.BalanceChangeResponse, error) {
+	args := m.Called(walletID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*walletDto.BalanceChangeResponse), args.Error(1)
+}
+
+func (m *MockWalletService) GetTransactions(
+	walletID uint,
+	filter *walletDto.TransactionFilter,
+) (*walletDto.TransactionListResponse, error) {
+	args := m.Called(walletID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*walletDto.TransactionListResponse), args.Error(1)
+}
+
+// NewMockWalletService returns a MockWalletService whose RequireWallet finds a wallet in
+// any currency, for tests that create payments without exercising wallets
+func NewMockWalletService() *MockWalletService {
+	m := &MockWalletService{}
+	m.On("RequireWallet", mock.Anything, mock.Anything).Return(false, nil).Maybe()
+	return m
+}
+
+// MockTaskScheduler is a mock implementation of the payment TaskScheduler
+type MockTaskScheduler struct {
+	mock.Mock
+}
+
+func (m *MockTaskScheduler) SchedulePaymentProcessing(paymentID uint) error {
+	args := m.Called(paymentID)
+	return args.Error(0)
+}
+
+func (m *MockTaskScheduler) SchedulePaymentStatusCheck(paymentID uint, delay time.Duration) error {
+	args := m.Called(paymentID, delay)
+	return args.Error(0)
+}
@@ -0,0 +1,28 @@
+// Package timezone resolves the tz query parameter that read endpoints use to format
+// timestamps. Timestamps are always stored in UTC.
+package timezone
+
+import (
+	"time"
+
+	// Embed the zone database so lookups work on images without one installed
+	_ "time/tzdata"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+)
+
+// ErrInvalid is returned when tz is not an IANA time zone name
+var ErrInvalid = apperror.New(apperror.CodeInvalidTimezone, "invalid timezone")
+
+// Parse resolves a tz query value such as "Asia/Jakarta". An empty value keeps
+// timestamps in UTC; the server's own zone ("Local") is not accepted.
+func Parse(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || loc == time.Local {
+		return nil, ErrInvalid
+	}
+	return loc, nil
+}
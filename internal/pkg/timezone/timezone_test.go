@@ -0,0 +1,41 @@
+package timezone
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("should default to UTC", func(t *testing.T) {
+		// When
+		loc, err := Parse("")
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, time.UTC, loc)
+	})
+
+	t.Run("should resolve an IANA zone", func(t *testing.T) {
+		// When
+		loc, err := Parse("Asia/Jakarta")
+
+		// Then
+		require.NoError(t, err)
+		stored := time.Date(2024, 3, 1, 20, 30, 0, 0, time.UTC)
+		assert.Equal(t, "2024-03-02T03:30:00+07:00", stored.In(loc).Format(time.RFC3339))
+	})
+
+	t.Run("should reject unknown and server-local zones", func(t *testing.T) {
+		for _, name := range []string{"Mars/Olympus", "Local", "+07:00"} {
+			// When
+			loc, err := Parse(name)
+
+			// Then
+			assert.ErrorIs(t, err, ErrInvalid, name)
+			assert.Nil(t, loc)
+		}
+	})
+}
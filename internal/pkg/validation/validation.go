@@ -0,0 +1,153 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report fields by the names clients send rather than Go struct field names. This
+	// must run before the first request is validated, since validator caches struct metadata.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+		_ = v.RegisterValidation("finite", finite)
+		_ = v.RegisterValidation("notblank", notBlank)
+	}
+}
+
+// IsFinite reports whether f is neither NaN nor an infinity
+func IsFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// finite rejects NaN and infinite floats, which gt=0 alone lets through as +Inf
+func finite(fl validator.FieldLevel) bool {
+	switch fl.Field().Kind() {
+	case reflect.Float32, reflect.Float64:
+		return IsFinite(fl.Field().Float())
+	default:
+		return true
+	}
+}
+
+// notBlank rejects strings made only of whitespace, which required lets through
+func notBlank(fl validator.FieldLevel) bool {
+	if fl.Field().Kind() != reflect.String {
+		return true
+	}
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+// fieldName returns the json name of a field, falling back to its form name for query
+// parameters and to the Go name when neither tag is set
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// FieldErrors maps each invalid field in err to a readable message. It reports false when
+// err is not a validation failure, such as malformed JSON.
+func FieldErrors(err error) (map[string]string, bool) {
+	return fieldErrors(err, i18n.English)
+}
+
+// fieldErrors is FieldErrors with messages in locale
+func fieldErrors(err error, locale string) (map[string]string, bool) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil, false
+	}
+
+	fields := make(map[string]string, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields[fieldErr.Field()] = message(fieldErr, locale)
+	}
+	return fields, true
+}
+
+// BindErrorResponse returns the status and body for a failed ShouldBind call: 422 with
+// per-field messages for validation failures, 400 with the error otherwise.
+func BindErrorResponse(ctx apperror.Context, err error) (int, gin.H) {
+	if fields, ok := fieldErrors(err, ctx.GetString(i18n.ContextKey)); ok {
+		return FieldsResponse(ctx, fields)
+	}
+	return http.StatusBadRequest, apperror.Body(ctx, apperror.CodeInvalidRequest, err.Error())
+}
+
+// FieldsResponse returns a 422 validation failure with a message per field, for checks
+// a handler makes beyond binding. English field messages are translated like the
+// envelope message.
+func FieldsResponse(ctx apperror.Context, fields map[string]string) (int, gin.H) {
+	locale := ctx.GetString(i18n.ContextKey)
+	translated := make(map[string]string, len(fields))
+	for field, msg := range fields {
+		translated[field] = i18n.Translate(locale, msg)
+	}
+
+	body := apperror.Body(ctx, apperror.CodeValidationFailed, "validation failed")
+	body["fields"] = translated
+	return http.StatusUnprocessableEntity, body
+}
+
+// message describes fieldErr in locale. Formats are translated before the parameter is
+// filled in, so the catalogs hold one entry per format.
+func message(fieldErr validator.FieldError, locale string) string {
+	param := fieldErr.Param()
+	isString := fieldErr.Kind() == reflect.String
+	format := func(msg string, args ...any) string {
+		return fmt.Sprintf(i18n.Translate(locale, msg), args...)
+	}
+
+	switch fieldErr.Tag() {
+	case "required":
+		return format("is required")
+	case "email":
+		return format("must be a valid email address")
+	case "min":
+		if isString {
+			return format("must be at least %s characters", param)
+		}
+		return format("must be at least %s", param)
+	case "max":
+		if isString {
+			return format("must be at most %s characters", param)
+		}
+		return format("must be at most %s", param)
+	case "len":
+		if isString {
+			return format("must be exactly %s characters", param)
+		}
+		return format("must have length %s", param)
+	case "gt":
+		return format("must be greater than %s", param)
+	case "gte":
+		return format("must be at least %s", param)
+	case "finite":
+		return format("must be a finite number")
+	case "notblank":
+		return format("must not be blank")
+	case "oneof":
+		return format("must be one of: %s", strings.Join(strings.Fields(param), ", "))
+	default:
+		return format("failed the %s validation", fieldErr.Tag())
+	}
+}
@@ -0,0 +1,197 @@
+package validation
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type signupRequest struct {
+	Name     string  `json:"name" binding:"required"`
+	Email    string  `json:"email" binding:"required,email"`
+	Password string  `json:"password" binding:"required,min=8"`
+	Currency string  `json:"currency" binding:"omitempty,len=3"`
+	Amount   float64 `json:"amount" binding:"omitempty,gt=0"`
+	Status   string  `json:"status" binding:"omitempty,oneof=pending completed"`
+}
+
+func bind(t *testing.T, body string) error {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("POST", "/", bytes.NewBufferString(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	var req signupRequest
+	return ctx.ShouldBindJSON(&req)
+}
+
+func TestFieldErrors(t *testing.T) {
+	t.Run("should key messages by json field name", func(t *testing.T) {
+		// Given
+		err := bind(t, `{"name":"John","password":"short"}`)
+		require.Error(t, err)
+
+		// When
+		fields, ok := FieldErrors(err)
+
+		// Then
+		assert.True(t, ok)
+		assert.Equal(t, map[string]string{
+			"email":    "is required",
+			"password": "must be at least 8 characters",
+		}, fields)
+	})
+
+	t.Run("should describe format and range rules", func(t *testing.T) {
+		// Given
+		err := bind(t, `{"name":"John","email":"not-an-email","password":"longenough","currency":"US","amount":-1,"status":"lost"}`)
+		require.Error(t, err)
+
+		// When
+		fields, ok := FieldErrors(err)
+
+		// Then
+		assert.True(t, ok)
+		assert.Equal(t, "must be a valid email address", fields["email"])
+		assert.Equal(t, "must be exactly 3 characters", fields["currency"])
+		assert.Equal(t, "must be greater than 0", fields["amount"])
+		assert.Equal(t, "must be one of: pending, completed", fields["status"])
+	})
+
+	t.Run("should not treat other errors as validation failures", func(t *testing.T) {
+		fields, ok := FieldErrors(errors.New("unexpected EOF"))
+
+		assert.False(t, ok)
+		assert.Nil(t, fields)
+	})
+}
+
+func TestBindErrorResponse(t *testing.T) {
+	t.Run("should return 422 with fields for validation failures", func(t *testing.T) {
+		// Given
+		err := bind(t, `{"name":"John","email":"john@example.com"}`)
+		require.Error(t, err)
+
+		// When
+		status, body := BindErrorResponse(&gin.Context{}, err)
+
+		// Then
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Equal(t, "validation failed", body["error"])
+		assert.Equal(t, map[string]string{"password": "is required"}, body["fields"])
+	})
+
+	t.Run("should localize messages for the request's locale", func(t *testing.T) {
+		// Given
+		err := bind(t, `{"name":"John","email":"john@example.com","password":"short"}`)
+		require.Error(t, err)
+		ctx := &gin.Context{}
+		ctx.Set(i18n.ContextKey, i18n.Indonesian)
+
+		// When
+		status, body := BindErrorResponse(ctx, err)
+
+		// Then
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Equal(t, "validasi gagal", body["error"])
+		assert.Equal(t, map[string]string{"password": "minimal 8 karakter"}, body["fields"])
+	})
+
+	t.Run("should return 400 for malformed bodies", func(t *testing.T) {
+		// Given
+		err := bind(t, `{"name":`)
+		require.Error(t, err)
+
+		// When
+		status, body := BindErrorResponse(&gin.Context{}, err)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.NotEqual(t, "validation failed", body["error"])
+	})
+}
+
+func TestFinite(t *testing.T) {
+	type amountRequest struct {
+		Amount float64 `json:"amount" binding:"required,gt=0,finite"`
+	}
+
+	cases := []struct {
+		name   string
+		amount float64
+		valid  bool
+	}{
+		{name: "NaN", amount: math.NaN(), valid: false},
+		{name: "positive infinity", amount: math.Inf(1), valid: false},
+		{name: "zero", amount: 0, valid: false},
+		{name: "normal value", amount: 100.5, valid: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// When
+			err := binding.Validator.ValidateStruct(&amountRequest{Amount: tc.amount})
+
+			// Then
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+			fields, ok := FieldErrors(err)
+			require.True(t, ok)
+			assert.Contains(t, fields, "amount")
+		})
+	}
+
+	t.Run("should describe non-finite values", func(t *testing.T) {
+		err := binding.Validator.ValidateStruct(&amountRequest{Amount: math.Inf(1)})
+
+		fields, ok := FieldErrors(err)
+
+		require.True(t, ok)
+		assert.Equal(t, "must be a finite number", fields["amount"])
+	})
+}
+
+func TestNotBlank(t *testing.T) {
+	type nameRequest struct {
+		Name string `json:"name" binding:"required,notblank"`
+	}
+
+	cases := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{name: "spaces", value: "   ", valid: false},
+		{name: "tabs and newlines", value: "\t\n", valid: false},
+		{name: "padded name", value: "  John  ", valid: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// When
+			err := binding.Validator.ValidateStruct(&nameRequest{Name: tc.value})
+
+			// Then
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+			fields, ok := FieldErrors(err)
+			require.True(t, ok)
+			assert.Equal(t, "must not be blank", fields["name"])
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignatureHeader carries the signature of a webhook delivery's body
+const SignatureHeader = "X-Webhook-Signature"
+
+// signaturePrefix names the scheme in front of the hex digest, as in "sha256=ab12..."
+const signaturePrefix = "sha256="
+
+// ComputeSignature returns the HMAC-SHA256 of body keyed by secret, in the
+// "sha256=<hex>" form sent in SignatureHeader.
+func ComputeSignature(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature matches body under secret. The
+// "sha256=" prefix is optional, and the comparison runs in constant time so the
+// expected digest cannot be recovered by timing repeated attempts.
+func VerifySignature(body []byte, signature, secret string) bool {
+	if secret == "" {
+		return false
+	}
+
+	given, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(given, mac.Sum(nil))
+}
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"event":"payment.completed","payment_id":42}`)
+	secret := "whsec_test"
+
+	t.Run("should accept a correctly signed body", func(t *testing.T) {
+		// Given
+		signature := ComputeSignature(body, secret)
+
+		// When
+		valid := VerifySignature(body, signature, secret)
+
+		// Then
+		assert.True(t, valid)
+	})
+
+	t.Run("should accept a bare hex digest", func(t *testing.T) {
+		signature := strings.TrimPrefix(ComputeSignature(body, secret), "sha256=")
+
+		assert.True(t, VerifySignature(body, signature, secret))
+	})
+
+	t.Run("should reject a tampered body", func(t *testing.T) {
+		// Given
+		signature := ComputeSignature(body, secret)
+		tampered := []byte(`{"event":"payment.completed","payment_id":43}`)
+
+		// When
+		valid := VerifySignature(tampered, signature, secret)
+
+		// Then
+		assert.False(t, valid)
+	})
+
+	t.Run("should reject a signature made with another secret", func(t *testing.T) {
+		signature := ComputeSignature(body, "other")
+
+		assert.False(t, VerifySignature(body, signature, secret))
+	})
+
+	t.Run("should reject malformed signatures and an empty secret", func(t *testing.T) {
+		assert.False(t, VerifySignature(body, "sha256=not-hex", secret))
+		assert.False(t, VerifySignature(body, "", secret))
+		assert.False(t, VerifySignature(body, ComputeSignature(body, ""), ""))
+	})
+}
+
+// ExampleVerifySignature shows how a consumer validates a delivery: read the raw
+// body, take the X-Webhook-Signature header, and check it against the shared secret
+// before trusting the payload.
+func ExampleVerifySignature() {
+	secret := "whsec_test"
+	body := []byte(`{"event":"payment.completed","payment_id":42}`)
+	header := ComputeSignature(body, secret) // sent by us in SignatureHeader
+
+	fmt.Println(VerifySignature(body, header, secret))
+	fmt.Println(VerifySignature([]byte(`{"event":"payment.completed","payment_id":1}`), header, secret))
+	// Output:
+	// true
+	// false
+}
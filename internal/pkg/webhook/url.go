@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ErrDisallowedURL is wrapped by every URLPolicy rejection
+var ErrDisallowedURL = errors.New("webhook URL not allowed")
+
+// blockedHostnames are names that reach the local machine or cloud metadata services
+var blockedHostnames = []string{"localhost", "metadata", "metadata.google.internal"}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which net.IP does not
+// classify as private
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// URLPolicy decides which URLs may receive webhook deliveries. Internal targets
+// (loopback, private and link-local addresses, metadata endpoints) are always
+// rejected so a registered webhook cannot make the service call into its own network.
+type URLPolicy struct {
+	// AllowedHosts, when set, restricts targets to these hosts and their subdomains
+	AllowedHosts []string
+	// DeniedHosts rejects these hosts and their subdomains
+	DeniedHosts []string
+	// LookupIP resolves hostnames so names pointing at internal addresses are rejected
+	// too; nil skips resolution and checks only IP literals
+	LookupIP func(host string) ([]net.IP, error)
+}
+
+// Validate returns nil when rawURL is an http(s) URL the policy allows, otherwise an
+// error wrapping ErrDisallowedURL that says why.
+func (p URLPolicy) Validate(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDisallowedURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", ErrDisallowedURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in the URL are not allowed", ErrDisallowedURL)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrDisallowedURL)
+	}
+
+	for _, blocked := range blockedHostnames {
+		if matchesHost(host, blocked) {
+			return fmt.Errorf("%w: host %q is internal", ErrDisallowedURL, host)
+		}
+	}
+	for _, denied := range p.DeniedHosts {
+		if matchesHost(host, denied) {
+			return fmt.Errorf("%w: host %q is denied", ErrDisallowedURL, host)
+		}
+	}
+	if len(p.AllowedHosts) > 0 && !matchesAny(host, p.AllowedHosts) {
+		return fmt.Errorf("%w: host %q is not in the allowlist", ErrDisallowedURL, host)
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return checkIP(host, ip)
+	}
+
+	if p.LookupIP != nil {
+		ips, err := p.LookupIP(host)
+		if err != nil {
+			return fmt.Errorf("%w: cannot resolve host %q: %v", ErrDisallowedURL, host, err)
+		}
+		for _, ip := range ips {
+			if err := checkIP(host, ip); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkIP(host string, ip net.IP) error {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("%w: host %q points at internal address %s", ErrDisallowedURL, host, ip)
+	}
+	return nil
+}
+
+func matchesAny(host string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matchesHost(host, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesHost reports whether host is pattern or a subdomain of it
+func matchesHost(host, pattern string) bool {
+	pattern = strings.TrimSuffix(strings.ToLower(pattern), ".")
+	return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
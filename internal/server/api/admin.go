@@ -0,0 +1,213 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/purge"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type logLevelRequest struct {
+	Level string `json:"level" binding:"required" example:"debug"`
+}
+
+type featureFlagRequest struct {
+	Enabled *bool `json:"enabled" binding:"required" example:"false"`
+}
+
+type purgeRequest struct {
+	// Retention overrides purge.retention for this run
+	Retention string `json:"retention" example:"720h"`
+}
+
+// taskEnqueuer is the part of the queue client admin endpoints enqueue tasks through
+type taskEnqueuer interface {
+	Enqueue(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
+}
+
+func (s *Server) registerAdminRoutes(api *gin.RouterGroup) {
+	admin := api.Group("/admin")
+	{
+		admin.GET("/log-level", s.getLogLevel)
+		admin.PUT("/log-level", s.setLogLevel)
+		admin.GET("/feature-flags", s.getFeatureFlags)
+		admin.PUT("/feature-flags/:name", s.setFeatureFlag)
+		admin.DELETE("/feature-flags/:name", s.resetFeatureFlag)
+		admin.GET("/tasks", s.getTasks)
+		admin.POST("/purge", s.purgeSoftDeleted)
+	}
+}
+
+// GetLogLevel godoc
+// @Summary Get the log level
+// @Description Get the level the running server logs at
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Current log level"
+// @Router /admin/log-level [get]
+func (s *Server) getLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"level": s.logLevel.Level().String()}})
+}
+
+// SetLogLevel godoc
+// @Summary Change the log level
+// @Description Change the level the running server logs at without a restart
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param level body logLevelRequest true "New log level (debug, info, warn, error, dpanic, panic, fatal)"
+// @Success 200 {object} map[string]interface{} "Updated log level"
+// @Failure 400 {object} map[string]interface{} "Invalid log level"
+// @Router /admin/log-level [put]
+func (s *Server) setLogLevel(c *gin.Context) {
+	var req logLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.JSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		apperror.Message(c, http.StatusBadRequest, apperror.CodeInvalidRequest, "invalid log level")
+		return
+	}
+
+	previous := s.logLevel.Level()
+	s.logLevel.SetLevel(level)
+	s.logger.Warn("Log level changed",
+		zap.String("from", previous.String()),
+		zap.String("to", level.String()))
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"level": level.String()}})
+}
+
+// GetFeatureFlags godoc
+// @Summary List feature flags
+// @Description Get the effective value of every feature flag, runtime overrides included
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Feature flags by name"
+// @Router /admin/feature-flags [get]
+func (s *Server) getFeatureFlags(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": s.flags.All()})
+}
+
+// SetFeatureFlag godoc
+// @Summary Override a feature flag
+// @Description Turn a feature flag on or off without a restart. The override lives in this process only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param name path string true "Feature flag name"
+// @Param flag body featureFlagRequest true "New flag value"
+// @Success 200 {object} map[string]interface{} "Updated feature flag"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Unknown feature flag"
+// @Router /admin/feature-flags/{name} [put]
+func (s *Server) setFeatureFlag(c *gin.Context) {
+	name := c.Param("name")
+	if !s.flags.Known(name) {
+		apperror.Message(c, http.StatusNotFound, apperror.CodeNotFound, "unknown feature flag")
+		return
+	}
+
+	var req featureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.JSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.flags.Set(name, *req.Enabled)
+	s.logger.Warn("Feature flag overridden", zap.String("flag", name), zap.Bool("enabled", *req.Enabled))
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"name": name, "enabled": *req.Enabled}})
+}
+
+// ResetFeatureFlag godoc
+// @Summary Reset a feature flag
+// @Description Drop a runtime override so the flag falls back to its configured value
+// @Tags admin
+// @Produce json
+// @Param name path string true "Feature flag name"
+// @Success 200 {object} map[string]interface{} "Feature flag after the reset"
+// @Failure 404 {object} map[string]interface{} "Unknown feature flag"
+// @Router /admin/feature-flags/{name} [delete]
+func (s *Server) resetFeatureFlag(c *gin.Context) {
+	name := c.Param("name")
+	if !s.flags.Known(name) {
+		apperror.Message(c, http.StatusNotFound, apperror.CodeNotFound, "unknown feature flag")
+		return
+	}
+
+	s.flags.Reset(name)
+	enabled := s.flags.IsEnabled(name)
+	s.logger.Warn("Feature flag override reset", zap.String("flag", name), zap.Bool("enabled", enabled))
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"name": name, "enabled": enabled}})
+}
+
+// GetTasks godoc
+// @Summary List worker task types
+// @Description List the registered background task types with the JSON schema of their payloads
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Registered task types"
+// @Router /admin/tasks [get]
+func (s *Server) getTasks(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": s.tasks.Tasks()})
+}
+
+// PurgeSoftDeleted godoc
+// @Summary Purge soft-deleted rows
+// @Description Enqueue a run of the worker's purge, hard-deleting users and payments soft-deleted longer than the retention. The retention defaults to purge.retention.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body purgeRequest false "Retention override"
+// @Success 202 {object} map[string]interface{} "Enqueued purge task"
+// @Failure 400 {object} map[string]interface{} "Invalid retention"
+// @Failure 503 {object} map[string]interface{} "Task queue unavailable"
+// @Router /admin/purge [post]
+func (s *Server) purgeSoftDeleted(c *gin.Context) {
+	var req purgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apperror.JSON(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var retention time.Duration
+	if req.Retention != "" {
+		parsed, err := time.ParseDuration(req.Retention)
+		if err != nil || parsed <= 0 {
+			apperror.Message(c, http.StatusBadRequest, apperror.CodeInvalidRequest,
+				"retention must be a positive duration such as 720h")
+			return
+		}
+		retention = parsed
+	}
+
+	task, err := purge.NewTask(retention)
+	if err != nil {
+		apperror.Message(c, http.StatusInternalServerError, apperror.CodeInternal, "Failed to enqueue purge")
+		return
+	}
+	info, err := s.enqueuer.Enqueue(task, asynq.Queue("low"))
+	if err != nil {
+		s.logger.Error("Failed to enqueue purge", zap.Error(err))
+		apperror.Message(c, http.StatusServiceUnavailable, apperror.CodeUnavailable, "task queue unavailable")
+		return
+	}
+
+	s.logger.Warn("Purge of soft-deleted rows requested",
+		zap.String("task_id", info.ID),
+		zap.Duration("retention", retention))
+	c.JSON(http.StatusAccepted, gin.H{"data": gin.H{"task_id": info.ID, "retention": req.Retention}})
+}
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	paymentWorker "github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/worker"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/purge"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupAdminRouter() (*gin.Engine, zap.AtomicLevel) {
+	gin.SetMode(gin.TestMode)
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	server := &Server{logger: testutil.NewSilentLogger(), logLevel: level}
+	router := gin.New()
+	server.registerAdminRoutes(router.Group("/api/v1"))
+	return router, level
+}
+
+func TestServer_LogLevel(t *testing.T) {
+	t.Run("should return current level", func(t *testing.T) {
+		// Setup
+		router, _ := setupAdminRouter()
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/admin/log-level", nil))
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":{"level":"info"}}`, w.Body.String())
+	})
+
+	t.Run("should change level", func(t *testing.T) {
+		// Setup
+		router, level := setupAdminRouter()
+
+		// When
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/api/v1/admin/log-level", bytes.NewBufferString(`{"level":"debug"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":{"level":"debug"}}`, w.Body.String())
+		assert.Equal(t, zap.DebugLevel, level.Level())
+	})
+
+	t.Run("should reject invalid level", func(t *testing.T) {
+		// Setup
+		router, level := setupAdminRouter()
+
+		// When
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/api/v1/admin/log-level", bytes.NewBufferString(`{"level":"verbose"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, zap.InfoLevel, level.Level())
+	})
+
+	t.Run("should reject missing level", func(t *testing.T) {
+		// Setup
+		router, _ := setupAdminRouter()
+
+		// When
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/api/v1/admin/log-level", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func setupFeatureFlagRouter() (*gin.Engine, *featureflag.Flags) {
+	gin.SetMode(gin.TestMode)
+	flags := testutil.NewTestFlags()
+	server := &Server{logger: testutil.NewSilentLogger(), flags: flags}
+	router := gin.New()
+	server.registerAdminRoutes(router.Group("/api/v1"))
+	return router, flags
+}
+
+func TestServer_FeatureFlags(t *testing.T) {
+	t.Run("should list effective flags", func(t *testing.T) {
+		// Setup
+		router, flags := setupFeatureFlagRouter()
+		flags.Set(featureflag.UserCache, false)
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/admin/feature-flags", nil))
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t,
+			`{"data":{"schedule_on_create":true,"user_cache":false}}`,
+			w.Body.String())
+	})
+
+	t.Run("should override a flag", func(t *testing.T) {
+		// Setup
+		router, flags := setupFeatureFlagRouter()
+
+		// When
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/api/v1/admin/feature-flags/schedule_on_create", bytes.NewBufferString(`{"enabled":false}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":{"name":"schedule_on_create","enabled":false}}`, w.Body.String())
+		assert.False(t, flags.IsEnabled(featureflag.ScheduleOnCreate))
+	})
+
+	t.Run("should reset an override", func(t *testing.T) {
+		// Setup
+		router, flags := setupFeatureFlagRouter()
+		flags.Set(featureflag.ScheduleOnCreate, false)
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/v1/admin/feature-flags/schedule_on_create", nil))
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":{"name":"schedule_on_create","enabled":true}}`, w.Body.String())
+		assert.True(t, flags.IsEnabled(featureflag.ScheduleOnCreate))
+	})
+
+	t.Run("should reject unknown flags", func(t *testing.T) {
+		// Setup
+		router, flags := setupFeatureFlagRouter()
+
+		// When
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/api/v1/admin/feature-flags/no_such_flag", bytes.NewBufferString(`{"enabled":true}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.False(t, flags.IsEnabled("no_such_flag"))
+	})
+
+	t.Run("should reject a missing value", func(t *testing.T) {
+		// Setup
+		router, _ := setupFeatureFlagRouter()
+
+		// When
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/api/v1/admin/feature-flags/user_cache", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestServer_Tasks(t *testing.T) {
+	t.Run("should list registered task types with payload schemas", func(t *testing.T) {
+		// Setup
+		gin.SetMode(gin.TestMode)
+		registry := queue.NewTaskRegistry()
+		paymentWorker.RegisterTasks(registry)
+		server := &Server{logger: testutil.NewSilentLogger(), tasks: registry}
+		router := gin.New()
+		server.registerAdminRoutes(router.Group("/api/v1"))
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/admin/tasks", nil))
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data []struct {
+				Type   string `json:"type"`
+				Schema struct {
+					Type       string                     `json:"type"`
+					Properties map[string]json.RawMessage `json:"properties"`
+					Required   []string                   `json:"required"`
+				} `json:"schema"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		fields := make(map[string][]string)
+		for _, task := range response.Data {
+			assert.Equal(t, "object", task.Schema.Type)
+			for name := range task.Schema.Properties {
+				fields[task.Type] = append(fields[task.Type], name)
+			}
+		}
+		assert.Equal(t, map[string][]string{
+			paymentWorker.TypeCheckPaymentStatus:  {"payment_id"},
+			paymentWorker.TypeProcessPayment:      {"payment_id"},
+			paymentWorker.TypeBatchProcessPending: {"batch_size"},
+		}, fields)
+	})
+}
+
+type fakeEnqueuer struct {
+	tasks []*asynq.Task
+	err   error
+}
+
+func (f *fakeEnqueuer) Enqueue(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.tasks = append(f.tasks, task)
+	return &asynq.TaskInfo{ID: "task-1", Type: task.Type()}, nil
+}
+
+func TestServer_Purge(t *testing.T) {
+	setup := func(enqueuer *fakeEnqueuer) *gin.Engine {
+		gin.SetMode(gin.TestMode)
+		server := &Server{logger: testutil.NewSilentLogger(), enqueuer: enqueuer}
+		router := gin.New()
+		server.registerAdminRoutes(router.Group("/api/v1"))
+		return router
+	}
+
+	t.Run("should enqueue a purge with the requested retention", func(t *testing.T) {
+		// Setup
+		enqueuer := &fakeEnqueuer{}
+		router := setup(enqueuer)
+
+		// When
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/v1/admin/purge", bytes.NewBufferString(`{"retention":"48h"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		require.Len(t, enqueuer.tasks, 1)
+		assert.Equal(t, purge.TypePurgeSoftDeleted, enqueuer.tasks[0].Type())
+		assert.JSONEq(t, `{"retention":"48h0m0s"}`, string(enqueuer.tasks[0].Payload()))
+	})
+
+	t.Run("should enqueue a purge with the configured retention when the body is empty", func(t *testing.T) {
+		// Setup
+		enqueuer := &fakeEnqueuer{}
+		router := setup(enqueuer)
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/admin/purge", nil))
+
+		// Then
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		require.Len(t, enqueuer.tasks, 1)
+		assert.JSONEq(t, `{}`, string(enqueuer.tasks[0].Payload()))
+	})
+
+	t.Run("should reject a non-positive retention", func(t *testing.T) {
+		// Setup
+		enqueuer := &fakeEnqueuer{}
+		router := setup(enqueuer)
+
+		// When
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/v1/admin/purge", bytes.NewBufferString(`{"retention":"-1h"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, enqueuer.tasks)
+	})
+
+	t.Run("should return 503 when the queue is unavailable", func(t *testing.T) {
+		// Setup
+		router := setup(&fakeEnqueuer{err: errors.New("redis down")})
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/admin/purge", nil))
+
+		// Then
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
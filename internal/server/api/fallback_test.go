@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupFallbackRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/users", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": []string{}})
+	})
+	registerFallbackRoutes(router)
+	return router
+}
+
+func TestServer_FallbackRoutes(t *testing.T) {
+	t.Run("should answer unknown paths with a JSON 404", func(t *testing.T) {
+		// Setup
+		router := setupFallbackRouter()
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/nope", nil))
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+		assert.JSONEq(t, `{"error":"route not found","code":"NOT_FOUND"}`, w.Body.String())
+	})
+
+	t.Run("should answer a wrong method with a JSON 405", func(t *testing.T) {
+		// Setup
+		router := setupFallbackRouter()
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("PATCH", "/api/v1/users", nil))
+
+		// Then
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+		assert.JSONEq(t, `{"error":"method not allowed","code":"METHOD_NOT_ALLOWED"}`, w.Body.String())
+	})
+
+	t.Run("should still serve known routes", func(t *testing.T) {
+		// Setup
+		router := setupFallbackRouter()
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/users", nil))
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"context"
+
+	"github.com/novriyantoAli/wallet-ms-backend/api/proto/payment"
+	"github.com/novriyantoAli/wallet-ms-backend/api/proto/user"
+	paymentHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/handler"
+	userHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/handler"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// NewGatewayMux exposes the gRPC services over REST using the HTTP bindings in
+// api/proto/*/*_gateway.yaml. Calls are dispatched to the gRPC handlers in process.
+func NewGatewayMux(
+	userHandler *userHandler.UserGrpcHandler,
+	paymentHandler *paymentHandler.PaymentGrpcHandler,
+) (*runtime.ServeMux, error) {
+	// Keep field names snake_case, matching the REST API
+	mux := runtime.NewServeMux(runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+		UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+	}))
+
+	ctx := context.Background()
+	if err := user.RegisterUserServiceHandlerServer(ctx, mux, userHandler); err != nil {
+		return nil, err
+	}
+	if err := payment.RegisterPaymentServiceHandlerServer(ctx, mux, paymentHandler); err != nil {
+		return nil, err
+	}
+
+	return mux, nil
+}
+
+func (s *Server) registerGatewayRoutes(router *gin.Engine) {
+	router.Any("/gateway/*path", gin.WrapH(s.gateway))
+}
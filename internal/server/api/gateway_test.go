@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
+	paymentHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/handler"
+	paymentRepository "github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/repository"
+	paymentService "github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/service"
+	userHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/handler"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupGatewayRouter(t *testing.T) (*gin.Engine, *testutil.MockPaymentRepository) {
+	gin.SetMode(gin.TestMode)
+	logger := testutil.NewSilentLogger()
+	mockRepo := &testutil.MockPaymentRepository{}
+	mockUserService := &testutil.MockUserService{}
+
+	payments := paymentService.NewPaymentService(mockRepo, mockUserService, testutil.NewMockWalletService(), testutil.NewTestConfig(), testutil.NewTestFlags(), logger)
+	mux, err := NewGatewayMux(
+		userHandler.NewUserGrpcHandler(mockUserService, logger),
+		paymentHandler.NewPaymentGrpcHandler(payments, logger),
+	)
+	require.NoError(t, err)
+
+	server := &Server{gateway: mux, logger: logger}
+	router := gin.New()
+	server.registerGatewayRoutes(router)
+	return router, mockRepo
+}
+
+func TestServer_Gateway(t *testing.T) {
+	t.Run("should route a REST call to the payment gRPC handler", func(t *testing.T) {
+		// Setup
+		router, mockRepo := setupGatewayRouter(t)
+		mockRepo.On("GetByID", uint(7)).Return(&entity.Payment{
+			ID:       7,
+			Amount:   25,
+			Currency: "USD",
+			Status:   entity.PaymentStatusPending,
+			UserID:   3,
+		}, nil)
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/gateway/v1/payments/7", nil))
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertExpectations(t)
+
+		var response struct {
+			Payment map[string]interface{} `json:"payment"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, float64(7), response.Payment["id"])
+		assert.Equal(t, float64(3), response.Payment["user_id"])
+		assert.Equal(t, "USD", response.Payment["currency"])
+	})
+
+	t.Run("should map gRPC errors to HTTP status codes", func(t *testing.T) {
+		// Setup
+		router, mockRepo := setupGatewayRouter(t)
+		mockRepo.On("GetByID", uint(404)).Return(nil, paymentRepository.ErrNotFound)
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/gateway/v1/payments/404", nil))
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should return not found for unmapped paths", func(t *testing.T) {
+		// Setup
+		router, _ := setupGatewayRouter(t)
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/gateway/v1/unknown", nil))
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
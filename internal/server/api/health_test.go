@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/health"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeInspector reports a fixed number of pending tasks per queue
+type fakeInspector struct {
+	pending map[string]int
+	err     error
+}
+
+func (f *fakeInspector) Queues() ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	queues := make([]string, 0, len(f.pending))
+	for queue := range f.pending {
+		queues = append(queues, queue)
+	}
+	return queues, nil
+}
+
+func (f *fakeInspector) GetQueueInfo(queue string) (*asynq.QueueInfo, error) {
+	return &asynq.QueueInfo{Queue: queue, Pending: f.pending[queue]}, nil
+}
+
+func setupHealthRouter(maxPending int, inspector queue.Inspector) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := testutil.NewTestConfig()
+	cfg.Worker.ReadinessMaxPending = maxPending
+	server := &Server{cfg: cfg, inspector: inspector, logger: testutil.NewSilentLogger()}
+	router := gin.New()
+	server.registerHealthRoutes(router.Group("/api/v1"))
+	return router
+}
+
+func TestServer_ReadinessCheck(t *testing.T) {
+	ready := func(router *gin.Engine) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/health/ready", nil))
+		return w
+	}
+
+	t.Run("should not consult the queue when the threshold is disabled", func(t *testing.T) {
+		// Setup: an inspector that would fail if it were asked
+		router := setupHealthRouter(0, &fakeInspector{err: errors.New("redis down")})
+
+		// When
+		w := ready(router)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"ready","checks":{"database":"ok","cache":"ok"}}`, w.Body.String())
+	})
+
+	t.Run("should be ready while the backlog is within the threshold", func(t *testing.T) {
+		// Setup
+		router := setupHealthRouter(100, &fakeInspector{pending: map[string]int{"critical": 40, "default": 60}})
+
+		// When
+		w := ready(router)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"ready","checks":{"database":"ok","cache":"ok","queue":"ok","queue_pending":100}}`, w.Body.String())
+	})
+
+	t.Run("should flip to not ready when the backlog exceeds the threshold", func(t *testing.T) {
+		// Setup
+		router := setupHealthRouter(100, &fakeInspector{pending: map[string]int{"critical": 5, "default": 5000}})
+
+		// When
+		w := ready(router)
+
+		// Then
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.JSONEq(t, `{"status":"not ready","checks":{"database":"ok","cache":"ok","queue":"backlog","queue_pending":5005}}`, w.Body.String())
+	})
+
+	t.Run("should be not ready when the queue cannot be inspected", func(t *testing.T) {
+		// Setup
+		router := setupHealthRouter(100, &fakeInspector{err: errors.New("redis down")})
+
+		// When
+		w := ready(router)
+
+		// Then
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.JSONEq(t, `{"status":"not ready","checks":{"database":"ok","cache":"ok","queue":"unreachable"}}`, w.Body.String())
+	})
+}
+
+func TestServer_HealthCheck(t *testing.T) {
+	setup := func(checks ...health.Check) *gin.Engine {
+		gin.SetMode(gin.TestMode)
+		server := &Server{cfg: testutil.NewTestConfig(), healthChecks: checks, logger: testutil.NewSilentLogger()}
+		server.cfg.Server.HealthCheckTimeout = time.Second
+		router := gin.New()
+		server.registerHealthRoutes(router.Group("/api/v1"))
+		return router
+	}
+	up := func(context.Context) error { return nil }
+
+	type report struct {
+		Status string                   `json:"status"`
+		Checks map[string]health.Result `json:"checks"`
+	}
+
+	t.Run("should be healthy when every dependency is up", func(t *testing.T) {
+		// Setup
+		router := setup(health.Check{Name: "database", Run: up}, health.Check{Name: "redis", Run: up}, health.Check{Name: "queue", Run: up})
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/health", nil))
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body report
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, health.StatusHealthy, body.Status)
+		assert.Len(t, body.Checks, 3)
+	})
+
+	t.Run("should report each dependency and 503 when one is down", func(t *testing.T) {
+		// Setup
+		router := setup(
+			health.Check{Name: "database", Run: up},
+			health.Check{Name: "redis", Run: func(context.Context) error { return errors.New("dial tcp: connection refused") }},
+			health.Check{Name: "queue", Run: up},
+		)
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/health", nil))
+
+		// Then
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		var body report
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, health.StatusUnhealthy, body.Status)
+		assert.Equal(t, health.StatusUp, body.Checks["database"].Status)
+		assert.Equal(t, health.StatusUp, body.Checks["queue"].Status)
+		assert.Equal(t, health.StatusDown, body.Checks["redis"].Status)
+		assert.Equal(t, "dial tcp: connection refused", body.Checks["redis"].Error)
+	})
+}
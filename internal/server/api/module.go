@@ -1,14 +1,26 @@
 package api
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 
 	paymentHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/handler"
 	userHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/handler"
+	walletHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/handler"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/middleware"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/apperror"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/featureflag"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/health"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/shutdown"
 
 	_ "github.com/novriyantoAli/wallet-ms-backend/docs" // This will be generated by swag
 )
@@ -16,25 +28,76 @@ import (
 type Server struct {
 	userHandler    *userHandler.UserHandler
 	paymentHandler *paymentHandler.PaymentHandler
+	webhookHandler *paymentHandler.WebhookHandler
+	walletHandler  *walletHandler.WalletHandler
+	gateway        *runtime.ServeMux
+	shuttingDown   *shutdown.Flag
+	flags          *featureflag.Flags
+	tasks          *queue.TaskRegistry
+	inspector      queue.Inspector
+	enqueuer       taskEnqueuer
+	healthChecks   []health.Check
+	cfg            *config.Config
 	logger         *zap.Logger
+	logLevel       zap.AtomicLevel
 }
 
 func NewServer(
 	userHandler *userHandler.UserHandler,
 	paymentHandler *paymentHandler.PaymentHandler,
+	webhookHandler *paymentHandler.WebhookHandler,
+	walletHandler *walletHandler.WalletHandler,
+	gateway *runtime.ServeMux,
+	shuttingDown *shutdown.Flag,
+	flags *featureflag.Flags,
+	tasks *queue.TaskRegistry,
+	inspector queue.Inspector,
+	client *queue.Client,
+	db *gorm.DB,
+	cfg *config.Config,
 	logger *zap.Logger,
+	logLevel zap.AtomicLevel,
 ) *Server {
+	healthChecks := []health.Check{
+		{Name: "database", Run: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}},
+		{Name: "redis", Run: queue.RedisCheck(cfg)},
+		{Name: "queue", Run: func(context.Context) error {
+			_, err := inspector.Queues()
+			return err
+		}},
+	}
+
 	return &Server{
 		userHandler:    userHandler,
 		paymentHandler: paymentHandler,
+		webhookHandler: webhookHandler,
+		walletHandler:  walletHandler,
+		gateway:        gateway,
+		shuttingDown:   shuttingDown,
+		flags:          flags,
+		tasks:          tasks,
+		inspector:      inspector,
+		enqueuer:       client,
+		healthChecks:   healthChecks,
+		cfg:            cfg,
 		logger:         logger,
+		logLevel:       logLevel,
 	}
 }
 
 func (s *Server) SetupRoutes(router *gin.Engine) {
 	// Apply global middleware
-	router.Use(middleware.Logger(s.logger))
-	router.Use(middleware.Recovery(s.logger))
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Locale(s.cfg.Server.DefaultLocale))
+	router.Use(middleware.Logger(s.logger, s.cfg.Server.LogRequestBodies, s.cfg.Logger.RedactKeys))
+	router.Use(middleware.Recovery(s.logger, s.cfg.Logger.RedactKeys))
+	router.Use(middleware.RejectWhenShuttingDown(&s.shuttingDown.Bool, config.DefaultStopTimeout))
 	router.Use(middleware.CORS())
 
 	// Swagger documentation routes
@@ -47,9 +110,31 @@ func (s *Server) SetupRoutes(router *gin.Engine) {
 	api := router.Group("/api/v1")
 	{
 		s.registerHealthRoutes(api)
+		s.registerAdminRoutes(api)
 		s.userHandler.RegisterRoutes(api)
 		s.paymentHandler.RegisterRoutes(api)
+		s.webhookHandler.RegisterRoutes(api)
+		s.walletHandler.RegisterRoutes(api)
 	}
+
+	// gRPC services over REST
+	s.registerGatewayRoutes(router)
+
+	s.registerPprofRoutes(router)
+
+	registerFallbackRoutes(router)
+}
+
+// registerFallbackRoutes answers unknown paths with 404 and known paths called with the
+// wrong method with 405, both in the usual JSON error envelope instead of gin's text
+func registerFallbackRoutes(router *gin.Engine) {
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(func(c *gin.Context) {
+		apperror.Message(c, http.StatusNotFound, apperror.CodeNotFound, "route not found")
+	})
+	router.NoMethod(func(c *gin.Context) {
+		apperror.Message(c, http.StatusMethodNotAllowed, apperror.CodeMethodNotAllowed, "method not allowed")
+	})
 }
 
 func (s *Server) registerHealthRoutes(api *gin.RouterGroup) {
@@ -62,34 +147,69 @@ func (s *Server) registerHealthRoutes(api *gin.RouterGroup) {
 
 // HealthCheck godoc
 // @Summary Show the status of server.
-// @Description get the status of server.
+// @Description get the status of the server and each dependency (database, redis, queue) with its latency. The checks run concurrently, each bounded by api.health_check_timeout.
 // @Tags health
 // @Accept */*
 // @Produce json
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} map[string]interface{} "Every dependency is up"
+// @Failure 503 {object} map[string]interface{} "At least one dependency is down"
 // @Router /health [get]
 func (s *Server) healthCheck(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"status":  "healthy",
+	report := health.Run(c.Request.Context(), s.cfg.Server.HealthCheckTimeout, s.healthChecks...)
+
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+		for name, check := range report.Checks {
+			if check.Status != health.StatusUp {
+				s.logger.Warn("Health check failed", zap.String("check", name), zap.String("error", check.Error))
+			}
+		}
+	}
+
+	c.JSON(status, gin.H{
+		"status":  report.Status,
 		"service": "github.com/novriyantoAli/wallet-ms-backend-api",
 		"version": "1.0.0",
+		"checks":  report.Checks,
 	})
 }
 
 // ReadinessCheck godoc
 // @Summary Show the readiness of server.
-// @Description get the readiness of server.
+// @Description get the readiness of server. When worker.readiness_max_pending is set, the server reports not ready while more tasks than that wait in the queues.
 // @Tags health
 // @Accept */*
 // @Produce json
 // @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{} "Queue backlog over the threshold or queue unreachable"
 // @Router /health/ready [get]
 func (s *Server) readinessCheck(c *gin.Context) {
-	c.JSON(200, gin.H{
+	checks := gin.H{
+		"database": "ok",
+		"cache":    "ok",
+	}
+
+	if maxPending := s.cfg.Worker.ReadinessMaxPending; maxPending > 0 {
+		pending, err := queue.PendingTasks(s.inspector)
+		if err != nil {
+			s.logger.Warn("Readiness queue check failed", zap.Error(err))
+			checks["queue"] = "unreachable"
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "checks": checks})
+			return
+		}
+
+		checks["queue_pending"] = pending
+		if pending > maxPending {
+			checks["queue"] = "backlog"
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "checks": checks})
+			return
+		}
+		checks["queue"] = "ok"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
 		"status": "ready",
-		"checks": gin.H{
-			"database": "ok",
-			"cache":    "ok",
-		},
+		"checks": checks,
 	})
 }
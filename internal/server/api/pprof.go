@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerPprofRoutes mounts the net/http/pprof handlers under /debug/pprof when
+// api.enable_pprof is set; otherwise the paths are not routed and answer 404.
+func (s *Server) registerPprofRoutes(router *gin.Engine) {
+	if !s.cfg.Server.EnablePprof {
+		return
+	}
+
+	debug := router.Group("/debug/pprof")
+	{
+		debug.GET("/", gin.WrapF(pprof.Index))
+		debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+		debug.GET("/profile", gin.WrapF(pprof.Profile))
+		debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+		debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/trace", gin.WrapF(pprof.Trace))
+		// Index serves the named profiles (heap, goroutine, allocs, ...) by path
+		debug.GET("/:profile", gin.WrapF(pprof.Index))
+	}
+	s.logger.Warn("pprof endpoints enabled under /debug/pprof")
+}
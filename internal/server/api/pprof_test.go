@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupPprofRouter(enabled bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{Server: config.ServerConfig{EnablePprof: enabled}}
+	server := &Server{cfg: cfg, logger: testutil.NewSilentLogger()}
+	router := gin.New()
+	server.registerPprofRoutes(router)
+	return router
+}
+
+func TestServer_Pprof(t *testing.T) {
+	paths := []string{
+		"/debug/pprof/",
+		"/debug/pprof/cmdline",
+		"/debug/pprof/heap?debug=1",
+		"/debug/pprof/goroutine?debug=1",
+	}
+
+	t.Run("should serve profiles when enabled", func(t *testing.T) {
+		// Setup
+		router := setupPprofRouter(true)
+
+		for _, path := range paths {
+			// When
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+			// Then
+			assert.Equal(t, http.StatusOK, w.Code, path)
+			assert.NotEmpty(t, w.Body.String(), path)
+		}
+	})
+
+	t.Run("should return 404 for an unknown profile", func(t *testing.T) {
+		// Setup
+		router := setupPprofRouter(true)
+
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/debug/pprof/nope", nil))
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should return 404 when disabled", func(t *testing.T) {
+		// Setup
+		router := setupPprofRouter(false)
+
+		for _, path := range paths {
+			// When
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+			// Then
+			assert.Equal(t, http.StatusNotFound, w.Code, path)
+		}
+	})
+}
@@ -2,7 +2,13 @@ package api
 
 import (
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment"
+	paymentHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/handler"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user"
+	userHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/handler"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/idempotency"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/purge"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
 
 	"go.uber.org/fx"
 )
@@ -11,7 +17,22 @@ var Module = fx.Options(
 	// Include all domain modules
 	user.Module,
 	payment.Module,
+	wallet.Module,
 
 	// API api
-	fx.Provide(NewServer),
+	fx.Provide(
+		userHandler.NewUserGrpcHandler,
+		paymentHandler.NewPaymentGrpcHandler,
+		NewGatewayMux,
+		queue.NewInspector,
+		idempotency.NewStore,
+		NewServer,
+	),
+	// List the worker's maintenance tasks under /admin/tasks
+	fx.Invoke(
+		func(registry *queue.TaskRegistry) {
+			idempotency.RegisterTasks(registry)
+		},
+		purge.RegisterTasks,
+	),
 )
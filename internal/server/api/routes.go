@@ -0,0 +1,44 @@
+package api
+
+import (
+	"sort"
+
+	paymentHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/handler"
+	userHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/handler"
+	walletHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/handler"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/shutdown"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.uber.org/zap"
+)
+
+// Routes lists the routes SetupRoutes registers for cfg, sorted by path then method.
+// Registering a route only takes the handler's method value, so the server is built
+// with empty handlers and needs no database, Redis or other dependency.
+func Routes(cfg *config.Config) []gin.RouteInfo {
+	server := &Server{
+		userHandler:    &userHandler.UserHandler{},
+		paymentHandler: &paymentHandler.PaymentHandler{},
+		webhookHandler: &paymentHandler.WebhookHandler{},
+		walletHandler:  &walletHandler.WalletHandler{},
+		gateway:        runtime.NewServeMux(),
+		shuttingDown:   &shutdown.Flag{},
+		cfg:            cfg,
+		logger:         zap.NewNop(),
+		logLevel:       zap.NewAtomicLevel(),
+	}
+
+	router := gin.New()
+	server.SetupRoutes(router)
+
+	routes := router.Routes()
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
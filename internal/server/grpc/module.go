@@ -52,9 +52,9 @@ func (s *Server) RegisterServices() {
 	s.logger.Info("gRPC services registered successfully")
 }
 
+// Start opens the listener and serves in the background. Listen errors, such as a port
+// already in use, are returned so the application fails to start.
 func (s *Server) Start(port string) error {
-	s.logger.Info("Starting gRPC api", zap.String("port", port))
-
 	listener, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		s.logger.Error("Failed to listen on port", zap.String("port", port), zap.Error(err))
@@ -63,8 +63,16 @@ func (s *Server) Start(port string) error {
 
 	s.RegisterServices()
 
-	s.logger.Info("gRPC api listening", zap.String("address", listener.Addr().String()))
-	return s.server.Serve(listener)
+	s.logger.Info("gRPC server listening",
+		zap.String("network", listener.Addr().Network()),
+		zap.String("addr", listener.Addr().String()))
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil {
+			s.logger.Fatal("gRPC server failed", zap.Error(err))
+		}
+	}()
+	return nil
 }
 
 func (s *Server) Stop() {
@@ -5,6 +5,7 @@ import (
 	paymentHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/handler"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user"
 	userHandler "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/handler"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet"
 
 	"go.uber.org/fx"
 )
@@ -13,6 +14,7 @@ var Module = fx.Options(
 	// Include domain modules
 	user.Module,
 	payment.Module,
+	wallet.WorkerModule,
 
 	// gRPC handlers
 	fx.Provide(
@@ -0,0 +1,79 @@
+package migration
+
+import (
+	"context"
+	"time"
+
+	userEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	walletEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultBackfillBatchSize keeps each backfill transaction short on large user tables
+const defaultBackfillBatchSize = 500
+
+// BackfillWallets creates a wallet in the configured default currency for every user
+// without one. Users are walked by ID in batches, each in its own transaction, so a
+// canceled run keeps the batches it finished and running it again only fills the gaps.
+// It returns how many wallets were created.
+func (s *Server) BackfillWallets(ctx context.Context) (int, error) {
+	currency := s.cfg.Wallet.DefaultCurrency
+	s.logger.Info("Starting wallet backfill",
+		zap.String("currency", currency),
+		zap.Int("batch_size", s.backfillBatchSize))
+
+	created := 0
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return created, err
+		}
+
+		var userIDs []uint
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			err := tx.Model(&userEntity.User{}).
+				Where("id > ?", lastID).
+				Where("NOT EXISTS (?)", tx.Model(&walletEntity.Wallet{}).Select("1").Where("wallets.user_id = users.id")).
+				Order("id").
+				Limit(s.backfillBatchSize).
+				Pluck("id", &userIDs).Error
+			if err != nil || len(userIDs) == 0 {
+				return err
+			}
+
+			now := time.Now().UTC()
+			wallets := make([]walletEntity.Wallet, 0, len(userIDs))
+			for _, userID := range userIDs {
+				wallets = append(wallets, walletEntity.Wallet{
+					UserID:    userID,
+					Currency:  currency,
+					CreatedAt: now,
+					UpdatedAt: now,
+				})
+			}
+
+			// A wallet created since the select, e.g. by a concurrent run, is left alone
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&wallets)
+			if result.Error != nil {
+				return result.Error
+			}
+			created += int(result.RowsAffected)
+			return nil
+		})
+		if err != nil {
+			s.logger.Error("Wallet backfill failed", zap.Uint("after_user_id", lastID), zap.Error(err))
+			return created, err
+		}
+
+		if len(userIDs) < s.backfillBatchSize {
+			break
+		}
+		lastID = userIDs[len(userIDs)-1]
+	}
+
+	s.logger.Info("Wallet backfill completed", zap.Int("created", created))
+	return created, nil
+}
@@ -0,0 +1,95 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	userEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	walletEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_BackfillWallets(t *testing.T) {
+	// Setup
+	db, err := testutil.SetupTestDB()
+	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
+	cfg := testutil.NewTestConfig()
+	cfg.Wallet.DefaultCurrency = "USD"
+	server := NewServer(db, cfg, testutil.NewSilentLogger())
+	// Smaller than the number of users so the backfill spans several batches
+	server.backfillBatchSize = 2
+
+	// Given: five users without wallets, one with a EUR wallet and one deleted user
+	seedUser := func(i int) *userEntity.User {
+		user := testutil.CreateUserFixture()
+		user.ID = 0
+		user.Email = fmt.Sprintf("user%d@example.com", i)
+		require.NoError(t, db.Create(user).Error)
+		return user
+	}
+	var withoutWallet []uint
+	for i := 0; i < 5; i++ {
+		withoutWallet = append(withoutWallet, seedUser(i).ID)
+	}
+	withWallet := seedUser(5)
+	require.NoError(t, db.Create(&walletEntity.Wallet{UserID: withWallet.ID, Currency: "EUR"}).Error)
+	deleted := seedUser(6)
+	require.NoError(t, db.Delete(deleted).Error)
+
+	walletsOf := func(userID uint) []walletEntity.Wallet {
+		var wallets []walletEntity.Wallet
+		require.NoError(t, db.Where("user_id = ?", userID).Find(&wallets).Error)
+		return wallets
+	}
+
+	t.Run("should give every user without a wallet exactly one", func(t *testing.T) {
+		// When
+		created, err := server.BackfillWallets(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 5, created)
+		for _, userID := range withoutWallet {
+			wallets := walletsOf(userID)
+			require.Len(t, wallets, 1, "user %d", userID)
+			assert.Equal(t, "USD", wallets[0].Currency)
+			assert.Zero(t, wallets[0].Balance)
+		}
+
+		// Users who already had a wallet, and deleted users, are left alone
+		existing := walletsOf(withWallet.ID)
+		require.Len(t, existing, 1)
+		assert.Equal(t, "EUR", existing[0].Currency)
+		assert.Empty(t, walletsOf(deleted.ID))
+	})
+
+	t.Run("should be a no-op when run again", func(t *testing.T) {
+		// When
+		created, err := server.BackfillWallets(context.Background())
+
+		// Then
+		require.NoError(t, err)
+		assert.Zero(t, created)
+		var count int64
+		require.NoError(t, db.Model(&walletEntity.Wallet{}).Count(&count).Error)
+		assert.Equal(t, int64(6), count)
+	})
+
+	t.Run("should stop when the context is canceled", func(t *testing.T) {
+		// Given
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// When
+		created, err := server.BackfillWallets(ctx)
+
+		// Then
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Zero(t, created)
+	})
+}
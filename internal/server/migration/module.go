@@ -3,20 +3,28 @@ package migration
 import (
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/entity"
 	userEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/user/entity"
+	walletEntity "github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet/entity"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/idempotency"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/outbox"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 type Server struct {
-	db     *gorm.DB
-	logger *zap.Logger
+	db                *gorm.DB
+	cfg               *config.Config
+	logger            *zap.Logger
+	backfillBatchSize int
 }
 
-func NewServer(db *gorm.DB, logger *zap.Logger) *Server {
+func NewServer(db *gorm.DB, cfg *config.Config, logger *zap.Logger) *Server {
 	return &Server{
-		db:     db,
-		logger: logger,
+		db:                db,
+		cfg:               cfg,
+		logger:            logger,
+		backfillBatchSize: defaultBackfillBatchSize,
 	}
 }
 
@@ -27,6 +35,14 @@ func (s *Server) RunMigrations() error {
 	err := s.db.AutoMigrate(
 		&userEntity.User{},
 		&entity.Payment{},
+		&entity.PaymentTag{},
+		&entity.ProcessedEvent{},
+		&entity.PaymentStatusChange{},
+		&entity.PaymentSequence{},
+		&walletEntity.Wallet{},
+		&walletEntity.Transaction{},
+		&outbox.Message{},
+		&idempotency.Key{},
 	)
 	if err != nil {
 		s.logger.Error("Failed to run database migrations", zap.Error(err))
@@ -53,6 +69,14 @@ func (s *Server) DropTables() error {
 	err := s.db.Migrator().DropTable(
 		&userEntity.User{},
 		&entity.Payment{},
+		&entity.PaymentTag{},
+		&entity.ProcessedEvent{},
+		&entity.PaymentStatusChange{},
+		&entity.PaymentSequence{},
+		&walletEntity.Wallet{},
+		&walletEntity.Transaction{},
+		&outbox.Message{},
+		&idempotency.Key{},
 	)
 	if err != nil {
 		s.logger.Error("Failed to drop database tables", zap.Error(err))
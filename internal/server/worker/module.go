@@ -2,6 +2,8 @@ package worker
 
 import (
 	paymentWorker "github.com/novriyantoAli/wallet-ms-backend/internal/application/payment/worker"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/idempotency"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/purge"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
 
 	"github.com/hibiken/asynq"
@@ -10,17 +12,23 @@ import (
 
 type Server struct {
 	paymentWorker *paymentWorker.PaymentWorker
+	cleaner       *idempotency.Cleaner
+	purger        *purge.Purger
 	queueServer   *queue.Server
 	logger        *zap.Logger
 }
 
 func NewServer(
 	paymentWorker *paymentWorker.PaymentWorker,
+	cleaner *idempotency.Cleaner,
+	purger *purge.Purger,
 	queueServer *queue.Server,
 	logger *zap.Logger,
 ) *Server {
 	return &Server{
 		paymentWorker: paymentWorker,
+		cleaner:       cleaner,
+		purger:        purger,
 		queueServer:   queueServer,
 		logger:        logger,
 	}
@@ -30,15 +38,22 @@ func (s *Server) RegisterHandlers() {
 	s.logger.Info("Registering worker handlers")
 
 	// Register payment workers
-	s.queueServer.RegisterHandler(
-		paymentWorker.TypeCheckPaymentStatus,
-		asynq.HandlerFunc(s.paymentWorker.HandleCheckPaymentStatus),
-	)
+	handlers := []struct {
+		taskType string
+		handler  asynq.HandlerFunc
+	}{
+		{paymentWorker.TypeCheckPaymentStatus, s.paymentWorker.HandleCheckPaymentStatus},
+		{paymentWorker.TypeProcessPayment, s.paymentWorker.HandleProcessPayment},
+		{paymentWorker.TypeBatchProcessPending, s.paymentWorker.HandleBatchProcessPending},
+		{idempotency.TypeCleanupIdempotencyKeys, s.cleaner.HandleCleanupKeys},
+		{purge.TypePurgeSoftDeleted, s.purger.HandlePurgeSoftDeleted},
+	}
 
-	s.queueServer.RegisterHandler(
-		paymentWorker.TypeProcessPayment,
-		asynq.HandlerFunc(s.paymentWorker.HandleProcessPayment),
-	)
+	taskTypes := make([]string, 0, len(handlers))
+	for _, h := range handlers {
+		s.queueServer.RegisterHandler(h.taskType, h.handler)
+		taskTypes = append(taskTypes, h.taskType)
+	}
 
-	s.logger.Info("Worker handlers registered successfully")
+	s.logger.Info("Worker handlers registered", zap.Strings("task_types", taskTypes))
 }
@@ -3,14 +3,36 @@ package worker
 import (
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/payment"
 	"github.com/novriyantoAli/wallet-ms-backend/internal/application/user"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/application/wallet"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/config"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/idempotency"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/purge"
+	"github.com/novriyantoAli/wallet-ms-backend/internal/pkg/queue"
 
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 )
 
 var Module = fx.Options(
 	// Include domain worker modules
 	payment.WorkerModule,
 	user.WorkerModule,
+	wallet.WorkerModule,
+
+	// Expired idempotency key cleanup
+	fx.Provide(idempotency.NewCleaner),
+	fx.Invoke(
+		func(registry *queue.TaskRegistry) {
+			idempotency.RegisterTasks(registry)
+		},
+		func(scheduler *queue.Scheduler, cfg *config.Config, logger *zap.Logger) error {
+			return idempotency.ScheduleCleanup(scheduler, cfg, logger)
+		},
+	),
+
+	// Hard-delete long soft-deleted rows
+	fx.Provide(purge.NewPurger),
+	fx.Invoke(purge.RegisterTasks, purge.Schedule),
 
 	// Worker api
 	fx.Provide(NewServer),
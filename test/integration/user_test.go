@@ -18,18 +18,19 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func setupUserIntegration(t *testing.T) (*gin.Engine, func()) {
+func setupUserIntegration(t *testing.T) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 
 	// Setup test database
 	db, err := testutil.SetupTestDB()
 	require.NoError(t, err)
+	testutil.WithCleanDB(t, db)
 
 	logger := testutil.NewTestLogger(t)
 
 	// Create real instances (no mocks)
 	userRepo := repository.NewUserRepository(db, logger)
-	userService := service.NewUserService(userRepo, logger)
+	userService := service.NewUserService(userRepo, testutil.NewTestConfig(), logger)
 	userHandler := handler.NewUserHandler(userService, logger)
 
 	// Setup Gin router
@@ -37,16 +38,11 @@ func setupUserIntegration(t *testing.T) (*gin.Engine, func()) {
 	api := router.Group("/api/v1")
 	userHandler.RegisterRoutes(api)
 
-	cleanup := func() {
-		testutil.CleanDB(db)
-	}
-
-	return router, cleanup
+	return router
 }
 
 func TestUserIntegration_CreateAndGetUser(t *testing.T) {
-	router, cleanup := setupUserIntegration(t)
-	defer cleanup()
+	router := setupUserIntegration(t)
 
 	// Test data
 	createReq := &dto.CreateUserRequest{
@@ -93,8 +89,7 @@ func TestUserIntegration_CreateAndGetUser(t *testing.T) {
 }
 
 func TestUserIntegration_CreateDuplicateEmail(t *testing.T) {
-	router, cleanup := setupUserIntegration(t)
-	defer cleanup()
+	router := setupUserIntegration(t)
 
 	// Test data
 	createReq := &dto.CreateUserRequest{
@@ -127,8 +122,7 @@ func TestUserIntegration_CreateDuplicateEmail(t *testing.T) {
 }
 
 func TestUserIntegration_GetUsers(t *testing.T) {
-	router, cleanup := setupUserIntegration(t)
-	defer cleanup()
+	router := setupUserIntegration(t)
 
 	// Create multiple users
 	users := []dto.CreateUserRequest{
@@ -167,8 +161,7 @@ func TestUserIntegration_GetUsers(t *testing.T) {
 }
 
 func TestUserIntegration_UpdateUser(t *testing.T) {
-	router, cleanup := setupUserIntegration(t)
-	defer cleanup()
+	router := setupUserIntegration(t)
 
 	// Create user
 	createReq := &dto.CreateUserRequest{
@@ -217,8 +210,7 @@ func TestUserIntegration_UpdateUser(t *testing.T) {
 }
 
 func TestUserIntegration_DeleteUser(t *testing.T) {
-	router, cleanup := setupUserIntegration(t)
-	defer cleanup()
+	router := setupUserIntegration(t)
 
 	// Create user
 	createReq := &dto.CreateUserRequest{